@@ -36,7 +36,10 @@ func main() {
 	fmt.Println("done")
 
 	// Initialize repositories
+	uow := repository.NewUnitOfWork(pool)
 	userRepo := repository.NewUserRepository(pool)
+	sessionRepo := repository.NewSessionRepository(pool)
+	householdRepo := repository.NewHouseholdRepository(pool)
 	accountRepo := repository.NewAccountRepository(pool)
 	categoryRepo := repository.NewCategoryRepository(pool)
 	transactionRepo := repository.NewTransactionRepository(pool)
@@ -44,16 +47,20 @@ func main() {
 	savingGoalRepo := repository.NewSavingGoalRepository(pool)
 	billReminderRepo := repository.NewBillReminderRepository(pool)
 	allowanceRepo := repository.NewAllowanceRepository(pool)
+	categorizationRuleRepo := repository.NewCategorizationRuleRepository(pool)
+	auditRepo := repository.NewAuditRepository(pool)
+	notificationRepo := repository.NewNotificationRepository(pool)
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, cfg.JWT.Secret)
-	accountService := service.NewAccountService(accountRepo)
+	authService := service.NewAuthService(uow, userRepo, sessionRepo, householdRepo, auditRepo, cfg.JWT.Secret)
+	accountService := service.NewAccountService(uow, accountRepo, auditRepo)
 	categoryService := service.NewCategoryService(categoryRepo)
-	transactionService := service.NewTransactionService(transactionRepo, accountRepo)
-	budgetService := service.NewBudgetService(budgetRepo)
-	savingGoalService := service.NewSavingGoalService(savingGoalRepo)
-	billReminderService := service.NewBillReminderService(billReminderRepo, transactionRepo, accountRepo)
-	allowanceService := service.NewAllowanceService(allowanceRepo)
+	budgetService := service.NewBudgetService(uow, budgetRepo, auditRepo)
+	notificationService := service.NewNotificationService(notificationRepo, userRepo, budgetService, allowanceRepo, billReminderRepo)
+	transactionService := service.NewTransactionService(uow, transactionRepo, accountRepo, userRepo, categorizationRuleRepo, auditRepo, notificationService)
+	allowanceService := service.NewAllowanceService(uow, allowanceRepo, transactionService, accountRepo, auditRepo)
+	savingGoalService := service.NewSavingGoalService(uow, savingGoalRepo, transactionService, auditRepo)
+	billReminderService := service.NewBillReminderService(uow, billReminderRepo, transactionService, auditRepo)
 
 	ctx := context.Background()
 	now := time.Now()
@@ -62,15 +69,17 @@ func main() {
 	fmt.Print("Seeding users... ")
 
 	admin, err := authService.Register(ctx, &model.RegisterRequest{
-		Email:    "admin@family.com",
-		Password: "password123",
-		Name:     "Arturas",
+		Email:         "admin@family.com",
+		Password:      "password123",
+		Name:          "Arturas",
+		HouseholdName: "Family",
 	})
 	if err != nil {
 		log.Fatalf("Failed to register admin: %v", err)
 	}
+	householdID := admin.HouseholdID
 
-	member, err := authService.CreateUser(ctx, &model.CreateUserRequest{
+	member, err := authService.CreateUser(ctx, householdID, &model.CreateUserRequest{
 		Email:    "wife@family.com",
 		Password: "password123",
 		Name:     "Greta",
@@ -80,7 +89,7 @@ func main() {
 		log.Fatalf("Failed to create member: %v", err)
 	}
 
-	morta, err := authService.CreateUser(ctx, &model.CreateUserRequest{
+	morta, err := authService.CreateUser(ctx, householdID, &model.CreateUserRequest{
 		Email:    "morta@family.com",
 		Password: "password123",
 		Name:     "Morta",
@@ -90,7 +99,7 @@ func main() {
 		log.Fatalf("Failed to create child Morta: %v", err)
 	}
 
-	agota, err := authService.CreateUser(ctx, &model.CreateUserRequest{
+	agota, err := authService.CreateUser(ctx, householdID, &model.CreateUserRequest{
 		Email:    "agota@family.com",
 		Password: "password123",
 		Name:     "Agota",
@@ -100,7 +109,7 @@ func main() {
 		log.Fatalf("Failed to create child Agota: %v", err)
 	}
 
-	pija, err := authService.CreateUser(ctx, &model.CreateUserRequest{
+	pija, err := authService.CreateUser(ctx, householdID, &model.CreateUserRequest{
 		Email:    "pija@family.com",
 		Password: "password123",
 		Name:     "Pija",
@@ -110,7 +119,7 @@ func main() {
 		log.Fatalf("Failed to create child Pija: %v", err)
 	}
 
-	juozas, err := authService.CreateUser(ctx, &model.CreateUserRequest{
+	juozas, err := authService.CreateUser(ctx, householdID, &model.CreateUserRequest{
 		Email:    "juozas@family.com",
 		Password: "password123",
 		Name:     "Juozas",
@@ -143,7 +152,7 @@ func main() {
 
 	categories := make(map[string]*model.Category) // name -> category
 	for i, cd := range catDefs {
-		cat, err := categoryService.Create(ctx, &model.CreateCategoryRequest{
+		cat, err := categoryService.Create(ctx, householdID, &model.CreateCategoryRequest{
 			Name:      cd.Name,
 			Type:      cd.Type,
 			Icon:      cd.Icon,
@@ -288,7 +297,7 @@ func main() {
 	}
 
 	for _, bd := range budgetDefs {
-		_, err := budgetService.Create(ctx, &model.CreateBudgetRequest{
+		_, err := budgetService.Create(ctx, householdID, &model.CreateBudgetRequest{
 			CategoryID: categories[bd.Category].ID,
 			Amount:     bd.Amount,
 			Month:      int(thisMonth),
@@ -307,21 +316,28 @@ func main() {
 	targetDate1 := fmt.Sprintf("%04d-12-31", thisYear)
 	targetDate2 := fmt.Sprintf("%04d-06-30", thisYear+1)
 
-	goal1, err := savingGoalService.Create(ctx, &model.CreateSavingGoalRequest{
+	savingsAccountID := accounts["Savings"].ID
+	checkingAccountID := accounts["Main Checking"].ID
+
+	goal1, err := savingGoalService.Create(ctx, householdID, &model.CreateSavingGoalRequest{
 		Name:         "Family Vacation",
 		TargetAmount: 300000,
+		AccountID:    &savingsAccountID,
 		TargetDate:   &targetDate1,
 		Priority:     1,
 	})
 	if err != nil {
 		log.Fatalf("Failed to create saving goal: %v", err)
 	}
-	_, err = savingGoalService.Contribute(ctx, goal1.ID, &model.ContributeRequest{Amount: 120000})
+	_, err = savingGoalService.Contribute(ctx, householdID, admin.ID, goal1.ID, &model.ContributeRequest{
+		Amount:        120000,
+		FromAccountID: &checkingAccountID,
+	})
 	if err != nil {
 		log.Fatalf("Failed to contribute to saving goal: %v", err)
 	}
 
-	goal2, err := savingGoalService.Create(ctx, &model.CreateSavingGoalRequest{
+	goal2, err := savingGoalService.Create(ctx, householdID, &model.CreateSavingGoalRequest{
 		Name:         "New Laptop",
 		TargetAmount: 150000,
 		TargetDate:   &targetDate2,
@@ -330,7 +346,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to create saving goal: %v", err)
 	}
-	_, err = savingGoalService.Contribute(ctx, goal2.ID, &model.ContributeRequest{Amount: 50000})
+	_, err = savingGoalService.Contribute(ctx, householdID, admin.ID, goal2.ID, &model.ContributeRequest{Amount: 50000})
 	if err != nil {
 		log.Fatalf("Failed to contribute to saving goal: %v", err)
 	}
@@ -358,7 +374,7 @@ func main() {
 		acctID := accounts[bd.AccountID].ID
 		nextDue := fmt.Sprintf("%04d-%02d-%02d", thisYear, thisMonth, bd.DueDay)
 
-		_, err := billReminderService.Create(ctx, &model.CreateBillReminderRequest{
+		_, err := billReminderService.Create(ctx, householdID, &model.CreateBillReminderRequest{
 			Name:        bd.Name,
 			Amount:      bd.Amount,
 			DueDay:      bd.DueDay,
@@ -390,7 +406,7 @@ func main() {
 	}
 
 	for _, ca := range childAllowances {
-		_, err = allowanceService.Create(ctx, &model.CreateAllowanceRequest{
+		_, err = allowanceService.Create(ctx, householdID, &model.CreateAllowanceRequest{
 			UserID:      ca.userID,
 			Amount:      ca.amount,
 			PeriodStart: periodStart,
@@ -420,7 +436,7 @@ func main() {
 
 func truncate(pool *pgxpool.Pool) {
 	ctx := context.Background()
-	_, err := pool.Exec(ctx, "TRUNCATE transactions, allowances, bill_reminders, saving_goals, budgets, accounts, categories, users CASCADE")
+	_, err := pool.Exec(ctx, "TRUNCATE transactions, allowances, bill_reminders, saving_goals, budgets, budget_templates, accounts, categories, users, households CASCADE")
 	if err != nil {
 		log.Fatalf("Failed to truncate tables: %v", err)
 	}
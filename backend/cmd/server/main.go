@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,7 +10,9 @@ import (
 	"github.com/asilingas/fambudg/backend/internal/database"
 	"github.com/asilingas/fambudg/backend/internal/handler"
 	"github.com/asilingas/fambudg/backend/internal/middleware"
+	"github.com/asilingas/fambudg/backend/internal/notify"
 	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/asilingas/fambudg/backend/internal/scheduler"
 	"github.com/asilingas/fambudg/backend/internal/service"
 	"github.com/go-chi/chi/v5"
 )
@@ -29,26 +32,69 @@ func main() {
 	defer pool.Close()
 
 	// Initialize repositories
+	uow := repository.NewUnitOfWork(pool)
 	userRepo := repository.NewUserRepository(pool)
+	sessionRepo := repository.NewSessionRepository(pool)
 	accountRepo := repository.NewAccountRepository(pool)
 	categoryRepo := repository.NewCategoryRepository(pool)
 	transactionRepo := repository.NewTransactionRepository(pool)
 	budgetRepo := repository.NewBudgetRepository(pool)
+	budgetTemplateRepo := repository.NewBudgetTemplateRepository(pool)
 	reportRepo := repository.NewReportRepository(pool)
 	savingGoalRepo := repository.NewSavingGoalRepository(pool)
 	billReminderRepo := repository.NewBillReminderRepository(pool)
 	allowanceRepo := repository.NewAllowanceRepository(pool)
+	choreRepo := repository.NewChoreRepository(pool)
+	householdRepo := repository.NewHouseholdRepository(pool)
+	exchangeRateRepo := repository.NewExchangeRateRepository(pool)
+	importProfileRepo := repository.NewImportProfileRepository(pool)
+	categorizationRuleRepo := repository.NewCategorizationRuleRepository(pool)
+	reconciliationRepo := repository.NewReconciliationRepository(pool)
+	jobRunRepo := repository.NewJobRunRepository(pool)
+	auditRepo := repository.NewAuditRepository(pool)
+	trashRepo := repository.NewTrashRepository(pool)
+	notificationRepo := repository.NewNotificationRepository(pool)
+
+	// Notification channels besides the in-app feed
+	var channels []notify.Channel
+	if cfg.SMTP.Host != "" {
+		channels = append(channels, notify.NewSMTPChannel(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From))
+	}
+	if cfg.Webhook.URL != "" {
+		channels = append(channels, notify.NewWebhookChannel(cfg.Webhook.URL))
+	}
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, cfg.JWT.Secret)
-	accountService := service.NewAccountService(accountRepo)
+	authService := service.NewAuthService(uow, userRepo, sessionRepo, householdRepo, auditRepo, cfg.JWT.Secret)
+	accountService := service.NewAccountService(uow, accountRepo, auditRepo)
 	categoryService := service.NewCategoryService(categoryRepo)
-	transactionService := service.NewTransactionService(transactionRepo, accountRepo)
-	budgetService := service.NewBudgetService(budgetRepo)
+	budgetService := service.NewBudgetService(uow, budgetRepo, auditRepo)
+	notificationService := service.NewNotificationService(notificationRepo, userRepo, budgetService, allowanceRepo, billReminderRepo, channels...)
+	transactionService := service.NewTransactionService(uow, transactionRepo, accountRepo, userRepo, categorizationRuleRepo, auditRepo, notificationService)
+	choreService := service.NewChoreService(uow, choreRepo, userRepo, allowanceRepo, transactionService, accountRepo, auditRepo)
+	allowanceService := service.NewAllowanceService(uow, allowanceRepo, transactionService, accountRepo, auditRepo)
+	budgetTemplateService := service.NewBudgetTemplateService(uow, budgetTemplateRepo, budgetRepo, auditRepo)
 	reportService := service.NewReportService(reportRepo, accountRepo)
-	savingGoalService := service.NewSavingGoalService(savingGoalRepo)
-	billReminderService := service.NewBillReminderService(billReminderRepo, transactionRepo, accountRepo)
-	allowanceService := service.NewAllowanceService(allowanceRepo)
+	savingGoalService := service.NewSavingGoalService(uow, savingGoalRepo, transactionService, auditRepo)
+	billReminderService := service.NewBillReminderService(uow, billReminderRepo, transactionService, auditRepo)
+	householdService := service.NewHouseholdService(householdRepo)
+	exchangeRateService := service.NewExchangeRateService(exchangeRateRepo)
+	importProfileService := service.NewImportProfileService(importProfileRepo)
+	statementImportService := service.NewStatementImportService(transactionService, transactionRepo, accountRepo, importProfileRepo, categorizationRuleRepo)
+	categorizationRuleService := service.NewCategorizationRuleService(uow, categorizationRuleRepo, transactionRepo, accountRepo, categoryRepo, auditRepo)
+	reconciliationService := service.NewReconciliationService(uow, reconciliationRepo, transactionRepo, transactionService, accountRepo)
+	auditService := service.NewAuditService(auditRepo)
+	trashService := service.NewTrashService(uow, trashRepo, transactionRepo, accountRepo, categoryRepo, budgetRepo, savingGoalRepo, auditRepo, cfg.Trash.Retention)
+
+	// Start background jobs (recurring transactions, overdue bills, trash purge)
+	if cfg.Scheduler.Enabled {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		jobs := scheduler.DefaultJobs(transactionService, billReminderService, allowanceService, notificationService, trashService)
+		go scheduler.New(uow, jobRunRepo, cfg.Scheduler.Interval, jobs...).Start(ctx)
+		log.Printf("Scheduler started, checking every %s", cfg.Scheduler.Interval)
+	}
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService)
@@ -57,23 +103,37 @@ func main() {
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 	transactionHandler := handler.NewTransactionHandler(transactionService)
 	budgetHandler := handler.NewBudgetHandler(budgetService)
+	budgetTemplateHandler := handler.NewBudgetTemplateHandler(budgetTemplateService)
 	reportHandler := handler.NewReportHandler(reportService)
 	savingGoalHandler := handler.NewSavingGoalHandler(savingGoalService)
 	billReminderHandler := handler.NewBillReminderHandler(billReminderService)
 	transferHandler := handler.NewTransferHandler(transactionService)
-	importExportHandler := handler.NewImportExportHandler(transactionService)
+	importExportHandler := handler.NewImportExportHandler(transactionService, statementImportService)
 	allowanceHandler := handler.NewAllowanceHandler(allowanceService)
+	choreHandler := handler.NewChoreHandler(choreService)
+	householdHandler := handler.NewHouseholdHandler(householdService)
+	exchangeRateHandler := handler.NewExchangeRateHandler(exchangeRateService)
+	importProfileHandler := handler.NewImportProfileHandler(importProfileService)
+	categorizationRuleHandler := handler.NewCategorizationRuleHandler(categorizationRuleService)
+	reconciliationHandler := handler.NewReconciliationHandler(reconciliationService, accountService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	trashHandler := handler.NewTrashHandler(trashService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
 
 	// Create router
 	r := chi.NewRouter()
 
 	// Global middleware
+	r.Use(middleware.RequestIDMiddleware)
 	r.Use(middleware.LoggingMiddleware)
 	r.Use(middleware.CORSMiddleware)
 
 	// Public routes
 	r.Post("/api/auth/register", authHandler.Register)
 	r.Post("/api/auth/login", authHandler.Login)
+	r.Post("/api/auth/login/verify", authHandler.VerifyLogin)
+	r.Post("/api/auth/refresh", authHandler.Refresh)
+	r.Post("/api/auth/logout", authHandler.Logout)
 
 	// All authenticated users (admin, member, child)
 	r.Group(func(r chi.Router) {
@@ -81,6 +141,12 @@ func main() {
 
 		// Auth
 		r.Get("/api/auth/me", authHandler.GetMe)
+		r.Post("/api/auth/2fa/setup", authHandler.SetupTwoFactor)
+		r.Post("/api/auth/2fa/enable", authHandler.EnableTwoFactor)
+		r.Post("/api/auth/2fa/disable", authHandler.DisableTwoFactor)
+
+		// Household (read for all)
+		r.Get("/api/household", householdHandler.Get)
 
 		// Accounts (admin sees all, others see own; ownership checks in handler)
 		r.Get("/api/accounts", accountHandler.List)
@@ -103,6 +169,7 @@ func main() {
 		r.Get("/api/reports/dashboard", reportHandler.Dashboard)
 		r.Get("/api/reports/monthly", reportHandler.Monthly)
 		r.Get("/api/reports/by-category", reportHandler.ByCategory)
+		r.Get("/api/reports/category-tree", reportHandler.CategoryTree)
 		r.Get("/api/reports/trends", reportHandler.Trends)
 
 		// Search (admin searches all, others search own)
@@ -110,6 +177,24 @@ func main() {
 
 		// Allowances (list: admin sees all, child sees own)
 		r.Get("/api/allowances", allowanceHandler.List)
+		r.Get("/api/allowances/{id}/periods", allowanceHandler.Periods)
+
+		// Chores (child sees and marks done their own; earnings: child sees own)
+		r.Get("/api/chores", choreHandler.List)
+		r.Get("/api/chores/earnings", choreHandler.Earnings)
+		r.Post("/api/chores/{id}/complete", choreHandler.Complete)
+		r.Get("/api/chore-completions", choreHandler.Completions)
+
+		// Trash (admin sees all, others their own transactions and accounts; ownership checks in handler)
+		r.Get("/api/trash", trashHandler.List)
+		r.Post("/api/trash/{type}/{id}/restore", trashHandler.Restore)
+
+		// Notifications (own feed and preferences)
+		r.Get("/api/notifications", notificationHandler.List)
+		r.Post("/api/notifications/read-all", notificationHandler.MarkAllRead)
+		r.Post("/api/notifications/{id}/read", notificationHandler.MarkRead)
+		r.Get("/api/notifications/preferences", notificationHandler.GetPreferences)
+		r.Put("/api/notifications/preferences", notificationHandler.UpdatePreferences)
 	})
 
 	// Admin + Member routes (read access to budgets/goals/reminders + write categories)
@@ -120,16 +205,24 @@ func main() {
 		// Categories create (admin + member)
 		r.Post("/api/categories", categoryHandler.Create)
 
-		// Budgets read (admin + member)
+		// Budgets and budget templates read (admin + member)
 		r.Get("/api/budgets", budgetHandler.List)
 		r.Get("/api/budgets/summary", budgetHandler.Summary)
+		r.Get("/api/budget-templates", budgetTemplateHandler.List)
 
 		// Saving Goals read (admin + member)
 		r.Get("/api/saving-goals", savingGoalHandler.List)
+		r.Get("/api/saving-goals/{id}/contributions", savingGoalHandler.Contributions)
+		r.Get("/api/saving-goals/{id}/projection", savingGoalHandler.Projection)
+
+		// Chores approval (admin + member)
+		r.Post("/api/chore-completions/{id}/approve", choreHandler.Approve)
+		r.Post("/api/chore-completions/{id}/reject", choreHandler.Reject)
 
 		// Bill Reminders read + pay (admin + member)
 		r.Get("/api/bill-reminders", billReminderHandler.List)
 		r.Get("/api/bill-reminders/upcoming", billReminderHandler.Upcoming)
+		r.Get("/api/bill-reminders/{id}/history", billReminderHandler.History)
 		r.Post("/api/bill-reminders/{id}/pay", billReminderHandler.Pay)
 
 		// Transfers (admin + member)
@@ -140,7 +233,32 @@ func main() {
 
 		// Import / Export (admin + member)
 		r.Post("/api/import/csv", importExportHandler.ImportCSV)
+		r.Post("/api/import/statement", importExportHandler.ImportStatement)
+		r.Post("/api/import/statement/preview", importExportHandler.PreviewStatement)
+		r.Get("/api/import/profiles", importProfileHandler.List)
+		r.Post("/api/import/profiles", importProfileHandler.Create)
+		r.Get("/api/import/profiles/{id}", importProfileHandler.Get)
+		r.Put("/api/import/profiles/{id}", importProfileHandler.Update)
+		r.Delete("/api/import/profiles/{id}", importProfileHandler.Delete)
 		r.Get("/api/export/csv", importExportHandler.ExportCSV)
+
+		// Categorization rules (admin + member)
+		r.Get("/api/categorization-rules", categorizationRuleHandler.List)
+		r.Post("/api/categorization-rules", categorizationRuleHandler.Create)
+		r.Get("/api/categorization-rules/{id}", categorizationRuleHandler.Get)
+		r.Put("/api/categorization-rules/{id}", categorizationRuleHandler.Update)
+		r.Delete("/api/categorization-rules/{id}", categorizationRuleHandler.Delete)
+
+		// Reconciliations (admin + member; account ownership checks in handler)
+		r.Get("/api/reconciliations", reconciliationHandler.List)
+		r.Post("/api/reconciliations", reconciliationHandler.Create)
+		r.Get("/api/reconciliations/{id}", reconciliationHandler.Get)
+		r.Delete("/api/reconciliations/{id}", reconciliationHandler.Delete)
+		r.Post("/api/reconciliations/{id}/clear", reconciliationHandler.Clear)
+		r.Post("/api/reconciliations/{id}/complete", reconciliationHandler.Complete)
+
+		// Exchange rates read (admin + member)
+		r.Get("/api/exchange-rates", exchangeRateHandler.List)
 	})
 
 	// Admin-only routes
@@ -153,25 +271,55 @@ func main() {
 		r.Post("/api/users", userHandler.Create)
 		r.Put("/api/users/{id}", userHandler.Update)
 		r.Delete("/api/users/{id}", userHandler.Delete)
+		r.Delete("/api/users/{id}/sessions", userHandler.RevokeSessions)
+		r.Put("/api/users/{id}/approval-threshold", userHandler.SetApprovalThreshold)
+
+		// Household settings (admin only)
+		r.Put("/api/household", householdHandler.Update)
+
+		// Exchange rates import (admin only)
+		r.Post("/api/exchange-rates/import", exchangeRateHandler.Import)
+
+		// Re-run categorization rules over the household's transactions (admin only)
+		r.Post("/api/categorization-rules/apply", categorizationRuleHandler.Apply)
+
+		// Unlock a reconciled transaction for editing (admin only)
+		r.Post("/api/transactions/{id}/unlock", transactionHandler.Unlock)
+
+		// Children's purchases above their approval threshold (admin only)
+		r.Get("/api/transactions/pending-approval", transactionHandler.PendingApproval)
+		r.Post("/api/transactions/{id}/approve", transactionHandler.Approve)
+		r.Post("/api/transactions/{id}/reject", transactionHandler.Reject)
 
 		// Categories update/delete (admin only)
 		r.Put("/api/categories/{id}", categoryHandler.Update)
 		r.Delete("/api/categories/{id}", categoryHandler.Delete)
 
-		// Budgets write (admin only)
+		// Budgets and budget templates write (admin only)
 		r.Post("/api/budgets", budgetHandler.Create)
 		r.Put("/api/budgets/{id}", budgetHandler.Update)
 		r.Delete("/api/budgets/{id}", budgetHandler.Delete)
+		r.Post("/api/budgets/copy", budgetHandler.Copy)
+		r.Post("/api/budget-templates", budgetTemplateHandler.Create)
+		r.Put("/api/budget-templates/{id}", budgetTemplateHandler.Update)
+		r.Delete("/api/budget-templates/{id}", budgetTemplateHandler.Delete)
 
 		// Saving Goals write (admin only)
 		r.Post("/api/saving-goals", savingGoalHandler.Create)
 		r.Put("/api/saving-goals/{id}", savingGoalHandler.Update)
 		r.Post("/api/saving-goals/{id}/contribute", savingGoalHandler.Contribute)
+		r.Post("/api/saving-goals/{id}/withdraw", savingGoalHandler.Withdraw)
+		r.Delete("/api/saving-goals/{id}", savingGoalHandler.Delete)
 
 		// Bill Reminders write (admin only)
 		r.Post("/api/bill-reminders", billReminderHandler.Create)
 		r.Put("/api/bill-reminders/{id}", billReminderHandler.Update)
 		r.Delete("/api/bill-reminders/{id}", billReminderHandler.Delete)
+		r.Post("/api/bill-reminders/{id}/skip", billReminderHandler.Skip)
+		r.Post("/api/bill-reminders/{id}/defer", billReminderHandler.Defer)
+
+		// Audit log of changes to the household's data
+		r.Get("/api/audit", auditHandler.List)
 
 		// Family member spending comparison
 		r.Get("/api/reports/by-member", reportHandler.ByMember)
@@ -179,6 +327,11 @@ func main() {
 		// Allowances management (admin only)
 		r.Post("/api/allowances", allowanceHandler.Create)
 		r.Put("/api/allowances/{id}", allowanceHandler.Update)
+
+		// Chores management (admin only)
+		r.Post("/api/chores", choreHandler.Create)
+		r.Put("/api/chores/{id}", choreHandler.Update)
+		r.Delete("/api/chores/{id}", choreHandler.Delete)
 	})
 
 	// Health check
@@ -0,0 +1,34 @@
+// Package audit carries who made a request, and which request it was, from
+// the HTTP middleware down to the services that write the audit log.
+package audit
+
+import "context"
+
+type contextKey string
+
+const (
+	actorKey     contextKey = "audit_actor"
+	requestIDKey contextKey = "audit_request_id"
+)
+
+// WithActor returns a copy of ctx recording that userID makes the changes
+func WithActor(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, actorKey, userID)
+}
+
+// Actor returns the user making the changes, or "" for background jobs
+func Actor(ctx context.Context) string {
+	userID, _ := ctx.Value(actorKey).(string)
+	return userID
+}
+
+// WithRequestID returns a copy of ctx carrying the ID of the HTTP request
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the ID of the HTTP request ctx belongs to, or ""
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
@@ -4,14 +4,19 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	JWT      JWTConfig
+	Database  DatabaseConfig
+	Server    ServerConfig
+	JWT       JWTConfig
+	Scheduler SchedulerConfig
+	Trash     TrashConfig
+	SMTP      SMTPConfig
+	Webhook   WebhookConfig
 }
 
 type DatabaseConfig struct {
@@ -31,6 +36,31 @@ type JWTConfig struct {
 	Secret string
 }
 
+type SchedulerConfig struct {
+	Enabled  bool
+	Interval time.Duration
+}
+
+type TrashConfig struct {
+	Retention time.Duration // how long deleted items can be restored before they are purged
+}
+
+// SMTPConfig is the mail server used for email notifications. Email is off
+// when Host is empty.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// WebhookConfig is the URL notifications are posted to as JSON. Webhook
+// notifications are off when URL is empty.
+type WebhookConfig struct {
+	URL string
+}
+
 func Load() (*Config, error) {
 	// Load .env file if it exists
 	_ = godotenv.Load()
@@ -63,6 +93,47 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("JWT_SECRET is required")
 	}
 
+	// Scheduler config
+	schedulerEnabled, err := strconv.ParseBool(getEnv("SCHEDULER_ENABLED", "true"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCHEDULER_ENABLED: %w", err)
+	}
+	cfg.Scheduler.Enabled = schedulerEnabled
+
+	schedulerInterval, err := time.ParseDuration(getEnv("SCHEDULER_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCHEDULER_INTERVAL: %w", err)
+	}
+	if schedulerInterval <= 0 {
+		return nil, fmt.Errorf("SCHEDULER_INTERVAL must be positive")
+	}
+	cfg.Scheduler.Interval = schedulerInterval
+
+	// Trash config
+	retentionDays, err := strconv.Atoi(getEnv("TRASH_RETENTION_DAYS", "30"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRASH_RETENTION_DAYS: %w", err)
+	}
+	if retentionDays <= 0 {
+		return nil, fmt.Errorf("TRASH_RETENTION_DAYS must be positive")
+	}
+	cfg.Trash.Retention = time.Duration(retentionDays) * 24 * time.Hour
+
+	// SMTP config
+	cfg.SMTP.Host = getEnv("SMTP_HOST", "")
+	cfg.SMTP.Username = getEnv("SMTP_USERNAME", "")
+	cfg.SMTP.Password = getEnv("SMTP_PASSWORD", "")
+	cfg.SMTP.From = getEnv("SMTP_FROM", "fambudg@localhost")
+
+	smtpPort, err := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP_PORT: %w", err)
+	}
+	cfg.SMTP.Port = smtpPort
+
+	// Webhook config
+	cfg.Webhook.URL = getEnv("NOTIFY_WEBHOOK_URL", "")
+
 	return cfg, nil
 }
 
@@ -32,12 +32,13 @@ func (h *AccountHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role := middleware.GetUserRole(r.Context())
+	householdID := middleware.GetHouseholdID(r.Context())
 
 	var accounts []*model.Account
 	var err error
 
 	if role == "admin" {
-		accounts, err = h.accountService.GetAll(r.Context())
+		accounts, err = h.accountService.GetAll(r.Context(), householdID)
 	} else {
 		accounts, err = h.accountService.GetByUserID(r.Context(), userID)
 	}
@@ -87,6 +88,7 @@ func (h *AccountHandler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role := middleware.GetUserRole(r.Context())
+	householdID := middleware.GetHouseholdID(r.Context())
 
 	accountID := chi.URLParam(r, "id")
 	if accountID == "" {
@@ -94,7 +96,7 @@ func (h *AccountHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	account, err := h.accountService.GetByID(r.Context(), accountID)
+	account, err := h.accountService.GetByID(r.Context(), householdID, accountID)
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, err.Error())
 		return
@@ -118,6 +120,7 @@ func (h *AccountHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role := middleware.GetUserRole(r.Context())
+	householdID := middleware.GetHouseholdID(r.Context())
 
 	accountID := chi.URLParam(r, "id")
 	if accountID == "" {
@@ -126,7 +129,7 @@ func (h *AccountHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check ownership for non-admin
-	existing, err := h.accountService.GetByID(r.Context(), accountID)
+	existing, err := h.accountService.GetByID(r.Context(), householdID, accountID)
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, err.Error())
 		return
@@ -147,7 +150,7 @@ func (h *AccountHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	account, err := h.accountService.Update(r.Context(), accountID, &req)
+	account, err := h.accountService.Update(r.Context(), householdID, accountID, &req)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -165,6 +168,7 @@ func (h *AccountHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role := middleware.GetUserRole(r.Context())
+	householdID := middleware.GetHouseholdID(r.Context())
 
 	accountID := chi.URLParam(r, "id")
 	if accountID == "" {
@@ -173,7 +177,7 @@ func (h *AccountHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check ownership for non-admin
-	existing, err := h.accountService.GetByID(r.Context(), accountID)
+	existing, err := h.accountService.GetByID(r.Context(), householdID, accountID)
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, err.Error())
 		return
@@ -183,7 +187,7 @@ func (h *AccountHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.accountService.Delete(r.Context(), accountID); err != nil {
+	if err := h.accountService.Delete(r.Context(), householdID, accountID); err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
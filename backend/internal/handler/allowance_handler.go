@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/asilingas/fambudg/backend/internal/middleware"
@@ -39,7 +40,7 @@ func (h *AllowanceHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	allowances, err := h.allowanceService.GetAll(r.Context())
+	allowances, err := h.allowanceService.GetAll(r.Context(), middleware.GetHouseholdID(r.Context()))
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -61,7 +62,11 @@ func (h *AllowanceHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	allowance, err := h.allowanceService.Create(r.Context(), &req)
+	allowance, err := h.allowanceService.Create(r.Context(), middleware.GetHouseholdID(r.Context()), &req)
+	if errors.Is(err, service.ErrAllowanceSameAccount) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -89,7 +94,11 @@ func (h *AllowanceHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	allowance, err := h.allowanceService.Update(r.Context(), allowanceID, &req)
+	allowance, err := h.allowanceService.Update(r.Context(), middleware.GetHouseholdID(r.Context()), allowanceID, &req)
+	if errors.Is(err, service.ErrAllowanceSameAccount) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -97,3 +106,33 @@ func (h *AllowanceHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	respondWithJSON(w, http.StatusOK, allowance)
 }
+
+// Periods returns the current and past periods of an allowance. A child can
+// only see their own.
+func (h *AllowanceHandler) Periods(w http.ResponseWriter, r *http.Request) {
+	allowanceID := chi.URLParam(r, "id")
+	if allowanceID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing allowance ID")
+		return
+	}
+
+	householdID := middleware.GetHouseholdID(r.Context())
+
+	allowance, err := h.allowanceService.GetByID(r.Context(), householdID, allowanceID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if middleware.GetUserRole(r.Context()) == "child" && allowance.UserID != middleware.GetUserID(r.Context()) {
+		respondWithError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+
+	periods, err := h.allowanceService.GetPeriods(r.Context(), householdID, allowanceID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, periods)
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/asilingas/fambudg/backend/internal/middleware"
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/service"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// List returns the household's audit log, newest first. It can be filtered by
+// entityType, entityId, actorId, action, startDate and endDate, and paged
+// with limit and cursor.
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filters := &model.AuditFilters{
+		EntityType: query.Get("entityType"),
+		EntityID:   query.Get("entityId"),
+		ActorID:    query.Get("actorId"),
+		Action:     query.Get("action"),
+		StartDate:  query.Get("startDate"),
+		EndDate:    query.Get("endDate"),
+		Cursor:     query.Get("cursor"),
+	}
+
+	if l := query.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit < 1 || limit > model.MaxPageLimit {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", model.MaxPageLimit))
+			return
+		}
+		filters.Limit = limit
+	}
+
+	page, err := h.auditService.GetAll(r.Context(), middleware.GetHouseholdID(r.Context()), filters)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
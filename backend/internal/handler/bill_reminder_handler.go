@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -25,7 +26,7 @@ func NewBillReminderHandler(billReminderService *service.BillReminderService) *B
 }
 
 func (h *BillReminderHandler) List(w http.ResponseWriter, r *http.Request) {
-	bills, err := h.billReminderService.GetAll(r.Context())
+	bills, err := h.billReminderService.GetAll(r.Context(), middleware.GetHouseholdID(r.Context()))
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -45,7 +46,7 @@ func (h *BillReminderHandler) Upcoming(w http.ResponseWriter, r *http.Request) {
 		days = parsed
 	}
 
-	bills, err := h.billReminderService.GetUpcoming(r.Context(), days)
+	bills, err := h.billReminderService.GetUpcoming(r.Context(), middleware.GetHouseholdID(r.Context()), days)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -66,7 +67,7 @@ func (h *BillReminderHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bill, err := h.billReminderService.Create(r.Context(), &req)
+	bill, err := h.billReminderService.Create(r.Context(), middleware.GetHouseholdID(r.Context()), &req)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -93,7 +94,7 @@ func (h *BillReminderHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bill, err := h.billReminderService.Update(r.Context(), billID, &req)
+	bill, err := h.billReminderService.Update(r.Context(), middleware.GetHouseholdID(r.Context()), billID, &req)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -109,7 +110,7 @@ func (h *BillReminderHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.billReminderService.Delete(r.Context(), billID); err != nil {
+	if err := h.billReminderService.Delete(r.Context(), middleware.GetHouseholdID(r.Context()), billID); err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -141,7 +142,7 @@ func (h *BillReminderHandler) Pay(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	transaction, err := h.billReminderService.Pay(r.Context(), userID, billID, &req)
+	transaction, err := h.billReminderService.Pay(r.Context(), middleware.GetHouseholdID(r.Context()), userID, billID, &req)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -149,3 +150,68 @@ func (h *BillReminderHandler) Pay(w http.ResponseWriter, r *http.Request) {
 
 	respondWithJSON(w, http.StatusCreated, transaction)
 }
+
+// History returns how each period of the bill was settled, with a forecast
+// of the next amount
+func (h *BillReminderHandler) History(w http.ResponseWriter, r *http.Request) {
+	billID := chi.URLParam(r, "id")
+	if billID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing bill reminder ID")
+		return
+	}
+
+	history, err := h.billReminderService.GetHistory(r.Context(), middleware.GetHouseholdID(r.Context()), billID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, history)
+}
+
+func (h *BillReminderHandler) Skip(w http.ResponseWriter, r *http.Request) {
+	billID := chi.URLParam(r, "id")
+	if billID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing bill reminder ID")
+		return
+	}
+
+	bill, err := h.billReminderService.Skip(r.Context(), middleware.GetHouseholdID(r.Context()), billID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, bill)
+}
+
+func (h *BillReminderHandler) Defer(w http.ResponseWriter, r *http.Request) {
+	billID := chi.URLParam(r, "id")
+	if billID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing bill reminder ID")
+		return
+	}
+
+	var req model.DeferBillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bill, err := h.billReminderService.Defer(r.Context(), middleware.GetHouseholdID(r.Context()), billID, &req)
+	if errors.Is(err, service.ErrDeferNotLater) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, bill)
+}
@@ -4,13 +4,19 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/asilingas/fambudg/backend/internal/middleware"
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/asilingas/fambudg/backend/internal/service"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 )
 
+// maxBudgetYearsAhead is how many years ahead budgets can be viewed or copied.
+// It also bounds the months of templates a request has to work through.
+const maxBudgetYearsAhead = 5
+
 type BudgetHandler struct {
 	budgetService *service.BudgetService
 	validator     *validator.Validate
@@ -28,7 +34,7 @@ func (h *BudgetHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	if m := r.URL.Query().Get("month"); m != "" {
 		month, err := strconv.Atoi(m)
-		if err != nil {
+		if err != nil || month < 1 || month > 12 {
 			respondWithError(w, http.StatusBadRequest, "invalid month")
 			return
 		}
@@ -37,14 +43,14 @@ func (h *BudgetHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	if y := r.URL.Query().Get("year"); y != "" {
 		year, err := strconv.Atoi(y)
-		if err != nil {
+		if err != nil || !validBudgetYear(year) {
 			respondWithError(w, http.StatusBadRequest, "invalid year")
 			return
 		}
 		filters.Year = year
 	}
 
-	budgets, err := h.budgetService.GetAll(r.Context(), filters)
+	budgets, err := h.budgetService.GetAll(r.Context(), middleware.GetHouseholdID(r.Context()), filters)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -65,7 +71,7 @@ func (h *BudgetHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	budget, err := h.budgetService.Create(r.Context(), &req)
+	budget, err := h.budgetService.Create(r.Context(), middleware.GetHouseholdID(r.Context()), &req)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -92,7 +98,7 @@ func (h *BudgetHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	budget, err := h.budgetService.Update(r.Context(), budgetID, &req)
+	budget, err := h.budgetService.Update(r.Context(), middleware.GetHouseholdID(r.Context()), budgetID, &req)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -108,7 +114,7 @@ func (h *BudgetHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.budgetService.Delete(r.Context(), budgetID); err != nil {
+	if err := h.budgetService.Delete(r.Context(), middleware.GetHouseholdID(r.Context()), budgetID); err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -116,6 +122,33 @@ func (h *BudgetHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Copy fills a month with the budgets of the month before
+func (h *BudgetHandler) Copy(w http.ResponseWriter, r *http.Request) {
+	var req model.CopyBudgetsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !validBudgetYear(req.Year) {
+		respondWithError(w, http.StatusBadRequest, "invalid year")
+		return
+	}
+
+	budgets, err := h.budgetService.CopyFromPreviousMonth(r.Context(), middleware.GetHouseholdID(r.Context()), req.Month, req.Year)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, budgets)
+}
+
 func (h *BudgetHandler) Summary(w http.ResponseWriter, r *http.Request) {
 	monthStr := r.URL.Query().Get("month")
 	yearStr := r.URL.Query().Get("year")
@@ -132,16 +165,22 @@ func (h *BudgetHandler) Summary(w http.ResponseWriter, r *http.Request) {
 	}
 
 	year, err := strconv.Atoi(yearStr)
-	if err != nil || year < 2000 {
+	if err != nil || !validBudgetYear(year) {
 		respondWithError(w, http.StatusBadRequest, "invalid year")
 		return
 	}
 
-	summaries, err := h.budgetService.GetSummary(r.Context(), month, year)
+	summaries, err := h.budgetService.GetSummary(r.Context(), middleware.GetHouseholdID(r.Context()), month, year)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, reportErrorStatus(err), err.Error())
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, summaries)
 }
+
+// validBudgetYear reports whether year is between 2000 and
+// maxBudgetYearsAhead years from now
+func validBudgetYear(year int) bool {
+	return year >= 2000 && year <= time.Now().Year()+maxBudgetYearsAhead
+}
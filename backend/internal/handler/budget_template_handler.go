@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/asilingas/fambudg/backend/internal/middleware"
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+type BudgetTemplateHandler struct {
+	budgetTemplateService *service.BudgetTemplateService
+	validator             *validator.Validate
+}
+
+func NewBudgetTemplateHandler(budgetTemplateService *service.BudgetTemplateService) *BudgetTemplateHandler {
+	return &BudgetTemplateHandler{
+		budgetTemplateService: budgetTemplateService,
+		validator:             validator.New(),
+	}
+}
+
+func (h *BudgetTemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.budgetTemplateService.GetAll(r.Context(), middleware.GetHouseholdID(r.Context()))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, templates)
+}
+
+func (h *BudgetTemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateBudgetTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	template, err := h.budgetTemplateService.Create(r.Context(), middleware.GetHouseholdID(r.Context()), &req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, template)
+}
+
+func (h *BudgetTemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	templateID := chi.URLParam(r, "id")
+	if templateID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing budget template ID")
+		return
+	}
+
+	var req model.UpdateBudgetTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	template, err := h.budgetTemplateService.Update(r.Context(), middleware.GetHouseholdID(r.Context()), templateID, &req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, template)
+}
+
+func (h *BudgetTemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	templateID := chi.URLParam(r, "id")
+	if templateID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing budget template ID")
+		return
+	}
+
+	if err := h.budgetTemplateService.Delete(r.Context(), middleware.GetHouseholdID(r.Context()), templateID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
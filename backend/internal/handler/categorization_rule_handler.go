@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/asilingas/fambudg/backend/internal/middleware"
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+type CategorizationRuleHandler struct {
+	ruleService *service.CategorizationRuleService
+	validator   *validator.Validate
+}
+
+func NewCategorizationRuleHandler(ruleService *service.CategorizationRuleService) *CategorizationRuleHandler {
+	return &CategorizationRuleHandler{
+		ruleService: ruleService,
+		validator:   validator.New(),
+	}
+}
+
+func (h *CategorizationRuleHandler) List(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.ruleService.GetAll(r.Context(), middleware.GetHouseholdID(r.Context()))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rules)
+}
+
+func (h *CategorizationRuleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "id")
+	if ruleID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing categorization rule ID")
+		return
+	}
+
+	rule, err := h.ruleService.GetByID(r.Context(), middleware.GetHouseholdID(r.Context()), ruleID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rule)
+}
+
+func (h *CategorizationRuleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateCategorizationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule, err := h.ruleService.Create(r.Context(), middleware.GetHouseholdID(r.Context()), &req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, rule)
+}
+
+func (h *CategorizationRuleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "id")
+	if ruleID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing categorization rule ID")
+		return
+	}
+
+	var req model.UpdateCategorizationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule, err := h.ruleService.Update(r.Context(), middleware.GetHouseholdID(r.Context()), ruleID, &req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rule)
+}
+
+func (h *CategorizationRuleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "id")
+	if ruleID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing categorization rule ID")
+		return
+	}
+
+	if err := h.ruleService.Delete(r.Context(), middleware.GetHouseholdID(r.Context()), ruleID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Apply runs the active rules over the household's uncategorized transactions
+func (h *CategorizationRuleHandler) Apply(w http.ResponseWriter, r *http.Request) {
+	result, err := h.ruleService.ApplyToUncategorized(r.Context(), middleware.GetHouseholdID(r.Context()))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
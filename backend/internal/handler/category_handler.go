@@ -2,8 +2,10 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	"github.com/asilingas/fambudg/backend/internal/middleware"
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/asilingas/fambudg/backend/internal/service"
 	"github.com/go-chi/chi/v5"
@@ -23,7 +25,7 @@ func NewCategoryHandler(categoryService *service.CategoryService) *CategoryHandl
 }
 
 func (h *CategoryHandler) List(w http.ResponseWriter, r *http.Request) {
-	categories, err := h.categoryService.GetAll(r.Context())
+	categories, err := h.categoryService.GetAll(r.Context(), middleware.GetHouseholdID(r.Context()))
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -44,7 +46,7 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	category, err := h.categoryService.Create(r.Context(), &req)
+	category, err := h.categoryService.Create(r.Context(), middleware.GetHouseholdID(r.Context()), &req)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -71,7 +73,7 @@ func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	category, err := h.categoryService.Update(r.Context(), categoryID, &req)
+	category, err := h.categoryService.Update(r.Context(), middleware.GetHouseholdID(r.Context()), categoryID, &req)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -87,7 +89,12 @@ func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.categoryService.Delete(r.Context(), categoryID); err != nil {
+	err := h.categoryService.Delete(r.Context(), middleware.GetHouseholdID(r.Context()), categoryID)
+	if errors.Is(err, service.ErrCategoryInUse) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
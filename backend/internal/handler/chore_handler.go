@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/middleware"
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+type ChoreHandler struct {
+	choreService *service.ChoreService
+	validator    *validator.Validate
+}
+
+func NewChoreHandler(choreService *service.ChoreService) *ChoreHandler {
+	return &ChoreHandler{
+		choreService: choreService,
+		validator:    validator.New(),
+	}
+}
+
+// List returns all chores, or for a child the active ones they can do
+func (h *ChoreHandler) List(w http.ResponseWriter, r *http.Request) {
+	var child *string
+	if middleware.GetUserRole(r.Context()) == "child" {
+		userID := middleware.GetUserID(r.Context())
+		child = &userID
+	}
+
+	chores, err := h.choreService.GetAll(r.Context(), middleware.GetHouseholdID(r.Context()), child)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chores)
+}
+
+// Create defines a chore (admin only)
+func (h *ChoreHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateChoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	chore, err := h.choreService.Create(r.Context(), middleware.GetHouseholdID(r.Context()), &req)
+	if errors.Is(err, service.ErrChoreNotChild) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, chore)
+}
+
+// Update changes a chore (admin only)
+func (h *ChoreHandler) Update(w http.ResponseWriter, r *http.Request) {
+	choreID := chi.URLParam(r, "id")
+	if choreID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing chore ID")
+		return
+	}
+
+	var req model.UpdateChoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	chore, err := h.choreService.Update(r.Context(), middleware.GetHouseholdID(r.Context()), choreID, &req)
+	if errors.Is(err, service.ErrChoreNotChild) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chore)
+}
+
+// Delete removes a chore (admin only)
+func (h *ChoreHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	choreID := chi.URLParam(r, "id")
+	if choreID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing chore ID")
+		return
+	}
+
+	if err := h.choreService.Delete(r.Context(), middleware.GetHouseholdID(r.Context()), choreID); err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Complete marks a chore done by the child making the request
+func (h *ChoreHandler) Complete(w http.ResponseWriter, r *http.Request) {
+	if middleware.GetUserRole(r.Context()) != "child" {
+		respondWithError(w, http.StatusForbidden, "only children can mark chores done")
+		return
+	}
+
+	choreID := chi.URLParam(r, "id")
+	if choreID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing chore ID")
+		return
+	}
+
+	var req model.CompleteChoreRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	completion, err := h.choreService.Complete(r.Context(), middleware.GetHouseholdID(r.Context()), middleware.GetUserID(r.Context()), choreID, &req, time.Now())
+	if errors.Is(err, service.ErrChoreNotAssigned) {
+		respondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrChoreDateInFuture) || errors.Is(err, service.ErrChoreDateTooEarly) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrChoreInactive) || errors.Is(err, service.ErrChoreAlreadyDone) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, completion)
+}
+
+// Completions lists chores marked done, filtered by status and, for parents,
+// userId. A child only sees their own.
+func (h *ChoreHandler) Completions(w http.ResponseWriter, r *http.Request) {
+	filters := &model.ChoreCompletionFilters{
+		UserID: r.URL.Query().Get("userId"),
+		Status: r.URL.Query().Get("status"),
+	}
+	if middleware.GetUserRole(r.Context()) == "child" {
+		filters.UserID = middleware.GetUserID(r.Context())
+	}
+
+	completions, err := h.choreService.GetCompletions(r.Context(), middleware.GetHouseholdID(r.Context()), filters)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, completions)
+}
+
+// Approve accepts a chore marked done and pays its reward (admin + member)
+func (h *ChoreHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	completionID := chi.URLParam(r, "id")
+	if completionID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing chore completion ID")
+		return
+	}
+
+	var req model.ApproveChoreRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	completion, err := h.choreService.Approve(r.Context(), middleware.GetHouseholdID(r.Context()), middleware.GetUserID(r.Context()), completionID, &req)
+	if errors.Is(err, service.ErrChoreAccountRequired) || errors.Is(err, service.ErrChoreNotChildAccount) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrChoreReviewed) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, completion)
+}
+
+// Reject turns down a chore marked done (admin + member)
+func (h *ChoreHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	completionID := chi.URLParam(r, "id")
+	if completionID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing chore completion ID")
+		return
+	}
+
+	completion, err := h.choreService.Reject(r.Context(), middleware.GetHouseholdID(r.Context()), middleware.GetUserID(r.Context()), completionID)
+	if errors.Is(err, service.ErrChoreReviewed) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, completion)
+}
+
+// Earnings reports what each child earned with chores between startDate and
+// endDate, both optional and inclusive. A child only sees their own.
+func (h *ChoreHandler) Earnings(w http.ResponseWriter, r *http.Request) {
+	var start, end *time.Time
+	if s := r.URL.Query().Get("startDate"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid startDate parameter")
+			return
+		}
+		start = &parsed
+	}
+	if s := r.URL.Query().Get("endDate"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid endDate parameter")
+			return
+		}
+		next := parsed.AddDate(0, 0, 1)
+		end = &next
+	}
+
+	var child *string
+	if middleware.GetUserRole(r.Context()) == "child" {
+		userID := middleware.GetUserID(r.Context())
+		child = &userID
+	}
+
+	earnings, err := h.choreService.GetEarnings(r.Context(), middleware.GetHouseholdID(r.Context()), start, end, child)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, earnings)
+}
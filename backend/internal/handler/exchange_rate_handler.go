@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/asilingas/fambudg/backend/internal/middleware"
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/service"
+)
+
+type ExchangeRateHandler struct {
+	exchangeRateService *service.ExchangeRateService
+}
+
+func NewExchangeRateHandler(exchangeRateService *service.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{exchangeRateService: exchangeRateService}
+}
+
+func (h *ExchangeRateHandler) List(w http.ResponseWriter, r *http.Request) {
+	filters := &model.ExchangeRateFilters{
+		Base:      r.URL.Query().Get("base"),
+		Quote:     r.URL.Query().Get("quote"),
+		StartDate: r.URL.Query().Get("startDate"),
+		EndDate:   r.URL.Query().Get("endDate"),
+	}
+
+	rates, err := h.exchangeRateService.GetAll(r.Context(), middleware.GetHouseholdID(r.Context()), filters)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rates)
+}
+
+// Import loads rates from an uploaded CSV file. The optional "base" form value
+// sets the base currency for ECB-style files with one column per currency.
+func (h *ExchangeRateHandler) Import(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "missing CSV file")
+		return
+	}
+	defer file.Close()
+
+	result, err := h.exchangeRateService.ImportCSV(r.Context(), middleware.GetHouseholdID(r.Context()), file, r.FormValue("base"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
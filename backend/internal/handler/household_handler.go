@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/asilingas/fambudg/backend/internal/middleware"
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/service"
+	"github.com/go-playground/validator/v10"
+)
+
+type HouseholdHandler struct {
+	householdService *service.HouseholdService
+	validator        *validator.Validate
+}
+
+func NewHouseholdHandler(householdService *service.HouseholdService) *HouseholdHandler {
+	return &HouseholdHandler{
+		householdService: householdService,
+		validator:        validator.New(),
+	}
+}
+
+func (h *HouseholdHandler) Get(w http.ResponseWriter, r *http.Request) {
+	household, err := h.householdService.Get(r.Context(), middleware.GetHouseholdID(r.Context()))
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, household)
+}
+
+func (h *HouseholdHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req model.UpdateHouseholdRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	household, err := h.householdService.Update(r.Context(), middleware.GetHouseholdID(r.Context()), &req)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, household)
+}
@@ -3,20 +3,28 @@ package handler
 import (
 	"encoding/csv"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 
 	"github.com/asilingas/fambudg/backend/internal/middleware"
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/asilingas/fambudg/backend/internal/service"
+	"github.com/go-playground/validator/v10"
 )
 
 type ImportExportHandler struct {
-	transactionService *service.TransactionService
+	transactionService     *service.TransactionService
+	statementImportService *service.StatementImportService
+	validator              *validator.Validate
 }
 
-func NewImportExportHandler(transactionService *service.TransactionService) *ImportExportHandler {
-	return &ImportExportHandler{transactionService: transactionService}
+func NewImportExportHandler(transactionService *service.TransactionService, statementImportService *service.StatementImportService) *ImportExportHandler {
+	return &ImportExportHandler{
+		transactionService:     transactionService,
+		statementImportService: statementImportService,
+		validator:              validator.New(),
+	}
 }
 
 func (h *ImportExportHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
@@ -31,7 +39,7 @@ func (h *ImportExportHandler) ExportCSV(w http.ResponseWriter, r *http.Request)
 		EndDate:   r.URL.Query().Get("endDate"),
 	}
 
-	transactions, err := h.transactionService.GetByUserID(r.Context(), userID, filters)
+	page, err := h.transactionService.GetByUserID(r.Context(), userID, filters)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -46,19 +54,27 @@ func (h *ImportExportHandler) ExportCSV(w http.ResponseWriter, r *http.Request)
 	// Header row
 	writer.Write([]string{"date", "amount", "type", "description", "category_id", "account_id", "is_shared"})
 
-	for _, t := range transactions {
+	for _, t := range page.Transactions {
+		var categoryID string
+		if t.CategoryID != nil {
+			categoryID = *t.CategoryID
+		}
+
 		writer.Write([]string{
 			t.Date.Format("2006-01-02"),
 			strconv.FormatInt(t.Amount, 10),
 			t.Type,
 			t.Description,
-			t.CategoryID,
+			categoryID,
 			t.AccountID,
 			strconv.FormatBool(t.IsShared),
 		})
 	}
 }
 
+// ImportCSV reads files in the layout produced by ExportCSV. Bank exports in
+// other layouts are read through an import profile: when a "profileId" form
+// value is present the upload is handled as a CSV statement instead.
 func (h *ImportExportHandler) ImportCSV(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 	if userID == "" {
@@ -66,6 +82,11 @@ func (h *ImportExportHandler) ImportCSV(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if r.FormValue("profileId") != "" {
+		h.ImportStatement(w, r)
+		return
+	}
+
 	file, _, err := r.FormFile("file")
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "missing CSV file")
@@ -132,3 +153,72 @@ func (h *ImportExportHandler) ImportCSV(w http.ResponseWriter, r *http.Request)
 		"errors":   errors,
 	})
 }
+
+// ImportStatement books an uploaded bank statement on the account given by
+// the "accountId" form value. Lines are categorized by the household's rules;
+// the rest go to "categoryId" (debits) or "incomeCategoryId" (credits) if
+// given, or stay uncategorized. The "format" form value (ofx, camt053 or csv) is
+// optional; OFX and CAMT.053 files are recognised by their content, and CSV
+// files are read with the import profile given by "profileId".
+func (h *ImportExportHandler) ImportStatement(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	req, file, ok := h.parseStatementForm(w, r)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	result, err := h.statementImportService.Import(r.Context(), middleware.GetHouseholdID(r.Context()), userID, req, file)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// PreviewStatement takes the same form as ImportStatement and returns every
+// parsed row with its validation errors, without importing anything
+func (h *ImportExportHandler) PreviewStatement(w http.ResponseWriter, r *http.Request) {
+	req, file, ok := h.parseStatementForm(w, r)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	result, err := h.statementImportService.Preview(r.Context(), middleware.GetHouseholdID(r.Context()), req, file)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+func (h *ImportExportHandler) parseStatementForm(w http.ResponseWriter, r *http.Request) (*model.ImportStatementRequest, multipart.File, bool) {
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "missing statement file")
+		return nil, nil, false
+	}
+
+	req := &model.ImportStatementRequest{
+		AccountID:        r.FormValue("accountId"),
+		CategoryID:       r.FormValue("categoryId"),
+		IncomeCategoryID: r.FormValue("incomeCategoryId"),
+		Format:           r.FormValue("format"),
+		ProfileID:        r.FormValue("profileId"),
+	}
+	if err := h.validator.Struct(req); err != nil {
+		file.Close()
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return nil, nil, false
+	}
+
+	return req, file, true
+}
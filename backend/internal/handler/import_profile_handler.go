@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/asilingas/fambudg/backend/internal/middleware"
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+type ImportProfileHandler struct {
+	importProfileService *service.ImportProfileService
+	validator            *validator.Validate
+}
+
+func NewImportProfileHandler(importProfileService *service.ImportProfileService) *ImportProfileHandler {
+	return &ImportProfileHandler{
+		importProfileService: importProfileService,
+		validator:            validator.New(),
+	}
+}
+
+func (h *ImportProfileHandler) List(w http.ResponseWriter, r *http.Request) {
+	profiles, err := h.importProfileService.GetAll(r.Context(), middleware.GetHouseholdID(r.Context()))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, profiles)
+}
+
+func (h *ImportProfileHandler) Get(w http.ResponseWriter, r *http.Request) {
+	profileID := chi.URLParam(r, "id")
+	if profileID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing import profile ID")
+		return
+	}
+
+	profile, err := h.importProfileService.GetByID(r.Context(), middleware.GetHouseholdID(r.Context()), profileID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, profile)
+}
+
+func (h *ImportProfileHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateImportProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	profile, err := h.importProfileService.Create(r.Context(), middleware.GetHouseholdID(r.Context()), &req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, profile)
+}
+
+func (h *ImportProfileHandler) Update(w http.ResponseWriter, r *http.Request) {
+	profileID := chi.URLParam(r, "id")
+	if profileID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing import profile ID")
+		return
+	}
+
+	var req model.UpdateImportProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	profile, err := h.importProfileService.Update(r.Context(), middleware.GetHouseholdID(r.Context()), profileID, &req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, profile)
+}
+
+func (h *ImportProfileHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	profileID := chi.URLParam(r, "id")
+	if profileID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing import profile ID")
+		return
+	}
+
+	if err := h.importProfileService.Delete(r.Context(), middleware.GetHouseholdID(r.Context()), profileID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
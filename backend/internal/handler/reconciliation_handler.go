@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/asilingas/fambudg/backend/internal/middleware"
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+type ReconciliationHandler struct {
+	reconciliationService *service.ReconciliationService
+	accountService        *service.AccountService
+	validator             *validator.Validate
+}
+
+func NewReconciliationHandler(reconciliationService *service.ReconciliationService, accountService *service.AccountService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: reconciliationService,
+		accountService:        accountService,
+		validator:             validator.New(),
+	}
+}
+
+// canReconcile reports whether the current user may reconcile the account:
+// admins any account in the household, others only their own
+func (h *ReconciliationHandler) canReconcile(w http.ResponseWriter, r *http.Request, accountID string) bool {
+	account, err := h.accountService.GetByID(r.Context(), middleware.GetHouseholdID(r.Context()), accountID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return false
+	}
+
+	if middleware.GetUserRole(r.Context()) != "admin" && account.UserID != middleware.GetUserID(r.Context()) {
+		respondWithError(w, http.StatusForbidden, "forbidden")
+		return false
+	}
+	return true
+}
+
+// find loads the reconciliation in the URL, checking the user may reconcile
+// its account
+func (h *ReconciliationHandler) find(w http.ResponseWriter, r *http.Request) (*model.Reconciliation, bool) {
+	reconciliationID := chi.URLParam(r, "id")
+	if reconciliationID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing reconciliation ID")
+		return nil, false
+	}
+
+	rec, err := h.reconciliationService.GetByID(r.Context(), middleware.GetHouseholdID(r.Context()), reconciliationID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return nil, false
+	}
+
+	if !h.canReconcile(w, r, rec.AccountID) {
+		return nil, false
+	}
+	return rec, true
+}
+
+// List returns the reconciliations of the account given by ?accountId=
+func (h *ReconciliationHandler) List(w http.ResponseWriter, r *http.Request) {
+	accountID := r.URL.Query().Get("accountId")
+	if accountID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing account ID")
+		return
+	}
+
+	if !h.canReconcile(w, r, accountID) {
+		return
+	}
+
+	reconciliations, err := h.reconciliationService.GetByAccountID(r.Context(), middleware.GetHouseholdID(r.Context()), accountID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, reconciliations)
+}
+
+func (h *ReconciliationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.find(w, r)
+	if !ok {
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rec)
+}
+
+// Create starts reconciling an account against a statement
+func (h *ReconciliationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateReconciliationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.canReconcile(w, r, req.AccountID) {
+		return
+	}
+
+	rec, err := h.reconciliationService.Start(r.Context(), middleware.GetHouseholdID(r.Context()), &req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, rec)
+}
+
+// Clear marks transactions cleared or uncleared
+func (h *ReconciliationHandler) Clear(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.find(w, r)
+	if !ok {
+		return
+	}
+
+	var req model.ClearTransactionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rec, err := h.reconciliationService.Clear(r.Context(), middleware.GetHouseholdID(r.Context()), rec.ID, &req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rec)
+}
+
+// Complete finishes the reconciliation, optionally booking an adjustment
+func (h *ReconciliationHandler) Complete(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.find(w, r)
+	if !ok {
+		return
+	}
+
+	var req model.CompleteReconciliationRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	rec, err := h.reconciliationService.Complete(r.Context(), middleware.GetHouseholdID(r.Context()), middleware.GetUserID(r.Context()), rec.ID, &req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rec)
+}
+
+// Delete cancels an open reconciliation
+func (h *ReconciliationHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	rec, ok := h.find(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.reconciliationService.Cancel(r.Context(), middleware.GetHouseholdID(r.Context()), rec.ID); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
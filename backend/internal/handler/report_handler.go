@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -28,6 +29,7 @@ func (h *ReportHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role := middleware.GetUserRole(r.Context())
+	householdID := middleware.GetHouseholdID(r.Context())
 
 	// Default to current month/year
 	now := time.Now()
@@ -56,13 +58,13 @@ func (h *ReportHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
 	var err error
 
 	if role == "admin" {
-		dashboard, err = h.reportService.GetDashboardAll(r.Context(), int(month), year)
+		dashboard, err = h.reportService.GetDashboardAll(r.Context(), householdID, int(month), year)
 	} else {
 		dashboard, err = h.reportService.GetDashboard(r.Context(), userID, int(month), year)
 	}
 
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, reportErrorStatus(err), err.Error())
 		return
 	}
 
@@ -77,6 +79,7 @@ func (h *ReportHandler) Monthly(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role := middleware.GetUserRole(r.Context())
+	householdID := middleware.GetHouseholdID(r.Context())
 
 	month, year, err := parseMonthYear(r)
 	if err != nil {
@@ -87,13 +90,13 @@ func (h *ReportHandler) Monthly(w http.ResponseWriter, r *http.Request) {
 	var summary *model.MonthSummary
 
 	if role == "admin" {
-		summary, err = h.reportService.GetMonthlySummaryAll(r.Context(), month, year)
+		summary, err = h.reportService.GetMonthlySummaryAll(r.Context(), householdID, month, year)
 	} else {
 		summary, err = h.reportService.GetMonthlySummary(r.Context(), userID, month, year)
 	}
 
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, reportErrorStatus(err), err.Error())
 		return
 	}
 
@@ -108,6 +111,7 @@ func (h *ReportHandler) ByCategory(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role := middleware.GetUserRole(r.Context())
+	householdID := middleware.GetHouseholdID(r.Context())
 
 	month, year, err := parseMonthYear(r)
 	if err != nil {
@@ -118,19 +122,57 @@ func (h *ReportHandler) ByCategory(w http.ResponseWriter, r *http.Request) {
 	var spending []*model.CategorySpending
 
 	if role == "admin" {
-		spending, err = h.reportService.GetSpendingByCategoryAll(r.Context(), month, year)
+		spending, err = h.reportService.GetSpendingByCategoryAll(r.Context(), householdID, month, year)
 	} else {
 		spending, err = h.reportService.GetSpendingByCategory(r.Context(), userID, month, year)
 	}
 
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, reportErrorStatus(err), err.Error())
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, spending)
 }
 
+// CategoryTree returns spending by category as a tree with subtotals,
+// optionally collapsed below ?depth=
+func (h *ReportHandler) CategoryTree(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	month, year, err := parseMonthYear(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	depth := 0
+	if d := r.URL.Query().Get("depth"); d != "" {
+		depth, err = strconv.Atoi(d)
+		if err != nil || depth < 1 {
+			respondWithError(w, http.StatusBadRequest, "invalid depth")
+			return
+		}
+	}
+
+	var owner *string
+	if middleware.GetUserRole(r.Context()) != "admin" {
+		owner = &userID
+	}
+
+	tree, err := h.reportService.GetSpendingTree(r.Context(), middleware.GetHouseholdID(r.Context()), owner, month, year, depth)
+	if err != nil {
+		respondWithError(w, reportErrorStatus(err), err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tree)
+}
+
 func (h *ReportHandler) ByMember(w http.ResponseWriter, r *http.Request) {
 	month, year, err := parseMonthYear(r)
 	if err != nil {
@@ -138,9 +180,9 @@ func (h *ReportHandler) ByMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	spending, err := h.reportService.GetSpendingByMember(r.Context(), month, year)
+	spending, err := h.reportService.GetSpendingByMember(r.Context(), middleware.GetHouseholdID(r.Context()), month, year)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, reportErrorStatus(err), err.Error())
 		return
 	}
 
@@ -155,6 +197,7 @@ func (h *ReportHandler) Search(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role := middleware.GetUserRole(r.Context())
+	householdID := middleware.GetHouseholdID(r.Context())
 
 	filters := &model.SearchFilters{
 		UserID:      userID,
@@ -187,15 +230,25 @@ func (h *ReportHandler) Search(w http.ResponseWriter, r *http.Request) {
 		filters.Tags = strings.Split(tagsStr, ",")
 	}
 
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filters.PageParams = page
+
 	var results *model.SearchResult
-	var err error
 
 	if role == "admin" {
-		results, err = h.reportService.SearchTransactionsAll(r.Context(), filters)
+		results, err = h.reportService.SearchTransactionsAll(r.Context(), householdID, filters)
 	} else {
 		results, err = h.reportService.SearchTransactions(r.Context(), filters)
 	}
 
+	if isCursorError(err) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -212,6 +265,7 @@ func (h *ReportHandler) Trends(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role := middleware.GetUserRole(r.Context())
+	householdID := middleware.GetHouseholdID(r.Context())
 
 	months := 6
 	if m := r.URL.Query().Get("months"); m != "" {
@@ -227,13 +281,13 @@ func (h *ReportHandler) Trends(w http.ResponseWriter, r *http.Request) {
 	var err error
 
 	if role == "admin" {
-		trends, err = h.reportService.GetTrendsAll(r.Context(), months)
+		trends, err = h.reportService.GetTrendsAll(r.Context(), householdID, months)
 	} else {
 		trends, err = h.reportService.GetTrends(r.Context(), userID, months)
 	}
 
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithError(w, reportErrorStatus(err), err.Error())
 		return
 	}
 
@@ -260,3 +314,12 @@ func parseMonthYear(r *http.Request) (int, int, error) {
 
 	return month, year, nil
 }
+
+// reportErrorStatus is the status for an error building a report: a missing
+// exchange rate is for the admin to load, anything else is a server error
+func reportErrorStatus(err error) int {
+	if errors.Is(err, service.ErrMissingExchangeRate) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -2,8 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
+	"github.com/asilingas/fambudg/backend/internal/middleware"
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/asilingas/fambudg/backend/internal/service"
 	"github.com/go-chi/chi/v5"
@@ -23,7 +26,7 @@ func NewSavingGoalHandler(savingGoalService *service.SavingGoalService) *SavingG
 }
 
 func (h *SavingGoalHandler) List(w http.ResponseWriter, r *http.Request) {
-	goals, err := h.savingGoalService.GetAll(r.Context())
+	goals, err := h.savingGoalService.GetAll(r.Context(), middleware.GetHouseholdID(r.Context()), time.Now())
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -44,7 +47,7 @@ func (h *SavingGoalHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	goal, err := h.savingGoalService.Create(r.Context(), &req)
+	goal, err := h.savingGoalService.Create(r.Context(), middleware.GetHouseholdID(r.Context()), &req)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -71,7 +74,7 @@ func (h *SavingGoalHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	goal, err := h.savingGoalService.Update(r.Context(), goalID, &req)
+	goal, err := h.savingGoalService.Update(r.Context(), middleware.GetHouseholdID(r.Context()), goalID, &req)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -81,6 +84,12 @@ func (h *SavingGoalHandler) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *SavingGoalHandler) Contribute(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
 	goalID := chi.URLParam(r, "id")
 	if goalID == "" {
 		respondWithError(w, http.StatusBadRequest, "missing saving goal ID")
@@ -98,7 +107,11 @@ func (h *SavingGoalHandler) Contribute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	goal, err := h.savingGoalService.Contribute(r.Context(), goalID, &req)
+	goal, err := h.savingGoalService.Contribute(r.Context(), middleware.GetHouseholdID(r.Context()), userID, goalID, &req)
+	if isGoalTransferError(err) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -106,3 +119,96 @@ func (h *SavingGoalHandler) Contribute(w http.ResponseWriter, r *http.Request) {
 
 	respondWithJSON(w, http.StatusOK, goal)
 }
+
+func (h *SavingGoalHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	goalID := chi.URLParam(r, "id")
+	if goalID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing saving goal ID")
+		return
+	}
+
+	var req model.WithdrawRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	goal, err := h.savingGoalService.Withdraw(r.Context(), middleware.GetHouseholdID(r.Context()), userID, goalID, &req)
+	if isGoalTransferError(err) || errors.Is(err, service.ErrGoalInsufficient) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, goal)
+}
+
+// Projection forecasts when the goal completes and what it needs per month
+// to reach its target date
+func (h *SavingGoalHandler) Projection(w http.ResponseWriter, r *http.Request) {
+	goalID := chi.URLParam(r, "id")
+	if goalID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing saving goal ID")
+		return
+	}
+
+	projection, err := h.savingGoalService.GetProjection(r.Context(), middleware.GetHouseholdID(r.Context()), goalID, time.Now())
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, projection)
+}
+
+// Contributions returns the goal's contributions and withdrawals
+func (h *SavingGoalHandler) Contributions(w http.ResponseWriter, r *http.Request) {
+	goalID := chi.URLParam(r, "id")
+	if goalID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing saving goal ID")
+		return
+	}
+
+	contributions, err := h.savingGoalService.GetContributions(r.Context(), middleware.GetHouseholdID(r.Context()), goalID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, contributions)
+}
+
+func (h *SavingGoalHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	goalID := chi.URLParam(r, "id")
+	if goalID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing saving goal ID")
+		return
+	}
+
+	if err := h.savingGoalService.Delete(r.Context(), middleware.GetHouseholdID(r.Context()), goalID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// isGoalTransferError reports whether err is about the accounts given to move
+// money in or out of a goal
+func isGoalTransferError(err error) bool {
+	return errors.Is(err, service.ErrGoalAccountRequired) || errors.Is(err, service.ErrGoalNotLinked) || errors.Is(err, service.ErrGoalSameAccount)
+}
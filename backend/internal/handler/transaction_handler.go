@@ -1,8 +1,12 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/asilingas/fambudg/backend/internal/middleware"
@@ -32,6 +36,7 @@ func (h *TransactionHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role := middleware.GetUserRole(r.Context())
+	householdID := middleware.GetHouseholdID(r.Context())
 
 	// Parse query parameters for filters
 	filters := &model.TransactionFilters{
@@ -47,15 +52,25 @@ func (h *TransactionHandler) List(w http.ResponseWriter, r *http.Request) {
 		filters.IsShared = &isShared
 	}
 
-	var transactions []*model.Transaction
-	var err error
+	page, err := parsePageParams(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filters.PageParams = page
+
+	var transactions *model.TransactionPage
 
 	if role == "admin" {
-		transactions, err = h.transactionService.GetAll(r.Context(), filters)
+		transactions, err = h.transactionService.GetAll(r.Context(), householdID, filters)
 	} else {
 		transactions, err = h.transactionService.GetByUserID(r.Context(), userID, filters)
 	}
 
+	if isCursorError(err) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -83,6 +98,14 @@ func (h *TransactionHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	transaction, err := h.transactionService.Create(r.Context(), userID, &req)
+	if errors.Is(err, service.ErrTransactionAmountSign) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrChildTransfer) {
+		respondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -99,6 +122,7 @@ func (h *TransactionHandler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role := middleware.GetUserRole(r.Context())
+	householdID := middleware.GetHouseholdID(r.Context())
 
 	transactionID := chi.URLParam(r, "id")
 	if transactionID == "" {
@@ -106,7 +130,7 @@ func (h *TransactionHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	transaction, err := h.transactionService.GetByID(r.Context(), transactionID)
+	transaction, err := h.transactionService.GetByID(r.Context(), householdID, transactionID)
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, err.Error())
 		return
@@ -129,6 +153,7 @@ func (h *TransactionHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role := middleware.GetUserRole(r.Context())
+	householdID := middleware.GetHouseholdID(r.Context())
 
 	transactionID := chi.URLParam(r, "id")
 	if transactionID == "" {
@@ -137,7 +162,7 @@ func (h *TransactionHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check ownership for non-admin
-	existing, err := h.transactionService.GetByID(r.Context(), transactionID)
+	existing, err := h.transactionService.GetByID(r.Context(), householdID, transactionID)
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, err.Error())
 		return
@@ -158,7 +183,15 @@ func (h *TransactionHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	transaction, err := h.transactionService.Update(r.Context(), transactionID, &req)
+	transaction, err := h.transactionService.Update(r.Context(), householdID, transactionID, &req)
+	if errors.Is(err, service.ErrTransactionReconciled) || errors.Is(err, service.ErrTransactionPendingApproval) || errors.Is(err, service.ErrTransactionRejected) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrTransactionAmountSign) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -175,6 +208,7 @@ func (h *TransactionHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	role := middleware.GetUserRole(r.Context())
+	householdID := middleware.GetHouseholdID(r.Context())
 
 	transactionID := chi.URLParam(r, "id")
 	if transactionID == "" {
@@ -183,7 +217,7 @@ func (h *TransactionHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check ownership for non-admin
-	existing, err := h.transactionService.GetByID(r.Context(), transactionID)
+	existing, err := h.transactionService.GetByID(r.Context(), householdID, transactionID)
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, err.Error())
 		return
@@ -193,7 +227,12 @@ func (h *TransactionHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.transactionService.Delete(r.Context(), transactionID); err != nil {
+	err = h.transactionService.Delete(r.Context(), householdID, transactionID)
+	if errors.Is(err, service.ErrTransactionReconciled) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -201,6 +240,64 @@ func (h *TransactionHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Unlock makes a reconciled transaction editable again
+func (h *TransactionHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	transactionID := chi.URLParam(r, "id")
+	if transactionID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing transaction ID")
+		return
+	}
+
+	transaction, err := h.transactionService.Unlock(r.Context(), middleware.GetHouseholdID(r.Context()), transactionID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, transaction)
+}
+
+// PendingApproval lists the children's purchases waiting for approval (admin only)
+func (h *TransactionHandler) PendingApproval(w http.ResponseWriter, r *http.Request) {
+	transactions, err := h.transactionService.GetPendingApproval(r.Context(), middleware.GetHouseholdID(r.Context()))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, transactions)
+}
+
+// Approve books a child's purchase waiting for approval (admin only)
+func (h *TransactionHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.transactionService.Approve)
+}
+
+// Reject turns down a child's purchase waiting for approval (admin only)
+func (h *TransactionHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.transactionService.Reject)
+}
+
+func (h *TransactionHandler) review(w http.ResponseWriter, r *http.Request, review func(ctx context.Context, householdID, id string) (*model.Transaction, error)) {
+	transactionID := chi.URLParam(r, "id")
+	if transactionID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing transaction ID")
+		return
+	}
+
+	transaction, err := review(r.Context(), middleware.GetHouseholdID(r.Context()), transactionID)
+	if errors.Is(err, service.ErrTransactionNotPending) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, transaction)
+}
+
 func (h *TransactionHandler) GenerateRecurring(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 	if userID == "" {
@@ -227,3 +324,33 @@ func (h *TransactionHandler) GenerateRecurring(w http.ResponseWriter, r *http.Re
 
 	respondWithJSON(w, http.StatusOK, result)
 }
+
+// parsePageParams reads the sort, order, limit and cursor query parameters.
+// The limit defaults to model.DefaultPageLimit.
+func parsePageParams(r *http.Request) (model.PageParams, error) {
+	page := model.PageParams{
+		Sort:   r.URL.Query().Get("sort"),
+		Order:  r.URL.Query().Get("order"),
+		Limit:  model.DefaultPageLimit,
+		Cursor: r.URL.Query().Get("cursor"),
+	}
+
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit < 1 || limit > model.MaxPageLimit {
+			return page, fmt.Errorf("limit must be between 1 and %d", model.MaxPageLimit)
+		}
+		page.Limit = limit
+	}
+
+	if _, err := page.Normalize(); err != nil {
+		return page, err
+	}
+
+	return page, nil
+}
+
+// isCursorError reports whether err is about a cursor the client sent
+func isCursorError(err error) bool {
+	return errors.Is(err, model.ErrInvalidCursor) || errors.Is(err, model.ErrCursorSortMismatch)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/asilingas/fambudg/backend/internal/middleware"
+	"github.com/asilingas/fambudg/backend/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type TrashHandler struct {
+	trashService *service.TrashService
+}
+
+func NewTrashHandler(trashService *service.TrashService) *TrashHandler {
+	return &TrashHandler{trashService: trashService}
+}
+
+// List returns the trash: admin sees the whole household's, others their own
+// transactions and accounts
+func (h *TrashHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var owner *string
+	if middleware.GetUserRole(r.Context()) != "admin" {
+		owner = &userID
+	}
+
+	items, err := h.trashService.GetAll(r.Context(), middleware.GetHouseholdID(r.Context()), owner)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, items)
+}
+
+// Restore takes an item back out of the trash with ownership check
+func (h *TrashHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	if userID == "" {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	role := middleware.GetUserRole(r.Context())
+	householdID := middleware.GetHouseholdID(r.Context())
+
+	itemType := chi.URLParam(r, "type")
+	itemID := chi.URLParam(r, "id")
+	if itemType == "" || itemID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing trash item type or ID")
+		return
+	}
+
+	// Check ownership for non-admin
+	item, err := h.trashService.GetByID(r.Context(), householdID, itemType, itemID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if role != "admin" && (item.UserID == nil || *item.UserID != userID) {
+		respondWithError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+
+	err = h.trashService.Restore(r.Context(), householdID, itemType, itemID)
+	if errors.Is(err, service.ErrRestoreAccountFirst) || errors.Is(err, service.ErrRestoreCategoryFirst) || errors.Is(err, service.ErrBudgetExists) {
+		respondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,8 +2,10 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	"github.com/asilingas/fambudg/backend/internal/middleware"
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/asilingas/fambudg/backend/internal/service"
 	"github.com/go-chi/chi/v5"
@@ -23,7 +25,7 @@ func NewUserHandler(authService *service.AuthService) *UserHandler {
 }
 
 func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
-	users, err := h.authService.ListUsers(r.Context())
+	users, err := h.authService.ListUsers(r.Context(), middleware.GetHouseholdID(r.Context()))
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -44,7 +46,7 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.authService.CreateUser(r.Context(), &req)
+	user, err := h.authService.CreateUser(r.Context(), middleware.GetHouseholdID(r.Context()), &req)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
@@ -71,7 +73,7 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.authService.UpdateUser(r.Context(), userID, &req)
+	user, err := h.authService.UpdateUser(r.Context(), middleware.GetHouseholdID(r.Context()), userID, &req)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -80,6 +82,39 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, user)
 }
 
+// SetApprovalThreshold sets the amount above which a child's expenses need
+// approval, or removes it when null
+func (h *UserHandler) SetApprovalThreshold(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	if userID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing user ID")
+		return
+	}
+
+	var req model.SetApprovalThresholdRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.authService.SetApprovalThreshold(r.Context(), middleware.GetHouseholdID(r.Context()), userID, req.ApprovalThreshold)
+	if errors.Is(err, service.ErrApprovalThresholdNotChild) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, user)
+}
+
 func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	userID := chi.URLParam(r, "id")
 	if userID == "" {
@@ -87,10 +122,27 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.authService.DeleteUser(r.Context(), userID); err != nil {
+	if err := h.authService.DeleteUser(r.Context(), middleware.GetHouseholdID(r.Context()), userID); err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// RevokeSessions signs a user out on every device
+func (h *UserHandler) RevokeSessions(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	if userID == "" {
+		respondWithError(w, http.StatusBadRequest, "missing user ID")
+		return
+	}
+
+	result, err := h.authService.RevokeSessions(r.Context(), middleware.GetHouseholdID(r.Context()), userID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/asilingas/fambudg/backend/internal/audit"
 	"github.com/asilingas/fambudg/backend/internal/service"
 )
 
@@ -12,6 +13,7 @@ type contextKey string
 
 const UserIDKey contextKey = "user_id"
 const UserRoleKey contextKey = "user_role"
+const HouseholdIDKey contextKey = "household_id"
 
 // AuthMiddleware validates JWT tokens and attaches user info to context
 func AuthMiddleware(authService *service.AuthService) func(http.Handler) http.Handler {
@@ -53,9 +55,24 @@ func AuthMiddleware(authService *service.AuthService) func(http.Handler) http.Ha
 				return
 			}
 
+			householdID, ok := claims["household_id"].(string)
+			if !ok || householdID == "" {
+				http.Error(w, `{"error":"invalid token claims"}`, http.StatusUnauthorized)
+				return
+			}
+
+			// Until required two-factor authentication is set up, only the
+			// auth endpoints are open
+			if setup, _ := claims[service.TwoFactorSetupClaim].(bool); setup && !strings.HasPrefix(r.URL.Path, "/api/auth/") {
+				http.Error(w, `{"error":"two-factor authentication setup required"}`, http.StatusForbidden)
+				return
+			}
+
 			// Add user info to context
 			ctx := context.WithValue(r.Context(), UserIDKey, userID)
 			ctx = context.WithValue(ctx, UserRoleKey, userRole)
+			ctx = context.WithValue(ctx, HouseholdIDKey, householdID)
+			ctx = audit.WithActor(ctx, userID)
 
 			// Call next handler with updated context
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -79,6 +96,14 @@ func GetUserRole(ctx context.Context) string {
 	return ""
 }
 
+// GetHouseholdID extracts the caller's household ID from context
+func GetHouseholdID(ctx context.Context) string {
+	if householdID, ok := ctx.Value(HouseholdIDKey).(string); ok {
+		return householdID
+	}
+	return ""
+}
+
 // RequireRole returns middleware that restricts access to the given roles
 func RequireRole(roles ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -4,6 +4,8 @@ import (
 	"log"
 	"net/http"
 	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/audit"
 )
 
 type responseWriter struct {
@@ -23,7 +25,8 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return size, err
 }
 
-// LoggingMiddleware logs HTTP requests with method, path, status, and duration
+// LoggingMiddleware logs HTTP requests with method, path, status, duration
+// and request ID
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -40,11 +43,12 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		// Log request details
 		duration := time.Since(start)
 		log.Printf(
-			"%s %s - %d - %v",
+			"%s %s - %d - %v - %s",
 			r.Method,
 			r.URL.Path,
 			rw.status,
 			duration,
+			audit.RequestID(r.Context()),
 		)
 	})
 }
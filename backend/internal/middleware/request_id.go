@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/asilingas/fambudg/backend/internal/audit"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds IDs passed in by a proxy, matching the column
+// they are stored in
+const maxRequestIDLength = 64
+
+// RequestIDMiddleware tags each request with an ID, taken from the
+// X-Request-ID header when a proxy set one. The ID is echoed in the response
+// and stored with the audit events the request causes.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			buf := make([]byte, 16)
+			rand.Read(buf)
+			requestID = hex.EncodeToString(buf)
+		}
+
+		w.Header().Set(requestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(audit.WithRequestID(r.Context(), requestID)))
+	})
+}
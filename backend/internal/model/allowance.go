@@ -2,24 +2,65 @@ package model
 
 import "time"
 
+// How often an allowance is paid
+const (
+	AllowanceWeekly   = "weekly"
+	AllowanceBiweekly = "biweekly"
+	AllowanceMonthly  = "monthly"
+)
+
 type Allowance struct {
-	ID          string    `json:"id"`
-	UserID      string    `json:"userId"`
-	Amount      int64     `json:"amount"`
-	Spent       int64     `json:"spent"`
-	Remaining   int64     `json:"remaining"`
-	PeriodStart time.Time `json:"periodStart"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID            string    `json:"id"`
+	UserID        string    `json:"userId"`
+	Amount        int64     `json:"amount"`
+	Frequency     string    `json:"frequency"`
+	Rollover      bool      `json:"rollover"`                // unspent money carries over into the next period
+	FromAccountID *string   `json:"fromAccountId,omitempty"` // parent's account each period is topped up from
+	ToAccountID   *string   `json:"toAccountId,omitempty"`   // child's account topped up
+	CarriedOver   int64     `json:"carriedOver"`
+	Spent         int64     `json:"spent"`
+	Remaining     int64     `json:"remaining"`
+	PeriodStart   time.Time `json:"periodStart"`
+	PeriodEnd     time.Time `json:"periodEnd"` // the day the next period starts
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
 }
 
 type CreateAllowanceRequest struct {
-	UserID      string `json:"userId" validate:"required"`
-	Amount      int64  `json:"amount" validate:"required,gt=0"`
-	PeriodStart string `json:"periodStart" validate:"required"` // YYYY-MM-DD
+	UserID        string  `json:"userId" validate:"required"`
+	Amount        int64   `json:"amount" validate:"required,gt=0"`
+	Frequency     string  `json:"frequency" validate:"omitempty,oneof=weekly biweekly monthly"` // defaults to monthly
+	Rollover      bool    `json:"rollover"`
+	FromAccountID *string `json:"fromAccountId,omitempty"`
+	ToAccountID   *string `json:"toAccountId,omitempty"`
+	PeriodStart   string  `json:"periodStart" validate:"required"` // YYYY-MM-DD
 }
 
 type UpdateAllowanceRequest struct {
-	Amount      *int64  `json:"amount,omitempty" validate:"omitempty,gt=0"`
-	PeriodStart *string `json:"periodStart,omitempty"` // YYYY-MM-DD
+	Amount        *int64  `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	Frequency     *string `json:"frequency,omitempty" validate:"omitempty,oneof=weekly biweekly monthly"`
+	Rollover      *bool   `json:"rollover,omitempty"`
+	FromAccountID *string `json:"fromAccountId,omitempty"`
+	ToAccountID   *string `json:"toAccountId,omitempty"`
+	PeriodStart   *string `json:"periodStart,omitempty"` // YYYY-MM-DD
+}
+
+// AllowancePeriod is one period of an allowance, current or past
+type AllowancePeriod struct {
+	ID            string    `json:"id"`
+	PeriodStart   time.Time `json:"periodStart"`
+	PeriodEnd     time.Time `json:"periodEnd"` // the day the next period starts
+	Amount        int64     `json:"amount"`
+	CarriedOver   int64     `json:"carriedOver"`
+	Spent         int64     `json:"spent"`
+	Remaining     int64     `json:"remaining"`
+	TransactionID *string   `json:"transactionId,omitempty"` // the top-up transfer
+}
+
+// DueAllowance is an allowance whose current period has ended or is still to
+// be topped up
+type DueAllowance struct {
+	HouseholdID string
+	Allowance   *Allowance
+	ToppedUp    bool // the current period has been topped up
 }
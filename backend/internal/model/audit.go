@@ -0,0 +1,70 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Entities whose changes are recorded in the audit log
+const (
+	AuditEntityTransaction     = "transaction"
+	AuditEntityAccount         = "account"
+	AuditEntityBudget          = "budget"
+	AuditEntityBudgetTemplate  = "budget_template"
+	AuditEntitySavingGoal      = "saving_goal"
+	AuditEntityBillReminder    = "bill_reminder"
+	AuditEntityAllowance       = "allowance"
+	AuditEntityChore           = "chore"
+	AuditEntityChoreCompletion = "chore_completion"
+	AuditEntityUser            = "user"
+)
+
+// Audit actions. Besides create, update and delete, a few changes get a
+// name of their own.
+const (
+	AuditActionCreate     = "create"
+	AuditActionUpdate     = "update"
+	AuditActionDelete     = "delete"
+	AuditActionUnlock     = "unlock"     // a reconciled transaction made editable
+	AuditActionPay        = "pay"        // a bill paid, advancing its due date
+	AuditActionSkip       = "skip"       // a bill period skipped, advancing its due date
+	AuditActionDefer      = "defer"      // a bill put off to a later date
+	AuditActionContribute = "contribute" // money put towards a saving goal
+	AuditActionWithdraw   = "withdraw"   // money taken out of a saving goal
+	AuditActionRestore    = "restore"    // taken back out of the trash
+	AuditActionApprove    = "approve"    // a chore done approved and its reward paid
+	AuditActionReject     = "reject"     // a chore done turned down
+)
+
+// AuditEvent is one change to a household's data. Before is empty for
+// creates and After for deletes.
+type AuditEvent struct {
+	ID         string          `json:"id"`
+	ActorID    *string         `json:"actorId,omitempty"` // nil for changes made by the scheduler
+	ActorName  *string         `json:"actorName,omitempty"`
+	EntityType string          `json:"entityType"`
+	EntityID   string          `json:"entityId"`
+	Action     string          `json:"action"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	RequestID  *string         `json:"requestId,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+type AuditFilters struct {
+	EntityType string
+	EntityID   string
+	ActorID    string
+	Action     string
+	StartDate  string
+	EndDate    string
+	Limit      int    // 0 means DefaultPageLimit
+	Cursor     string // nextCursor of the previous page
+}
+
+// AuditPage is one page of the audit log, newest first. NextCursor is empty
+// on the last page.
+type AuditPage struct {
+	Events     []*AuditEvent `json:"events"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
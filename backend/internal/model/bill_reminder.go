@@ -2,43 +2,95 @@ package model
 
 import "time"
 
+// DefaultBillLeadDays is how many days before its due date a bill sends its
+// first notice unless set otherwise
+const DefaultBillLeadDays = 3
+
 type BillReminder struct {
-	ID          string     `json:"id"`
-	Name        string     `json:"name"`
-	Amount      int64      `json:"amount"`
-	DueDay      int        `json:"dueDay"`
-	Frequency   string     `json:"frequency"`
-	CategoryID  *string    `json:"categoryId,omitempty"`
-	AccountID   *string    `json:"accountId,omitempty"`
-	IsActive    bool       `json:"isActive"`
-	NextDueDate time.Time  `json:"nextDueDate"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Amount       int64      `json:"amount"`
+	DueDay       int        `json:"dueDay"`
+	Frequency    string     `json:"frequency"`
+	CategoryID   *string    `json:"categoryId,omitempty"`
+	AccountID    *string    `json:"accountId,omitempty"`
+	IsActive     bool       `json:"isActive"`
+	IsOverdue    bool       `json:"isOverdue"` // set by the scheduler once next_due_date has passed
+	NextDueDate  time.Time  `json:"nextDueDate"`
+	LeadDays     int        `json:"leadDays"`               // days before the due date to send the first notice
+	DeferredFrom *time.Time `json:"deferredFrom,omitempty"` // original due date of a deferred bill
+	RecipientIDs []string   `json:"recipientIds"`           // users notified; empty for every admin and member
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
 }
 
 type CreateBillReminderRequest struct {
-	Name        string  `json:"name" validate:"required,max=200"`
-	Amount      int64   `json:"amount" validate:"required,gt=0"`
-	DueDay      int     `json:"dueDay" validate:"required,min=1,max=31"`
-	Frequency   string  `json:"frequency" validate:"required,oneof=monthly quarterly yearly"`
-	CategoryID  *string `json:"categoryId,omitempty"`
-	AccountID   *string `json:"accountId,omitempty"`
-	NextDueDate string  `json:"nextDueDate" validate:"required"`
+	Name         string   `json:"name" validate:"required,max=200"`
+	Amount       int64    `json:"amount" validate:"required,gt=0"`
+	DueDay       int      `json:"dueDay" validate:"required,min=1,max=31"`
+	Frequency    string   `json:"frequency" validate:"required,oneof=monthly quarterly yearly"`
+	CategoryID   *string  `json:"categoryId,omitempty"`
+	AccountID    *string  `json:"accountId,omitempty"`
+	NextDueDate  string   `json:"nextDueDate" validate:"required"`
+	LeadDays     *int     `json:"leadDays,omitempty" validate:"omitempty,min=0,max=60"` // defaults to 3
+	RecipientIDs []string `json:"recipientIds,omitempty"`
 }
 
 type UpdateBillReminderRequest struct {
-	Name       *string `json:"name,omitempty" validate:"omitempty,max=200"`
-	Amount     *int64  `json:"amount,omitempty" validate:"omitempty,gt=0"`
-	DueDay     *int    `json:"dueDay,omitempty" validate:"omitempty,min=1,max=31"`
-	Frequency  *string `json:"frequency,omitempty" validate:"omitempty,oneof=monthly quarterly yearly"`
-	CategoryID *string `json:"categoryId,omitempty"`
-	AccountID  *string `json:"accountId,omitempty"`
-	IsActive   *bool   `json:"isActive,omitempty"`
+	Name         *string   `json:"name,omitempty" validate:"omitempty,max=200"`
+	Amount       *int64    `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	DueDay       *int      `json:"dueDay,omitempty" validate:"omitempty,min=1,max=31"`
+	Frequency    *string   `json:"frequency,omitempty" validate:"omitempty,oneof=monthly quarterly yearly"`
+	CategoryID   *string   `json:"categoryId,omitempty"`
+	AccountID    *string   `json:"accountId,omitempty"`
+	IsActive     *bool     `json:"isActive,omitempty"`
+	LeadDays     *int      `json:"leadDays,omitempty" validate:"omitempty,min=0,max=60"`
+	RecipientIDs *[]string `json:"recipientIds,omitempty"` // replaces the recipients; empty for every admin and member
+}
+
+// DueBill is an active bill within its lead time or overdue, with the
+// household it belongs to
+type DueBill struct {
+	HouseholdID string
+	Bill        *BillReminder
 }
 
 type PayBillRequest struct {
 	AccountID string `json:"accountId" validate:"required"`
 	Date      string `json:"date" validate:"required"`
+	Amount    *int64 `json:"amount,omitempty" validate:"omitempty,gt=0"` // amount actually paid; defaults to the bill's amount
+}
+
+type DeferBillRequest struct {
+	Date string `json:"date" validate:"required"` // new due date, after the current one
+}
+
+// How a period of a bill was settled
+const (
+	BillPaymentPaid    = "paid"
+	BillPaymentSkipped = "skipped"
+)
+
+// BillPayment records how one period of a bill was settled
+type BillPayment struct {
+	ID            string     `json:"id"`
+	TransactionID *string    `json:"transactionId,omitempty"` // the payment, unless skipped or purged
+	Status        string     `json:"status"`
+	PeriodDate    time.Time  `json:"periodDate"` // date the period was scheduled for
+	DueDate       time.Time  `json:"dueDate"`    // date it was due, after any deferral
+	Amount        *int64     `json:"amount,omitempty"`
+	PaidOn        *time.Time `json:"paidOn,omitempty"`
+	IsLate        bool       `json:"isLate"` // paid after the due date
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// BillHistory lists a bill's settled periods, newest first, with the amounts
+// used to forecast the next one
+type BillHistory struct {
+	Payments       []*BillPayment `json:"payments"`
+	AverageAmount  *int64         `json:"averageAmount,omitempty"`  // mean of the paid amounts
+	LastPaidAmount *int64         `json:"lastPaidAmount,omitempty"` // most recent paid amount
+	ForecastAmount int64          `json:"forecastAmount"`           // expected amount of the next period
 }
 
 type TransferRequest struct {
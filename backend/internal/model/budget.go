@@ -2,12 +2,20 @@ package model
 
 import "time"
 
+// What a budget's leftover does at the end of its month
+const (
+	RolloverNone    = "none"    // nothing carries over
+	RolloverSurplus = "surplus" // unspent money is added to next month's budget
+	RolloverFull    = "full"    // overspending is taken off next month's budget too
+)
+
 type Budget struct {
 	ID         string    `json:"id"`
 	CategoryID string    `json:"categoryId"`
 	Amount     int64     `json:"amount"` // budget limit in cents
 	Month      int       `json:"month"`
 	Year       int       `json:"year"`
+	Rollover   string    `json:"rollover"`
 	CreatedAt  time.Time `json:"createdAt"`
 }
 
@@ -16,18 +24,23 @@ type CreateBudgetRequest struct {
 	Amount     int64  `json:"amount" validate:"required,gt=0"`
 	Month      int    `json:"month" validate:"required,min=1,max=12"`
 	Year       int    `json:"year" validate:"required,min=2000"`
+	Rollover   string `json:"rollover" validate:"omitempty,oneof=none surplus full"`
 }
 
 type UpdateBudgetRequest struct {
-	Amount *int64 `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	Amount   *int64  `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	Rollover *string `json:"rollover,omitempty" validate:"omitempty,oneof=none surplus full"`
 }
 
 type BudgetSummary struct {
-	CategoryID   string `json:"categoryId"`
-	CategoryName string `json:"categoryName"`
-	BudgetAmount int64  `json:"budgetAmount"` // budget limit in cents
-	ActualAmount int64  `json:"actualAmount"` // actual spending in cents (positive = spent)
-	Remaining    int64  `json:"remaining"`    // budget - actual
+	CategoryID      string `json:"categoryId"`
+	CategoryName    string `json:"categoryName"`
+	Rollover        string `json:"rollover"`
+	BudgetAmount    int64  `json:"budgetAmount"`    // budget limit in cents
+	CarriedIn       int64  `json:"carriedIn"`       // carried over from last month, negative for overspending
+	EffectiveAmount int64  `json:"effectiveAmount"` // budget + carried in
+	ActualAmount    int64  `json:"actualAmount"`    // actual spending in cents (positive = spent)
+	Remaining       int64  `json:"remaining"`       // effective - actual, the running balance
 }
 
 type BudgetFilters struct {
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// BudgetTemplate sets the same budget for a category every month from its
+// start month until its end month, if it has one. It has either a monthly
+// Amount or an AnnualAmount spread over the twelve months of each year.
+type BudgetTemplate struct {
+	ID           string    `json:"id"`
+	CategoryID   string    `json:"categoryId"`
+	Amount       *int64    `json:"amount,omitempty"`       // monthly limit in cents
+	AnnualAmount *int64    `json:"annualAmount,omitempty"` // yearly limit in cents
+	StartMonth   int       `json:"startMonth"`
+	StartYear    int       `json:"startYear"`
+	EndMonth     *int      `json:"endMonth,omitempty"`
+	EndYear      *int      `json:"endYear,omitempty"`
+	Rollover     string    `json:"rollover"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type CreateBudgetTemplateRequest struct {
+	CategoryID   string `json:"categoryId" validate:"required"`
+	Amount       *int64 `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	AnnualAmount *int64 `json:"annualAmount,omitempty" validate:"omitempty,gt=0"`
+	StartMonth   int    `json:"startMonth" validate:"required,min=1,max=12"`
+	StartYear    int    `json:"startYear" validate:"required,min=2000"`
+	EndMonth     *int   `json:"endMonth,omitempty" validate:"omitempty,min=1,max=12"`
+	EndYear      *int   `json:"endYear,omitempty" validate:"omitempty,min=2000"`
+	Rollover     string `json:"rollover" validate:"omitempty,oneof=none surplus full"`
+}
+
+// UpdateBudgetTemplateRequest replaces the whole template
+type UpdateBudgetTemplateRequest = CreateBudgetTemplateRequest
+
+// CopyBudgetsRequest copies the budgets of the month before into a month
+type CopyBudgetsRequest struct {
+	Month int `json:"month" validate:"required,min=1,max=12"`
+	Year  int `json:"year" validate:"required,min=2000"`
+}
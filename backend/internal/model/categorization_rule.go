@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+const (
+	DescriptionMatchContains = "contains" // case-insensitive substring
+	DescriptionMatchRegex    = "regex"    // Go regular expression
+)
+
+// CategorizationRule fills in the category, tags or shared flag of
+// transactions that match all of its conditions. Rules are tried in ascending
+// priority order.
+type CategorizationRule struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+	IsActive bool   `json:"isActive"`
+
+	// Conditions; at least one is set
+	DescriptionPattern *string `json:"descriptionPattern,omitempty"`
+	DescriptionMatch   string  `json:"descriptionMatch"`
+	MinAmount          *int64  `json:"minAmount,omitempty"` // in cents, compared without sign
+	MaxAmount          *int64  `json:"maxAmount,omitempty"` // in cents, compared without sign
+	AccountID          *string `json:"accountId,omitempty"`
+	Counterparty       *string `json:"counterparty,omitempty"` // case-insensitive substring
+
+	// Actions; at least one is set
+	CategoryID *string  `json:"categoryId,omitempty"`
+	AddTags    []string `json:"addTags,omitempty"`
+	SetShared  *bool    `json:"setShared,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type CreateCategorizationRuleRequest struct {
+	Name               string   `json:"name" validate:"required,min=1,max=100"`
+	Priority           int      `json:"priority"`
+	IsActive           *bool    `json:"isActive,omitempty"`
+	DescriptionPattern *string  `json:"descriptionPattern,omitempty" validate:"omitempty,min=1,max=255"`
+	DescriptionMatch   string   `json:"descriptionMatch" validate:"omitempty,oneof=contains regex"`
+	MinAmount          *int64   `json:"minAmount,omitempty" validate:"omitempty,min=0"`
+	MaxAmount          *int64   `json:"maxAmount,omitempty" validate:"omitempty,min=0"`
+	AccountID          *string  `json:"accountId,omitempty"`
+	Counterparty       *string  `json:"counterparty,omitempty" validate:"omitempty,min=1,max=255"`
+	CategoryID         *string  `json:"categoryId,omitempty"`
+	AddTags            []string `json:"addTags,omitempty"`
+	SetShared          *bool    `json:"setShared,omitempty"`
+}
+
+// UpdateCategorizationRuleRequest replaces the whole rule; conditions and
+// actions left out are cleared
+type UpdateCategorizationRuleRequest = CreateCategorizationRuleRequest
+
+type ApplyRulesResponse struct {
+	Checked     int `json:"checked"`
+	Categorized int `json:"categorized"`
+}
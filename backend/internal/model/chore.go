@@ -0,0 +1,86 @@
+package model
+
+import "time"
+
+// How often a chore can be done, earning its reward each time
+const (
+	ChoreOnce    = "once"
+	ChoreDaily   = "daily"
+	ChoreWeekly  = "weekly"
+	ChoreMonthly = "monthly"
+)
+
+// Review states of a chore marked done
+const (
+	ChorePending  = "pending"
+	ChoreApproved = "approved"
+	ChoreRejected = "rejected"
+)
+
+type Chore struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Reward      int64     `json:"reward"`
+	Frequency   string    `json:"frequency"`
+	AssignedTo  *string   `json:"assignedTo,omitempty"` // the child who does it; empty for any child
+	IsActive    bool      `json:"isActive"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type CreateChoreRequest struct {
+	Name        string  `json:"name" validate:"required,max=200"`
+	Description string  `json:"description" validate:"max=1000"`
+	Reward      int64   `json:"reward" validate:"required,gt=0"`
+	Frequency   string  `json:"frequency" validate:"omitempty,oneof=once daily weekly monthly"` // defaults to once
+	AssignedTo  *string `json:"assignedTo,omitempty"`
+}
+
+type UpdateChoreRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,max=200"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Reward      *int64  `json:"reward,omitempty" validate:"omitempty,gt=0"`
+	Frequency   *string `json:"frequency,omitempty" validate:"omitempty,oneof=once daily weekly monthly"`
+	AssignedTo  *string `json:"assignedTo,omitempty"`
+	IsActive    *bool   `json:"isActive,omitempty"`
+}
+
+type CompleteChoreRequest struct {
+	Date *string `json:"date,omitempty"` // defaults to today
+}
+
+// ApproveChoreRequest picks the child's account the reward is paid into
+type ApproveChoreRequest struct {
+	AccountID *string `json:"accountId,omitempty"` // defaults to the account the child's allowance is paid into
+}
+
+// ChoreCompletion is a chore a child marked done, with its review
+type ChoreCompletion struct {
+	ID            string     `json:"id"`
+	ChoreID       *string    `json:"choreId,omitempty"` // empty once the chore is deleted
+	ChoreName     string     `json:"choreName"`
+	UserID        string     `json:"userId"`
+	Reward        int64      `json:"reward"`
+	Date          time.Time  `json:"date"`
+	Status        string     `json:"status"`
+	ReviewedBy    *string    `json:"reviewedBy,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewedAt,omitempty"`
+	TransactionID *string    `json:"transactionId,omitempty"` // the income transaction paying an approved chore
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+type ChoreCompletionFilters struct {
+	UserID string // only this child's
+	Status string
+}
+
+// ChoreEarnings is what a child earned with chores in a date range
+type ChoreEarnings struct {
+	UserID        string `json:"userId"`
+	Name          string `json:"name"`
+	Approved      int    `json:"approved"`
+	Earned        int64  `json:"earned"`
+	Pending       int    `json:"pending"`
+	PendingAmount int64  `json:"pendingAmount"`
+}
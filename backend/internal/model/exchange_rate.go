@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// ExchangeRate says that on Date one unit of Base is worth Rate units of Quote
+type ExchangeRate struct {
+	ID        string    `json:"id"`
+	Date      time.Time `json:"date"`
+	Base      string    `json:"base"`
+	Quote     string    `json:"quote"`
+	Rate      float64   `json:"rate"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ExchangeRateFilters struct {
+	Base      string
+	Quote     string
+	StartDate string // YYYY-MM-DD
+	EndDate   string // YYYY-MM-DD
+}
+
+type ImportExchangeRatesResponse struct {
+	Imported int      `json:"imported"`
+	Errors   []string `json:"errors"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+type Household struct {
+	ID                    string    `json:"id"`
+	Name                  string    `json:"name"`
+	BaseCurrency          string    `json:"baseCurrency"`          // currency reports and budget summaries are converted to
+	RequireAdminTwoFactor bool      `json:"requireAdminTwoFactor"` // admins must set up two-factor authentication
+	CreatedAt             time.Time `json:"createdAt"`
+	UpdatedAt             time.Time `json:"updatedAt"`
+}
+
+type UpdateHouseholdRequest struct {
+	Name                  *string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	BaseCurrency          *string `json:"baseCurrency,omitempty" validate:"omitempty,len=3,uppercase"`
+	RequireAdminTwoFactor *bool   `json:"requireAdminTwoFactor,omitempty"`
+}
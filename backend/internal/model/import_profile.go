@@ -0,0 +1,81 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// Amount sign conventions of a bank CSV export
+const (
+	AmountSignSigned      = "signed"       // one amount column, negative for money going out
+	AmountSignInverted    = "inverted"     // one amount column, positive for money going out (card statements)
+	AmountSignDebitCredit = "debit_credit" // separate debit and credit columns, both unsigned
+)
+
+// ImportProfile describes the CSV layout of one bank's export
+type ImportProfile struct {
+	ID               string              `json:"id"`
+	Name             string              `json:"name"`
+	Delimiter        string              `json:"delimiter"`
+	Encoding         string              `json:"encoding"`         // WHATWG name, e.g. utf-8, windows-1257
+	HeaderRow        int                 `json:"headerRow"`        // 1-based line of the header, 0 if there is none; lines above it are skipped
+	DateLayout       string              `json:"dateLayout"`       // Go reference layout, e.g. 02.01.2006
+	DecimalSeparator string              `json:"decimalSeparator"` // "." or ","; the other one is read as a thousands separator
+	AmountSign       string              `json:"amountSign"`
+	Columns          ImportColumnMapping `json:"columns"`
+	CreatedAt        time.Time           `json:"createdAt"`
+	UpdatedAt        time.Time           `json:"updatedAt"`
+}
+
+// ImportColumnMapping maps transaction fields to CSV columns. A column is
+// given by its header name (case-insensitive) or by its 1-based position.
+type ImportColumnMapping struct {
+	Date         string `json:"date"`
+	Amount       string `json:"amount,omitempty"`
+	Debit        string `json:"debit,omitempty"`
+	Credit       string `json:"credit,omitempty"`
+	Description  string `json:"description,omitempty"`
+	Counterparty string `json:"counterparty,omitempty"`
+	Reference    string `json:"reference,omitempty"` // bank's transaction ID, used to skip rows already imported
+	Currency     string `json:"currency,omitempty"`
+}
+
+// Scan implements sql.Scanner for ImportColumnMapping
+func (m *ImportColumnMapping) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, m)
+}
+
+// Value implements driver.Valuer for ImportColumnMapping
+func (m ImportColumnMapping) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
+
+type CreateImportProfileRequest struct {
+	Name             string              `json:"name" validate:"required,min=1,max=100"`
+	Delimiter        string              `json:"delimiter" validate:"omitempty,len=1"`
+	Encoding         string              `json:"encoding"`
+	HeaderRow        *int                `json:"headerRow,omitempty" validate:"omitempty,min=0"`
+	DateLayout       string              `json:"dateLayout" validate:"omitempty,max=50"`
+	DecimalSeparator string              `json:"decimalSeparator" validate:"omitempty,oneof=. ,"`
+	AmountSign       string              `json:"amountSign" validate:"omitempty,oneof=signed inverted debit_credit"`
+	Columns          ImportColumnMapping `json:"columns"`
+}
+
+type UpdateImportProfileRequest struct {
+	Name             *string              `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Delimiter        *string              `json:"delimiter,omitempty" validate:"omitempty,len=1"`
+	Encoding         *string              `json:"encoding,omitempty"`
+	HeaderRow        *int                 `json:"headerRow,omitempty" validate:"omitempty,min=0"`
+	DateLayout       *string              `json:"dateLayout,omitempty" validate:"omitempty,max=50"`
+	DecimalSeparator *string              `json:"decimalSeparator,omitempty" validate:"omitempty,oneof=. ,"`
+	AmountSign       *string              `json:"amountSign,omitempty" validate:"omitempty,oneof=signed inverted debit_credit"`
+	Columns          *ImportColumnMapping `json:"columns,omitempty"`
+}
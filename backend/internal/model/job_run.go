@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// JobRun records one execution of a background job
+type JobRun struct {
+	ID         string     `json:"id"`
+	JobName    string     `json:"jobName"`
+	RunDate    time.Time  `json:"runDate"` // the day the job ran for
+	Status     string     `json:"status"`  // running, succeeded, failed
+	Summary    string     `json:"summary"`
+	Error      *string    `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
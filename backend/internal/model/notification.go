@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// Kinds of notifications
+const (
+	NotificationBudgetThreshold   = "budget_threshold"   // a budget passed 80% or 100%
+	NotificationAllowanceDepleted = "allowance_depleted" // a child's allowance ran out
+	NotificationLargeTransaction  = "large_transaction"  // a transaction over the user's threshold
+	NotificationBillDue           = "bill_due"           // a bill is coming up, due today or overdue
+)
+
+// NotificationTypes lists every kind of notification
+var NotificationTypes = []string{
+	NotificationBudgetThreshold,
+	NotificationAllowanceDepleted,
+	NotificationLargeTransaction,
+	NotificationBillDue,
+}
+
+// Channels that deliver notifications besides the in-app feed
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+// DefaultLargeTransactionThreshold is the amount in cents from which a
+// transaction counts as large unless the user chose otherwise
+const DefaultLargeTransactionThreshold int64 = 50000
+
+type Notification struct {
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body,omitempty"`
+	EntityID  *string    `json:"entityId,omitempty"` // the budgeted category, allowance or transaction it is about
+	ReadAt    *time.Time `json:"readAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// NotificationPreference is a user's choice for one kind of notification
+type NotificationPreference struct {
+	Type      string   `json:"type"`
+	Enabled   bool     `json:"enabled"`
+	Channels  []string `json:"channels"`            // delivery channels besides the in-app feed
+	Threshold *int64   `json:"threshold,omitempty"` // large transactions only, in cents
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceRequest `json:"preferences" validate:"required,dive"`
+}
+
+type NotificationPreferenceRequest struct {
+	Type      string   `json:"type" validate:"required,oneof=budget_threshold allowance_depleted large_transaction bill_due"`
+	Enabled   bool     `json:"enabled"`
+	Channels  []string `json:"channels" validate:"dive,oneof=email webhook"`
+	Threshold *int64   `json:"threshold,omitempty" validate:"omitempty,gt=0"`
+}
+
+// NotificationFeed is a user's notifications, newest first, with the number
+// not yet read
+type NotificationFeed struct {
+	Notifications []*Notification `json:"notifications"`
+	Unread        int             `json:"unread"`
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+var (
+	// ErrInvalidCursor is returned for a cursor that was not issued as the
+	// nextCursor of a page
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrCursorSortMismatch is returned for a cursor issued for a different
+	// sort field or order than the one requested
+	ErrCursorSortMismatch = errors.New("cursor does not match sort order")
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// TransactionSortFields are the fields transaction lists can be sorted by
+var TransactionSortFields = map[string]bool{
+	"date":        true,
+	"amount":      true,
+	"description": true,
+	"createdAt":   true,
+}
+
+// PageParams selects one page of a keyset-paginated list. A zero Limit
+// returns every row.
+type PageParams struct {
+	Sort   string // date (default), amount, description, createdAt
+	Order  string // desc (default), asc
+	Limit  int
+	Cursor string // nextCursor of the previous page
+}
+
+// Cursor identifies the last row of a page. Ties on the sort field are broken
+// by created_at and then uuid, so every row has a unique position.
+type Cursor struct {
+	Sort      string    `json:"s"`
+	Order     string    `json:"o"`
+	Value     string    `json:"v"`
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+}
+
+// Encode returns the cursor as an opaque URL-safe string
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Normalize applies the default sort and order, validates them and decodes
+// the cursor. It returns nil when no cursor was given.
+func (p *PageParams) Normalize() (*Cursor, error) {
+	if p.Sort == "" {
+		p.Sort = "date"
+	}
+	if p.Order == "" {
+		p.Order = "desc"
+	}
+
+	if !TransactionSortFields[p.Sort] {
+		return nil, fmt.Errorf("invalid sort field")
+	}
+	if p.Order != "asc" && p.Order != "desc" {
+		return nil, fmt.Errorf("invalid sort order")
+	}
+	if p.Limit < 0 || p.Limit > MaxPageLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxPageLimit)
+	}
+
+	if p.Cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	cursor := &Cursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || !cursor.valid() {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != p.Sort || cursor.Order != p.Order {
+		return nil, ErrCursorSortMismatch
+	}
+
+	return cursor, nil
+}
+
+// valid reports whether the cursor's ID is a UUID and its value can be read
+// as its sort field, so that a tampered cursor fails here rather than in the
+// database
+func (c *Cursor) valid() bool {
+	if !uuidPattern.MatchString(c.ID) {
+		return false
+	}
+
+	var err error
+	switch c.Sort {
+	case "date":
+		_, err = time.Parse("2006-01-02", c.Value)
+	case "amount":
+		_, err = strconv.ParseInt(c.Value, 10, 64)
+	case "createdAt":
+		_, err = time.Parse(time.RFC3339Nano, c.Value)
+	}
+	return err == nil
+}
+
+// TransactionPage is one page of a transaction list. TotalCount counts every
+// matching transaction, not just this page; NextCursor is empty on the last page.
+type TransactionPage struct {
+	Transactions []*Transaction `json:"transactions"`
+	TotalCount   int            `json:"totalCount"`
+	NextCursor   string         `json:"nextCursor,omitempty"`
+}
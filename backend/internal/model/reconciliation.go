@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+const (
+	ReconciliationOpen      = "open"
+	ReconciliationCompleted = "completed"
+)
+
+// Reconciliation checks an account against a bank statement. While it is
+// open, transactions are marked cleared until the cleared balance matches the
+// statement's closing balance; completing it marks them reconciled.
+type Reconciliation struct {
+	ID                      string     `json:"id"`
+	AccountID               string     `json:"accountId"`
+	StatementDate           time.Time  `json:"statementDate"`
+	ClosingBalance          int64      `json:"closingBalance"` // in cents
+	Status                  string     `json:"status"`
+	AdjustmentTransactionID *string    `json:"adjustmentTransactionId,omitempty"`
+	CompletedAt             *time.Time `json:"completedAt,omitempty"`
+	CreatedAt               time.Time  `json:"createdAt"`
+
+	// Only set while open
+	ClearedBalance *int64         `json:"clearedBalance,omitempty"` // account balance counting cleared and reconciled transactions only
+	Difference     *int64         `json:"difference,omitempty"`     // closing balance minus cleared balance
+	Transactions   []*Transaction `json:"transactions,omitempty"`   // not yet reconciled, up to the statement date
+}
+
+type CreateReconciliationRequest struct {
+	AccountID      string `json:"accountId" validate:"required"`
+	StatementDate  string `json:"statementDate" validate:"required"` // YYYY-MM-DD format
+	ClosingBalance int64  `json:"closingBalance"`
+}
+
+type ClearTransactionsRequest struct {
+	TransactionIDs []string `json:"transactionIds" validate:"required,min=1"`
+	Cleared        bool     `json:"cleared"` // false marks them uncleared again
+}
+
+type CompleteReconciliationRequest struct {
+	// Book the difference as an adjustment transaction instead of failing
+	Adjust     bool   `json:"adjust"`
+	CategoryID string `json:"categoryId,omitempty"` // category of the adjustment
+}
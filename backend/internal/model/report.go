@@ -23,6 +23,19 @@ type CategorySpending struct {
 	Percentage   float64 `json:"percentage"`
 }
 
+// CategorySpendingNode is a category in the spending tree. Its subtotal
+// includes everything spent in the categories below it.
+type CategorySpendingNode struct {
+	CategoryID   string                  `json:"categoryId"`
+	CategoryName string                  `json:"categoryName"`
+	ParentID     *string                 `json:"parentId,omitempty"`
+	Depth        int                     `json:"depth"`       // 1 for top-level categories
+	OwnAmount    int64                   `json:"ownAmount"`   // spent in the category itself
+	TotalAmount  int64                   `json:"totalAmount"` // subtotal with all subcategories
+	Percentage   float64                 `json:"percentage"`  // of all spending
+	Children     []*CategorySpendingNode `json:"children,omitempty"`
+}
+
 type MemberSpending struct {
 	UserID       string `json:"userId"`
 	UserName     string `json:"userName"`
@@ -49,12 +62,11 @@ type SearchFilters struct {
 	AccountID   string
 	Tags        []string
 	UserID      string
+	PageParams
 }
 
-type SearchResult struct {
-	Transactions []*Transaction `json:"transactions"`
-	TotalCount   int            `json:"totalCount"`
-}
+// SearchResult is a page of search matches
+type SearchResult = TransactionPage
 
 // Helpers to compute date ranges from month/year
 func (f *ReportFilters) DateRange() (time.Time, time.Time) {
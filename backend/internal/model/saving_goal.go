@@ -6,17 +6,38 @@ type SavingGoal struct {
 	ID            string     `json:"id"`
 	Name          string     `json:"name"`
 	TargetAmount  int64      `json:"targetAmount"`
-	CurrentAmount int64      `json:"currentAmount"`
+	CurrentAmount int64      `json:"currentAmount"`       // sum of the goal's contributions
+	AccountID     *string    `json:"accountId,omitempty"` // savings account the money is kept in
 	TargetDate    *time.Time `json:"targetDate,omitempty"`
 	Priority      int        `json:"priority"`
 	Status        string     `json:"status"`
 	CreatedAt     time.Time  `json:"createdAt"`
 	UpdatedAt     time.Time  `json:"updatedAt"`
+
+	Projection *GoalProjection `json:"projection,omitempty"` // in the goals list; none for cancelled goals
+}
+
+// How a goal is doing against its target date
+const (
+	GoalOnTrack      = "on_track"
+	GoalBehind       = "behind"
+	GoalCompleted    = "completed"
+	GoalNoTargetDate = "no_target_date"
+)
+
+// GoalProjection forecasts a saving goal from its contribution history
+type GoalProjection struct {
+	Remaining           int64      `json:"remaining"`                     // still to save
+	MonthlyPace         int64      `json:"monthlyPace"`                   // net saved per month recently
+	ProjectedCompletion *time.Time `json:"projectedCompletion,omitempty"` // when the target is reached at the current pace
+	RequiredMonthly     *int64     `json:"requiredMonthly,omitempty"`     // per month needed to reach the target by its date
+	Status              string     `json:"status"`
 }
 
 type CreateSavingGoalRequest struct {
 	Name         string  `json:"name" validate:"required,max=200"`
 	TargetAmount int64   `json:"targetAmount" validate:"required,gt=0"`
+	AccountID    *string `json:"accountId,omitempty"`
 	TargetDate   *string `json:"targetDate,omitempty"`
 	Priority     int     `json:"priority" validate:"min=1"`
 }
@@ -24,11 +45,35 @@ type CreateSavingGoalRequest struct {
 type UpdateSavingGoalRequest struct {
 	Name         *string `json:"name,omitempty" validate:"omitempty,max=200"`
 	TargetAmount *int64  `json:"targetAmount,omitempty" validate:"omitempty,gt=0"`
+	AccountID    *string `json:"accountId,omitempty"`
 	TargetDate   *string `json:"targetDate,omitempty"`
 	Priority     *int    `json:"priority,omitempty" validate:"omitempty,min=1"`
 	Status       *string `json:"status,omitempty" validate:"omitempty,oneof=active completed cancelled"`
 }
 
+// ContributeRequest puts money towards a goal. For a goal bound to an
+// account, it is transferred there from FromAccountID.
 type ContributeRequest struct {
-	Amount int64 `json:"amount" validate:"required,gt=0"`
+	Amount        int64   `json:"amount" validate:"required,gt=0"`
+	FromAccountID *string `json:"fromAccountId,omitempty"`
+	Date          *string `json:"date,omitempty"` // defaults to today
+}
+
+// WithdrawRequest takes money out of a goal. For a goal bound to an account,
+// it is transferred from there to ToAccountID.
+type WithdrawRequest struct {
+	Amount      int64   `json:"amount" validate:"required,gt=0"`
+	ToAccountID *string `json:"toAccountId,omitempty"`
+	Date        *string `json:"date,omitempty"` // defaults to today
+}
+
+// GoalContribution is money put towards a saving goal, or taken out of it
+// when Amount is negative
+type GoalContribution struct {
+	ID            string    `json:"id"`
+	UserID        *string   `json:"userId,omitempty"`        // who made it; empty for amounts saved before contributions were recorded
+	TransactionID *string   `json:"transactionId,omitempty"` // the transfer, for a goal bound to an account
+	Amount        int64     `json:"amount"`
+	Date          time.Time `json:"date"`
+	CreatedAt     time.Time `json:"createdAt"`
 }
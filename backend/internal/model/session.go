@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Session is a refresh token issued at login. Only a hash of the token is
+// stored.
+type Session struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"userId"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+type RevokeSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
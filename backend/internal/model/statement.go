@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+const (
+	StatementFormatOFX     = "ofx"     // OFX 1.x (SGML) and 2.x (XML), including Quicken QFX
+	StatementFormatCAMT053 = "camt053" // ISO 20022 bank-to-customer statement
+	StatementFormatCSV     = "csv"     // bank CSV export read through an import profile
+)
+
+// StatementLine is one entry read from a bank statement file
+type StatementLine struct {
+	Row          int    // CSV line number, or position of the entry in OFX/CAMT.053 files
+	ExternalID   string // FITID (OFX), AcctSvcrRef (CAMT.053) or the profile's reference column
+	Date         time.Time
+	Amount       int64  // in cents, positive=credit, negative=debit
+	Currency     string // empty if the file does not say
+	Description  string
+	Counterparty string   // payee or payer, if the file names one
+	Errors       []string // why the line cannot be imported
+}
+
+type ImportStatementRequest struct {
+	AccountID        string `validate:"required"`
+	CategoryID       string // fallback for debits no rule categorizes, and credits too unless IncomeCategoryID is set
+	IncomeCategoryID string
+	Format           string `validate:"omitempty,oneof=ofx camt053 csv"` // detected from the file if empty
+	ProfileID        string `validate:"required_if=Format csv"`
+}
+
+type ImportStatementResponse struct {
+	Imported   int      `json:"imported"`
+	Duplicates int      `json:"duplicates"`
+	Errors     []string `json:"errors"`
+}
+
+// Statuses of a previewed statement line
+const (
+	ImportRowValid     = "valid"
+	ImportRowDuplicate = "duplicate"
+	ImportRowInvalid   = "invalid"
+)
+
+// ImportPreviewRow is a statement line as it would be imported
+type ImportPreviewRow struct {
+	Row         int      `json:"row"`
+	Status      string   `json:"status"`
+	Date        string   `json:"date,omitempty"`
+	Amount      int64    `json:"amount"`
+	Type        string   `json:"type,omitempty"`
+	CategoryID  string   `json:"categoryId,omitempty"`
+	Description string   `json:"description,omitempty"`
+	ExternalID  string   `json:"externalId,omitempty"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+type ImportPreviewResponse struct {
+	Rows       []*ImportPreviewRow `json:"rows"`
+	Valid      int                 `json:"valid"`
+	Duplicates int                 `json:"duplicates"`
+	Invalid    int                 `json:"invalid"`
+}
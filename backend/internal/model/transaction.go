@@ -6,22 +6,64 @@ import (
 	"time"
 )
 
+// Transaction statuses, from entered to confirmed against a bank statement
+const (
+	TransactionUncleared  = "uncleared"
+	TransactionCleared    = "cleared"    // seen on the bank account
+	TransactionReconciled = "reconciled" // part of a completed reconciliation, locked
+)
+
+// Statuses of a child's purchase above their approval threshold. Until it is
+// approved it does not count towards balances, budgets or reports.
+const (
+	TransactionPendingApproval = "pending_approval"
+	TransactionRejected        = "rejected"
+)
+
 type Transaction struct {
-	ID                  string         `json:"id"`
-	UserID              string         `json:"userId"`
-	AccountID           string         `json:"accountId"`
-	CategoryID          string         `json:"categoryId"`
-	Amount              int64          `json:"amount"` // in cents, positive=income, negative=expense
-	Type                string         `json:"type" validate:"required,oneof=expense income transfer"`
-	Description         string         `json:"description,omitempty"`
-	Date                time.Time      `json:"date"`
-	IsShared            bool           `json:"isShared"`
-	IsRecurring         bool           `json:"isRecurring"`
-	RecurringRule       *RecurringRule `json:"recurringRule,omitempty"`
-	Tags                []string       `json:"tags,omitempty"`
-	TransferToAccountID *string        `json:"transferToAccountId,omitempty"`
-	CreatedAt           time.Time      `json:"createdAt"`
-	UpdatedAt           time.Time      `json:"updatedAt"`
+	ID                  string              `json:"id"`
+	UserID              string              `json:"userId"`
+	AccountID           string              `json:"accountId"`
+	CategoryID          *string             `json:"categoryId,omitempty"` // nil until categorized
+	Amount              int64               `json:"amount"`               // in cents, positive=income, negative=expense
+	Type                string              `json:"type" validate:"required,oneof=expense income transfer"`
+	Description         string              `json:"description,omitempty"`
+	Counterparty        string              `json:"counterparty,omitempty"`
+	Date                time.Time           `json:"date"`
+	IsShared            bool                `json:"isShared"`
+	IsRecurring         bool                `json:"isRecurring"`
+	RecurringRule       *RecurringRule      `json:"recurringRule,omitempty"`
+	Tags                []string            `json:"tags,omitempty"`
+	TransferToAccountID *string             `json:"transferToAccountId,omitempty"`
+	ExternalID          *string             `json:"externalId,omitempty"` // bank reference of an imported statement line
+	Status              string              `json:"status"`
+	Splits              []*TransactionSplit `json:"splits,omitempty"`
+	CreatedAt           time.Time           `json:"createdAt"`
+	UpdatedAt           time.Time           `json:"updatedAt"`
+}
+
+// AffectsBalance reports whether the transaction counts towards its account
+// balance, which it does unless it waits for approval or was rejected
+func (t *Transaction) AffectsBalance() bool {
+	return t.Status != TransactionPendingApproval && t.Status != TransactionRejected
+}
+
+// TransactionSplit is the part of a transaction that belongs to one category,
+// e.g. the household supplies on a supermarket receipt. The splits of a
+// transaction add up to its amount.
+type TransactionSplit struct {
+	ID         string   `json:"id"`
+	CategoryID string   `json:"categoryId"`
+	Amount     int64    `json:"amount"` // in cents, same sign as the transaction
+	Memo       string   `json:"memo,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+type TransactionSplitRequest struct {
+	CategoryID string   `json:"categoryId" validate:"required"`
+	Amount     int64    `json:"amount" validate:"required"`
+	Memo       string   `json:"memo" validate:"max=255"`
+	Tags       []string `json:"tags,omitempty"`
 }
 
 type RecurringRule struct {
@@ -48,27 +90,34 @@ func (r RecurringRule) Value() (driver.Value, error) {
 }
 
 type CreateTransactionRequest struct {
-	AccountID           string         `json:"accountId" validate:"required"`
-	CategoryID          string         `json:"categoryId" validate:"required"`
-	Amount              int64          `json:"amount" validate:"required"`
-	Type                string         `json:"type" validate:"required,oneof=expense income transfer"`
-	Description         string         `json:"description"`
-	Date                string         `json:"date" validate:"required"` // YYYY-MM-DD format
-	IsShared            bool           `json:"isShared"`
-	IsRecurring         bool           `json:"isRecurring"`
-	RecurringRule       *RecurringRule `json:"recurringRule,omitempty"`
-	Tags                []string       `json:"tags,omitempty"`
-	TransferToAccountID *string        `json:"transferToAccountId,omitempty"`
+	AccountID           string                    `json:"accountId" validate:"required"`
+	CategoryID          string                    `json:"categoryId"` // optional; categorization rules fill it in when empty
+	Amount              int64                     `json:"amount" validate:"required"`
+	Type                string                    `json:"type" validate:"required,oneof=expense income transfer"`
+	Description         string                    `json:"description"`
+	Counterparty        string                    `json:"counterparty" validate:"max=255"`
+	Date                string                    `json:"date" validate:"required"` // YYYY-MM-DD format
+	IsShared            bool                      `json:"isShared"`
+	IsRecurring         bool                      `json:"isRecurring"`
+	RecurringRule       *RecurringRule            `json:"recurringRule,omitempty"`
+	Tags                []string                  `json:"tags,omitempty"`
+	TransferToAccountID *string                   `json:"transferToAccountId,omitempty"`
+	ExternalID          *string                   `json:"externalId,omitempty"`
+	Splits              []TransactionSplitRequest `json:"splits,omitempty" validate:"omitempty,dive"`
+	Status              string                    `json:"status,omitempty" validate:"omitempty,oneof=uncleared cleared"` // uncleared if empty
 }
 
 type UpdateTransactionRequest struct {
-	AccountID   *string  `json:"accountId,omitempty"`
-	CategoryID  *string  `json:"categoryId,omitempty"`
-	Amount      *int64   `json:"amount,omitempty"`
-	Description *string  `json:"description,omitempty"`
-	Date        *string  `json:"date,omitempty"` // YYYY-MM-DD format
-	IsShared    *bool    `json:"isShared,omitempty"`
-	Tags        []string `json:"tags,omitempty"`
+	AccountID    *string                    `json:"accountId,omitempty"`
+	CategoryID   *string                    `json:"categoryId,omitempty"`
+	Amount       *int64                     `json:"amount,omitempty"`
+	Description  *string                    `json:"description,omitempty"`
+	Counterparty *string                    `json:"counterparty,omitempty" validate:"omitempty,max=255"`
+	Date         *string                    `json:"date,omitempty"` // YYYY-MM-DD format
+	IsShared     *bool                      `json:"isShared,omitempty"`
+	Tags         []string                   `json:"tags,omitempty"`
+	Splits       *[]TransactionSplitRequest `json:"splits,omitempty" validate:"omitempty,dive"` // replaces all splits; empty removes them
+	Status       *string                    `json:"status,omitempty" validate:"omitempty,oneof=uncleared cleared"`
 }
 
 type GenerateRecurringResponse struct {
@@ -84,4 +133,5 @@ type TransactionFilters struct {
 	EndDate    string // YYYY-MM-DD
 	Type       string
 	IsShared   *bool
+	PageParams
 }
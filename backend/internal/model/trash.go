@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Kinds of rows that are moved to the trash when deleted
+const (
+	TrashTransaction = "transaction"
+	TrashAccount     = "account"
+	TrashCategory    = "category"
+	TrashBudget      = "budget"
+	TrashSavingGoal  = "saving_goal"
+)
+
+// TrashItem is a deleted row that can still be restored until PurgeAt
+type TrashItem struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Amount    *int64    `json:"amount,omitempty"` // transaction amount, account balance, budget amount or goal target
+	UserID    *string   `json:"userId,omitempty"` // owner of a transaction or account
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt"`
+}
+
+// PurgeResult counts the rows removed for good from the trash
+type PurgeResult struct {
+	Transactions int64 `json:"transactions"`
+	Accounts     int64 `json:"accounts"`
+	Categories   int64 `json:"categories"`
+	Budgets      int64 `json:"budgets"`
+	SavingGoals  int64 `json:"savingGoals"`
+}
@@ -3,19 +3,24 @@ package model
 import "time"
 
 type User struct {
-	ID           string    `json:"id"`
-	Email        string    `json:"email" validate:"required,email"`
-	PasswordHash string    `json:"-"`
-	Name         string    `json:"name" validate:"required,min=2,max=100"`
-	Role         string    `json:"role" validate:"required,oneof=admin member child"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID                string    `json:"id"`
+	HouseholdID       string    `json:"householdId"`
+	Email             string    `json:"email" validate:"required,email"`
+	PasswordHash      string    `json:"-"`
+	Name              string    `json:"name" validate:"required,min=2,max=100"`
+	Role              string    `json:"role" validate:"required,oneof=admin member child"`
+	TwoFactorEnabled  bool      `json:"twoFactorEnabled"`
+	ApprovalThreshold *int64    `json:"approvalThreshold,omitempty"` // children only: expenses above it wait for approval
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
 }
 
 type RegisterRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
-	Name     string `json:"name" validate:"required,min=2,max=100"`
+	Email         string `json:"email" validate:"required,email"`
+	Password      string `json:"password" validate:"required,min=8"`
+	Name          string `json:"name" validate:"required,min=2,max=100"`
+	HouseholdName string `json:"householdName,omitempty" validate:"omitempty,min=2,max=100"`
+	BaseCurrency  string `json:"baseCurrency,omitempty" validate:"omitempty,len=3,uppercase"` // defaults to EUR
 }
 
 type CreateUserRequest struct {
@@ -30,12 +35,49 @@ type UpdateUserRequest struct {
 	Role *string `json:"role,omitempty" validate:"omitempty,oneof=admin member child"`
 }
 
+// SetApprovalThresholdRequest sets the amount (in cents) above which a
+// child's expenses need an admin's approval; null removes it
+type SetApprovalThresholdRequest struct {
+	ApprovalThreshold *int64 `json:"approvalThreshold" validate:"omitempty,min=0"`
+}
+
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
 }
 
+// LoginResponse carries the tokens of a new session. When the user has
+// two-factor authentication enabled, Login returns only a challenge token
+// instead, to be exchanged for the session with a code.
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token        string     `json:"token,omitempty"` // short-lived access token
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	RefreshToken string     `json:"refreshToken,omitempty"`
+	User         User       `json:"user"`
+
+	TwoFactorRequired bool   `json:"twoFactorRequired,omitempty"`
+	ChallengeToken    string `json:"challengeToken,omitempty"`
+
+	// The household requires two-factor authentication for the user's role
+	// and it is not set up yet; until it is, only the /api/auth endpoints
+	// accept the access token
+	TwoFactorSetupRequired bool `json:"twoFactorSetupRequired,omitempty"`
+}
+
+type VerifyLoginRequest struct {
+	ChallengeToken string `json:"challengeToken" validate:"required"`
+	Code           string `json:"code" validate:"required"` // TOTP code or recovery code
+}
+
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"` // otpauth:// URI to show as a QR code
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type TwoFactorEnableResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"` // shown once; each signs in a single time
 }
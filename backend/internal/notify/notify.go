@@ -0,0 +1,20 @@
+// Package notify delivers notifications outside the app, by email or to a
+// webhook.
+package notify
+
+import "context"
+
+// Message is a notification addressed to one user
+type Message struct {
+	Type    string // kind of notification, e.g. budget_threshold
+	To      string // the user's email address
+	Subject string
+	Body    string
+}
+
+// Channel delivers messages by one means, e.g. email. Channels are registered
+// with the notification service by name; users pick the names they want.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/asilingas/fambudg/backend/internal/notify/smtptest"
+)
+
+func TestSMTPChannelSend(t *testing.T) {
+	server, err := smtptest.NewServer()
+	if err != nil {
+		t.Fatalf("failed to start fake SMTP server: %v", err)
+	}
+	defer server.Close()
+
+	channel := NewSMTPChannel(server.Host, server.Port, "", "", "fambudg@example.com")
+	err = channel.Send(context.Background(), Message{
+		To:      "parent@example.com",
+		Subject: "Budget alert: Food",
+		Body:    "Food is at 85% of its budget.\n.\nSee the budgets page.",
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+
+	msg := messages[0]
+	if msg.From != "fambudg@example.com" {
+		t.Errorf("expected sender fambudg@example.com, got %q", msg.From)
+	}
+	if len(msg.To) != 1 || msg.To[0] != "parent@example.com" {
+		t.Errorf("expected recipient parent@example.com, got %v", msg.To)
+	}
+	if !strings.Contains(msg.Data, "Subject: Budget alert: Food\r\n") {
+		t.Errorf("expected subject header, got %q", msg.Data)
+	}
+	if !strings.Contains(msg.Data, "\r\n\r\nFood is at 85% of its budget.\r\n.\r\nSee the budgets page.\r\n") {
+		t.Errorf("expected body with CRLF line endings, got %q", msg.Data)
+	}
+}
+
+func TestSMTPChannelStripsHeaderLineBreaks(t *testing.T) {
+	server, err := smtptest.NewServer()
+	if err != nil {
+		t.Fatalf("failed to start fake SMTP server: %v", err)
+	}
+	defer server.Close()
+
+	channel := NewSMTPChannel(server.Host, server.Port, "", "", "fambudg@example.com")
+	err = channel.Send(context.Background(), Message{
+		To:      "parent@example.com",
+		Subject: "Large transaction\r\nBcc: someone@example.com",
+		Body:    "Body",
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	data := server.Messages()[0].Data
+	if strings.Contains(data, "\r\nBcc:") {
+		t.Errorf("expected no injected header, got %q", data)
+	}
+}
+
+func TestSMTPChannelConnectionRefused(t *testing.T) {
+	server, err := smtptest.NewServer()
+	if err != nil {
+		t.Fatalf("failed to start fake SMTP server: %v", err)
+	}
+	host, port := server.Host, server.Port
+	server.Close()
+
+	channel := NewSMTPChannel(host, port, "", "", "fambudg@example.com")
+	err = channel.Send(context.Background(), Message{To: "parent@example.com", Subject: "Hi", Body: "Body"})
+	if err == nil {
+		t.Fatal("expected an error when the server is down")
+	}
+}
@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookChannelSend(t *testing.T) {
+	var got webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected JSON content type, got %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	err := NewWebhookChannel(server.URL).Send(context.Background(), Message{
+		Type:    "bill_due",
+		To:      "parent@example.com",
+		Subject: "Electricity is due today",
+		Body:    "150.00 due on 15 March 2026.",
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if got.Type != "bill_due" || got.To != "parent@example.com" || got.Title != "Electricity is due today" || got.Body != "150.00 due on 15 March 2026." {
+		t.Errorf("unexpected payload: %+v", got)
+	}
+	if got.SentAt.IsZero() {
+		t.Error("expected sentAt to be set")
+	}
+}
+
+func TestWebhookChannelErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := NewWebhookChannel(server.URL).Send(context.Background(), Message{To: "parent@example.com", Subject: "Hi"})
+	if err == nil {
+		t.Fatal("expected an error for a 502 response")
+	}
+}
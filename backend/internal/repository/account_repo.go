@@ -10,20 +10,25 @@ import (
 )
 
 type AccountRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 func NewAccountRepository(db *pgxpool.Pool) *AccountRepository {
 	return &AccountRepository{db: db}
 }
 
+// WithTx returns a copy of the repository that runs its queries inside tx
+func (r *AccountRepository) WithTx(tx pgx.Tx) *AccountRepository {
+	return &AccountRepository{db: tx}
+}
+
 // Create creates a new account
 func (r *AccountRepository) Create(ctx context.Context, userID string, req *model.CreateAccountRequest) (*model.Account, error) {
 	account := &model.Account{}
 	query := `
 		WITH inserted AS (
-			INSERT INTO accounts (user_id, name, type, currency, balance)
-			VALUES ((SELECT id FROM users WHERE uuid = $1), $2, $3, $4, $5)
+			INSERT INTO accounts (household_id, user_id, name, type, currency, balance)
+			SELECT u.household_id, u.id, $2, $3, $4, $5 FROM users u WHERE u.uuid = $1
 			RETURNING *
 		)
 		SELECT i.uuid, u.uuid, i.name, i.type, i.currency, i.balance, i.created_at
@@ -40,16 +45,16 @@ func (r *AccountRepository) Create(ctx context.Context, userID string, req *mode
 	return account, nil
 }
 
-// FindByID finds an account by ID
-func (r *AccountRepository) FindByID(ctx context.Context, id string) (*model.Account, error) {
+// FindByID finds an account by ID within a household
+func (r *AccountRepository) FindByID(ctx context.Context, householdID, id string) (*model.Account, error) {
 	account := &model.Account{}
 	query := `
 		SELECT a.uuid, u.uuid, a.name, a.type, a.currency, a.balance, a.created_at
 		FROM accounts a JOIN users u ON u.id = a.user_id
-		WHERE a.uuid = $1
+		WHERE a.uuid = $1 AND a.household_id = (SELECT id FROM households WHERE uuid = $2) AND a.deleted_at IS NULL
 	`
 
-	err := r.db.QueryRow(ctx, query, id).
+	err := r.db.QueryRow(ctx, query, id, householdID).
 		Scan(&account.ID, &account.UserID, &account.Name, &account.Type, &account.Currency, &account.Balance, &account.CreatedAt)
 
 	if err == pgx.ErrNoRows {
@@ -67,7 +72,7 @@ func (r *AccountRepository) FindByUserID(ctx context.Context, userID string) ([]
 	query := `
 		SELECT a.uuid, u.uuid, a.name, a.type, a.currency, a.balance, a.created_at
 		FROM accounts a JOIN users u ON u.id = a.user_id
-		WHERE a.user_id = (SELECT id FROM users WHERE uuid = $1)
+		WHERE a.user_id = (SELECT id FROM users WHERE uuid = $1) AND a.deleted_at IS NULL
 		ORDER BY a.created_at DESC
 	`
 
@@ -89,15 +94,16 @@ func (r *AccountRepository) FindByUserID(ctx context.Context, userID string) ([]
 	return accounts, nil
 }
 
-// FindAll returns all accounts (for admin)
-func (r *AccountRepository) FindAll(ctx context.Context) ([]*model.Account, error) {
+// FindAll returns all accounts in a household (for admin)
+func (r *AccountRepository) FindAll(ctx context.Context, householdID string) ([]*model.Account, error) {
 	query := `
 		SELECT a.uuid, u.uuid, a.name, a.type, a.currency, a.balance, a.created_at
 		FROM accounts a JOIN users u ON u.id = a.user_id
+		WHERE a.household_id = (SELECT id FROM households WHERE uuid = $1) AND a.deleted_at IS NULL
 		ORDER BY a.created_at DESC
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, householdID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find accounts: %w", err)
 	}
@@ -116,14 +122,14 @@ func (r *AccountRepository) FindAll(ctx context.Context) ([]*model.Account, erro
 }
 
 // Update updates an account
-func (r *AccountRepository) Update(ctx context.Context, id string, req *model.UpdateAccountRequest) (*model.Account, error) {
+func (r *AccountRepository) Update(ctx context.Context, householdID, id string, req *model.UpdateAccountRequest) (*model.Account, error) {
 	query := `
 		WITH updated AS (
 			UPDATE accounts
 			SET name = COALESCE(NULLIF($1, ''), name),
 			    type = COALESCE(NULLIF($2, ''), type),
 			    currency = COALESCE(NULLIF($3, ''), currency)
-			WHERE uuid = $4
+			WHERE uuid = $4 AND household_id = (SELECT id FROM households WHERE uuid = $5) AND deleted_at IS NULL
 			RETURNING *
 		)
 		SELECT up.uuid, u.uuid, up.name, up.type, up.currency, up.balance, up.created_at
@@ -131,7 +137,7 @@ func (r *AccountRepository) Update(ctx context.Context, id string, req *model.Up
 	`
 
 	account := &model.Account{}
-	err := r.db.QueryRow(ctx, query, req.Name, req.Type, req.Currency, id).
+	err := r.db.QueryRow(ctx, query, req.Name, req.Type, req.Currency, id, householdID).
 		Scan(&account.ID, &account.UserID, &account.Name, &account.Type, &account.Currency, &account.Balance, &account.CreatedAt)
 
 	if err == pgx.ErrNoRows {
@@ -144,15 +150,59 @@ func (r *AccountRepository) Update(ctx context.Context, id string, req *model.Up
 	return account, nil
 }
 
-// Delete deletes an account
-func (r *AccountRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM accounts WHERE uuid = $1`
-	result, err := r.db.Exec(ctx, query, id)
-	if err != nil {
+// Delete moves an account to the trash together with its transactions. The
+// transactions are stamped with the account's deletion time so that restoring
+// the account brings back exactly those.
+func (r *AccountRepository) Delete(ctx context.Context, householdID, id string) error {
+	query := `
+		WITH deleted AS (
+			UPDATE accounts SET deleted_at = NOW()
+			WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2) AND deleted_at IS NULL
+			RETURNING id, deleted_at
+		), deleted_transactions AS (
+			UPDATE transactions t SET deleted_at = d.deleted_at
+			FROM deleted d
+			WHERE t.account_id = d.id AND t.deleted_at IS NULL
+		)
+		SELECT COUNT(*) FROM deleted
+	`
+
+	var deleted int
+	if err := r.db.QueryRow(ctx, query, id, householdID).Scan(&deleted); err != nil {
 		return fmt.Errorf("failed to delete account: %w", err)
 	}
 
-	if result.RowsAffected() == 0 {
+	if deleted == 0 {
+		return fmt.Errorf("account not found")
+	}
+
+	return nil
+}
+
+// Restore takes an account back out of the trash along with the transactions
+// that were deleted with it
+func (r *AccountRepository) Restore(ctx context.Context, householdID, id string) error {
+	query := `
+		WITH restored AS (
+			UPDATE accounts a SET deleted_at = NULL
+			FROM accounts old
+			WHERE a.id = old.id AND a.uuid = $1
+				AND a.household_id = (SELECT id FROM households WHERE uuid = $2) AND a.deleted_at IS NOT NULL
+			RETURNING a.id, old.deleted_at
+		), restored_transactions AS (
+			UPDATE transactions t SET deleted_at = NULL
+			FROM restored r
+			WHERE t.account_id = r.id AND t.deleted_at = r.deleted_at
+		)
+		SELECT COUNT(*) FROM restored
+	`
+
+	var restored int
+	if err := r.db.QueryRow(ctx, query, id, householdID).Scan(&restored); err != nil {
+		return fmt.Errorf("failed to restore account: %w", err)
+	}
+
+	if restored == 0 {
 		return fmt.Errorf("account not found")
 	}
 
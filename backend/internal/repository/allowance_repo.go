@@ -12,33 +12,105 @@ import (
 )
 
 type AllowanceRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 func NewAllowanceRepository(db *pgxpool.Pool) *AllowanceRepository {
 	return &AllowanceRepository{db: db}
 }
 
-func (r *AllowanceRepository) Create(ctx context.Context, req *model.CreateAllowanceRequest) (*model.Allowance, error) {
+// WithTx returns a copy of the repository that runs its queries inside tx
+func (r *AllowanceRepository) WithTx(tx pgx.Tx) *AllowanceRepository {
+	return &AllowanceRepository{db: tx}
+}
+
+// allowanceFrom joins each allowance with its child, its top-up accounts and
+// its current period, the one started last
+const allowanceFrom = `
+		FROM allowances a
+		JOIN users u ON u.id = a.user_id
+		JOIN LATERAL (
+			SELECT p.id, p.period_end, p.carried_over, p.transaction_id
+			FROM allowance_periods p
+			WHERE p.allowance_id = a.id
+			ORDER BY p.id DESC
+			LIMIT 1
+		) cur ON TRUE
+		LEFT JOIN accounts fa ON fa.id = a.from_account_id AND fa.deleted_at IS NULL
+		LEFT JOIN accounts ta ON ta.id = a.to_account_id AND ta.deleted_at IS NULL`
+
+const allowanceColumns = `a.uuid, u.uuid, a.amount, a.frequency, a.rollover, fa.uuid, ta.uuid,
+			cur.carried_over, a.period_start, cur.period_end, a.created_at, a.updated_at`
+
+// periodEndSQL is the end of the allowance period starting at startCol for an
+// allowance paid at frequencyCol
+func periodEndSQL(startCol, frequencyCol string) string {
+	return `(CASE ` + frequencyCol + `
+			WHEN 'weekly' THEN ` + startCol + ` + 7
+			WHEN 'biweekly' THEN ` + startCol + ` + 14
+			ELSE (` + startCol + ` + INTERVAL '1 month')::date
+		END)`
+}
+
+// spentColumn sums the expenses of the user with the given internal id from
+// startCol up to, but not including, endCol
+func spentColumn(userCol, startCol, endCol string) string {
+	return `(
+		SELECT COALESCE(SUM(ABS(tl.amount)), 0)
+		FROM ` + transactionLines + ` tl
+		WHERE tl.user_id = ` + userCol + `
+			AND tl.amount < 0
+			AND tl.date >= ` + startCol + `
+			AND tl.date < ` + endCol + `)`
+}
+
+func scanAllowance(row interface{ Scan(dest ...any) error }) (*model.Allowance, error) {
+	a := &model.Allowance{}
+	err := row.Scan(
+		&a.ID, &a.UserID, &a.Amount, &a.Frequency, &a.Rollover, &a.FromAccountID, &a.ToAccountID,
+		&a.CarriedOver, &a.PeriodStart, &a.PeriodEnd, &a.CreatedAt, &a.UpdatedAt,
+	)
+	return a, err
+}
+
+// Create sets up the allowance together with its first period
+func (r *AllowanceRepository) Create(ctx context.Context, householdID string, req *model.CreateAllowanceRequest) (*model.Allowance, error) {
 	periodStart, err := time.Parse("2006-01-02", req.PeriodStart)
 	if err != nil {
 		return nil, fmt.Errorf("invalid period_start date format: %w", err)
 	}
 
-	allowance := &model.Allowance{}
 	query := `
 		WITH inserted AS (
-			INSERT INTO allowances (user_id, amount, period_start)
-			VALUES ((SELECT id FROM users WHERE uuid = $1), $2, $3)
+			INSERT INTO allowances (household_id, user_id, amount, frequency, rollover, from_account_id, to_account_id, period_start)
+			SELECT u.household_id, u.id, $2, COALESCE(NULLIF($3, ''), 'monthly'), $4,
+				(SELECT id FROM accounts WHERE uuid = $5 AND household_id = u.household_id AND deleted_at IS NULL),
+				(SELECT id FROM accounts WHERE uuid = $6 AND household_id = u.household_id AND deleted_at IS NULL),
+				$7
+			FROM users u
+			WHERE u.uuid = $1 AND u.household_id = (SELECT id FROM households WHERE uuid = $8)
+			RETURNING *
+		), period AS (
+			INSERT INTO allowance_periods (allowance_id, period_start, period_end, amount)
+			SELECT i.id, i.period_start, ` + periodEndSQL("i.period_start", "i.frequency") + `, i.amount
+			FROM inserted i
 			RETURNING *
 		)
-		SELECT i.uuid, u.uuid, i.amount, i.period_start, i.created_at, i.updated_at
-		FROM inserted i JOIN users u ON u.id = i.user_id
+		SELECT i.uuid, u.uuid, i.amount, i.frequency, i.rollover, fa.uuid, ta.uuid,
+			p.carried_over, i.period_start, p.period_end, i.created_at, i.updated_at
+		FROM inserted i
+		JOIN period p ON p.allowance_id = i.id
+		JOIN users u ON u.id = i.user_id
+		LEFT JOIN accounts fa ON fa.id = i.from_account_id
+		LEFT JOIN accounts ta ON ta.id = i.to_account_id
 	`
 
-	err = r.db.QueryRow(ctx, query, req.UserID, req.Amount, periodStart).
-		Scan(&allowance.ID, &allowance.UserID, &allowance.Amount, &allowance.PeriodStart, &allowance.CreatedAt, &allowance.UpdatedAt)
-
+	allowance, err := scanAllowance(r.db.QueryRow(ctx, query,
+		req.UserID, req.Amount, req.Frequency, req.Rollover, req.FromAccountID, req.ToAccountID, periodStart, householdID,
+	))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create allowance: %w", err)
 	}
@@ -46,17 +118,13 @@ func (r *AllowanceRepository) Create(ctx context.Context, req *model.CreateAllow
 	return allowance, nil
 }
 
-func (r *AllowanceRepository) FindByID(ctx context.Context, id string) (*model.Allowance, error) {
-	allowance := &model.Allowance{}
+func (r *AllowanceRepository) FindByID(ctx context.Context, householdID, id string) (*model.Allowance, error) {
 	query := `
-		SELECT a.uuid, u.uuid, a.amount, a.period_start, a.created_at, a.updated_at
-		FROM allowances a JOIN users u ON u.id = a.user_id
-		WHERE a.uuid = $1
+		SELECT ` + allowanceColumns + allowanceFrom + `
+		WHERE a.uuid = $1 AND a.household_id = (SELECT id FROM households WHERE uuid = $2)
 	`
 
-	err := r.db.QueryRow(ctx, query, id).
-		Scan(&allowance.ID, &allowance.UserID, &allowance.Amount, &allowance.PeriodStart, &allowance.CreatedAt, &allowance.UpdatedAt)
-
+	allowance, err := scanAllowance(r.db.QueryRow(ctx, query, id, householdID))
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("allowance not found")
 	}
@@ -68,16 +136,12 @@ func (r *AllowanceRepository) FindByID(ctx context.Context, id string) (*model.A
 }
 
 func (r *AllowanceRepository) FindByUserID(ctx context.Context, userID string) (*model.Allowance, error) {
-	allowance := &model.Allowance{}
 	query := `
-		SELECT a.uuid, u.uuid, a.amount, a.period_start, a.created_at, a.updated_at
-		FROM allowances a JOIN users u ON u.id = a.user_id
+		SELECT ` + allowanceColumns + allowanceFrom + `
 		WHERE a.user_id = (SELECT id FROM users WHERE uuid = $1)
 	`
 
-	err := r.db.QueryRow(ctx, query, userID).
-		Scan(&allowance.ID, &allowance.UserID, &allowance.Amount, &allowance.PeriodStart, &allowance.CreatedAt, &allowance.UpdatedAt)
-
+	allowance, err := scanAllowance(r.db.QueryRow(ctx, query, userID))
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("allowance not found")
 	}
@@ -88,14 +152,14 @@ func (r *AllowanceRepository) FindByUserID(ctx context.Context, userID string) (
 	return allowance, nil
 }
 
-func (r *AllowanceRepository) ListAll(ctx context.Context) ([]*model.Allowance, error) {
+func (r *AllowanceRepository) ListAll(ctx context.Context, householdID string) ([]*model.Allowance, error) {
 	query := `
-		SELECT a.uuid, u.uuid, a.amount, a.period_start, a.created_at, a.updated_at
-		FROM allowances a JOIN users u ON u.id = a.user_id
+		SELECT ` + allowanceColumns + allowanceFrom + `
+		WHERE a.household_id = (SELECT id FROM households WHERE uuid = $1)
 		ORDER BY a.created_at ASC
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, householdID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list allowances: %w", err)
 	}
@@ -103,8 +167,8 @@ func (r *AllowanceRepository) ListAll(ctx context.Context) ([]*model.Allowance,
 
 	var allowances []*model.Allowance
 	for rows.Next() {
-		a := &model.Allowance{}
-		if err := rows.Scan(&a.ID, &a.UserID, &a.Amount, &a.PeriodStart, &a.CreatedAt, &a.UpdatedAt); err != nil {
+		a, err := scanAllowance(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan allowance: %w", err)
 		}
 		allowances = append(allowances, a)
@@ -113,7 +177,9 @@ func (r *AllowanceRepository) ListAll(ctx context.Context) ([]*model.Allowance,
 	return allowances, nil
 }
 
-func (r *AllowanceRepository) Update(ctx context.Context, id string, req *model.UpdateAllowanceRequest) (*model.Allowance, error) {
+// Update changes the allowance. A new amount, frequency or period start
+// applies to the current period as well.
+func (r *AllowanceRepository) Update(ctx context.Context, householdID, id string, req *model.UpdateAllowanceRequest) (*model.Allowance, error) {
 	updates := []string{}
 	args := []any{}
 	argPos := 1
@@ -124,6 +190,30 @@ func (r *AllowanceRepository) Update(ctx context.Context, id string, req *model.
 		argPos++
 	}
 
+	if req.Frequency != nil {
+		updates = append(updates, fmt.Sprintf("frequency = $%d", argPos))
+		args = append(args, *req.Frequency)
+		argPos++
+	}
+
+	if req.Rollover != nil {
+		updates = append(updates, fmt.Sprintf("rollover = $%d", argPos))
+		args = append(args, *req.Rollover)
+		argPos++
+	}
+
+	if req.FromAccountID != nil {
+		updates = append(updates, fmt.Sprintf("from_account_id = (SELECT id FROM accounts WHERE uuid = $%d AND household_id = allowances.household_id AND deleted_at IS NULL)", argPos))
+		args = append(args, *req.FromAccountID)
+		argPos++
+	}
+
+	if req.ToAccountID != nil {
+		updates = append(updates, fmt.Sprintf("to_account_id = (SELECT id FROM accounts WHERE uuid = $%d AND household_id = allowances.household_id AND deleted_at IS NULL)", argPos))
+		args = append(args, *req.ToAccountID)
+		argPos++
+	}
+
 	if req.PeriodStart != nil {
 		periodStart, err := time.Parse("2006-01-02", *req.PeriodStart)
 		if err != nil {
@@ -135,27 +225,35 @@ func (r *AllowanceRepository) Update(ctx context.Context, id string, req *model.
 	}
 
 	if len(updates) == 0 {
-		return r.FindByID(ctx, id)
+		return r.FindByID(ctx, householdID, id)
 	}
 
 	updates = append(updates, "updated_at = NOW()")
 
-	args = append(args, id)
+	args = append(args, id, householdID)
 	query := fmt.Sprintf(`
 		WITH updated AS (
 			UPDATE allowances
 			SET %s
-			WHERE uuid = $%d
+			WHERE uuid = $%d AND household_id = (SELECT id FROM households WHERE uuid = $%d)
 			RETURNING *
+		), cur AS (
+			UPDATE allowance_periods p
+			SET period_start = up.period_start, period_end = %s, amount = up.amount
+			FROM updated up
+			WHERE p.id = (SELECT MAX(id) FROM allowance_periods WHERE allowance_id = up.id)
+			RETURNING p.*
 		)
-		SELECT up.uuid, u.uuid, up.amount, up.period_start, up.created_at, up.updated_at
-		FROM updated up JOIN users u ON u.id = up.user_id
-	`, strings.Join(updates, ", "), argPos)
-
-	allowance := &model.Allowance{}
-	err := r.db.QueryRow(ctx, query, args...).
-		Scan(&allowance.ID, &allowance.UserID, &allowance.Amount, &allowance.PeriodStart, &allowance.CreatedAt, &allowance.UpdatedAt)
-
+		SELECT up.uuid, u.uuid, up.amount, up.frequency, up.rollover, fa.uuid, ta.uuid,
+			cur.carried_over, up.period_start, cur.period_end, up.created_at, up.updated_at
+		FROM updated up
+		JOIN cur ON cur.allowance_id = up.id
+		JOIN users u ON u.id = up.user_id
+		LEFT JOIN accounts fa ON fa.id = up.from_account_id
+		LEFT JOIN accounts ta ON ta.id = up.to_account_id
+	`, strings.Join(updates, ", "), argPos, argPos+1, periodEndSQL("up.period_start", "up.frequency"))
+
+	allowance, err := scanAllowance(r.db.QueryRow(ctx, query, args...))
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("allowance not found")
 	}
@@ -166,20 +264,126 @@ func (r *AllowanceRepository) Update(ctx context.Context, id string, req *model.
 	return allowance, nil
 }
 
-// GetSpentInPeriod calculates spending for a user from period_start to end of that month
-func (r *AllowanceRepository) GetSpentInPeriod(ctx context.Context, userID string, periodStart time.Time) (int64, error) {
-	// Calculate period end: one month after period_start
-	periodEnd := periodStart.AddDate(0, 1, 0)
+// FindDue returns the allowances of all households whose current period has
+// ended by the given day, or has started and is still to be topped up
+func (r *AllowanceRepository) FindDue(ctx context.Context, today time.Time) ([]*model.DueAllowance, error) {
+	query := `
+		SELECT h.uuid, ` + allowanceColumns + `, cur.transaction_id IS NOT NULL
+		` + allowanceFrom + `
+		JOIN households h ON h.id = a.household_id
+		WHERE cur.period_end <= $1
+			OR (cur.transaction_id IS NULL AND a.period_start <= $1 AND fa.id IS NOT NULL AND ta.id IS NOT NULL)
+		ORDER BY a.id
+	`
+
+	rows, err := r.db.Query(ctx, query, today)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find due allowances: %w", err)
+	}
+	defer rows.Close()
+
+	var due []*model.DueAllowance
+	for rows.Next() {
+		d := &model.DueAllowance{Allowance: &model.Allowance{}}
+		a := d.Allowance
+		if err := rows.Scan(
+			&d.HouseholdID, &a.ID, &a.UserID, &a.Amount, &a.Frequency, &a.Rollover, &a.FromAccountID, &a.ToAccountID,
+			&a.CarriedOver, &a.PeriodStart, &a.PeriodEnd, &a.CreatedAt, &a.UpdatedAt, &d.ToppedUp,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan due allowance: %w", err)
+		}
+		due = append(due, d)
+	}
+
+	return due, rows.Err()
+}
+
+// StartPeriod begins the allowance's next period at start, for its current
+// amount plus what was carried over
+func (r *AllowanceRepository) StartPeriod(ctx context.Context, id string, start time.Time, carriedOver int64) error {
+	query := `
+		WITH started AS (
+			INSERT INTO allowance_periods (allowance_id, period_start, period_end, amount, carried_over)
+			SELECT a.id, $2::date, ` + periodEndSQL("$2::date", "a.frequency") + `, a.amount, $3
+			FROM allowances a
+			WHERE a.uuid = $1
+			RETURNING allowance_id, period_start
+		)
+		UPDATE allowances a SET period_start = s.period_start
+		FROM started s
+		WHERE a.id = s.allowance_id
+	`
+
+	result, err := r.db.Exec(ctx, query, id, start, carriedOver)
+	if err != nil {
+		return fmt.Errorf("failed to start allowance period: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("allowance not found")
+	}
+
+	return nil
+}
+
+// SetTopUp records the transfer that topped up the allowance's current period
+func (r *AllowanceRepository) SetTopUp(ctx context.Context, id, transactionID string) error {
+	query := `
+		UPDATE allowance_periods
+		SET transaction_id = (SELECT id FROM transactions WHERE uuid = $2)
+		WHERE id = (
+			SELECT MAX(p.id) FROM allowance_periods p
+			JOIN allowances a ON a.id = p.allowance_id
+			WHERE a.uuid = $1
+		)
+	`
+
+	result, err := r.db.Exec(ctx, query, id, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to record allowance top-up: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("allowance not found")
+	}
 
+	return nil
+}
+
+// FindPeriods returns every period of the allowance with what the child
+// spent in it, latest first
+func (r *AllowanceRepository) FindPeriods(ctx context.Context, householdID, id string) ([]*model.AllowancePeriod, error) {
 	query := `
-		SELECT COALESCE(SUM(ABS(amount)), 0)
-		FROM transactions
-		WHERE user_id = (SELECT id FROM users WHERE uuid = $1)
-			AND amount < 0
-			AND date >= $2
-			AND date < $3
+		SELECT p.uuid, p.period_start, p.period_end, p.amount, p.carried_over,
+			` + spentColumn("a.user_id", "p.period_start", "p.period_end") + `, t.uuid
+		FROM allowance_periods p
+		JOIN allowances a ON a.id = p.allowance_id
+		LEFT JOIN transactions t ON t.id = p.transaction_id AND t.deleted_at IS NULL
+		WHERE a.uuid = $1 AND a.household_id = (SELECT id FROM households WHERE uuid = $2)
+		ORDER BY p.period_start DESC, p.id DESC
 	`
 
+	rows, err := r.db.Query(ctx, query, id, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find allowance periods: %w", err)
+	}
+	defer rows.Close()
+
+	periods := []*model.AllowancePeriod{}
+	for rows.Next() {
+		p := &model.AllowancePeriod{}
+		if err := rows.Scan(&p.ID, &p.PeriodStart, &p.PeriodEnd, &p.Amount, &p.CarriedOver, &p.Spent, &p.TransactionID); err != nil {
+			return nil, fmt.Errorf("failed to scan allowance period: %w", err)
+		}
+		periods = append(periods, p)
+	}
+
+	return periods, rows.Err()
+}
+
+// GetSpentInPeriod calculates spending for a user from periodStart up to,
+// but not including, periodEnd
+func (r *AllowanceRepository) GetSpentInPeriod(ctx context.Context, userID string, periodStart, periodEnd time.Time) (int64, error) {
+	query := `SELECT ` + spentColumn("(SELECT id FROM users WHERE uuid = $1)", "$2::date", "$3::date")
+
 	var spent int64
 	err := r.db.QueryRow(ctx, query, userID, periodStart, periodEnd).Scan(&spent)
 	if err != nil {
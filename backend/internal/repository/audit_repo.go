@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditRepository struct {
+	db DBTX
+}
+
+func NewAuditRepository(db *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries inside tx
+func (r *AuditRepository) WithTx(tx pgx.Tx) *AuditRepository {
+	return &AuditRepository{db: tx}
+}
+
+// Create appends event to the audit log of the household
+func (r *AuditRepository) Create(ctx context.Context, householdID string, event *model.AuditEvent) error {
+	return r.create(ctx, "(SELECT id FROM households WHERE uuid = $1)", householdID, event)
+}
+
+// CreateForUser appends event to the audit log of the household userID
+// belongs to, for changes made where only the user is known
+func (r *AuditRepository) CreateForUser(ctx context.Context, userID string, event *model.AuditEvent) error {
+	return r.create(ctx, "(SELECT household_id FROM users WHERE uuid = $1)", userID, event)
+}
+
+func (r *AuditRepository) create(ctx context.Context, household, key string, event *model.AuditEvent) error {
+	query := `
+		INSERT INTO audit_events (household_id, actor_id, entity_type, entity_id, action, before, after, request_id)
+		VALUES (` + household + `, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		key, event.ActorID, event.EntityType, event.EntityID, event.Action, nullJSON(event.Before), nullJSON(event.After), event.RequestID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+
+	return nil
+}
+
+// nullJSON stores a missing snapshot as NULL rather than JSON null
+func nullJSON(data json.RawMessage) any {
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
+
+// FindAll returns one page of the household's audit log, newest first
+func (r *AuditRepository) FindAll(ctx context.Context, householdID string, filters *model.AuditFilters) (*model.AuditPage, error) {
+	where := "ae.household_id = (SELECT id FROM households WHERE uuid = $1)"
+	args := []any{householdID}
+	argPos := 2
+
+	if filters.EntityType != "" {
+		where += fmt.Sprintf(" AND ae.entity_type = $%d", argPos)
+		args = append(args, filters.EntityType)
+		argPos++
+	}
+
+	if filters.EntityID != "" {
+		where += fmt.Sprintf(" AND ae.entity_id = $%d", argPos)
+		args = append(args, filters.EntityID)
+		argPos++
+	}
+
+	if filters.ActorID != "" {
+		where += fmt.Sprintf(" AND ae.actor_id = $%d", argPos)
+		args = append(args, filters.ActorID)
+		argPos++
+	}
+
+	if filters.Action != "" {
+		where += fmt.Sprintf(" AND ae.action = $%d", argPos)
+		args = append(args, filters.Action)
+		argPos++
+	}
+
+	if filters.StartDate != "" {
+		where += fmt.Sprintf(" AND ae.created_at >= $%d::date", argPos)
+		args = append(args, filters.StartDate)
+		argPos++
+	}
+
+	if filters.EndDate != "" {
+		where += fmt.Sprintf(" AND ae.created_at < $%d::date + 1", argPos)
+		args = append(args, filters.EndDate)
+		argPos++
+	}
+
+	// Events keep their position however many are appended while paging
+	if filters.Cursor != "" {
+		where += fmt.Sprintf(" AND (ae.created_at, ae.id) < (SELECT created_at, id FROM audit_events WHERE uuid = $%d)", argPos)
+		args = append(args, filters.Cursor)
+		argPos++
+	}
+
+	limit := filters.Limit
+	if limit == 0 {
+		limit = model.DefaultPageLimit
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	query := fmt.Sprintf(`
+		SELECT ae.uuid, ae.actor_id, u.name, ae.entity_type, ae.entity_id, ae.action, ae.before, ae.after, ae.request_id, ae.created_at
+		FROM audit_events ae
+		LEFT JOIN users u ON u.uuid = ae.actor_id
+		WHERE %s
+		ORDER BY ae.created_at DESC, ae.id DESC
+		LIMIT $%d
+	`, where, argPos)
+	args = append(args, limit+1)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find audit events: %w", err)
+	}
+	defer rows.Close()
+
+	page := &model.AuditPage{Events: []*model.AuditEvent{}}
+	for rows.Next() {
+		event := &model.AuditEvent{}
+		err := rows.Scan(
+			&event.ID, &event.ActorID, &event.ActorName, &event.EntityType, &event.EntityID, &event.Action,
+			&event.Before, &event.After, &event.RequestID, &event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		page.Events = append(page.Events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find audit events: %w", err)
+	}
+
+	if len(page.Events) > limit {
+		page.Events = page.Events[:limit]
+		page.NextCursor = page.Events[limit-1].ID
+	}
+
+	return page, nil
+}
@@ -12,14 +12,30 @@ import (
 )
 
 type BillReminderRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 func NewBillReminderRepository(db *pgxpool.Pool) *BillReminderRepository {
 	return &BillReminderRepository{db: db}
 }
 
-func (r *BillReminderRepository) Create(ctx context.Context, req *model.CreateBillReminderRequest) (*model.BillReminder, error) {
+// WithTx returns a copy of the repository that runs its queries inside tx
+func (r *BillReminderRepository) WithTx(tx pgx.Tx) *BillReminderRepository {
+	return &BillReminderRepository{db: tx}
+}
+
+// recipientIDsColumn selects the recipients of the bill reminder with the
+// given internal id as an array of user UUIDs
+func recipientIDsColumn(idCol string) string {
+	return `ARRAY(
+		SELECT u.uuid::text
+		FROM bill_reminder_recipients r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.bill_reminder_id = ` + idCol + `
+		ORDER BY u.created_at, u.id)`
+}
+
+func (r *BillReminderRepository) Create(ctx context.Context, householdID string, req *model.CreateBillReminderRequest) (*model.BillReminder, error) {
 	bill := &model.BillReminder{}
 
 	nextDueDate, err := time.Parse("2006-01-02", req.NextDueDate)
@@ -29,25 +45,26 @@ func (r *BillReminderRepository) Create(ctx context.Context, req *model.CreateBi
 
 	query := `
 		WITH inserted AS (
-			INSERT INTO bill_reminders (name, amount, due_day, frequency, category_id, account_id, next_due_date)
-			VALUES ($1, $2, $3, $4,
-				(SELECT id FROM categories WHERE uuid = $5),
-				(SELECT id FROM accounts WHERE uuid = $6),
-				$7)
+			INSERT INTO bill_reminders (household_id, name, amount, due_day, frequency, category_id, account_id, next_due_date, lead_days)
+			SELECT h.id, $2, $3, $4, $5,
+				(SELECT id FROM categories WHERE uuid = $6 AND household_id = h.id AND deleted_at IS NULL),
+				(SELECT id FROM accounts WHERE uuid = $7 AND household_id = h.id AND deleted_at IS NULL),
+				$8, $9
+			FROM households h WHERE h.uuid = $1
 			RETURNING *
 		)
 		SELECT i.uuid, i.name, i.amount, i.due_day, i.frequency,
-			c.uuid, a.uuid, i.is_active, i.next_due_date, i.created_at, i.updated_at
+			c.uuid, a.uuid, i.is_active, i.is_overdue, i.next_due_date, i.lead_days, i.deferred_from, ARRAY[]::text[], i.created_at, i.updated_at
 		FROM inserted i
-		LEFT JOIN categories c ON c.id = i.category_id
-		LEFT JOIN accounts a ON a.id = i.account_id
+		LEFT JOIN categories c ON c.id = i.category_id AND c.deleted_at IS NULL
+		LEFT JOIN accounts a ON a.id = i.account_id AND a.deleted_at IS NULL
 	`
 
 	err = r.db.QueryRow(ctx, query,
-		req.Name, req.Amount, req.DueDay, req.Frequency, req.CategoryID, req.AccountID, nextDueDate,
+		householdID, req.Name, req.Amount, req.DueDay, req.Frequency, req.CategoryID, req.AccountID, nextDueDate, req.LeadDays,
 	).Scan(
 		&bill.ID, &bill.Name, &bill.Amount, &bill.DueDay, &bill.Frequency,
-		&bill.CategoryID, &bill.AccountID, &bill.IsActive, &bill.NextDueDate,
+		&bill.CategoryID, &bill.AccountID, &bill.IsActive, &bill.IsOverdue, &bill.NextDueDate, &bill.LeadDays, &bill.DeferredFrom, &bill.RecipientIDs,
 		&bill.CreatedAt, &bill.UpdatedAt,
 	)
 	if err != nil {
@@ -57,20 +74,31 @@ func (r *BillReminderRepository) Create(ctx context.Context, req *model.CreateBi
 	return bill, nil
 }
 
-func (r *BillReminderRepository) FindByID(ctx context.Context, id string) (*model.BillReminder, error) {
+func (r *BillReminderRepository) FindByID(ctx context.Context, householdID, id string) (*model.BillReminder, error) {
+	return r.findByID(ctx, householdID, id, "")
+}
+
+// FindByIDForUpdate is FindByID that also locks the bill until the
+// transaction ends, so that it is paid for one period at a time
+func (r *BillReminderRepository) FindByIDForUpdate(ctx context.Context, householdID, id string) (*model.BillReminder, error) {
+	return r.findByID(ctx, householdID, id, "FOR UPDATE OF br")
+}
+
+func (r *BillReminderRepository) findByID(ctx context.Context, householdID, id, lock string) (*model.BillReminder, error) {
 	bill := &model.BillReminder{}
 	query := `
 		SELECT br.uuid, br.name, br.amount, br.due_day, br.frequency,
-			c.uuid, a.uuid, br.is_active, br.next_due_date, br.created_at, br.updated_at
+			c.uuid, a.uuid, br.is_active, br.is_overdue, br.next_due_date, br.lead_days, br.deferred_from,
+			` + recipientIDsColumn("br.id") + `, br.created_at, br.updated_at
 		FROM bill_reminders br
-		LEFT JOIN categories c ON c.id = br.category_id
-		LEFT JOIN accounts a ON a.id = br.account_id
-		WHERE br.uuid = $1
-	`
+		LEFT JOIN categories c ON c.id = br.category_id AND c.deleted_at IS NULL
+		LEFT JOIN accounts a ON a.id = br.account_id AND a.deleted_at IS NULL
+		WHERE br.uuid = $1 AND br.household_id = (SELECT id FROM households WHERE uuid = $2)
+		` + lock
 
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := r.db.QueryRow(ctx, query, id, householdID).Scan(
 		&bill.ID, &bill.Name, &bill.Amount, &bill.DueDay, &bill.Frequency,
-		&bill.CategoryID, &bill.AccountID, &bill.IsActive, &bill.NextDueDate,
+		&bill.CategoryID, &bill.AccountID, &bill.IsActive, &bill.IsOverdue, &bill.NextDueDate, &bill.LeadDays, &bill.DeferredFrom, &bill.RecipientIDs,
 		&bill.CreatedAt, &bill.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
@@ -83,17 +111,19 @@ func (r *BillReminderRepository) FindByID(ctx context.Context, id string) (*mode
 	return bill, nil
 }
 
-func (r *BillReminderRepository) FindAll(ctx context.Context) ([]*model.BillReminder, error) {
+func (r *BillReminderRepository) FindAll(ctx context.Context, householdID string) ([]*model.BillReminder, error) {
 	query := `
 		SELECT br.uuid, br.name, br.amount, br.due_day, br.frequency,
-			c.uuid, a.uuid, br.is_active, br.next_due_date, br.created_at, br.updated_at
+			c.uuid, a.uuid, br.is_active, br.is_overdue, br.next_due_date, br.lead_days, br.deferred_from,
+			` + recipientIDsColumn("br.id") + `, br.created_at, br.updated_at
 		FROM bill_reminders br
-		LEFT JOIN categories c ON c.id = br.category_id
-		LEFT JOIN accounts a ON a.id = br.account_id
+		LEFT JOIN categories c ON c.id = br.category_id AND c.deleted_at IS NULL
+		LEFT JOIN accounts a ON a.id = br.account_id AND a.deleted_at IS NULL
+		WHERE br.household_id = (SELECT id FROM households WHERE uuid = $1)
 		ORDER BY br.next_due_date ASC
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, householdID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find bill reminders: %w", err)
 	}
@@ -104,7 +134,7 @@ func (r *BillReminderRepository) FindAll(ctx context.Context) ([]*model.BillRemi
 		bill := &model.BillReminder{}
 		if err := rows.Scan(
 			&bill.ID, &bill.Name, &bill.Amount, &bill.DueDay, &bill.Frequency,
-			&bill.CategoryID, &bill.AccountID, &bill.IsActive, &bill.NextDueDate,
+			&bill.CategoryID, &bill.AccountID, &bill.IsActive, &bill.IsOverdue, &bill.NextDueDate, &bill.LeadDays, &bill.DeferredFrom, &bill.RecipientIDs,
 			&bill.CreatedAt, &bill.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan bill reminder: %w", err)
@@ -115,18 +145,20 @@ func (r *BillReminderRepository) FindAll(ctx context.Context) ([]*model.BillRemi
 	return bills, nil
 }
 
-func (r *BillReminderRepository) FindUpcoming(ctx context.Context, days int) ([]*model.BillReminder, error) {
+func (r *BillReminderRepository) FindUpcoming(ctx context.Context, householdID string, days int) ([]*model.BillReminder, error) {
 	query := `
 		SELECT br.uuid, br.name, br.amount, br.due_day, br.frequency,
-			c.uuid, a.uuid, br.is_active, br.next_due_date, br.created_at, br.updated_at
+			c.uuid, a.uuid, br.is_active, br.is_overdue, br.next_due_date, br.lead_days, br.deferred_from,
+			` + recipientIDsColumn("br.id") + `, br.created_at, br.updated_at
 		FROM bill_reminders br
-		LEFT JOIN categories c ON c.id = br.category_id
-		LEFT JOIN accounts a ON a.id = br.account_id
-		WHERE br.is_active = true AND br.next_due_date <= CURRENT_DATE + make_interval(days => $1)
+		LEFT JOIN categories c ON c.id = br.category_id AND c.deleted_at IS NULL
+		LEFT JOIN accounts a ON a.id = br.account_id AND a.deleted_at IS NULL
+		WHERE br.household_id = (SELECT id FROM households WHERE uuid = $2)
+			AND br.is_active = true AND br.next_due_date <= CURRENT_DATE + make_interval(days => $1)
 		ORDER BY br.next_due_date ASC
 	`
 
-	rows, err := r.db.Query(ctx, query, days)
+	rows, err := r.db.Query(ctx, query, days, householdID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find upcoming bill reminders: %w", err)
 	}
@@ -137,7 +169,7 @@ func (r *BillReminderRepository) FindUpcoming(ctx context.Context, days int) ([]
 		bill := &model.BillReminder{}
 		if err := rows.Scan(
 			&bill.ID, &bill.Name, &bill.Amount, &bill.DueDay, &bill.Frequency,
-			&bill.CategoryID, &bill.AccountID, &bill.IsActive, &bill.NextDueDate,
+			&bill.CategoryID, &bill.AccountID, &bill.IsActive, &bill.IsOverdue, &bill.NextDueDate, &bill.LeadDays, &bill.DeferredFrom, &bill.RecipientIDs,
 			&bill.CreatedAt, &bill.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan upcoming bill reminder: %w", err)
@@ -148,7 +180,7 @@ func (r *BillReminderRepository) FindUpcoming(ctx context.Context, days int) ([]
 	return bills, nil
 }
 
-func (r *BillReminderRepository) Update(ctx context.Context, id string, req *model.UpdateBillReminderRequest) (*model.BillReminder, error) {
+func (r *BillReminderRepository) Update(ctx context.Context, householdID, id string, req *model.UpdateBillReminderRequest) (*model.BillReminder, error) {
 	updates := []string{}
 	args := []any{}
 	argPos := 1
@@ -178,13 +210,13 @@ func (r *BillReminderRepository) Update(ctx context.Context, id string, req *mod
 	}
 
 	if req.CategoryID != nil {
-		updates = append(updates, fmt.Sprintf("category_id = (SELECT id FROM categories WHERE uuid = $%d)", argPos))
+		updates = append(updates, fmt.Sprintf("category_id = (SELECT id FROM categories WHERE uuid = $%d AND household_id = bill_reminders.household_id AND deleted_at IS NULL)", argPos))
 		args = append(args, *req.CategoryID)
 		argPos++
 	}
 
 	if req.AccountID != nil {
-		updates = append(updates, fmt.Sprintf("account_id = (SELECT id FROM accounts WHERE uuid = $%d)", argPos))
+		updates = append(updates, fmt.Sprintf("account_id = (SELECT id FROM accounts WHERE uuid = $%d AND household_id = bill_reminders.household_id AND deleted_at IS NULL)", argPos))
 		args = append(args, *req.AccountID)
 		argPos++
 	}
@@ -195,31 +227,38 @@ func (r *BillReminderRepository) Update(ctx context.Context, id string, req *mod
 		argPos++
 	}
 
+	if req.LeadDays != nil {
+		updates = append(updates, fmt.Sprintf("lead_days = $%d", argPos))
+		args = append(args, *req.LeadDays)
+		argPos++
+	}
+
 	updates = append(updates, "updated_at = NOW()")
 
 	if len(updates) == 1 {
-		return r.FindByID(ctx, id)
+		return r.FindByID(ctx, householdID, id)
 	}
 
-	args = append(args, id)
+	args = append(args, id, householdID)
 	query := fmt.Sprintf(`
 		WITH updated AS (
 			UPDATE bill_reminders
 			SET %s
-			WHERE uuid = $%d
+			WHERE uuid = $%d AND household_id = (SELECT id FROM households WHERE uuid = $%d)
 			RETURNING *
 		)
 		SELECT up.uuid, up.name, up.amount, up.due_day, up.frequency,
-			c.uuid, a.uuid, up.is_active, up.next_due_date, up.created_at, up.updated_at
+			c.uuid, a.uuid, up.is_active, up.is_overdue, up.next_due_date, up.lead_days, up.deferred_from,
+			`+recipientIDsColumn("up.id")+`, up.created_at, up.updated_at
 		FROM updated up
-		LEFT JOIN categories c ON c.id = up.category_id
-		LEFT JOIN accounts a ON a.id = up.account_id
-	`, strings.Join(updates, ", "), argPos)
+		LEFT JOIN categories c ON c.id = up.category_id AND c.deleted_at IS NULL
+		LEFT JOIN accounts a ON a.id = up.account_id AND a.deleted_at IS NULL
+	`, strings.Join(updates, ", "), argPos, argPos+1)
 
 	bill := &model.BillReminder{}
 	err := r.db.QueryRow(ctx, query, args...).Scan(
 		&bill.ID, &bill.Name, &bill.Amount, &bill.DueDay, &bill.Frequency,
-		&bill.CategoryID, &bill.AccountID, &bill.IsActive, &bill.NextDueDate,
+		&bill.CategoryID, &bill.AccountID, &bill.IsActive, &bill.IsOverdue, &bill.NextDueDate, &bill.LeadDays, &bill.DeferredFrom, &bill.RecipientIDs,
 		&bill.CreatedAt, &bill.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
@@ -232,9 +271,9 @@ func (r *BillReminderRepository) Update(ctx context.Context, id string, req *mod
 	return bill, nil
 }
 
-func (r *BillReminderRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM bill_reminders WHERE uuid = $1`
-	result, err := r.db.Exec(ctx, query, id)
+func (r *BillReminderRepository) Delete(ctx context.Context, householdID, id string) error {
+	query := `DELETE FROM bill_reminders WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2)`
+	result, err := r.db.Exec(ctx, query, id, householdID)
 	if err != nil {
 		return fmt.Errorf("failed to delete bill reminder: %w", err)
 	}
@@ -246,7 +285,86 @@ func (r *BillReminderRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (r *BillReminderRepository) AdvanceNextDueDate(ctx context.Context, id string, frequency string, currentDueDate time.Time) error {
+// SetRecipients replaces the users notified about the bill. Every user must
+// belong to the bill's household.
+func (r *BillReminderRepository) SetRecipients(ctx context.Context, householdID, id string, userIDs []string) error {
+	query := `
+		DELETE FROM bill_reminder_recipients
+		WHERE bill_reminder_id = (
+			SELECT id FROM bill_reminders WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2)
+		)
+	`
+	if _, err := r.db.Exec(ctx, query, id, householdID); err != nil {
+		return fmt.Errorf("failed to set bill reminder recipients: %w", err)
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	query = `
+		INSERT INTO bill_reminder_recipients (bill_reminder_id, user_id)
+		SELECT br.id, u.id
+		FROM bill_reminders br
+		JOIN users u ON u.household_id = br.household_id
+		WHERE br.uuid = $1 AND br.household_id = (SELECT id FROM households WHERE uuid = $2)
+			AND u.uuid::text = ANY($3)
+		ON CONFLICT DO NOTHING
+	`
+	tag, err := r.db.Exec(ctx, query, id, householdID, userIDs)
+	if err != nil {
+		return fmt.Errorf("failed to set bill reminder recipients: %w", err)
+	}
+
+	unique := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		unique[userID] = true
+	}
+	if int(tag.RowsAffected()) != len(unique) {
+		return fmt.Errorf("recipient not found")
+	}
+
+	return nil
+}
+
+// FindDue returns the active bills of all households that are within their
+// lead time of the given day or past due
+func (r *BillReminderRepository) FindDue(ctx context.Context, today time.Time) ([]*model.DueBill, error) {
+	query := `
+		SELECT h.uuid, br.uuid, br.name, br.amount, br.due_day, br.frequency,
+			c.uuid, a.uuid, br.is_active, br.is_overdue, br.next_due_date, br.lead_days, br.deferred_from,
+			` + recipientIDsColumn("br.id") + `, br.created_at, br.updated_at
+		FROM bill_reminders br
+		JOIN households h ON h.id = br.household_id
+		LEFT JOIN categories c ON c.id = br.category_id AND c.deleted_at IS NULL
+		LEFT JOIN accounts a ON a.id = br.account_id AND a.deleted_at IS NULL
+		WHERE br.is_active AND br.next_due_date <= $1::date + br.lead_days
+		ORDER BY br.next_due_date, br.id
+	`
+
+	rows, err := r.db.Query(ctx, query, today)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find due bill reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var due []*model.DueBill
+	for rows.Next() {
+		d := &model.DueBill{Bill: &model.BillReminder{}}
+		bill := d.Bill
+		if err := rows.Scan(
+			&d.HouseholdID, &bill.ID, &bill.Name, &bill.Amount, &bill.DueDay, &bill.Frequency,
+			&bill.CategoryID, &bill.AccountID, &bill.IsActive, &bill.IsOverdue, &bill.NextDueDate, &bill.LeadDays, &bill.DeferredFrom, &bill.RecipientIDs,
+			&bill.CreatedAt, &bill.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan due bill reminder: %w", err)
+		}
+		due = append(due, d)
+	}
+
+	return due, rows.Err()
+}
+
+func (r *BillReminderRepository) AdvanceNextDueDate(ctx context.Context, householdID, id string, frequency string, currentDueDate time.Time) error {
 	var nextDate time.Time
 	switch frequency {
 	case "monthly":
@@ -259,11 +377,119 @@ func (r *BillReminderRepository) AdvanceNextDueDate(ctx context.Context, id stri
 		nextDate = currentDueDate.AddDate(0, 1, 0)
 	}
 
-	query := `UPDATE bill_reminders SET next_due_date = $1, updated_at = NOW() WHERE uuid = $2`
-	_, err := r.db.Exec(ctx, query, nextDate, id)
+	query := `
+		UPDATE bill_reminders SET next_due_date = $1, deferred_from = NULL, is_overdue = $1 < CURRENT_DATE, updated_at = NOW()
+		WHERE uuid = $2 AND household_id = (SELECT id FROM households WHERE uuid = $3)
+	`
+	_, err := r.db.Exec(ctx, query, nextDate, id, householdID)
 	if err != nil {
 		return fmt.Errorf("failed to advance next due date: %w", err)
 	}
 
 	return nil
 }
+
+// Defer moves the bill's due date to a later date, remembering the date it
+// was originally due
+func (r *BillReminderRepository) Defer(ctx context.Context, householdID, id string, date time.Time) error {
+	query := `
+		UPDATE bill_reminders
+		SET deferred_from = COALESCE(deferred_from, next_due_date), next_due_date = $1,
+			is_overdue = $1 < CURRENT_DATE, updated_at = NOW()
+		WHERE uuid = $2 AND household_id = (SELECT id FROM households WHERE uuid = $3)
+	`
+	tag, err := r.db.Exec(ctx, query, date, id, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to defer bill reminder: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("bill reminder not found")
+	}
+
+	return nil
+}
+
+// CreatePayment records how the bill's current period was settled. For a
+// paid period, transactionID, amount and paidOn are set.
+func (r *BillReminderRepository) CreatePayment(ctx context.Context, householdID, id, status string, transactionID *string, amount *int64, paidOn *time.Time) error {
+	query := `
+		INSERT INTO bill_payments (bill_reminder_id, transaction_id, status, period_date, due_date, amount, paid_on)
+		SELECT br.id, (SELECT id FROM transactions WHERE uuid = $3), $4,
+			COALESCE(br.deferred_from, br.next_due_date), br.next_due_date, $5, $6
+		FROM bill_reminders br
+		WHERE br.uuid = $1 AND br.household_id = (SELECT id FROM households WHERE uuid = $2)
+	`
+	tag, err := r.db.Exec(ctx, query, id, householdID, transactionID, status, amount, paidOn)
+	if err != nil {
+		return fmt.Errorf("failed to record bill payment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("bill reminder not found")
+	}
+
+	return nil
+}
+
+// FindPayments returns how the bill's periods were settled, newest first
+func (r *BillReminderRepository) FindPayments(ctx context.Context, householdID, id string) ([]*model.BillPayment, error) {
+	query := `
+		SELECT bp.uuid, t.uuid, bp.status, bp.period_date, bp.due_date, bp.amount, bp.paid_on,
+			COALESCE(bp.paid_on > bp.due_date, FALSE), bp.created_at
+		FROM bill_payments bp
+		JOIN bill_reminders br ON br.id = bp.bill_reminder_id
+		LEFT JOIN transactions t ON t.id = bp.transaction_id
+		WHERE br.uuid = $1 AND br.household_id = (SELECT id FROM households WHERE uuid = $2)
+		ORDER BY bp.period_date DESC, bp.id DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, id, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bill payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []*model.BillPayment{}
+	for rows.Next() {
+		p := &model.BillPayment{}
+		if err := rows.Scan(
+			&p.ID, &p.TransactionID, &p.Status, &p.PeriodDate, &p.DueDate, &p.Amount, &p.PaidOn,
+			&p.IsLate, &p.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan bill payment: %w", err)
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, rows.Err()
+}
+
+// MarkOverdue flags active bills whose due date is before today and clears
+// the flag on bills that are no longer overdue, across all households. It
+// returns the number of bills newly flagged.
+func (r *BillReminderRepository) MarkOverdue(ctx context.Context, today time.Time) (int, error) {
+	query := `
+		UPDATE bill_reminders
+		SET is_overdue = (is_active AND next_due_date < $1), updated_at = NOW()
+		WHERE is_overdue <> (is_active AND next_due_date < $1)
+		RETURNING is_overdue
+	`
+
+	rows, err := r.db.Query(ctx, query, today)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark overdue bill reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var flagged int
+	for rows.Next() {
+		var overdue bool
+		if err := rows.Scan(&overdue); err != nil {
+			return 0, fmt.Errorf("failed to scan overdue bill reminder: %w", err)
+		}
+		if overdue {
+			flagged++
+		}
+	}
+
+	return flagged, rows.Err()
+}
@@ -10,30 +10,36 @@ import (
 )
 
 type BudgetRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 func NewBudgetRepository(db *pgxpool.Pool) *BudgetRepository {
 	return &BudgetRepository{db: db}
 }
 
-func (r *BudgetRepository) Create(ctx context.Context, req *model.CreateBudgetRequest) (*model.Budget, error) {
+// WithTx returns a copy of the repository that runs its queries inside tx
+func (r *BudgetRepository) WithTx(tx pgx.Tx) *BudgetRepository {
+	return &BudgetRepository{db: tx}
+}
+
+func (r *BudgetRepository) Create(ctx context.Context, householdID string, req *model.CreateBudgetRequest) (*model.Budget, error) {
 	budget := &model.Budget{}
 	query := `
 		WITH inserted AS (
-			INSERT INTO budgets (category_id, amount, month, year)
-			VALUES ((SELECT id FROM categories WHERE uuid = $1), $2, $3, $4)
+			INSERT INTO budgets (household_id, category_id, amount, month, year, rollover)
+			SELECT h.id, (SELECT id FROM categories WHERE uuid = $2 AND household_id = h.id AND deleted_at IS NULL), $3, $4, $5, $6
+			FROM households h WHERE h.uuid = $1
 			RETURNING *
 		)
-		SELECT i.uuid, c.uuid, i.amount, i.month, i.year, i.created_at
+		SELECT i.uuid, c.uuid, i.amount, i.month, i.year, i.rollover, i.created_at
 		FROM inserted i JOIN categories c ON c.id = i.category_id
 	`
 
 	err := r.db.QueryRow(ctx, query,
-		req.CategoryID, req.Amount, req.Month, req.Year,
+		householdID, req.CategoryID, req.Amount, req.Month, req.Year, req.Rollover,
 	).Scan(
 		&budget.ID, &budget.CategoryID, &budget.Amount,
-		&budget.Month, &budget.Year, &budget.CreatedAt,
+		&budget.Month, &budget.Year, &budget.Rollover, &budget.CreatedAt,
 	)
 
 	if err != nil {
@@ -43,17 +49,17 @@ func (r *BudgetRepository) Create(ctx context.Context, req *model.CreateBudgetRe
 	return budget, nil
 }
 
-func (r *BudgetRepository) FindByID(ctx context.Context, id string) (*model.Budget, error) {
+func (r *BudgetRepository) FindByID(ctx context.Context, householdID, id string) (*model.Budget, error) {
 	budget := &model.Budget{}
 	query := `
-		SELECT b.uuid, c.uuid, b.amount, b.month, b.year, b.created_at
+		SELECT b.uuid, c.uuid, b.amount, b.month, b.year, b.rollover, b.created_at
 		FROM budgets b JOIN categories c ON c.id = b.category_id
-		WHERE b.uuid = $1
+		WHERE b.uuid = $1 AND b.household_id = (SELECT id FROM households WHERE uuid = $2) AND b.deleted_at IS NULL
 	`
 
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := r.db.QueryRow(ctx, query, id, householdID).Scan(
 		&budget.ID, &budget.CategoryID, &budget.Amount,
-		&budget.Month, &budget.Year, &budget.CreatedAt,
+		&budget.Month, &budget.Year, &budget.Rollover, &budget.CreatedAt,
 	)
 
 	if err == pgx.ErrNoRows {
@@ -66,15 +72,21 @@ func (r *BudgetRepository) FindByID(ctx context.Context, id string) (*model.Budg
 	return budget, nil
 }
 
-func (r *BudgetRepository) FindAll(ctx context.Context, filters *model.BudgetFilters) ([]*model.Budget, error) {
+// FindAll lists budgets. Listing a single month also lists the budgets its
+// templates call for, without an ID, as they are not stored.
+func (r *BudgetRepository) FindAll(ctx context.Context, householdID string, filters *model.BudgetFilters) ([]*model.Budget, error) {
+	if filters.Month > 0 && filters.Year > 0 {
+		return r.findMonth(ctx, householdID, filters.Month, filters.Year)
+	}
+
 	query := `
-		SELECT b.uuid, c.uuid, b.amount, b.month, b.year, b.created_at
+		SELECT b.uuid, c.uuid, b.amount, b.month, b.year, b.rollover, b.created_at
 		FROM budgets b JOIN categories c ON c.id = b.category_id
-		WHERE 1=1
+		WHERE b.household_id = (SELECT id FROM households WHERE uuid = $1) AND b.deleted_at IS NULL
 	`
 
-	args := []any{}
-	argPos := 1
+	args := []any{householdID}
+	argPos := 2
 
 	if filters.Month > 0 {
 		query += fmt.Sprintf(" AND b.month = $%d", argPos)
@@ -101,7 +113,7 @@ func (r *BudgetRepository) FindAll(ctx context.Context, filters *model.BudgetFil
 		budget := &model.Budget{}
 		if err := rows.Scan(
 			&budget.ID, &budget.CategoryID, &budget.Amount,
-			&budget.Month, &budget.Year, &budget.CreatedAt,
+			&budget.Month, &budget.Year, &budget.Rollover, &budget.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan budget: %w", err)
 		}
@@ -111,26 +123,46 @@ func (r *BudgetRepository) FindAll(ctx context.Context, filters *model.BudgetFil
 	return budgets, nil
 }
 
-func (r *BudgetRepository) Update(ctx context.Context, id string, req *model.UpdateBudgetRequest) (*model.Budget, error) {
-	if req.Amount == nil {
-		return r.FindByID(ctx, id)
+func (r *BudgetRepository) findMonth(ctx context.Context, householdID string, month, year int) ([]*model.Budget, error) {
+	query := `
+		WITH month_budgets AS (
+			SELECT b.uuid, b.category_id, b.amount, b.month, b.year, b.rollover, b.created_at
+			FROM budgets b
+			WHERE b.household_id = (SELECT id FROM households WHERE uuid = $3) AND b.deleted_at IS NULL
+				AND b.month = $1 AND b.year = $2
+			UNION ALL
+			SELECT NULL::uuid, tb.category_id, tb.amount, tb.month, tb.year, tb.rollover, tb.created_at
+			FROM (` + templateBudgets + `) tb
+			WHERE tb.month = $1 AND tb.year = $2
+		)
+		SELECT COALESCE(mb.uuid::text, ''), c.uuid, mb.amount, mb.month, mb.year, mb.rollover, mb.created_at
+		FROM month_budgets mb JOIN categories c ON c.id = mb.category_id
+		ORDER BY c.name
+	`
+
+	return r.queryBudgets(ctx, "failed to find budgets", query, month, year, householdID)
+}
+
+func (r *BudgetRepository) Update(ctx context.Context, householdID, id string, req *model.UpdateBudgetRequest) (*model.Budget, error) {
+	if req.Amount == nil && req.Rollover == nil {
+		return r.FindByID(ctx, householdID, id)
 	}
 
 	budget := &model.Budget{}
 	query := `
 		WITH updated AS (
 			UPDATE budgets
-			SET amount = $1
-			WHERE uuid = $2
+			SET amount = COALESCE($1, amount), rollover = COALESCE($2, rollover)
+			WHERE uuid = $3 AND household_id = (SELECT id FROM households WHERE uuid = $4) AND deleted_at IS NULL
 			RETURNING *
 		)
-		SELECT up.uuid, c.uuid, up.amount, up.month, up.year, up.created_at
+		SELECT up.uuid, c.uuid, up.amount, up.month, up.year, up.rollover, up.created_at
 		FROM updated up JOIN categories c ON c.id = up.category_id
 	`
 
-	err := r.db.QueryRow(ctx, query, *req.Amount, id).Scan(
+	err := r.db.QueryRow(ctx, query, req.Amount, req.Rollover, id, householdID).Scan(
 		&budget.ID, &budget.CategoryID, &budget.Amount,
-		&budget.Month, &budget.Year, &budget.CreatedAt,
+		&budget.Month, &budget.Year, &budget.Rollover, &budget.CreatedAt,
 	)
 
 	if err == pgx.ErrNoRows {
@@ -143,9 +175,13 @@ func (r *BudgetRepository) Update(ctx context.Context, id string, req *model.Upd
 	return budget, nil
 }
 
-func (r *BudgetRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM budgets WHERE uuid = $1`
-	result, err := r.db.Exec(ctx, query, id)
+// Delete moves a budget to the trash
+func (r *BudgetRepository) Delete(ctx context.Context, householdID, id string) error {
+	query := `
+		UPDATE budgets SET deleted_at = NOW()
+		WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2) AND deleted_at IS NULL
+	`
+	result, err := r.db.Exec(ctx, query, id, householdID)
 	if err != nil {
 		return fmt.Errorf("failed to delete budget: %w", err)
 	}
@@ -157,40 +193,225 @@ func (r *BudgetRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (r *BudgetRepository) GetSummary(ctx context.Context, month, year int) ([]*model.BudgetSummary, error) {
+// Restore takes a budget back out of the trash. It fails with a unique
+// violation if another budget for the same category and month exists by now.
+func (r *BudgetRepository) Restore(ctx context.Context, householdID, id string) error {
+	query := `
+		UPDATE budgets SET deleted_at = NULL
+		WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2) AND deleted_at IS NOT NULL
+	`
+	result, err := r.db.Exec(ctx, query, id, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to restore budget: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("budget not found")
+	}
+
+	return nil
+}
+
+// templateBudgets selects the budgets the household's templates call for up to
+// and including a month, in the months that have no budget for the category
+// yet, even one in the trash. Where templates overlap the oldest one wins. The
+// month, year and household are $1, $2 and $3.
+const templateBudgets = `
+	SELECT DISTINCT ON (bt.category_id, p.year, p.month)
+		bt.household_id, bt.category_id,
+		-- An annual amount is split evenly, with the cents left over going to the first months
+		COALESCE(bt.amount, bt.annual_amount / 12 + CASE WHEN p.month <= bt.annual_amount % 12 THEN 1 ELSE 0 END) AS amount,
+		p.month, p.year, bt.rollover, bt.created_at
+	FROM budget_templates bt
+	JOIN categories c ON c.id = bt.category_id AND c.deleted_at IS NULL
+	CROSS JOIN LATERAL (
+		SELECT EXTRACT(MONTH FROM d)::int AS month, EXTRACT(YEAR FROM d)::int AS year
+		FROM generate_series(make_date(bt.start_year, bt.start_month, 1)::timestamp, make_date($2, $1, 1)::timestamp, interval '1 month') AS d
+	) p
+	WHERE bt.household_id = (SELECT id FROM households WHERE uuid = $3)
+		AND (bt.end_year IS NULL OR p.year * 12 + p.month <= bt.end_year * 12 + bt.end_month)
+		AND NOT EXISTS (
+			SELECT 1 FROM budgets b
+			WHERE b.category_id = bt.category_id AND b.month = p.month AND b.year = p.year
+		)
+	ORDER BY bt.category_id, p.year, p.month, bt.created_at
+`
+
+// MaterializeTemplates creates the budgets the household's templates call for
+// up to and including the given month, so that they stay put when the
+// templates change. Months that already have a budget for the category, even
+// one in the trash, are left alone.
+func (r *BudgetRepository) MaterializeTemplates(ctx context.Context, householdID string, month, year int) ([]*model.Budget, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO budgets (household_id, category_id, amount, month, year, rollover)
+			SELECT tb.household_id, tb.category_id, tb.amount, tb.month, tb.year, tb.rollover
+			FROM (` + templateBudgets + `) tb
+			ON CONFLICT (category_id, month, year) WHERE deleted_at IS NULL DO NOTHING
+			RETURNING *
+		)
+		SELECT i.uuid, c.uuid, i.amount, i.month, i.year, i.rollover, i.created_at
+		FROM inserted i JOIN categories c ON c.id = i.category_id
+		ORDER BY i.year, i.month, c.name
+	`
+
+	return r.queryBudgets(ctx, "failed to materialize budget templates", query, month, year, householdID)
+}
+
+// CopyFromPreviousMonth copies the budgets of the month before into the given
+// month, skipping categories that already have a budget for it
+func (r *BudgetRepository) CopyFromPreviousMonth(ctx context.Context, householdID string, month, year int) ([]*model.Budget, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO budgets (household_id, category_id, amount, month, year, rollover)
+			SELECT b.household_id, b.category_id, b.amount, $1, $2, b.rollover
+			FROM budgets b
+			JOIN categories c ON c.id = b.category_id AND c.deleted_at IS NULL
+			WHERE b.household_id = (SELECT id FROM households WHERE uuid = $3)
+				AND b.deleted_at IS NULL
+				AND b.year * 12 + b.month = $2 * 12 + $1 - 1
+			ON CONFLICT (category_id, month, year) WHERE deleted_at IS NULL DO NOTHING
+			RETURNING *
+		)
+		SELECT i.uuid, c.uuid, i.amount, i.month, i.year, i.rollover, i.created_at
+		FROM inserted i JOIN categories c ON c.id = i.category_id
+		ORDER BY c.name
+	`
+
+	return r.queryBudgets(ctx, "failed to copy budgets", query, month, year, householdID)
+}
+
+func (r *BudgetRepository) queryBudgets(ctx context.Context, failure, query string, args ...any) ([]*model.Budget, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", failure, err)
+	}
+	defer rows.Close()
+
+	budgets := []*model.Budget{}
+	for rows.Next() {
+		budget := &model.Budget{}
+		if err := rows.Scan(
+			&budget.ID, &budget.CategoryID, &budget.Amount,
+			&budget.Month, &budget.Year, &budget.Rollover, &budget.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan budget: %w", err)
+		}
+		budgets = append(budgets, budget)
+	}
+
+	return budgets, rows.Err()
+}
+
+// GetSummary compares each budget of the month with what was spent in its
+// category and every category below it. A budget that rolls over carries its
+// leftover into the next month's budget for the same category, so the summary
+// walks back through the unbroken run of monthly budgets leading up to the
+// month. Months a template covers count with the template's budget whether or
+// not it has been stored yet.
+func (r *BudgetRepository) GetSummary(ctx context.Context, householdID string, month, year int) ([]*model.BudgetSummary, error) {
+	return r.summary(ctx, householdID, month, year, nil)
+}
+
+// GetCategorySummary is GetSummary limited to the budgets that count spending
+// in any of the given categories: those of the categories themselves and of
+// every category above them
+func (r *BudgetRepository) GetCategorySummary(ctx context.Context, householdID string, month, year int, categoryIDs []string) ([]*model.BudgetSummary, error) {
+	if len(categoryIDs) == 0 {
+		return nil, nil
+	}
+	return r.summary(ctx, householdID, month, year, categoryIDs)
+}
+
+// summary works out the summary of the budgets counting spending in
+// categoryIDs, or of all the month's budgets when categoryIDs is nil
+func (r *BudgetRepository) summary(ctx context.Context, householdID string, month, year int, categoryIDs []string) ([]*model.BudgetSummary, error) {
 	query := `
+		WITH RECURSIVE subtree AS (
+			-- Every category paired with itself and each category below it
+			SELECT c.id AS root_id, c.id
+			FROM categories c
+			WHERE c.household_id = (SELECT id FROM households WHERE uuid = $3) AND c.deleted_at IS NULL
+			UNION ALL
+			SELECT s.root_id, c.id
+			FROM subtree s JOIN categories c ON c.parent_id = s.id AND c.deleted_at IS NULL
+		),
+		month_budgets AS (
+			SELECT household_id, category_id, amount, month, year, rollover
+			FROM budgets
+			WHERE household_id = (SELECT id FROM households WHERE uuid = $3)
+				AND deleted_at IS NULL
+				AND year * 12 + month <= $2 * 12 + $1
+			UNION ALL
+			SELECT tb.household_id, tb.category_id, tb.amount, tb.month, tb.year, tb.rollover
+			FROM (` + templateBudgets + `) tb
+		)
 		SELECT
 			c.uuid,
 			c.name AS category_name,
+			b.month,
+			b.year,
+			b.rollover,
 			b.amount AS budget_amount,
 			COALESCE(ABS(SUM(CASE WHEN t.amount < 0 THEN t.amount ELSE 0 END)), 0) AS actual_amount
-		FROM budgets b
+		FROM month_budgets b
 		JOIN categories c ON c.id = b.category_id
-		LEFT JOIN transactions t ON t.category_id = b.category_id
-			AND EXTRACT(MONTH FROM t.date) = $1
-			AND EXTRACT(YEAR FROM t.date) = $2
-		WHERE b.month = $1 AND b.year = $2
-		GROUP BY c.uuid, c.name, b.amount
-		ORDER BY c.name
+		JOIN subtree st ON st.root_id = b.category_id
+		LEFT JOIN ` + convertedTransactionLines + ` t ON t.category_id = st.id
+			AND t.household_id = b.household_id
+			AND EXTRACT(MONTH FROM t.date) = b.month
+			AND EXTRACT(YEAR FROM t.date) = b.year
+		WHERE b.category_id IN (SELECT category_id FROM month_budgets WHERE month = $1 AND year = $2)
+			AND ($4::uuid[] IS NULL OR b.category_id IN (
+				SELECT st.root_id FROM subtree st JOIN categories sc ON sc.id = st.id WHERE sc.uuid = ANY($4)
+			))
+		GROUP BY c.uuid, c.name, b.month, b.year, b.rollover, b.amount
+		ORDER BY c.name, c.uuid, b.year, b.month
 	`
 
-	rows, err := r.db.Query(ctx, query, month, year)
+	rows, err := r.db.Query(ctx, query, month, year, householdID, categoryIDs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get budget summary: %w", err)
 	}
 	defer rows.Close()
 
 	var summaries []*model.BudgetSummary
+	var prev *model.BudgetSummary
+	prevPeriod := 0
 	for rows.Next() {
 		s := &model.BudgetSummary{}
+		var m, y int
 		if err := rows.Scan(
-			&s.CategoryID, &s.CategoryName, &s.BudgetAmount, &s.ActualAmount,
+			&s.CategoryID, &s.CategoryName, &m, &y, &s.Rollover, &s.BudgetAmount, &s.ActualAmount,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan budget summary: %w", err)
 		}
-		s.Remaining = s.BudgetAmount - s.ActualAmount
-		summaries = append(summaries, s)
+
+		// Only last month's budget for the same category carries anything in
+		period := y*12 + m
+		if prev != nil && prev.CategoryID == s.CategoryID && prevPeriod == period-1 {
+			s.CarriedIn = carryOver(prev)
+		}
+		s.EffectiveAmount = s.BudgetAmount + s.CarriedIn
+		s.Remaining = s.EffectiveAmount - s.ActualAmount
+
+		if m == month && y == year {
+			summaries = append(summaries, s)
+		}
+		prev, prevPeriod = s, period
 	}
 
-	return summaries, nil
+	return summaries, rows.Err()
+}
+
+// carryOver returns what a month's budget passes on to the next month
+func carryOver(s *model.BudgetSummary) int64 {
+	switch s.Rollover {
+	case model.RolloverSurplus:
+		return max(s.Remaining, 0)
+	case model.RolloverFull:
+		return s.Remaining
+	default:
+		return 0
+	}
 }
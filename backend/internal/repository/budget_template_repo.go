@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BudgetTemplateRepository struct {
+	db DBTX
+}
+
+func NewBudgetTemplateRepository(db *pgxpool.Pool) *BudgetTemplateRepository {
+	return &BudgetTemplateRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries inside tx
+func (r *BudgetTemplateRepository) WithTx(tx pgx.Tx) *BudgetTemplateRepository {
+	return &BudgetTemplateRepository{db: tx}
+}
+
+const budgetTemplateCols = `bt.uuid, c.uuid, bt.amount, bt.annual_amount, bt.start_month, bt.start_year,
+	bt.end_month, bt.end_year, bt.rollover, bt.created_at, bt.updated_at`
+
+func scanBudgetTemplate(row interface{ Scan(dest ...any) error }) (*model.BudgetTemplate, error) {
+	t := &model.BudgetTemplate{}
+	err := row.Scan(
+		&t.ID, &t.CategoryID, &t.Amount, &t.AnnualAmount, &t.StartMonth, &t.StartYear,
+		&t.EndMonth, &t.EndYear, &t.Rollover, &t.CreatedAt, &t.UpdatedAt,
+	)
+	return t, err
+}
+
+func (r *BudgetTemplateRepository) Create(ctx context.Context, householdID string, req *model.CreateBudgetTemplateRequest) (*model.BudgetTemplate, error) {
+	query := `
+		WITH bt AS (
+			INSERT INTO budget_templates (household_id, category_id, amount, annual_amount, start_month, start_year, end_month, end_year, rollover)
+			SELECT h.id, (SELECT id FROM categories WHERE uuid = $2 AND household_id = h.id AND deleted_at IS NULL), $3, $4, $5, $6, $7, $8, $9
+			FROM households h WHERE h.uuid = $1
+			RETURNING *
+		)
+		SELECT ` + budgetTemplateCols + `
+		FROM bt JOIN categories c ON c.id = bt.category_id
+	`
+
+	t, err := scanBudgetTemplate(r.db.QueryRow(ctx, query,
+		householdID, req.CategoryID, req.Amount, req.AnnualAmount, req.StartMonth, req.StartYear, req.EndMonth, req.EndYear, req.Rollover,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create budget template: %w", err)
+	}
+
+	return t, nil
+}
+
+func (r *BudgetTemplateRepository) FindByID(ctx context.Context, householdID, id string) (*model.BudgetTemplate, error) {
+	query := `
+		SELECT ` + budgetTemplateCols + `
+		FROM budget_templates bt JOIN categories c ON c.id = bt.category_id
+		WHERE bt.uuid = $1 AND bt.household_id = (SELECT id FROM households WHERE uuid = $2)
+	`
+
+	t, err := scanBudgetTemplate(r.db.QueryRow(ctx, query, id, householdID))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("budget template not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find budget template: %w", err)
+	}
+
+	return t, nil
+}
+
+func (r *BudgetTemplateRepository) FindAll(ctx context.Context, householdID string) ([]*model.BudgetTemplate, error) {
+	query := `
+		SELECT ` + budgetTemplateCols + `
+		FROM budget_templates bt JOIN categories c ON c.id = bt.category_id
+		WHERE bt.household_id = (SELECT id FROM households WHERE uuid = $1)
+		ORDER BY c.name, bt.start_year, bt.start_month
+	`
+
+	rows, err := r.db.Query(ctx, query, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find budget templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []*model.BudgetTemplate{}
+	for rows.Next() {
+		t, err := scanBudgetTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan budget template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, rows.Err()
+}
+
+// Update overwrites every setting of the template with those in req. Budgets
+// already created from it are left as they are.
+func (r *BudgetTemplateRepository) Update(ctx context.Context, householdID, id string, req *model.UpdateBudgetTemplateRequest) (*model.BudgetTemplate, error) {
+	query := `
+		WITH bt AS (
+			UPDATE budget_templates
+			SET category_id = (SELECT id FROM categories WHERE uuid = $1 AND household_id = budget_templates.household_id AND deleted_at IS NULL),
+				amount = $2, annual_amount = $3, start_month = $4, start_year = $5,
+				end_month = $6, end_year = $7, rollover = $8, updated_at = NOW()
+			WHERE uuid = $9 AND household_id = (SELECT id FROM households WHERE uuid = $10)
+			RETURNING *
+		)
+		SELECT ` + budgetTemplateCols + `
+		FROM bt JOIN categories c ON c.id = bt.category_id
+	`
+
+	t, err := scanBudgetTemplate(r.db.QueryRow(ctx, query,
+		req.CategoryID, req.Amount, req.AnnualAmount, req.StartMonth, req.StartYear, req.EndMonth, req.EndYear, req.Rollover,
+		id, householdID,
+	))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("budget template not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update budget template: %w", err)
+	}
+
+	return t, nil
+}
+
+// Delete removes a template. Budgets already created from it are kept.
+func (r *BudgetTemplateRepository) Delete(ctx context.Context, householdID, id string) error {
+	query := `DELETE FROM budget_templates WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2)`
+	result, err := r.db.Exec(ctx, query, id, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to delete budget template: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("budget template not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CategorizationRuleRepository struct {
+	db DBTX
+}
+
+func NewCategorizationRuleRepository(db *pgxpool.Pool) *CategorizationRuleRepository {
+	return &CategorizationRuleRepository{db: db}
+}
+
+const ruleSelectCols = `cr.uuid, cr.name, cr.priority, cr.is_active, cr.description_pattern, cr.description_match,
+	cr.min_amount, cr.max_amount, a.uuid, cr.counterparty, c.uuid, cr.add_tags, cr.set_shared, cr.created_at, cr.updated_at`
+
+const ruleJoins = `
+	FROM categorization_rules cr
+	LEFT JOIN accounts a ON a.id = cr.account_id AND a.deleted_at IS NULL
+	LEFT JOIN categories c ON c.id = cr.category_id AND c.deleted_at IS NULL`
+
+// A rule whose account or category is in the trash is left out until they
+// are restored, rather than matching every account or clearing the category
+const ruleLive = ` AND (cr.account_id IS NULL OR a.id IS NOT NULL) AND (cr.category_id IS NULL OR c.id IS NOT NULL)`
+
+// Rules run in priority order; ties go to the older rule
+const ruleOrder = ` ORDER BY cr.priority, cr.created_at, cr.id`
+
+func scanRule(row interface{ Scan(dest ...any) error }) (*model.CategorizationRule, error) {
+	rule := &model.CategorizationRule{}
+	err := row.Scan(
+		&rule.ID, &rule.Name, &rule.Priority, &rule.IsActive, &rule.DescriptionPattern, &rule.DescriptionMatch,
+		&rule.MinAmount, &rule.MaxAmount, &rule.AccountID, &rule.Counterparty, &rule.CategoryID,
+		&rule.AddTags, &rule.SetShared, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	return rule, err
+}
+
+func scanRules(rows pgx.Rows) ([]*model.CategorizationRule, error) {
+	defer rows.Close()
+
+	var rules []*model.CategorizationRule
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan categorization rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *CategorizationRuleRepository) Create(ctx context.Context, householdID string, req *model.CreateCategorizationRuleRequest) (*model.CategorizationRule, error) {
+	isActive := req.IsActive == nil || *req.IsActive
+
+	query := `
+		WITH cr AS (
+			INSERT INTO categorization_rules (household_id, name, priority, is_active, description_pattern, description_match,
+				min_amount, max_amount, account_id, counterparty, category_id, add_tags, set_shared)
+			SELECT h.id, $2, $3, $4, $5, $6, $7, $8,
+				(SELECT id FROM accounts WHERE uuid = $9 AND household_id = h.id AND deleted_at IS NULL),
+				$10,
+				(SELECT id FROM categories WHERE uuid = $11 AND household_id = h.id AND deleted_at IS NULL),
+				$12, $13
+			FROM households h WHERE h.uuid = $1
+			RETURNING *
+		)
+		SELECT ` + ruleSelectCols + `
+		FROM cr
+		LEFT JOIN accounts a ON a.id = cr.account_id AND a.deleted_at IS NULL
+		LEFT JOIN categories c ON c.id = cr.category_id AND c.deleted_at IS NULL
+	`
+
+	rule, err := scanRule(r.db.QueryRow(ctx, query,
+		householdID, req.Name, req.Priority, isActive, req.DescriptionPattern, req.DescriptionMatch,
+		req.MinAmount, req.MaxAmount, req.AccountID, req.Counterparty, req.CategoryID, req.AddTags, req.SetShared,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create categorization rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (r *CategorizationRuleRepository) FindByID(ctx context.Context, householdID, id string) (*model.CategorizationRule, error) {
+	query := `SELECT ` + ruleSelectCols + ruleJoins + `
+		WHERE cr.uuid = $1 AND cr.household_id = (SELECT id FROM households WHERE uuid = $2)` + ruleLive
+
+	rule, err := scanRule(r.db.QueryRow(ctx, query, id, householdID))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("categorization rule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find categorization rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (r *CategorizationRuleRepository) FindAll(ctx context.Context, householdID string) ([]*model.CategorizationRule, error) {
+	query := `SELECT ` + ruleSelectCols + ruleJoins + `
+		WHERE cr.household_id = (SELECT id FROM households WHERE uuid = $1)` + ruleLive + ruleOrder
+
+	rows, err := r.db.Query(ctx, query, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find categorization rules: %w", err)
+	}
+
+	return scanRules(rows)
+}
+
+// FindActive returns the active rules of a household in the order they are
+// applied
+func (r *CategorizationRuleRepository) FindActive(ctx context.Context, householdID string) ([]*model.CategorizationRule, error) {
+	query := `SELECT ` + ruleSelectCols + ruleJoins + `
+		WHERE cr.household_id = (SELECT id FROM households WHERE uuid = $1)
+		AND cr.is_active = true` + ruleLive + ruleOrder
+
+	rows, err := r.db.Query(ctx, query, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find categorization rules: %w", err)
+	}
+
+	return scanRules(rows)
+}
+
+// FindActiveByUserID returns the active rules of the user's household in the
+// order they are applied
+func (r *CategorizationRuleRepository) FindActiveByUserID(ctx context.Context, userID string) ([]*model.CategorizationRule, error) {
+	query := `SELECT ` + ruleSelectCols + ruleJoins + `
+		WHERE cr.household_id = (SELECT household_id FROM users WHERE uuid = $1)
+		AND cr.is_active = true` + ruleLive + ruleOrder
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find categorization rules: %w", err)
+	}
+
+	return scanRules(rows)
+}
+
+// Update replaces every condition and action of the rule with those in req
+func (r *CategorizationRuleRepository) Update(ctx context.Context, householdID, id string, req *model.UpdateCategorizationRuleRequest) (*model.CategorizationRule, error) {
+	isActive := req.IsActive == nil || *req.IsActive
+
+	query := `
+		WITH cr AS (
+			UPDATE categorization_rules
+			SET name = $1, priority = $2, is_active = $3, description_pattern = $4, description_match = $5,
+				min_amount = $6, max_amount = $7,
+				account_id = (SELECT id FROM accounts WHERE uuid = $8 AND household_id = categorization_rules.household_id AND deleted_at IS NULL),
+				counterparty = $9,
+				category_id = (SELECT id FROM categories WHERE uuid = $10 AND household_id = categorization_rules.household_id AND deleted_at IS NULL),
+				add_tags = $11, set_shared = $12, updated_at = NOW()
+			WHERE uuid = $13 AND household_id = (SELECT id FROM households WHERE uuid = $14)
+			RETURNING *
+		)
+		SELECT ` + ruleSelectCols + `
+		FROM cr
+		LEFT JOIN accounts a ON a.id = cr.account_id AND a.deleted_at IS NULL
+		LEFT JOIN categories c ON c.id = cr.category_id AND c.deleted_at IS NULL
+	`
+
+	rule, err := scanRule(r.db.QueryRow(ctx, query,
+		req.Name, req.Priority, isActive, req.DescriptionPattern, req.DescriptionMatch,
+		req.MinAmount, req.MaxAmount, req.AccountID, req.Counterparty, req.CategoryID, req.AddTags, req.SetShared,
+		id, householdID,
+	))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("categorization rule not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update categorization rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (r *CategorizationRuleRepository) Delete(ctx context.Context, householdID, id string) error {
+	query := `DELETE FROM categorization_rules WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2)`
+	result, err := r.db.Exec(ctx, query, id, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to delete categorization rule: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("categorization rule not found")
+	}
+
+	return nil
+}
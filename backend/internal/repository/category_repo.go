@@ -17,19 +17,20 @@ func NewCategoryRepository(db *pgxpool.Pool) *CategoryRepository {
 	return &CategoryRepository{db: db}
 }
 
-func (r *CategoryRepository) Create(ctx context.Context, req *model.CreateCategoryRequest) (*model.Category, error) {
+func (r *CategoryRepository) Create(ctx context.Context, householdID string, req *model.CreateCategoryRequest) (*model.Category, error) {
 	category := &model.Category{}
 	query := `
 		WITH inserted AS (
-			INSERT INTO categories (parent_id, name, type, icon, sort_order)
-			VALUES ((SELECT id FROM categories WHERE uuid = $1), $2, $3, $4, $5)
+			INSERT INTO categories (household_id, parent_id, name, type, icon, sort_order)
+			SELECT h.id, (SELECT id FROM categories WHERE uuid = $2 AND household_id = h.id AND deleted_at IS NULL), $3, $4, $5, $6
+			FROM households h WHERE h.uuid = $1
 			RETURNING *
 		)
 		SELECT i.uuid, p.uuid, i.name, i.type, i.icon, i.sort_order
 		FROM inserted i LEFT JOIN categories p ON p.id = i.parent_id
 	`
 
-	err := r.db.QueryRow(ctx, query, req.ParentID, req.Name, req.Type, req.Icon, req.SortOrder).
+	err := r.db.QueryRow(ctx, query, householdID, req.ParentID, req.Name, req.Type, req.Icon, req.SortOrder).
 		Scan(&category.ID, &category.ParentID, &category.Name, &category.Type, &category.Icon, &category.SortOrder)
 
 	if err != nil {
@@ -39,14 +40,15 @@ func (r *CategoryRepository) Create(ctx context.Context, req *model.CreateCatego
 	return category, nil
 }
 
-func (r *CategoryRepository) FindAll(ctx context.Context) ([]*model.Category, error) {
+func (r *CategoryRepository) FindAll(ctx context.Context, householdID string) ([]*model.Category, error) {
 	query := `
 		SELECT c.uuid, p.uuid, c.name, c.type, c.icon, c.sort_order
-		FROM categories c LEFT JOIN categories p ON p.id = c.parent_id
+		FROM categories c LEFT JOIN categories p ON p.id = c.parent_id AND p.deleted_at IS NULL
+		WHERE c.household_id = (SELECT id FROM households WHERE uuid = $1) AND c.deleted_at IS NULL
 		ORDER BY c.sort_order, c.name
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, householdID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find categories: %w", err)
 	}
@@ -64,15 +66,15 @@ func (r *CategoryRepository) FindAll(ctx context.Context) ([]*model.Category, er
 	return categories, nil
 }
 
-func (r *CategoryRepository) FindByID(ctx context.Context, id string) (*model.Category, error) {
+func (r *CategoryRepository) FindByID(ctx context.Context, householdID, id string) (*model.Category, error) {
 	category := &model.Category{}
 	query := `
 		SELECT c.uuid, p.uuid, c.name, c.type, c.icon, c.sort_order
-		FROM categories c LEFT JOIN categories p ON p.id = c.parent_id
-		WHERE c.uuid = $1
+		FROM categories c LEFT JOIN categories p ON p.id = c.parent_id AND p.deleted_at IS NULL
+		WHERE c.uuid = $1 AND c.household_id = (SELECT id FROM households WHERE uuid = $2) AND c.deleted_at IS NULL
 	`
 
-	err := r.db.QueryRow(ctx, query, id).
+	err := r.db.QueryRow(ctx, query, id, householdID).
 		Scan(&category.ID, &category.ParentID, &category.Name, &category.Type, &category.Icon, &category.SortOrder)
 
 	if err == pgx.ErrNoRows {
@@ -85,7 +87,7 @@ func (r *CategoryRepository) FindByID(ctx context.Context, id string) (*model.Ca
 	return category, nil
 }
 
-func (r *CategoryRepository) Update(ctx context.Context, id string, req *model.UpdateCategoryRequest) (*model.Category, error) {
+func (r *CategoryRepository) Update(ctx context.Context, householdID, id string, req *model.UpdateCategoryRequest) (*model.Category, error) {
 	category := &model.Category{}
 	query := `
 		WITH updated AS (
@@ -93,14 +95,14 @@ func (r *CategoryRepository) Update(ctx context.Context, id string, req *model.U
 			SET name = COALESCE(NULLIF($1, ''), name),
 			    icon = COALESCE(NULLIF($2, ''), icon),
 			    sort_order = COALESCE($3, sort_order)
-			WHERE uuid = $4
+			WHERE uuid = $4 AND household_id = (SELECT id FROM households WHERE uuid = $5) AND deleted_at IS NULL
 			RETURNING *
 		)
 		SELECT up.uuid, p.uuid, up.name, up.type, up.icon, up.sort_order
-		FROM updated up LEFT JOIN categories p ON p.id = up.parent_id
+		FROM updated up LEFT JOIN categories p ON p.id = up.parent_id AND p.deleted_at IS NULL
 	`
 
-	err := r.db.QueryRow(ctx, query, req.Name, req.Icon, req.SortOrder, id).
+	err := r.db.QueryRow(ctx, query, req.Name, req.Icon, req.SortOrder, id, householdID).
 		Scan(&category.ID, &category.ParentID, &category.Name, &category.Type, &category.Icon, &category.SortOrder)
 
 	if err == pgx.ErrNoRows {
@@ -113,9 +115,40 @@ func (r *CategoryRepository) Update(ctx context.Context, id string, req *model.U
 	return category, nil
 }
 
-func (r *CategoryRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM categories WHERE uuid = $1`
-	result, err := r.db.Exec(ctx, query, id)
+// IsInUse reports whether transactions, splits or budgets that are not in the
+// trash, or budget templates, still point at the category
+func (r *CategoryRepository) IsInUse(ctx context.Context, householdID, id string) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM transactions t WHERE t.category_id = c.id AND t.deleted_at IS NULL)
+			OR EXISTS (
+				SELECT 1 FROM transaction_splits s JOIN transactions t ON t.id = s.transaction_id
+				WHERE s.category_id = c.id AND t.deleted_at IS NULL
+			)
+			OR EXISTS (SELECT 1 FROM budgets b WHERE b.category_id = c.id AND b.deleted_at IS NULL)
+			OR EXISTS (SELECT 1 FROM budget_templates bt WHERE bt.category_id = c.id)
+		FROM categories c
+		WHERE c.uuid = $1 AND c.household_id = (SELECT id FROM households WHERE uuid = $2)
+	`
+
+	var inUse bool
+	err := r.db.QueryRow(ctx, query, id, householdID).Scan(&inUse)
+	if err == pgx.ErrNoRows {
+		return false, fmt.Errorf("category not found")
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check category usage: %w", err)
+	}
+
+	return inUse, nil
+}
+
+// Delete moves a category to the trash
+func (r *CategoryRepository) Delete(ctx context.Context, householdID, id string) error {
+	query := `
+		UPDATE categories SET deleted_at = NOW()
+		WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2) AND deleted_at IS NULL
+	`
+	result, err := r.db.Exec(ctx, query, id, householdID)
 	if err != nil {
 		return fmt.Errorf("failed to delete category: %w", err)
 	}
@@ -126,3 +159,21 @@ func (r *CategoryRepository) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// Restore takes a category back out of the trash
+func (r *CategoryRepository) Restore(ctx context.Context, householdID, id string) error {
+	query := `
+		UPDATE categories SET deleted_at = NULL
+		WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2) AND deleted_at IS NOT NULL
+	`
+	result, err := r.db.Exec(ctx, query, id, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to restore category: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("category not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,375 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ChoreRepository struct {
+	db DBTX
+}
+
+func NewChoreRepository(db *pgxpool.Pool) *ChoreRepository {
+	return &ChoreRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries inside tx
+func (r *ChoreRepository) WithTx(tx pgx.Tx) *ChoreRepository {
+	return &ChoreRepository{db: tx}
+}
+
+func scanChore(row interface{ Scan(dest ...any) error }) (*model.Chore, error) {
+	c := &model.Chore{}
+	err := row.Scan(&c.ID, &c.Name, &c.Description, &c.Reward, &c.Frequency, &c.AssignedTo, &c.IsActive, &c.CreatedAt, &c.UpdatedAt)
+	return c, err
+}
+
+// choreCompletionSelect selects chore completions as cc with their chore,
+// child, reviewer and transaction
+const choreCompletionSelect = `
+		SELECT cc.uuid, ch.uuid, cc.chore_name, u.uuid, cc.reward, cc.date, cc.status,
+			rv.uuid, cc.reviewed_at, t.uuid, cc.created_at
+		FROM chore_completions cc
+		JOIN users u ON u.id = cc.user_id
+		LEFT JOIN chores ch ON ch.id = cc.chore_id
+		LEFT JOIN users rv ON rv.id = cc.reviewed_by
+		LEFT JOIN transactions t ON t.id = cc.transaction_id AND t.deleted_at IS NULL`
+
+func scanChoreCompletion(row interface{ Scan(dest ...any) error }) (*model.ChoreCompletion, error) {
+	c := &model.ChoreCompletion{}
+	err := row.Scan(
+		&c.ID, &c.ChoreID, &c.ChoreName, &c.UserID, &c.Reward, &c.Date, &c.Status,
+		&c.ReviewedBy, &c.ReviewedAt, &c.TransactionID, &c.CreatedAt,
+	)
+	return c, err
+}
+
+func (r *ChoreRepository) Create(ctx context.Context, householdID string, req *model.CreateChoreRequest) (*model.Chore, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO chores (household_id, name, description, reward, frequency, assigned_to)
+			SELECT h.id, $2, $3, $4, COALESCE(NULLIF($5, ''), 'once'),
+				(SELECT id FROM users WHERE uuid = $6 AND household_id = h.id)
+			FROM households h WHERE h.uuid = $1
+			RETURNING *
+		)
+		SELECT i.uuid, i.name, i.description, i.reward, i.frequency, u.uuid, i.is_active, i.created_at, i.updated_at
+		FROM inserted i
+		LEFT JOIN users u ON u.id = i.assigned_to
+	`
+
+	chore, err := scanChore(r.db.QueryRow(ctx, query,
+		householdID, req.Name, req.Description, req.Reward, req.Frequency, req.AssignedTo,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chore: %w", err)
+	}
+
+	return chore, nil
+}
+
+func (r *ChoreRepository) FindByID(ctx context.Context, householdID, id string) (*model.Chore, error) {
+	return r.findByID(ctx, householdID, id, "")
+}
+
+// FindByIDForUpdate is FindByID that also locks the chore until the
+// transaction ends, so that it is marked done once per period
+func (r *ChoreRepository) FindByIDForUpdate(ctx context.Context, householdID, id string) (*model.Chore, error) {
+	return r.findByID(ctx, householdID, id, "FOR UPDATE OF c")
+}
+
+func (r *ChoreRepository) findByID(ctx context.Context, householdID, id, lock string) (*model.Chore, error) {
+	query := `
+		SELECT c.uuid, c.name, c.description, c.reward, c.frequency, u.uuid, c.is_active, c.created_at, c.updated_at
+		FROM chores c
+		LEFT JOIN users u ON u.id = c.assigned_to
+		WHERE c.uuid = $1 AND c.household_id = (SELECT id FROM households WHERE uuid = $2)
+		` + lock
+
+	chore, err := scanChore(r.db.QueryRow(ctx, query, id, householdID))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("chore not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find chore: %w", err)
+	}
+
+	return chore, nil
+}
+
+// FindAll returns the household's chores. If userID is set, only the active
+// ones that user can do are returned.
+func (r *ChoreRepository) FindAll(ctx context.Context, householdID string, userID *string) ([]*model.Chore, error) {
+	query := `
+		SELECT c.uuid, c.name, c.description, c.reward, c.frequency, u.uuid, c.is_active, c.created_at, c.updated_at
+		FROM chores c
+		LEFT JOIN users u ON u.id = c.assigned_to
+		WHERE c.household_id = (SELECT id FROM households WHERE uuid = $1)
+			AND ($2::uuid IS NULL OR (c.is_active AND (c.assigned_to IS NULL OR u.uuid = $2)))
+		ORDER BY c.name, c.id
+	`
+
+	rows, err := r.db.Query(ctx, query, householdID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find chores: %w", err)
+	}
+	defer rows.Close()
+
+	chores := []*model.Chore{}
+	for rows.Next() {
+		chore, err := scanChore(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan chore: %w", err)
+		}
+		chores = append(chores, chore)
+	}
+
+	return chores, rows.Err()
+}
+
+func (r *ChoreRepository) Update(ctx context.Context, householdID, id string, req *model.UpdateChoreRequest) (*model.Chore, error) {
+	updates := []string{}
+	args := []any{}
+	argPos := 1
+
+	if req.Name != nil {
+		updates = append(updates, fmt.Sprintf("name = $%d", argPos))
+		args = append(args, *req.Name)
+		argPos++
+	}
+
+	if req.Description != nil {
+		updates = append(updates, fmt.Sprintf("description = $%d", argPos))
+		args = append(args, *req.Description)
+		argPos++
+	}
+
+	if req.Reward != nil {
+		updates = append(updates, fmt.Sprintf("reward = $%d", argPos))
+		args = append(args, *req.Reward)
+		argPos++
+	}
+
+	if req.Frequency != nil {
+		updates = append(updates, fmt.Sprintf("frequency = $%d", argPos))
+		args = append(args, *req.Frequency)
+		argPos++
+	}
+
+	if req.AssignedTo != nil {
+		// An empty ID opens the chore to every child
+		updates = append(updates, fmt.Sprintf("assigned_to = (SELECT id FROM users WHERE uuid = NULLIF($%d, '')::uuid AND household_id = chores.household_id)", argPos))
+		args = append(args, *req.AssignedTo)
+		argPos++
+	}
+
+	if req.IsActive != nil {
+		updates = append(updates, fmt.Sprintf("is_active = $%d", argPos))
+		args = append(args, *req.IsActive)
+		argPos++
+	}
+
+	if len(updates) == 0 {
+		return r.FindByID(ctx, householdID, id)
+	}
+
+	updates = append(updates, "updated_at = NOW()")
+
+	args = append(args, id, householdID)
+	query := fmt.Sprintf(`
+		WITH updated AS (
+			UPDATE chores
+			SET %s
+			WHERE uuid = $%d AND household_id = (SELECT id FROM households WHERE uuid = $%d)
+			RETURNING *
+		)
+		SELECT up.uuid, up.name, up.description, up.reward, up.frequency, u.uuid, up.is_active, up.created_at, up.updated_at
+		FROM updated up
+		LEFT JOIN users u ON u.id = up.assigned_to
+	`, strings.Join(updates, ", "), argPos, argPos+1)
+
+	chore, err := scanChore(r.db.QueryRow(ctx, query, args...))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("chore not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update chore: %w", err)
+	}
+
+	return chore, nil
+}
+
+// Delete removes the chore. Completions are kept for the earnings report.
+func (r *ChoreRepository) Delete(ctx context.Context, householdID, id string) error {
+	query := `DELETE FROM chores WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2)`
+	result, err := r.db.Exec(ctx, query, id, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to delete chore: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("chore not found")
+	}
+
+	return nil
+}
+
+// CreateCompletion records the child doing the chore on the given date, for
+// the chore's current name and reward
+func (r *ChoreRepository) CreateCompletion(ctx context.Context, householdID, choreID, userID string, date time.Time) (*model.ChoreCompletion, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO chore_completions (household_id, chore_id, user_id, chore_name, reward, date)
+			SELECT c.household_id, c.id, u.id, c.name, c.reward, $4
+			FROM chores c
+			JOIN users u ON u.uuid = $3 AND u.household_id = c.household_id
+			WHERE c.uuid = $2 AND c.household_id = (SELECT id FROM households WHERE uuid = $1)
+			RETURNING *
+		)
+		SELECT i.uuid, c.uuid, i.chore_name, u.uuid, i.reward, i.date, i.status,
+			NULL::uuid, i.reviewed_at, NULL::uuid, i.created_at
+		FROM inserted i
+		JOIN chores c ON c.id = i.chore_id
+		JOIN users u ON u.id = i.user_id
+	`
+
+	completion, err := scanChoreCompletion(r.db.QueryRow(ctx, query, householdID, choreID, userID, date))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("chore not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record chore completion: %w", err)
+	}
+
+	return completion, nil
+}
+
+// CountCompletions counts the times the child did the chore, not counting
+// those turned down, from start up to but not including end. A nil bound is
+// left open.
+func (r *ChoreRepository) CountCompletions(ctx context.Context, choreID, userID string, start, end *time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM chore_completions cc
+		WHERE cc.chore_id = (SELECT id FROM chores WHERE uuid = $1)
+			AND cc.user_id = (SELECT id FROM users WHERE uuid = $2)
+			AND cc.status <> 'rejected'
+			AND ($3::date IS NULL OR cc.date >= $3)
+			AND ($4::date IS NULL OR cc.date < $4)
+	`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, choreID, userID, start, end).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count chore completions: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *ChoreRepository) FindCompletionByID(ctx context.Context, householdID, id string) (*model.ChoreCompletion, error) {
+	query := choreCompletionSelect + `
+		WHERE cc.uuid = $1 AND cc.household_id = (SELECT id FROM households WHERE uuid = $2)
+	`
+
+	completion, err := scanChoreCompletion(r.db.QueryRow(ctx, query, id, householdID))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("chore completion not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find chore completion: %w", err)
+	}
+
+	return completion, nil
+}
+
+// FindCompletions returns the household's chore completions, latest first
+func (r *ChoreRepository) FindCompletions(ctx context.Context, householdID string, filters *model.ChoreCompletionFilters) ([]*model.ChoreCompletion, error) {
+	query := choreCompletionSelect + `
+		WHERE cc.household_id = (SELECT id FROM households WHERE uuid = $1)
+			AND (NULLIF($2, '')::uuid IS NULL OR u.uuid = NULLIF($2, '')::uuid)
+			AND ($3 = '' OR cc.status = $3)
+		ORDER BY cc.date DESC, cc.id DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, householdID, filters.UserID, filters.Status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find chore completions: %w", err)
+	}
+	defer rows.Close()
+
+	completions := []*model.ChoreCompletion{}
+	for rows.Next() {
+		completion, err := scanChoreCompletion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan chore completion: %w", err)
+		}
+		completions = append(completions, completion)
+	}
+
+	return completions, rows.Err()
+}
+
+// Review approves or turns down a pending chore completion. It reports false
+// if the completion was already reviewed.
+func (r *ChoreRepository) Review(ctx context.Context, householdID, id, reviewerID, status string, transactionID *string) (bool, error) {
+	query := `
+		UPDATE chore_completions
+		SET status = $3,
+			reviewed_by = (SELECT id FROM users WHERE uuid = $4),
+			reviewed_at = NOW(),
+			transaction_id = (SELECT id FROM transactions WHERE uuid = $5)
+		WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2) AND status = 'pending'
+	`
+
+	result, err := r.db.Exec(ctx, query, id, householdID, status, reviewerID, transactionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to review chore completion: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// GetEarnings sums each child's approved and pending chore rewards from start
+// up to but not including end. A nil bound is left open. If userID is set,
+// only that child is returned.
+func (r *ChoreRepository) GetEarnings(ctx context.Context, householdID string, start, end *time.Time, userID *string) ([]*model.ChoreEarnings, error) {
+	query := `
+		SELECT u.uuid, u.name,
+			COUNT(cc.id) FILTER (WHERE cc.status = 'approved'),
+			COALESCE(SUM(cc.reward) FILTER (WHERE cc.status = 'approved'), 0),
+			COUNT(cc.id) FILTER (WHERE cc.status = 'pending'),
+			COALESCE(SUM(cc.reward) FILTER (WHERE cc.status = 'pending'), 0)
+		FROM users u
+		LEFT JOIN chore_completions cc ON cc.user_id = u.id
+			AND ($2::date IS NULL OR cc.date >= $2)
+			AND ($3::date IS NULL OR cc.date < $3)
+		WHERE u.household_id = (SELECT id FROM households WHERE uuid = $1)
+			AND u.role = 'child'
+			AND ($4::uuid IS NULL OR u.uuid = $4)
+		GROUP BY u.id
+		ORDER BY u.name, u.id
+	`
+
+	rows, err := r.db.Query(ctx, query, householdID, start, end, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chore earnings: %w", err)
+	}
+	defer rows.Close()
+
+	earnings := []*model.ChoreEarnings{}
+	for rows.Next() {
+		e := &model.ChoreEarnings{}
+		if err := rows.Scan(&e.UserID, &e.Name, &e.Approved, &e.Earned, &e.Pending, &e.PendingAmount); err != nil {
+			return nil, fmt.Errorf("failed to scan chore earnings: %w", err)
+		}
+		earnings = append(earnings, e)
+	}
+
+	return earnings, rows.Err()
+}
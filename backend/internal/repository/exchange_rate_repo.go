@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ExchangeRateRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewExchangeRateRepository(db *pgxpool.Pool) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+// Upsert stores rates for a household in a single statement, replacing any
+// existing rate for the same date and currency pair. Rates must not contain
+// the same date and pair twice.
+func (r *ExchangeRateRepository) Upsert(ctx context.Context, householdID string, rates []*model.ExchangeRate) (int, error) {
+	if len(rates) == 0 {
+		return 0, nil
+	}
+
+	dates := make([]time.Time, len(rates))
+	bases := make([]string, len(rates))
+	quotes := make([]string, len(rates))
+	values := make([]float64, len(rates))
+	for i, rate := range rates {
+		dates[i] = rate.Date
+		bases[i] = rate.Base
+		quotes[i] = rate.Quote
+		values[i] = rate.Rate
+	}
+
+	query := `
+		INSERT INTO exchange_rates (household_id, date, base, quote, rate)
+		SELECT (SELECT id FROM households WHERE uuid = $1), r.date, r.base, r.quote, r.rate
+		FROM unnest($2::date[], $3::varchar[], $4::varchar[], $5::numeric[]) AS r(date, base, quote, rate)
+		ON CONFLICT (household_id, date, base, quote) DO UPDATE SET rate = EXCLUDED.rate
+	`
+
+	result, err := r.db.Exec(ctx, query, householdID, dates, bases, quotes, values)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store exchange rates: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// FindAll returns a household's exchange rates with optional filters
+func (r *ExchangeRateRepository) FindAll(ctx context.Context, householdID string, filters *model.ExchangeRateFilters) ([]*model.ExchangeRate, error) {
+	query := `
+		SELECT uuid, date, base, quote, rate, created_at
+		FROM exchange_rates
+		WHERE household_id = (SELECT id FROM households WHERE uuid = $1)
+	`
+
+	args := []any{householdID}
+	argPos := 2
+
+	if filters.Base != "" {
+		query += fmt.Sprintf(" AND base = $%d", argPos)
+		args = append(args, filters.Base)
+		argPos++
+	}
+
+	if filters.Quote != "" {
+		query += fmt.Sprintf(" AND quote = $%d", argPos)
+		args = append(args, filters.Quote)
+		argPos++
+	}
+
+	if filters.StartDate != "" {
+		query += fmt.Sprintf(" AND date >= $%d", argPos)
+		args = append(args, filters.StartDate)
+		argPos++
+	}
+
+	if filters.EndDate != "" {
+		query += fmt.Sprintf(" AND date <= $%d", argPos)
+		args = append(args, filters.EndDate)
+		argPos++
+	}
+
+	query += " ORDER BY date DESC, base, quote"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []*model.ExchangeRate
+	for rows.Next() {
+		rate := &model.ExchangeRate{}
+		if err := rows.Scan(&rate.ID, &rate.Date, &rate.Base, &rate.Quote, &rate.Rate, &rate.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type HouseholdRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewHouseholdRepository(db *pgxpool.Pool) *HouseholdRepository {
+	return &HouseholdRepository{db: db}
+}
+
+// FindByID finds a household by ID
+func (r *HouseholdRepository) FindByID(ctx context.Context, id string) (*model.Household, error) {
+	household := &model.Household{}
+	query := `
+		SELECT uuid, name, base_currency, require_admin_two_factor, created_at, updated_at
+		FROM households
+		WHERE uuid = $1
+	`
+
+	err := r.db.QueryRow(ctx, query, id).
+		Scan(&household.ID, &household.Name, &household.BaseCurrency, &household.RequireAdminTwoFactor, &household.CreatedAt, &household.UpdatedAt)
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("household not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find household: %w", err)
+	}
+
+	return household, nil
+}
+
+// Update updates a household's name, base currency and two-factor requirement
+func (r *HouseholdRepository) Update(ctx context.Context, id string, req *model.UpdateHouseholdRequest) (*model.Household, error) {
+	updates := []string{}
+	args := []any{}
+	argPos := 1
+
+	if req.Name != nil {
+		updates = append(updates, fmt.Sprintf("name = $%d", argPos))
+		args = append(args, *req.Name)
+		argPos++
+	}
+
+	if req.BaseCurrency != nil {
+		updates = append(updates, fmt.Sprintf("base_currency = $%d", argPos))
+		args = append(args, *req.BaseCurrency)
+		argPos++
+	}
+
+	if req.RequireAdminTwoFactor != nil {
+		updates = append(updates, fmt.Sprintf("require_admin_two_factor = $%d", argPos))
+		args = append(args, *req.RequireAdminTwoFactor)
+		argPos++
+	}
+
+	if len(updates) == 0 {
+		return r.FindByID(ctx, id)
+	}
+
+	updates = append(updates, "updated_at = NOW()")
+
+	args = append(args, id)
+	query := fmt.Sprintf(`
+		UPDATE households
+		SET %s
+		WHERE uuid = $%d
+		RETURNING uuid, name, base_currency, require_admin_two_factor, created_at, updated_at
+	`, strings.Join(updates, ", "), argPos)
+
+	household := &model.Household{}
+	err := r.db.QueryRow(ctx, query, args...).
+		Scan(&household.ID, &household.Name, &household.BaseCurrency, &household.RequireAdminTwoFactor, &household.CreatedAt, &household.UpdatedAt)
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("household not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update household: %w", err)
+	}
+
+	return household, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ImportProfileRepository struct {
+	db DBTX
+}
+
+func NewImportProfileRepository(db *pgxpool.Pool) *ImportProfileRepository {
+	return &ImportProfileRepository{db: db}
+}
+
+const importProfileCols = `uuid, name, delimiter, encoding, header_row, date_layout, decimal_separator, amount_sign, columns, created_at, updated_at`
+
+func scanImportProfile(row interface{ Scan(dest ...any) error }) (*model.ImportProfile, error) {
+	p := &model.ImportProfile{}
+	err := row.Scan(
+		&p.ID, &p.Name, &p.Delimiter, &p.Encoding, &p.HeaderRow, &p.DateLayout,
+		&p.DecimalSeparator, &p.AmountSign, &p.Columns, &p.CreatedAt, &p.UpdatedAt,
+	)
+	return p, err
+}
+
+func (r *ImportProfileRepository) Create(ctx context.Context, householdID string, p *model.ImportProfile) (*model.ImportProfile, error) {
+	query := `
+		INSERT INTO import_profiles (household_id, name, delimiter, encoding, header_row, date_layout, decimal_separator, amount_sign, columns)
+		SELECT h.id, $2, $3, $4, $5, $6, $7, $8, $9
+		FROM households h WHERE h.uuid = $1
+		RETURNING ` + importProfileCols
+
+	created, err := scanImportProfile(r.db.QueryRow(ctx, query,
+		householdID, p.Name, p.Delimiter, p.Encoding, p.HeaderRow, p.DateLayout, p.DecimalSeparator, p.AmountSign, p.Columns,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create import profile: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *ImportProfileRepository) FindByID(ctx context.Context, householdID, id string) (*model.ImportProfile, error) {
+	query := `SELECT ` + importProfileCols + ` FROM import_profiles
+		WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2)`
+
+	p, err := scanImportProfile(r.db.QueryRow(ctx, query, id, householdID))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("import profile not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find import profile: %w", err)
+	}
+
+	return p, nil
+}
+
+func (r *ImportProfileRepository) FindAll(ctx context.Context, householdID string) ([]*model.ImportProfile, error) {
+	query := `SELECT ` + importProfileCols + ` FROM import_profiles
+		WHERE household_id = (SELECT id FROM households WHERE uuid = $1)
+		ORDER BY name`
+
+	rows, err := r.db.Query(ctx, query, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find import profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []*model.ImportProfile
+	for rows.Next() {
+		p, err := scanImportProfile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan import profile: %w", err)
+		}
+		profiles = append(profiles, p)
+	}
+
+	return profiles, nil
+}
+
+// Update overwrites every setting of the profile with those in p
+func (r *ImportProfileRepository) Update(ctx context.Context, householdID, id string, p *model.ImportProfile) (*model.ImportProfile, error) {
+	query := `
+		UPDATE import_profiles
+		SET name = $1, delimiter = $2, encoding = $3, header_row = $4, date_layout = $5,
+			decimal_separator = $6, amount_sign = $7, columns = $8, updated_at = NOW()
+		WHERE uuid = $9 AND household_id = (SELECT id FROM households WHERE uuid = $10)
+		RETURNING ` + importProfileCols
+
+	updated, err := scanImportProfile(r.db.QueryRow(ctx, query,
+		p.Name, p.Delimiter, p.Encoding, p.HeaderRow, p.DateLayout, p.DecimalSeparator, p.AmountSign, p.Columns,
+		id, householdID,
+	))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("import profile not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update import profile: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (r *ImportProfileRepository) Delete(ctx context.Context, householdID, id string) error {
+	query := `DELETE FROM import_profiles WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2)`
+	result, err := r.db.Exec(ctx, query, id, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to delete import profile: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("import profile not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type JobRunRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewJobRunRepository(db *pgxpool.Pool) *JobRunRepository {
+	return &JobRunRepository{db: db}
+}
+
+// Start records a new running job for runDate and returns it
+func (r *JobRunRepository) Start(ctx context.Context, jobName string, runDate time.Time) (*model.JobRun, error) {
+	run := &model.JobRun{}
+	query := `
+		INSERT INTO job_runs (job_name, run_date)
+		VALUES ($1, $2)
+		RETURNING uuid, job_name, run_date, status, summary, error, started_at, finished_at
+	`
+
+	err := r.db.QueryRow(ctx, query, jobName, runDate).Scan(
+		&run.ID, &run.JobName, &run.RunDate, &run.Status, &run.Summary, &run.Error, &run.StartedAt, &run.FinishedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start job run: %w", err)
+	}
+
+	return run, nil
+}
+
+// Finish marks a job run as succeeded or failed. jobErr is recorded either
+// way, so a run that succeeded for all but some items keeps their errors.
+func (r *JobRunRepository) Finish(ctx context.Context, id, summary string, succeeded bool, jobErr error) error {
+	status := "succeeded"
+	if !succeeded {
+		status = "failed"
+	}
+	var errMsg *string
+	if jobErr != nil {
+		msg := jobErr.Error()
+		errMsg = &msg
+	}
+
+	query := `
+		UPDATE job_runs SET status = $1, summary = $2, error = $3, finished_at = NOW()
+		WHERE uuid = $4
+	`
+	if _, err := r.db.Exec(ctx, query, status, summary, errMsg, id); err != nil {
+		return fmt.Errorf("failed to finish job run: %w", err)
+	}
+
+	return nil
+}
+
+// HasSucceeded reports whether the job already completed successfully for runDate
+func (r *JobRunRepository) HasSucceeded(ctx context.Context, jobName string, runDate time.Time) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM job_runs
+			WHERE job_name = $1 AND run_date = $2 AND status = 'succeeded'
+		)
+	`
+
+	if err := r.db.QueryRow(ctx, query, jobName, runDate).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check job runs: %w", err)
+	}
+
+	return exists, nil
+}
+
+// CountFailed returns how many times the job failed for runDate
+func (r *JobRunRepository) CountFailed(ctx context.Context, jobName string, runDate time.Time) (int, error) {
+	var failed int
+	query := `
+		SELECT COUNT(*) FROM job_runs
+		WHERE job_name = $1 AND run_date = $2 AND status = 'failed'
+	`
+
+	if err := r.db.QueryRow(ctx, query, jobName, runDate).Scan(&failed); err != nil {
+		return 0, fmt.Errorf("failed to count failed job runs: %w", err)
+	}
+
+	return failed, nil
+}
+
+// FindRecent returns the latest job runs, newest first
+func (r *JobRunRepository) FindRecent(ctx context.Context, limit int) ([]*model.JobRun, error) {
+	query := `
+		SELECT uuid, job_name, run_date, status, summary, error, started_at, finished_at
+		FROM job_runs
+		ORDER BY started_at DESC
+		LIMIT $1
+	`
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find job runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*model.JobRun
+	for rows.Next() {
+		run := &model.JobRun{}
+		if err := rows.Scan(
+			&run.ID, &run.JobName, &run.RunDate, &run.Status, &run.Summary, &run.Error, &run.StartedAt, &run.FinishedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan job run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
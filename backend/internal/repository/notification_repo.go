@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type NotificationRepository struct {
+	db DBTX
+}
+
+func NewNotificationRepository(db *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries inside tx
+func (r *NotificationRepository) WithTx(tx pgx.Tx) *NotificationRepository {
+	return &NotificationRepository{db: tx}
+}
+
+// notificationFeedLimit caps the feed at the most recent notifications
+const notificationFeedLimit = 100
+
+// Create adds a notification to the user's feed. It returns nil if the user
+// already has one with the same dedupe key.
+func (r *NotificationRepository) Create(ctx context.Context, userID string, n *model.Notification, dedupeKey string) (*model.Notification, error) {
+	created := &model.Notification{}
+	query := `
+		INSERT INTO notifications (user_id, type, title, body, entity_id, dedupe_key)
+		SELECT u.id, $2, $3, $4, $5, $6
+		FROM users u
+		WHERE u.uuid = $1
+		ON CONFLICT (user_id, dedupe_key) DO NOTHING
+		RETURNING uuid, type, title, body, entity_id, read_at, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, userID, n.Type, n.Title, n.Body, n.EntityID, dedupeKey).
+		Scan(&created.ID, &created.Type, &created.Title, &created.Body, &created.EntityID, &created.ReadAt, &created.CreatedAt)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return created, nil
+}
+
+// FindByUserID returns the user's most recent notifications, newest first,
+// optionally only the unread ones
+func (r *NotificationRepository) FindByUserID(ctx context.Context, userID string, unreadOnly bool) ([]*model.Notification, error) {
+	query := `
+		SELECT n.uuid, n.type, n.title, n.body, n.entity_id, n.read_at, n.created_at
+		FROM notifications n
+		WHERE n.user_id = (SELECT id FROM users WHERE uuid = $1)
+			AND (NOT $2 OR n.read_at IS NULL)
+		ORDER BY n.created_at DESC, n.id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, unreadOnly, notificationFeedLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []*model.Notification{}
+	for rows.Next() {
+		n := &model.Notification{}
+		if err := rows.Scan(&n.ID, &n.Type, &n.Title, &n.Body, &n.EntityID, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// CountUnread counts the user's notifications that have not been read
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE user_id = (SELECT id FROM users WHERE uuid = $1) AND read_at IS NULL
+	`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	return count, nil
+}
+
+// MarkRead marks one of the user's notifications as read
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id string) error {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, NOW())
+		WHERE uuid = $1 AND user_id = (SELECT id FROM users WHERE uuid = $2)
+	`
+
+	tag, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("notification not found")
+	}
+
+	return nil
+}
+
+// MarkAllRead marks all of the user's notifications as read
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string) error {
+	query := `
+		UPDATE notifications
+		SET read_at = NOW()
+		WHERE user_id = (SELECT id FROM users WHERE uuid = $1) AND read_at IS NULL
+	`
+
+	if _, err := r.db.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+
+	return nil
+}
+
+// FindPreferences returns the preferences the user has saved. Kinds of
+// notifications the user never changed are missing.
+func (r *NotificationRepository) FindPreferences(ctx context.Context, userID string) ([]*model.NotificationPreference, error) {
+	query := `
+		SELECT p.type, p.enabled, p.channels, p.threshold
+		FROM notification_preferences p
+		WHERE p.user_id = (SELECT id FROM users WHERE uuid = $1)
+		ORDER BY p.type
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	var prefs []*model.NotificationPreference
+	for rows.Next() {
+		p := &model.NotificationPreference{}
+		if err := rows.Scan(&p.Type, &p.Enabled, &p.Channels, &p.Threshold); err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %w", err)
+		}
+		prefs = append(prefs, p)
+	}
+
+	return prefs, rows.Err()
+}
+
+// SavePreference stores the user's preference for one kind of notification
+func (r *NotificationRepository) SavePreference(ctx context.Context, userID string, req *model.NotificationPreferenceRequest) error {
+	channels := req.Channels
+	if channels == nil {
+		channels = []string{}
+	}
+
+	query := `
+		INSERT INTO notification_preferences (user_id, type, enabled, channels, threshold)
+		SELECT u.id, $2, $3, $4, $5
+		FROM users u
+		WHERE u.uuid = $1
+		ON CONFLICT (user_id, type) DO UPDATE
+		SET enabled = EXCLUDED.enabled, channels = EXCLUDED.channels, threshold = EXCLUDED.threshold, updated_at = NOW()
+	`
+
+	tag, err := r.db.Exec(ctx, query, userID, req.Type, req.Enabled, channels, req.Threshold)
+	if err != nil {
+		return fmt.Errorf("failed to save notification preference: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
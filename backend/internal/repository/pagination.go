@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+)
+
+// transactionSortColumns maps each sort field to its column and the type the
+// cursor value is cast to
+var transactionSortColumns = map[string]struct{ column, cast string }{
+	"date":        {"t.date", "date"},
+	"amount":      {"t.amount", "bigint"},
+	"description": {"COALESCE(t.description, '')", "text"},
+	"createdAt":   {"t.created_at", "timestamptz"},
+}
+
+// findTransactionPage returns one page of the transactions matching where, a
+// condition on t whose placeholders are args. Transactions in the trash are
+// left out. Pages are keyset-paginated on (sort field, created_at, uuid), so
+// rows inserted while a client is scrolling never shift later pages.
+func findTransactionPage(ctx context.Context, db DBTX, where string, args []any, page model.PageParams) (*model.TransactionPage, error) {
+	cursor, err := page.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	where = "t.deleted_at IS NULL AND " + where
+
+	sortCol := transactionSortColumns[page.Sort]
+	dir, cmp := "DESC", "<"
+	if page.Order == "asc" {
+		dir, cmp = "ASC", ">"
+	}
+
+	query := `SELECT ` + txnSelectCols + txnJoins + ` WHERE ` + where
+	pageArgs := append([]any{}, args...)
+	argPos := len(args) + 1
+
+	if cursor != nil {
+		query += fmt.Sprintf(" AND (%s, t.created_at, t.uuid) %s ($%d::%s, $%d::timestamptz, $%d::uuid)",
+			sortCol.column, cmp, argPos, sortCol.cast, argPos+1, argPos+2)
+		pageArgs = append(pageArgs, cursor.Value, cursor.CreatedAt, cursor.ID)
+		argPos += 3
+	}
+
+	query += fmt.Sprintf(" ORDER BY %s %s, t.created_at %s, t.uuid %s", sortCol.column, dir, dir, dir)
+
+	// Fetch one extra row to learn whether another page follows
+	if page.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argPos)
+		pageArgs = append(pageArgs, page.Limit+1)
+	}
+
+	rows, err := db.Query(ctx, query, pageArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []*model.Transaction{}
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find transactions: %w", err)
+	}
+
+	result := &model.TransactionPage{Transactions: transactions}
+
+	if page.Limit > 0 && len(transactions) > page.Limit {
+		result.Transactions = transactions[:page.Limit]
+		last := result.Transactions[page.Limit-1]
+		next := &model.Cursor{
+			Sort:      page.Sort,
+			Order:     page.Order,
+			Value:     transactionSortValue(last, page.Sort),
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		}
+		result.NextCursor = next.Encode()
+	}
+
+	// An unpaginated first page already holds every match
+	if page.Limit == 0 && cursor == nil {
+		result.TotalCount = len(result.Transactions)
+		return result, nil
+	}
+
+	countQuery := `SELECT COUNT(*) FROM transactions t WHERE ` + where
+	if err := db.QueryRow(ctx, countQuery, args...).Scan(&result.TotalCount); err != nil {
+		return nil, fmt.Errorf("failed to count transactions: %w", err)
+	}
+
+	return result, nil
+}
+
+// transactionSortValue renders t's sort field the way the cursor stores it
+func transactionSortValue(t *model.Transaction, sort string) string {
+	switch sort {
+	case "amount":
+		return strconv.FormatInt(t.Amount, 10)
+	case "description":
+		return t.Description
+	case "createdAt":
+		return t.CreatedAt.Format(time.RFC3339Nano)
+	default:
+		return t.Date.Format("2006-01-02")
+	}
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReconciliationRepository struct {
+	db DBTX
+}
+
+func NewReconciliationRepository(db *pgxpool.Pool) *ReconciliationRepository {
+	return &ReconciliationRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries inside tx
+func (r *ReconciliationRepository) WithTx(tx pgx.Tx) *ReconciliationRepository {
+	return &ReconciliationRepository{db: tx}
+}
+
+const reconciliationSelectCols = `rc.uuid, a.uuid, rc.statement_date, rc.closing_balance, rc.status, adj.uuid, rc.completed_at, rc.created_at`
+
+const reconciliationJoins = `
+	FROM reconciliations rc
+	JOIN accounts a ON a.id = rc.account_id
+	LEFT JOIN transactions adj ON adj.id = rc.adjustment_transaction_id`
+
+func scanReconciliation(row interface{ Scan(dest ...any) error }) (*model.Reconciliation, error) {
+	rec := &model.Reconciliation{}
+	err := row.Scan(
+		&rec.ID, &rec.AccountID, &rec.StatementDate, &rec.ClosingBalance, &rec.Status,
+		&rec.AdjustmentTransactionID, &rec.CompletedAt, &rec.CreatedAt,
+	)
+	return rec, err
+}
+
+func (r *ReconciliationRepository) Create(ctx context.Context, householdID, accountID string, statementDate time.Time, closingBalance int64) (*model.Reconciliation, error) {
+	query := `
+		WITH rc AS (
+			INSERT INTO reconciliations (household_id, account_id, statement_date, closing_balance)
+			SELECT h.id, (SELECT id FROM accounts WHERE uuid = $2 AND household_id = h.id AND deleted_at IS NULL), $3, $4
+			FROM households h WHERE h.uuid = $1
+			RETURNING *
+		)
+		SELECT ` + reconciliationSelectCols + `
+		FROM rc
+		JOIN accounts a ON a.id = rc.account_id
+		LEFT JOIN transactions adj ON adj.id = rc.adjustment_transaction_id
+	`
+
+	rec, err := scanReconciliation(r.db.QueryRow(ctx, query, householdID, accountID, statementDate, closingBalance))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reconciliation: %w", err)
+	}
+
+	return rec, nil
+}
+
+func (r *ReconciliationRepository) FindByID(ctx context.Context, householdID, id string) (*model.Reconciliation, error) {
+	query := `SELECT ` + reconciliationSelectCols + reconciliationJoins + `
+		WHERE rc.uuid = $1 AND rc.household_id = (SELECT id FROM households WHERE uuid = $2)`
+
+	rec, err := scanReconciliation(r.db.QueryRow(ctx, query, id, householdID))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("reconciliation not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reconciliation: %w", err)
+	}
+
+	return rec, nil
+}
+
+// FindByAccountID returns the reconciliations of an account, latest statement first
+func (r *ReconciliationRepository) FindByAccountID(ctx context.Context, householdID, accountID string) ([]*model.Reconciliation, error) {
+	query := `SELECT ` + reconciliationSelectCols + reconciliationJoins + `
+		WHERE a.uuid = $1 AND rc.household_id = (SELECT id FROM households WHERE uuid = $2)
+		ORDER BY rc.statement_date DESC, rc.created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, accountID, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reconciliations: %w", err)
+	}
+	defer rows.Close()
+
+	reconciliations := []*model.Reconciliation{}
+	for rows.Next() {
+		rec, err := scanReconciliation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reconciliation: %w", err)
+		}
+		reconciliations = append(reconciliations, rec)
+	}
+
+	return reconciliations, rows.Err()
+}
+
+// ClearedBalance returns the account balance as of the statement date,
+// counting only cleared and reconciled transactions
+func (r *ReconciliationRepository) ClearedBalance(ctx context.Context, accountID string, statementDate time.Time) (int64, error) {
+	query := `
+		SELECT a.balance - COALESCE((
+			SELECT SUM(CASE WHEN t.account_id = a.id THEN t.amount ELSE -t.amount END)
+			FROM transactions t
+			WHERE (t.account_id = a.id OR t.transfer_to_account_id = a.id)
+			AND (t.status = 'uncleared' OR (t.status = 'cleared' AND t.date > $2))
+			AND t.deleted_at IS NULL
+		), 0)
+		FROM accounts a WHERE a.uuid = $1
+	`
+
+	var balance int64
+	err := r.db.QueryRow(ctx, query, accountID, statementDate).Scan(&balance)
+	if err == pgx.ErrNoRows {
+		return 0, fmt.Errorf("account not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to calculate cleared balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// Complete closes an open reconciliation, recording the adjustment booked to
+// balance it, if any
+func (r *ReconciliationRepository) Complete(ctx context.Context, householdID, id string, adjustmentTransactionID *string) error {
+	query := `
+		UPDATE reconciliations
+		SET status = 'completed', completed_at = NOW(),
+			adjustment_transaction_id = (SELECT id FROM transactions WHERE uuid = $1)
+		WHERE uuid = $2 AND household_id = (SELECT id FROM households WHERE uuid = $3) AND status = 'open'
+	`
+
+	result, err := r.db.Exec(ctx, query, adjustmentTransactionID, id, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to complete reconciliation: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("reconciliation is not open")
+	}
+
+	return nil
+}
+
+// Delete discards an open reconciliation; completed ones are kept as history
+func (r *ReconciliationRepository) Delete(ctx context.Context, householdID, id string) error {
+	query := `DELETE FROM reconciliations WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2) AND status = 'open'`
+	result, err := r.db.Exec(ctx, query, id, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to delete reconciliation: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("reconciliation not found or already completed")
+	}
+
+	return nil
+}
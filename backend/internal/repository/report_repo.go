@@ -16,6 +16,42 @@ func NewReportRepository(db *pgxpool.Pool) *ReportRepository {
 	return &ReportRepository{db: db}
 }
 
+// convertedTransactions selects transactions with amount converted from the
+// account currency to the household base currency at the transaction date's
+// exchange rate. Aggregating queries read from it instead of transactions.
+// Transactions in the trash and purchases not approved are left out.
+const convertedTransactions = `(
+		SELECT tx.id, tx.household_id, tx.user_id, tx.category_id, tx.type, tx.date,
+			convert_amount(tx.household_id, tx.amount, acc.currency, hh.base_currency, tx.date) AS amount
+		FROM transactions tx
+		JOIN accounts acc ON acc.id = tx.account_id
+		JOIN households hh ON hh.id = tx.household_id
+		WHERE tx.deleted_at IS NULL AND tx.status NOT IN ('pending_approval', 'rejected')
+	)`
+
+// transactionLines has a row for each split of a split transaction and one
+// for every other transaction, so totals by category count each part of a
+// receipt under its own category. Transactions in the trash and purchases not
+// approved are left out.
+const transactionLines = `(
+		SELECT tx.id, tx.household_id, tx.user_id, tx.account_id, tx.type, tx.date,
+			COALESCE(s.category_id, tx.category_id) AS category_id,
+			COALESCE(s.amount, tx.amount) AS amount
+		FROM transactions tx
+		LEFT JOIN transaction_splits s ON s.transaction_id = tx.id
+		WHERE tx.deleted_at IS NULL AND tx.status NOT IN ('pending_approval', 'rejected')
+	)`
+
+// convertedTransactionLines is transactionLines with amounts converted to the
+// household base currency like convertedTransactions
+const convertedTransactionLines = `(
+		SELECT tl.id, tl.household_id, tl.user_id, tl.category_id, tl.type, tl.date,
+			convert_amount(tl.household_id, tl.amount, acc.currency, hh.base_currency, tl.date) AS amount
+		FROM ` + transactionLines + ` tl
+		JOIN accounts acc ON acc.id = tl.account_id
+		JOIN households hh ON hh.id = tl.household_id
+	)`
+
 func (r *ReportRepository) GetMonthSummary(ctx context.Context, userID string, month, year int) (*model.MonthSummary, error) {
 	summary := &model.MonthSummary{
 		Month: month,
@@ -26,7 +62,7 @@ func (r *ReportRepository) GetMonthSummary(ctx context.Context, userID string, m
 		SELECT
 			COALESCE(SUM(CASE WHEN amount > 0 THEN amount ELSE 0 END), 0) AS total_income,
 			COALESCE(SUM(CASE WHEN amount < 0 THEN ABS(amount) ELSE 0 END), 0) AS total_expense
-		FROM transactions
+		FROM ` + convertedTransactions + ` transactions
 		WHERE user_id = (SELECT id FROM users WHERE uuid = $1)
 			AND EXTRACT(MONTH FROM date) = $2
 			AND EXTRACT(YEAR FROM date) = $3
@@ -45,7 +81,7 @@ func (r *ReportRepository) GetMonthSummary(ctx context.Context, userID string, m
 
 func (r *ReportRepository) GetRecentTransactions(ctx context.Context, userID string, limit int) ([]*model.Transaction, error) {
 	query := `SELECT ` + txnSelectCols + txnJoins + `
-		WHERE t.user_id = (SELECT id FROM users WHERE uuid = $1)
+		WHERE t.user_id = (SELECT id FROM users WHERE uuid = $1) AND t.deleted_at IS NULL
 		ORDER BY t.date DESC, t.created_at DESC
 		LIMIT $2
 	`
@@ -68,8 +104,8 @@ func (r *ReportRepository) GetRecentTransactions(ctx context.Context, userID str
 	return transactions, nil
 }
 
-// GetMonthSummaryAll returns month summary for all users (admin)
-func (r *ReportRepository) GetMonthSummaryAll(ctx context.Context, month, year int) (*model.MonthSummary, error) {
+// GetMonthSummaryAll returns month summary for all users in a household (admin)
+func (r *ReportRepository) GetMonthSummaryAll(ctx context.Context, householdID string, month, year int) (*model.MonthSummary, error) {
 	summary := &model.MonthSummary{
 		Month: month,
 		Year:  year,
@@ -79,12 +115,13 @@ func (r *ReportRepository) GetMonthSummaryAll(ctx context.Context, month, year i
 		SELECT
 			COALESCE(SUM(CASE WHEN amount > 0 THEN amount ELSE 0 END), 0) AS total_income,
 			COALESCE(SUM(CASE WHEN amount < 0 THEN ABS(amount) ELSE 0 END), 0) AS total_expense
-		FROM transactions
-		WHERE EXTRACT(MONTH FROM date) = $1
+		FROM ` + convertedTransactions + ` transactions
+		WHERE household_id = (SELECT id FROM households WHERE uuid = $3)
+			AND EXTRACT(MONTH FROM date) = $1
 			AND EXTRACT(YEAR FROM date) = $2
 	`
 
-	err := r.db.QueryRow(ctx, query, month, year).Scan(
+	err := r.db.QueryRow(ctx, query, month, year, householdID).Scan(
 		&summary.TotalIncome, &summary.TotalExpense,
 	)
 	if err != nil {
@@ -95,14 +132,15 @@ func (r *ReportRepository) GetMonthSummaryAll(ctx context.Context, month, year i
 	return summary, nil
 }
 
-// GetRecentTransactionsAll returns recent transactions for all users (admin)
-func (r *ReportRepository) GetRecentTransactionsAll(ctx context.Context, limit int) ([]*model.Transaction, error) {
+// GetRecentTransactionsAll returns recent transactions for all users in a household (admin)
+func (r *ReportRepository) GetRecentTransactionsAll(ctx context.Context, householdID string, limit int) ([]*model.Transaction, error) {
 	query := `SELECT ` + txnSelectCols + txnJoins + `
+		WHERE t.household_id = (SELECT id FROM households WHERE uuid = $2) AND t.deleted_at IS NULL
 		ORDER BY t.date DESC, t.created_at DESC
 		LIMIT $1
 	`
 
-	rows, err := r.db.Query(ctx, query, limit)
+	rows, err := r.db.Query(ctx, query, limit, householdID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get recent transactions: %w", err)
 	}
@@ -120,23 +158,26 @@ func (r *ReportRepository) GetRecentTransactionsAll(ctx context.Context, limit i
 	return transactions, nil
 }
 
-// GetSpendingByCategoryAll returns spending by category for all users (admin)
-func (r *ReportRepository) GetSpendingByCategoryAll(ctx context.Context, month, year int) ([]*model.CategorySpending, error) {
+// GetSpendingByCategoryAll returns spending by category for all users in a household (admin)
+func (r *ReportRepository) GetSpendingByCategoryAll(ctx context.Context, householdID string, month, year int) ([]*model.CategorySpending, error) {
 	query := `
 		SELECT
-			c.uuid,
-			c.name AS category_name,
+			COALESCE(c.uuid::text, '') AS category_id,
+			COALESCE(c.name, 'Uncategorized') AS category_name,
 			COALESCE(SUM(ABS(t.amount)), 0) AS total_amount
-		FROM transactions t
-		JOIN categories c ON c.id = t.category_id
-		WHERE t.amount < 0
+		FROM ` + convertedTransactionLines + ` t
+		LEFT JOIN categories c ON c.id = t.category_id
+		WHERE t.household_id = (SELECT id FROM households WHERE uuid = $3)
+			AND t.amount < 0
 			AND EXTRACT(MONTH FROM t.date) = $1
 			AND EXTRACT(YEAR FROM t.date) = $2
+			-- Uncategorized transfers move money rather than spend it
+			AND (c.id IS NOT NULL OR t.type <> 'transfer')
 		GROUP BY c.uuid, c.name
 		ORDER BY total_amount DESC
 	`
 
-	rows, err := r.db.Query(ctx, query, month, year)
+	rows, err := r.db.Query(ctx, query, month, year, householdID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get spending by category: %w", err)
 	}
@@ -162,21 +203,22 @@ func (r *ReportRepository) GetSpendingByCategoryAll(ctx context.Context, month,
 	return results, nil
 }
 
-// GetTrendsAll returns trends for all users (admin)
-func (r *ReportRepository) GetTrendsAll(ctx context.Context, months int) ([]*model.TrendPoint, error) {
+// GetTrendsAll returns trends for all users in a household (admin)
+func (r *ReportRepository) GetTrendsAll(ctx context.Context, householdID string, months int) ([]*model.TrendPoint, error) {
 	query := `
 		SELECT
 			EXTRACT(MONTH FROM date)::int AS month,
 			EXTRACT(YEAR FROM date)::int AS year,
 			COALESCE(SUM(CASE WHEN amount > 0 THEN amount ELSE 0 END), 0) AS total_income,
 			COALESCE(SUM(CASE WHEN amount < 0 THEN ABS(amount) ELSE 0 END), 0) AS total_expense
-		FROM transactions
-		WHERE date >= (CURRENT_DATE - make_interval(months => $1))
+		FROM ` + convertedTransactions + ` transactions
+		WHERE household_id = (SELECT id FROM households WHERE uuid = $2)
+			AND date >= (CURRENT_DATE - make_interval(months => $1))
 		GROUP BY EXTRACT(YEAR FROM date), EXTRACT(MONTH FROM date)
 		ORDER BY year, month
 	`
 
-	rows, err := r.db.Query(ctx, query, months)
+	rows, err := r.db.Query(ctx, query, months, householdID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get trends: %w", err)
 	}
@@ -195,96 +237,26 @@ func (r *ReportRepository) GetTrendsAll(ctx context.Context, months int) ([]*mod
 	return trends, nil
 }
 
-// SearchTransactionsAll searches all transactions without user filter (admin)
-func (r *ReportRepository) SearchTransactionsAll(ctx context.Context, filters *model.SearchFilters) (*model.SearchResult, error) {
-	query := `SELECT ` + txnSelectCols + txnJoins + ` WHERE 1=1`
-
-	args := []any{}
-	argPos := 1
-
-	if filters.Description != "" {
-		query += fmt.Sprintf(" AND t.description ILIKE $%d", argPos)
-		args = append(args, "%"+filters.Description+"%")
-		argPos++
-	}
-
-	if filters.MinAmount != nil {
-		query += fmt.Sprintf(" AND ABS(t.amount) >= $%d", argPos)
-		args = append(args, *filters.MinAmount)
-		argPos++
-	}
-
-	if filters.MaxAmount != nil {
-		query += fmt.Sprintf(" AND ABS(t.amount) <= $%d", argPos)
-		args = append(args, *filters.MaxAmount)
-		argPos++
-	}
-
-	if filters.StartDate != "" {
-		query += fmt.Sprintf(" AND t.date >= $%d", argPos)
-		args = append(args, filters.StartDate)
-		argPos++
-	}
-
-	if filters.EndDate != "" {
-		query += fmt.Sprintf(" AND t.date <= $%d", argPos)
-		args = append(args, filters.EndDate)
-		argPos++
-	}
-
-	if filters.CategoryID != "" {
-		query += fmt.Sprintf(" AND t.category_id = (SELECT id FROM categories WHERE uuid = $%d)", argPos)
-		args = append(args, filters.CategoryID)
-		argPos++
-	}
-
-	if filters.AccountID != "" {
-		query += fmt.Sprintf(" AND t.account_id = (SELECT id FROM accounts WHERE uuid = $%d)", argPos)
-		args = append(args, filters.AccountID)
-		argPos++
-	}
-
-	if len(filters.Tags) > 0 {
-		query += fmt.Sprintf(" AND t.tags && $%d", argPos)
-		args = append(args, filters.Tags)
-		argPos++
-	}
-
-	query += " ORDER BY t.date DESC, t.created_at DESC LIMIT 100"
-
-	rows, err := r.db.Query(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search transactions: %w", err)
-	}
-	defer rows.Close()
-
-	var transactions []*model.Transaction
-	for rows.Next() {
-		t, err := scanTransaction(rows)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan transaction: %w", err)
-		}
-		transactions = append(transactions, t)
-	}
-
-	return &model.SearchResult{
-		Transactions: transactions,
-		TotalCount:   len(transactions),
-	}, nil
+// SearchTransactionsAll searches all transactions in a household without user filter (admin)
+func (r *ReportRepository) SearchTransactionsAll(ctx context.Context, householdID string, filters *model.SearchFilters) (*model.SearchResult, error) {
+	where, args := searchFilterClause("t.household_id = (SELECT id FROM households WHERE uuid = $1)", []any{householdID}, filters)
+	return findTransactionPage(ctx, r.db, where, args, filters.PageParams)
 }
 
 func (r *ReportRepository) GetSpendingByCategory(ctx context.Context, userID string, month, year int) ([]*model.CategorySpending, error) {
 	query := `
 		SELECT
-			c.uuid,
-			c.name AS category_name,
+			COALESCE(c.uuid::text, '') AS category_id,
+			COALESCE(c.name, 'Uncategorized') AS category_name,
 			COALESCE(SUM(ABS(t.amount)), 0) AS total_amount
-		FROM transactions t
-		JOIN categories c ON c.id = t.category_id
+		FROM ` + convertedTransactionLines + ` t
+		LEFT JOIN categories c ON c.id = t.category_id
 		WHERE t.user_id = (SELECT id FROM users WHERE uuid = $1)
 			AND t.amount < 0
 			AND EXTRACT(MONTH FROM t.date) = $2
 			AND EXTRACT(YEAR FROM t.date) = $3
+			-- Uncategorized transfers move money rather than spend it
+			AND (c.id IS NOT NULL OR t.type <> 'transfer')
 		GROUP BY c.uuid, c.name
 		ORDER BY total_amount DESC
 	`
@@ -316,22 +288,93 @@ func (r *ReportRepository) GetSpendingByCategory(ctx context.Context, userID str
 	return results, nil
 }
 
-func (r *ReportRepository) GetSpendingByMember(ctx context.Context, month, year int) ([]*model.MemberSpending, error) {
+// GetSpendingTree returns spending for every category with spending in it or
+// below it, each with its own amount and the subtotal of its whole subtree.
+// If userID is set, only that user's spending is counted. Categories under a
+// parent in the trash count as top-level.
+func (r *ReportRepository) GetSpendingTree(ctx context.Context, householdID string, userID *string, month, year int) ([]*model.CategorySpendingNode, error) {
+	query := `
+		WITH RECURSIVE live AS (
+			SELECT c.id, c.uuid, c.name, p.id AS parent_id
+			FROM categories c
+			LEFT JOIN categories p ON p.id = c.parent_id AND p.deleted_at IS NULL
+			WHERE c.household_id = (SELECT id FROM households WHERE uuid = $1) AND c.deleted_at IS NULL
+		),
+		lines AS (
+			SELECT t.category_id, t.type, ABS(t.amount) AS amount
+			FROM ` + convertedTransactionLines + ` t
+			WHERE t.household_id = (SELECT id FROM households WHERE uuid = $1)
+				AND ($2::uuid IS NULL OR t.user_id = (SELECT id FROM users WHERE uuid = $2))
+				AND t.amount < 0
+				AND EXTRACT(MONTH FROM t.date) = $3
+				AND EXTRACT(YEAR FROM t.date) = $4
+		),
+		own AS (
+			SELECT category_id, SUM(amount) AS amount
+			FROM lines WHERE category_id IS NOT NULL
+			GROUP BY category_id
+		),
+		-- Every category paired with itself and each of its ancestors
+		lineage AS (
+			SELECT id, id AS ancestor_id FROM live
+			UNION ALL
+			SELECT l.id, live.parent_id
+			FROM lineage l JOIN live ON live.id = l.ancestor_id
+			WHERE live.parent_id IS NOT NULL
+		),
+		totals AS (
+			SELECT l.ancestor_id AS id, SUM(own.amount) AS amount
+			FROM lineage l JOIN own ON own.category_id = l.id
+			GROUP BY l.ancestor_id
+		)
+		SELECT live.uuid::text, live.name, parent.uuid::text, COALESCE(own.amount, 0), totals.amount
+		FROM totals
+		JOIN live ON live.id = totals.id
+		LEFT JOIN live parent ON parent.id = live.parent_id
+		LEFT JOIN own ON own.category_id = live.id
+		UNION ALL
+		-- Uncategorized transfers move money rather than spend it
+		SELECT '', 'Uncategorized', NULL, SUM(amount), SUM(amount)
+		FROM lines WHERE category_id IS NULL AND type <> 'transfer'
+		HAVING COUNT(*) > 0
+		ORDER BY 5 DESC, 2
+	`
+
+	rows, err := r.db.Query(ctx, query, householdID, userID, month, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending tree: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []*model.CategorySpendingNode
+	for rows.Next() {
+		n := &model.CategorySpendingNode{}
+		if err := rows.Scan(&n.CategoryID, &n.CategoryName, &n.ParentID, &n.OwnAmount, &n.TotalAmount); err != nil {
+			return nil, fmt.Errorf("failed to scan category spending: %w", err)
+		}
+		nodes = append(nodes, n)
+	}
+
+	return nodes, rows.Err()
+}
+
+func (r *ReportRepository) GetSpendingByMember(ctx context.Context, householdID string, month, year int) ([]*model.MemberSpending, error) {
 	query := `
 		SELECT
 			u.uuid,
 			u.name AS user_name,
 			COALESCE(SUM(CASE WHEN t.amount < 0 THEN ABS(t.amount) ELSE 0 END), 0) AS total_expense,
 			COALESCE(SUM(CASE WHEN t.amount > 0 THEN t.amount ELSE 0 END), 0) AS total_income
-		FROM transactions t
+		FROM ` + convertedTransactions + ` t
 		JOIN users u ON u.id = t.user_id
-		WHERE EXTRACT(MONTH FROM t.date) = $1
+		WHERE t.household_id = (SELECT id FROM households WHERE uuid = $3)
+			AND EXTRACT(MONTH FROM t.date) = $1
 			AND EXTRACT(YEAR FROM t.date) = $2
 		GROUP BY u.uuid, u.name
 		ORDER BY total_expense DESC
 	`
 
-	rows, err := r.db.Query(ctx, query, month, year)
+	rows, err := r.db.Query(ctx, query, month, year, householdID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get spending by member: %w", err)
 	}
@@ -351,82 +394,64 @@ func (r *ReportRepository) GetSpendingByMember(ctx context.Context, month, year
 }
 
 func (r *ReportRepository) SearchTransactions(ctx context.Context, filters *model.SearchFilters) (*model.SearchResult, error) {
-	query := `SELECT ` + txnSelectCols + txnJoins + `
-		WHERE t.user_id = (SELECT id FROM users WHERE uuid = $1)
-	`
+	where, args := searchFilterClause("t.user_id = (SELECT id FROM users WHERE uuid = $1)", []any{filters.UserID}, filters)
+	return findTransactionPage(ctx, r.db, where, args, filters.PageParams)
+}
 
-	args := []any{filters.UserID}
-	argPos := 2
+// searchFilterClause appends the search filters to where, whose own
+// placeholders are args
+func searchFilterClause(where string, args []any, filters *model.SearchFilters) (string, []any) {
+	argPos := len(args) + 1
 
 	if filters.Description != "" {
-		query += fmt.Sprintf(" AND t.description ILIKE $%d", argPos)
+		where += fmt.Sprintf(" AND t.description ILIKE $%d", argPos)
 		args = append(args, "%"+filters.Description+"%")
 		argPos++
 	}
 
 	if filters.MinAmount != nil {
-		query += fmt.Sprintf(" AND ABS(t.amount) >= $%d", argPos)
+		where += fmt.Sprintf(" AND ABS(t.amount) >= $%d", argPos)
 		args = append(args, *filters.MinAmount)
 		argPos++
 	}
 
 	if filters.MaxAmount != nil {
-		query += fmt.Sprintf(" AND ABS(t.amount) <= $%d", argPos)
+		where += fmt.Sprintf(" AND ABS(t.amount) <= $%d", argPos)
 		args = append(args, *filters.MaxAmount)
 		argPos++
 	}
 
 	if filters.StartDate != "" {
-		query += fmt.Sprintf(" AND t.date >= $%d", argPos)
+		where += fmt.Sprintf(" AND t.date >= $%d", argPos)
 		args = append(args, filters.StartDate)
 		argPos++
 	}
 
 	if filters.EndDate != "" {
-		query += fmt.Sprintf(" AND t.date <= $%d", argPos)
+		where += fmt.Sprintf(" AND t.date <= $%d", argPos)
 		args = append(args, filters.EndDate)
 		argPos++
 	}
 
 	if filters.CategoryID != "" {
-		query += fmt.Sprintf(" AND t.category_id = (SELECT id FROM categories WHERE uuid = $%d)", argPos)
+		where += fmt.Sprintf(" AND t.category_id = (SELECT id FROM categories WHERE uuid = $%d)", argPos)
 		args = append(args, filters.CategoryID)
 		argPos++
 	}
 
 	if filters.AccountID != "" {
-		query += fmt.Sprintf(" AND t.account_id = (SELECT id FROM accounts WHERE uuid = $%d)", argPos)
+		where += fmt.Sprintf(" AND t.account_id = (SELECT id FROM accounts WHERE uuid = $%d)", argPos)
 		args = append(args, filters.AccountID)
 		argPos++
 	}
 
 	if len(filters.Tags) > 0 {
-		query += fmt.Sprintf(" AND t.tags && $%d", argPos)
+		where += fmt.Sprintf(" AND t.tags && $%d", argPos)
 		args = append(args, filters.Tags)
 		argPos++
 	}
 
-	query += " ORDER BY t.date DESC, t.created_at DESC LIMIT 100"
-
-	rows, err := r.db.Query(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search transactions: %w", err)
-	}
-	defer rows.Close()
-
-	var transactions []*model.Transaction
-	for rows.Next() {
-		t, err := scanTransaction(rows)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan transaction: %w", err)
-		}
-		transactions = append(transactions, t)
-	}
-
-	return &model.SearchResult{
-		Transactions: transactions,
-		TotalCount:   len(transactions),
-	}, nil
+	return where, args
 }
 
 func (r *ReportRepository) GetTrends(ctx context.Context, userID string, months int) ([]*model.TrendPoint, error) {
@@ -436,7 +461,7 @@ func (r *ReportRepository) GetTrends(ctx context.Context, userID string, months
 			EXTRACT(YEAR FROM date)::int AS year,
 			COALESCE(SUM(CASE WHEN amount > 0 THEN amount ELSE 0 END), 0) AS total_income,
 			COALESCE(SUM(CASE WHEN amount < 0 THEN ABS(amount) ELSE 0 END), 0) AS total_expense
-		FROM transactions
+		FROM ` + convertedTransactions + ` transactions
 		WHERE user_id = (SELECT id FROM users WHERE uuid = $1)
 			AND date >= (CURRENT_DATE - make_interval(months => $2))
 		GROUP BY EXTRACT(YEAR FROM date), EXTRACT(MONTH FROM date)
@@ -12,16 +12,38 @@ import (
 )
 
 type SavingGoalRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 func NewSavingGoalRepository(db *pgxpool.Pool) *SavingGoalRepository {
 	return &SavingGoalRepository{db: db}
 }
 
-func (r *SavingGoalRepository) Create(ctx context.Context, req *model.CreateSavingGoalRequest) (*model.SavingGoal, error) {
+// WithTx returns a copy of the repository that runs its queries inside tx
+func (r *SavingGoalRepository) WithTx(tx pgx.Tx) *SavingGoalRepository {
+	return &SavingGoalRepository{db: tx}
+}
+
+// currentAmountColumn sums the contributions of the saving goal with the
+// given internal id. Those whose transfer is in the trash are left out.
+func currentAmountColumn(idCol string) string {
+	return `(
+		SELECT COALESCE(SUM(gc.amount), 0)
+		FROM goal_contributions gc
+		LEFT JOIN transactions t ON t.id = gc.transaction_id
+		WHERE gc.saving_goal_id = ` + idCol + ` AND (t.id IS NULL OR t.deleted_at IS NULL))`
+}
+
+func scanSavingGoal(row interface{ Scan(dest ...any) error }) (*model.SavingGoal, error) {
 	goal := &model.SavingGoal{}
+	err := row.Scan(
+		&goal.ID, &goal.Name, &goal.TargetAmount, &goal.CurrentAmount, &goal.AccountID,
+		&goal.TargetDate, &goal.Priority, &goal.Status, &goal.CreatedAt, &goal.UpdatedAt,
+	)
+	return goal, err
+}
 
+func (r *SavingGoalRepository) Create(ctx context.Context, householdID string, req *model.CreateSavingGoalRequest) (*model.SavingGoal, error) {
 	var targetDate *time.Time
 	if req.TargetDate != nil {
 		parsed, err := time.Parse("2006-01-02", *req.TargetDate)
@@ -32,17 +54,22 @@ func (r *SavingGoalRepository) Create(ctx context.Context, req *model.CreateSavi
 	}
 
 	query := `
-		INSERT INTO saving_goals (name, target_amount, target_date, priority)
-		VALUES ($1, $2, $3, $4)
-		RETURNING uuid, name, target_amount, current_amount, target_date, priority, status, created_at, updated_at
+		WITH inserted AS (
+			INSERT INTO saving_goals (household_id, name, target_amount, account_id, target_date, priority)
+			SELECT h.id, $2, $3,
+				(SELECT id FROM accounts WHERE uuid = $4 AND household_id = h.id AND deleted_at IS NULL),
+				$5, $6
+			FROM households h WHERE h.uuid = $1
+			RETURNING *
+		)
+		SELECT i.uuid, i.name, i.target_amount, 0::bigint, a.uuid, i.target_date, i.priority, i.status, i.created_at, i.updated_at
+		FROM inserted i
+		LEFT JOIN accounts a ON a.id = i.account_id
 	`
 
-	err := r.db.QueryRow(ctx, query,
-		req.Name, req.TargetAmount, targetDate, req.Priority,
-	).Scan(
-		&goal.ID, &goal.Name, &goal.TargetAmount, &goal.CurrentAmount,
-		&goal.TargetDate, &goal.Priority, &goal.Status, &goal.CreatedAt, &goal.UpdatedAt,
-	)
+	goal, err := scanSavingGoal(r.db.QueryRow(ctx, query,
+		householdID, req.Name, req.TargetAmount, req.AccountID, targetDate, req.Priority,
+	))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create saving goal: %w", err)
 	}
@@ -50,18 +77,16 @@ func (r *SavingGoalRepository) Create(ctx context.Context, req *model.CreateSavi
 	return goal, nil
 }
 
-func (r *SavingGoalRepository) FindByID(ctx context.Context, id string) (*model.SavingGoal, error) {
-	goal := &model.SavingGoal{}
+func (r *SavingGoalRepository) FindByID(ctx context.Context, householdID, id string) (*model.SavingGoal, error) {
 	query := `
-		SELECT uuid, name, target_amount, current_amount, target_date, priority, status, created_at, updated_at
-		FROM saving_goals
-		WHERE uuid = $1
+		SELECT g.uuid, g.name, g.target_amount, ` + currentAmountColumn("g.id") + `, a.uuid,
+			g.target_date, g.priority, g.status, g.created_at, g.updated_at
+		FROM saving_goals g
+		LEFT JOIN accounts a ON a.id = g.account_id AND a.deleted_at IS NULL
+		WHERE g.uuid = $1 AND g.household_id = (SELECT id FROM households WHERE uuid = $2) AND g.deleted_at IS NULL
 	`
 
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&goal.ID, &goal.Name, &goal.TargetAmount, &goal.CurrentAmount,
-		&goal.TargetDate, &goal.Priority, &goal.Status, &goal.CreatedAt, &goal.UpdatedAt,
-	)
+	goal, err := scanSavingGoal(r.db.QueryRow(ctx, query, id, householdID))
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("saving goal not found")
 	}
@@ -72,14 +97,17 @@ func (r *SavingGoalRepository) FindByID(ctx context.Context, id string) (*model.
 	return goal, nil
 }
 
-func (r *SavingGoalRepository) FindAll(ctx context.Context) ([]*model.SavingGoal, error) {
+func (r *SavingGoalRepository) FindAll(ctx context.Context, householdID string) ([]*model.SavingGoal, error) {
 	query := `
-		SELECT uuid, name, target_amount, current_amount, target_date, priority, status, created_at, updated_at
-		FROM saving_goals
-		ORDER BY priority, name
+		SELECT g.uuid, g.name, g.target_amount, ` + currentAmountColumn("g.id") + `, a.uuid,
+			g.target_date, g.priority, g.status, g.created_at, g.updated_at
+		FROM saving_goals g
+		LEFT JOIN accounts a ON a.id = g.account_id AND a.deleted_at IS NULL
+		WHERE g.household_id = (SELECT id FROM households WHERE uuid = $1) AND g.deleted_at IS NULL
+		ORDER BY g.priority, g.name
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, householdID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find saving goals: %w", err)
 	}
@@ -87,11 +115,8 @@ func (r *SavingGoalRepository) FindAll(ctx context.Context) ([]*model.SavingGoal
 
 	var goals []*model.SavingGoal
 	for rows.Next() {
-		goal := &model.SavingGoal{}
-		if err := rows.Scan(
-			&goal.ID, &goal.Name, &goal.TargetAmount, &goal.CurrentAmount,
-			&goal.TargetDate, &goal.Priority, &goal.Status, &goal.CreatedAt, &goal.UpdatedAt,
-		); err != nil {
+		goal, err := scanSavingGoal(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan saving goal: %w", err)
 		}
 		goals = append(goals, goal)
@@ -100,7 +125,7 @@ func (r *SavingGoalRepository) FindAll(ctx context.Context) ([]*model.SavingGoal
 	return goals, nil
 }
 
-func (r *SavingGoalRepository) Update(ctx context.Context, id string, req *model.UpdateSavingGoalRequest) (*model.SavingGoal, error) {
+func (r *SavingGoalRepository) Update(ctx context.Context, householdID, id string, req *model.UpdateSavingGoalRequest) (*model.SavingGoal, error) {
 	updates := []string{}
 	args := []any{}
 	argPos := 1
@@ -117,6 +142,12 @@ func (r *SavingGoalRepository) Update(ctx context.Context, id string, req *model
 		argPos++
 	}
 
+	if req.AccountID != nil {
+		updates = append(updates, fmt.Sprintf("account_id = (SELECT id FROM accounts WHERE uuid = $%d AND household_id = saving_goals.household_id AND deleted_at IS NULL)", argPos))
+		args = append(args, *req.AccountID)
+		argPos++
+	}
+
 	if req.TargetDate != nil {
 		parsed, err := time.Parse("2006-01-02", *req.TargetDate)
 		if err != nil {
@@ -142,22 +173,24 @@ func (r *SavingGoalRepository) Update(ctx context.Context, id string, req *model
 	updates = append(updates, "updated_at = NOW()")
 
 	if len(updates) == 1 {
-		return r.FindByID(ctx, id)
+		return r.FindByID(ctx, householdID, id)
 	}
 
-	args = append(args, id)
+	args = append(args, id, householdID)
 	query := fmt.Sprintf(`
-		UPDATE saving_goals
-		SET %s
-		WHERE uuid = $%d
-		RETURNING uuid, name, target_amount, current_amount, target_date, priority, status, created_at, updated_at
-	`, strings.Join(updates, ", "), argPos)
+		WITH updated AS (
+			UPDATE saving_goals
+			SET %s
+			WHERE uuid = $%d AND household_id = (SELECT id FROM households WHERE uuid = $%d) AND deleted_at IS NULL
+			RETURNING *
+		)
+		SELECT up.uuid, up.name, up.target_amount, `+currentAmountColumn("up.id")+`, a.uuid,
+			up.target_date, up.priority, up.status, up.created_at, up.updated_at
+		FROM updated up
+		LEFT JOIN accounts a ON a.id = up.account_id AND a.deleted_at IS NULL
+	`, strings.Join(updates, ", "), argPos, argPos+1)
 
-	goal := &model.SavingGoal{}
-	err := r.db.QueryRow(ctx, query, args...).Scan(
-		&goal.ID, &goal.Name, &goal.TargetAmount, &goal.CurrentAmount,
-		&goal.TargetDate, &goal.Priority, &goal.Status, &goal.CreatedAt, &goal.UpdatedAt,
-	)
+	goal, err := scanSavingGoal(r.db.QueryRow(ctx, query, args...))
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("saving goal not found")
 	}
@@ -168,25 +201,101 @@ func (r *SavingGoalRepository) Update(ctx context.Context, id string, req *model
 	return goal, nil
 }
 
-func (r *SavingGoalRepository) Contribute(ctx context.Context, id string, amount int64) (*model.SavingGoal, error) {
-	goal := &model.SavingGoal{}
+// CreateContribution records money put towards the goal, or taken out of it
+// for a negative amount. transactionID is the transfer that moved it, if any.
+func (r *SavingGoalRepository) CreateContribution(ctx context.Context, householdID, id, userID string, transactionID *string, amount int64, date time.Time) (*model.GoalContribution, error) {
 	query := `
-		UPDATE saving_goals
-		SET current_amount = current_amount + $1, updated_at = NOW()
-		WHERE uuid = $2
-		RETURNING uuid, name, target_amount, current_amount, target_date, priority, status, created_at, updated_at
+		WITH inserted AS (
+			INSERT INTO goal_contributions (saving_goal_id, user_id, transaction_id, amount, date)
+			SELECT g.id, (SELECT id FROM users WHERE uuid = $3), (SELECT id FROM transactions WHERE uuid = $4), $5, $6
+			FROM saving_goals g
+			WHERE g.uuid = $1 AND g.household_id = (SELECT id FROM households WHERE uuid = $2) AND g.deleted_at IS NULL
+			RETURNING *
+		)
+		SELECT i.uuid, u.uuid, t.uuid, i.amount, i.date, i.created_at
+		FROM inserted i
+		LEFT JOIN users u ON u.id = i.user_id
+		LEFT JOIN transactions t ON t.id = i.transaction_id
 	`
 
-	err := r.db.QueryRow(ctx, query, amount, id).Scan(
-		&goal.ID, &goal.Name, &goal.TargetAmount, &goal.CurrentAmount,
-		&goal.TargetDate, &goal.Priority, &goal.Status, &goal.CreatedAt, &goal.UpdatedAt,
+	c := &model.GoalContribution{}
+	err := r.db.QueryRow(ctx, query, id, householdID, userID, transactionID, amount, date).Scan(
+		&c.ID, &c.UserID, &c.TransactionID, &c.Amount, &c.Date, &c.CreatedAt,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("saving goal not found")
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to contribute to saving goal: %w", err)
+		return nil, fmt.Errorf("failed to record goal contribution: %w", err)
 	}
 
-	return goal, nil
+	return c, nil
+}
+
+// FindContributions returns the goal's contributions and withdrawals, newest
+// first. Those whose transfer is in the trash are left out.
+func (r *SavingGoalRepository) FindContributions(ctx context.Context, householdID, id string) ([]*model.GoalContribution, error) {
+	query := `
+		SELECT gc.uuid, u.uuid, t.uuid, gc.amount, gc.date, gc.created_at
+		FROM goal_contributions gc
+		JOIN saving_goals g ON g.id = gc.saving_goal_id
+		LEFT JOIN users u ON u.id = gc.user_id
+		LEFT JOIN transactions t ON t.id = gc.transaction_id
+		WHERE g.uuid = $1 AND g.household_id = (SELECT id FROM households WHERE uuid = $2) AND g.deleted_at IS NULL
+			AND (t.id IS NULL OR t.deleted_at IS NULL)
+		ORDER BY gc.date DESC, gc.id DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, id, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find goal contributions: %w", err)
+	}
+	defer rows.Close()
+
+	contributions := []*model.GoalContribution{}
+	for rows.Next() {
+		c := &model.GoalContribution{}
+		if err := rows.Scan(&c.ID, &c.UserID, &c.TransactionID, &c.Amount, &c.Date, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan goal contribution: %w", err)
+		}
+		contributions = append(contributions, c)
+	}
+
+	return contributions, rows.Err()
+}
+
+// Delete moves a saving goal to the trash
+func (r *SavingGoalRepository) Delete(ctx context.Context, householdID, id string) error {
+	query := `
+		UPDATE saving_goals SET deleted_at = NOW()
+		WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2) AND deleted_at IS NULL
+	`
+	result, err := r.db.Exec(ctx, query, id, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to delete saving goal: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("saving goal not found")
+	}
+
+	return nil
+}
+
+// Restore takes a saving goal back out of the trash
+func (r *SavingGoalRepository) Restore(ctx context.Context, householdID, id string) error {
+	query := `
+		UPDATE saving_goals SET deleted_at = NULL, updated_at = NOW()
+		WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2) AND deleted_at IS NOT NULL
+	`
+	result, err := r.db.Exec(ctx, query, id, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to restore saving goal: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("saving goal not found")
+	}
+
+	return nil
 }
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SessionRepository struct {
+	db DBTX
+}
+
+func NewSessionRepository(db *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries inside tx
+func (r *SessionRepository) WithTx(tx pgx.Tx) *SessionRepository {
+	return &SessionRepository{db: tx}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, userID, tokenHash string, expiresAt time.Time) (*model.Session, error) {
+	session := &model.Session{}
+	query := `
+		WITH s AS (
+			INSERT INTO sessions (user_id, token_hash, expires_at)
+			SELECT id, $2, $3 FROM users WHERE uuid = $1
+			RETURNING *
+		)
+		SELECT s.uuid, u.uuid, s.expires_at, s.revoked_at, s.created_at
+		FROM s JOIN users u ON u.id = s.user_id
+	`
+
+	err := r.db.QueryRow(ctx, query, userID, tokenHash, expiresAt).
+		Scan(&session.ID, &session.UserID, &session.ExpiresAt, &session.RevokedAt, &session.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return session, nil
+}
+
+// FindByTokenHash returns the session of a refresh token, revoked or not
+func (r *SessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*model.Session, error) {
+	session := &model.Session{}
+	query := `
+		SELECT s.uuid, u.uuid, s.expires_at, s.revoked_at, s.created_at
+		FROM sessions s JOIN users u ON u.id = s.user_id
+		WHERE s.token_hash = $1
+	`
+
+	err := r.db.QueryRow(ctx, query, tokenHash).
+		Scan(&session.ID, &session.UserID, &session.ExpiresAt, &session.RevokedAt, &session.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("session not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find session: %w", err)
+	}
+
+	return session, nil
+}
+
+// Revoke ends a session. It reports false if the session was already
+// revoked, e.g. by a concurrent refresh with the same token.
+func (r *SessionRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE uuid = $1 AND revoked_at IS NULL`
+	result, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// RevokeAllForUser ends every open session of a user and returns how many
+// were ended
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID string) (int64, error) {
+	query := `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE user_id = (SELECT id FROM users WHERE uuid = $1) AND revoked_at IS NULL
+	`
+	result, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
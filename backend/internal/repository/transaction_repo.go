@@ -12,29 +12,44 @@ import (
 )
 
 type TransactionRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 func NewTransactionRepository(db *pgxpool.Pool) *TransactionRepository {
 	return &TransactionRepository{db: db}
 }
 
-const txnSelectCols = `t.uuid, u.uuid, acc.uuid, cat.uuid, t.amount, t.type, t.description, t.date, t.is_shared, t.is_recurring, t.recurring_rule, t.tags, xfer.uuid, t.created_at, t.updated_at`
+// WithTx returns a copy of the repository that runs its queries inside tx
+func (r *TransactionRepository) WithTx(tx pgx.Tx) *TransactionRepository {
+	return &TransactionRepository{db: tx}
+}
+
+var txnSelectCols = `t.uuid, u.uuid, acc.uuid, cat.uuid, t.amount, t.type, t.description, t.counterparty, t.date, t.is_shared, t.is_recurring, t.recurring_rule, t.tags, xfer.uuid, t.external_id, t.status, t.created_at, t.updated_at, ` + splitsColumn("t.id")
+
+// splitsColumn selects the splits of the transaction with the given internal
+// id as a JSON array, or NULL if it is not split
+func splitsColumn(idCol string) string {
+	return `(
+		SELECT json_agg(json_build_object('id', s.uuid, 'categoryId', sc.uuid, 'amount', s.amount, 'memo', s.memo, 'tags', s.tags) ORDER BY s.id)
+		FROM transaction_splits s
+		JOIN categories sc ON sc.id = s.category_id
+		WHERE s.transaction_id = ` + idCol + `)`
+}
 
 const txnJoins = `
 	FROM transactions t
 	JOIN users u ON u.id = t.user_id
 	JOIN accounts acc ON acc.id = t.account_id
-	JOIN categories cat ON cat.id = t.category_id
+	LEFT JOIN categories cat ON cat.id = t.category_id
 	LEFT JOIN accounts xfer ON xfer.id = t.transfer_to_account_id`
 
 func scanTransaction(row interface{ Scan(dest ...any) error }) (*model.Transaction, error) {
 	t := &model.Transaction{}
 	err := row.Scan(
 		&t.ID, &t.UserID, &t.AccountID, &t.CategoryID,
-		&t.Amount, &t.Type, &t.Description, &t.Date,
+		&t.Amount, &t.Type, &t.Description, &t.Counterparty, &t.Date,
 		&t.IsShared, &t.IsRecurring, &t.RecurringRule,
-		&t.Tags, &t.TransferToAccountID, &t.CreatedAt, &t.UpdatedAt,
+		&t.Tags, &t.TransferToAccountID, &t.ExternalID, &t.Status, &t.CreatedAt, &t.UpdatedAt, &t.Splits,
 	)
 	return t, err
 }
@@ -47,27 +62,30 @@ func (r *TransactionRepository) Create(ctx context.Context, userID string, req *
 
 	query := `
 		WITH inserted AS (
-			INSERT INTO transactions (user_id, account_id, category_id, amount, type, description, date, is_shared, is_recurring, recurring_rule, tags, transfer_to_account_id)
-			VALUES (
-				(SELECT id FROM users WHERE uuid = $1),
-				(SELECT id FROM accounts WHERE uuid = $2),
-				(SELECT id FROM categories WHERE uuid = $3),
-				$4, $5, $6, $7, $8, $9, $10, $11,
-				(SELECT id FROM accounts WHERE uuid = $12)
-			)
+			INSERT INTO transactions (household_id, user_id, account_id, category_id, amount, type, description, counterparty, date, is_shared, is_recurring, recurring_rule, tags, transfer_to_account_id, external_id, status)
+			SELECT
+				u.household_id,
+				u.id,
+				(SELECT id FROM accounts WHERE uuid = $2 AND household_id = u.household_id AND deleted_at IS NULL),
+				(SELECT id FROM categories WHERE uuid = NULLIF($3, '')::uuid AND household_id = u.household_id AND deleted_at IS NULL),
+				$4, $5, $6, $7, $8, $9, $10, $11, $12,
+				(SELECT id FROM accounts WHERE uuid = $13 AND household_id = u.household_id AND deleted_at IS NULL),
+				$14, COALESCE(NULLIF($15, ''), 'uncleared')
+			FROM users u WHERE u.uuid = $1
 			RETURNING *
 		)
-		SELECT i.uuid, u.uuid, acc.uuid, cat.uuid, i.amount, i.type, i.description, i.date, i.is_shared, i.is_recurring, i.recurring_rule, i.tags, xfer.uuid, i.created_at, i.updated_at
+		SELECT i.uuid, u.uuid, acc.uuid, cat.uuid, i.amount, i.type, i.description, i.counterparty, i.date, i.is_shared, i.is_recurring, i.recurring_rule, i.tags, xfer.uuid, i.external_id, i.status, i.created_at, i.updated_at,
+			NULL::json -- splits are added once the transaction exists
 		FROM inserted i
 		JOIN users u ON u.id = i.user_id
 		JOIN accounts acc ON acc.id = i.account_id
-		JOIN categories cat ON cat.id = i.category_id
+		LEFT JOIN categories cat ON cat.id = i.category_id
 		LEFT JOIN accounts xfer ON xfer.id = i.transfer_to_account_id
 	`
 
 	t, err := scanTransaction(r.db.QueryRow(ctx, query,
-		userID, req.AccountID, req.CategoryID, req.Amount, req.Type, req.Description,
-		date, req.IsShared, req.IsRecurring, req.RecurringRule, req.Tags, req.TransferToAccountID,
+		userID, req.AccountID, req.CategoryID, req.Amount, req.Type, req.Description, req.Counterparty,
+		date, req.IsShared, req.IsRecurring, req.RecurringRule, req.Tags, req.TransferToAccountID, req.ExternalID, req.Status,
 	))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create transaction: %w", err)
@@ -76,10 +94,10 @@ func (r *TransactionRepository) Create(ctx context.Context, userID string, req *
 	return t, nil
 }
 
-func (r *TransactionRepository) FindByID(ctx context.Context, id string) (*model.Transaction, error) {
-	query := `SELECT ` + txnSelectCols + txnJoins + ` WHERE t.uuid = $1`
+func (r *TransactionRepository) FindByID(ctx context.Context, householdID, id string) (*model.Transaction, error) {
+	query := `SELECT ` + txnSelectCols + txnJoins + ` WHERE t.uuid = $1 AND t.household_id = (SELECT id FROM households WHERE uuid = $2) AND t.deleted_at IS NULL`
 
-	t, err := scanTransaction(r.db.QueryRow(ctx, query, id))
+	t, err := scanTransaction(r.db.QueryRow(ctx, query, id, householdID))
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("transaction not found")
 	}
@@ -90,147 +108,90 @@ func (r *TransactionRepository) FindByID(ctx context.Context, id string) (*model
 	return t, nil
 }
 
-func (r *TransactionRepository) FindByUserID(ctx context.Context, userID string, filters *model.TransactionFilters) ([]*model.Transaction, error) {
-	query := `SELECT ` + txnSelectCols + txnJoins + `
-		WHERE t.user_id = (SELECT id FROM users WHERE uuid = $1)
-	`
-
-	args := []any{userID}
-	argPos := 2
-
-	if filters.AccountID != "" {
-		query += fmt.Sprintf(" AND t.account_id = (SELECT id FROM accounts WHERE uuid = $%d)", argPos)
-		args = append(args, filters.AccountID)
-		argPos++
-	}
-
-	if filters.CategoryID != "" {
-		query += fmt.Sprintf(" AND t.category_id = (SELECT id FROM categories WHERE uuid = $%d)", argPos)
-		args = append(args, filters.CategoryID)
-		argPos++
-	}
-
-	if filters.Type != "" {
-		query += fmt.Sprintf(" AND t.type = $%d", argPos)
-		args = append(args, filters.Type)
-		argPos++
-	}
-
-	if filters.StartDate != "" {
-		query += fmt.Sprintf(" AND t.date >= $%d", argPos)
-		args = append(args, filters.StartDate)
-		argPos++
-	}
-
-	if filters.EndDate != "" {
-		query += fmt.Sprintf(" AND t.date <= $%d", argPos)
-		args = append(args, filters.EndDate)
-		argPos++
-	}
+// FindDeletedByID finds a transaction in the trash
+func (r *TransactionRepository) FindDeletedByID(ctx context.Context, householdID, id string) (*model.Transaction, error) {
+	query := `SELECT ` + txnSelectCols + txnJoins + ` WHERE t.uuid = $1 AND t.household_id = (SELECT id FROM households WHERE uuid = $2) AND t.deleted_at IS NOT NULL`
 
-	if filters.IsShared != nil {
-		query += fmt.Sprintf(" AND t.is_shared = $%d", argPos)
-		args = append(args, *filters.IsShared)
-		argPos++
+	t, err := scanTransaction(r.db.QueryRow(ctx, query, id, householdID))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("transaction not found")
 	}
-
-	query += " ORDER BY t.date DESC, t.created_at DESC"
-
-	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find transactions: %w", err)
+		return nil, fmt.Errorf("failed to find transaction: %w", err)
 	}
-	defer rows.Close()
 
-	var transactions []*model.Transaction
-	for rows.Next() {
-		t, err := scanTransaction(rows)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan transaction: %w", err)
-		}
-		transactions = append(transactions, t)
-	}
+	return t, nil
+}
 
-	return transactions, nil
+func (r *TransactionRepository) FindByUserID(ctx context.Context, userID string, filters *model.TransactionFilters) (*model.TransactionPage, error) {
+	where, args := transactionFilterClause("t.user_id = (SELECT id FROM users WHERE uuid = $1)", []any{userID}, filters)
+	return findTransactionPage(ctx, r.db, where, args, filters.PageParams)
 }
 
-// FindAll returns all transactions with optional filters (for admin)
-func (r *TransactionRepository) FindAll(ctx context.Context, filters *model.TransactionFilters) ([]*model.Transaction, error) {
-	query := `SELECT ` + txnSelectCols + txnJoins + ` WHERE 1=1`
+// FindAll returns all transactions in a household with optional filters (for admin)
+func (r *TransactionRepository) FindAll(ctx context.Context, householdID string, filters *model.TransactionFilters) (*model.TransactionPage, error) {
+	where, args := transactionFilterClause("t.household_id = (SELECT id FROM households WHERE uuid = $1)", []any{householdID}, filters)
+	return findTransactionPage(ctx, r.db, where, args, filters.PageParams)
+}
 
-	args := []any{}
-	argPos := 1
+// transactionFilterClause appends the list filters to where, whose own
+// placeholders are args
+func transactionFilterClause(where string, args []any, filters *model.TransactionFilters) (string, []any) {
+	argPos := len(args) + 1
 
 	if filters.AccountID != "" {
-		query += fmt.Sprintf(" AND t.account_id = (SELECT id FROM accounts WHERE uuid = $%d)", argPos)
+		where += fmt.Sprintf(" AND t.account_id = (SELECT id FROM accounts WHERE uuid = $%d)", argPos)
 		args = append(args, filters.AccountID)
 		argPos++
 	}
 
 	if filters.CategoryID != "" {
-		query += fmt.Sprintf(" AND t.category_id = (SELECT id FROM categories WHERE uuid = $%d)", argPos)
+		where += fmt.Sprintf(" AND t.category_id = (SELECT id FROM categories WHERE uuid = $%d)", argPos)
 		args = append(args, filters.CategoryID)
 		argPos++
 	}
 
 	if filters.Type != "" {
-		query += fmt.Sprintf(" AND t.type = $%d", argPos)
+		where += fmt.Sprintf(" AND t.type = $%d", argPos)
 		args = append(args, filters.Type)
 		argPos++
 	}
 
 	if filters.StartDate != "" {
-		query += fmt.Sprintf(" AND t.date >= $%d", argPos)
+		where += fmt.Sprintf(" AND t.date >= $%d", argPos)
 		args = append(args, filters.StartDate)
 		argPos++
 	}
 
 	if filters.EndDate != "" {
-		query += fmt.Sprintf(" AND t.date <= $%d", argPos)
+		where += fmt.Sprintf(" AND t.date <= $%d", argPos)
 		args = append(args, filters.EndDate)
 		argPos++
 	}
 
 	if filters.IsShared != nil {
-		query += fmt.Sprintf(" AND t.is_shared = $%d", argPos)
+		where += fmt.Sprintf(" AND t.is_shared = $%d", argPos)
 		args = append(args, *filters.IsShared)
 		argPos++
 	}
 
-	query += " ORDER BY t.date DESC, t.created_at DESC"
-
-	rows, err := r.db.Query(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find transactions: %w", err)
-	}
-	defer rows.Close()
-
-	var transactions []*model.Transaction
-	for rows.Next() {
-		t, err := scanTransaction(rows)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan transaction: %w", err)
-		}
-		transactions = append(transactions, t)
-	}
-
-	return transactions, nil
+	return where, args
 }
 
-func (r *TransactionRepository) Update(ctx context.Context, id string, req *model.UpdateTransactionRequest) (*model.Transaction, error) {
+func (r *TransactionRepository) Update(ctx context.Context, householdID, id string, req *model.UpdateTransactionRequest) (*model.Transaction, error) {
 	// Build dynamic update query
 	updates := []string{}
 	args := []any{}
 	argPos := 1
 
 	if req.AccountID != nil {
-		updates = append(updates, fmt.Sprintf("account_id = (SELECT id FROM accounts WHERE uuid = $%d)", argPos))
+		updates = append(updates, fmt.Sprintf("account_id = (SELECT id FROM accounts WHERE uuid = $%d AND household_id = transactions.household_id AND deleted_at IS NULL)", argPos))
 		args = append(args, *req.AccountID)
 		argPos++
 	}
 
 	if req.CategoryID != nil {
-		updates = append(updates, fmt.Sprintf("category_id = (SELECT id FROM categories WHERE uuid = $%d)", argPos))
+		updates = append(updates, fmt.Sprintf("category_id = (SELECT id FROM categories WHERE uuid = $%d AND household_id = transactions.household_id AND deleted_at IS NULL)", argPos))
 		args = append(args, *req.CategoryID)
 		argPos++
 	}
@@ -247,6 +208,12 @@ func (r *TransactionRepository) Update(ctx context.Context, id string, req *mode
 		argPos++
 	}
 
+	if req.Counterparty != nil {
+		updates = append(updates, fmt.Sprintf("counterparty = $%d", argPos))
+		args = append(args, *req.Counterparty)
+		argPos++
+	}
+
 	if req.Date != nil {
 		date, err := time.Parse("2006-01-02", *req.Date)
 		if err != nil {
@@ -269,27 +236,34 @@ func (r *TransactionRepository) Update(ctx context.Context, id string, req *mode
 		argPos++
 	}
 
+	if req.Status != nil {
+		updates = append(updates, fmt.Sprintf("status = $%d", argPos))
+		args = append(args, *req.Status)
+		argPos++
+	}
+
 	updates = append(updates, "updated_at = NOW()")
 
 	if len(updates) == 0 {
-		return r.FindByID(ctx, id)
+		return r.FindByID(ctx, householdID, id)
 	}
 
-	args = append(args, id)
+	args = append(args, id, householdID)
 	query := fmt.Sprintf(`
 		WITH updated AS (
 			UPDATE transactions
 			SET %s
-			WHERE uuid = $%d
+			WHERE uuid = $%d AND household_id = (SELECT id FROM households WHERE uuid = $%d) AND deleted_at IS NULL
 			RETURNING *
 		)
-		SELECT up.uuid, u.uuid, acc.uuid, cat.uuid, up.amount, up.type, up.description, up.date, up.is_shared, up.is_recurring, up.recurring_rule, up.tags, xfer.uuid, up.created_at, up.updated_at
+		SELECT up.uuid, u.uuid, acc.uuid, cat.uuid, up.amount, up.type, up.description, up.counterparty, up.date, up.is_shared, up.is_recurring, up.recurring_rule, up.tags, xfer.uuid, up.external_id, up.status, up.created_at, up.updated_at,
+			`+splitsColumn("up.id")+`
 		FROM updated up
 		JOIN users u ON u.id = up.user_id
 		JOIN accounts acc ON acc.id = up.account_id
-		JOIN categories cat ON cat.id = up.category_id
+		LEFT JOIN categories cat ON cat.id = up.category_id
 		LEFT JOIN accounts xfer ON xfer.id = up.transfer_to_account_id
-	`, strings.Join(updates, ", "), argPos)
+	`, strings.Join(updates, ", "), argPos, argPos+1)
 
 	t, err := scanTransaction(r.db.QueryRow(ctx, query, args...))
 	if err == pgx.ErrNoRows {
@@ -305,6 +279,7 @@ func (r *TransactionRepository) Update(ctx context.Context, id string, req *mode
 func (r *TransactionRepository) FindRecurring(ctx context.Context, userID string) ([]*model.Transaction, error) {
 	query := `SELECT ` + txnSelectCols + txnJoins + `
 		WHERE t.user_id = (SELECT id FROM users WHERE uuid = $1) AND t.is_recurring = true AND t.recurring_rule IS NOT NULL
+			AND t.deleted_at IS NULL
 		ORDER BY t.date DESC
 	`
 
@@ -326,12 +301,43 @@ func (r *TransactionRepository) FindRecurring(ctx context.Context, userID string
 	return transactions, nil
 }
 
-func (r *TransactionRepository) FindLatestByTemplate(ctx context.Context, userID, accountID, categoryID, description string) (*model.Transaction, error) {
+// FindRecurringUserIDs returns every user, across all households, that owns at
+// least one recurring template. Used by the background scheduler.
+func (r *TransactionRepository) FindRecurringUserIDs(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT DISTINCT u.uuid
+		FROM transactions t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.is_recurring = true AND t.recurring_rule IS NOT NULL AND t.deleted_at IS NULL
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recurring users: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan recurring user: %w", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	return userIDs, nil
+}
+
+// FindLatestByTemplate finds the latest copy generated from a recurring
+// template. An uncategorized template matches copies in any category, since
+// categorization rules may have filled theirs in.
+func (r *TransactionRepository) FindLatestByTemplate(ctx context.Context, userID, accountID string, categoryID *string, description string) (*model.Transaction, error) {
 	query := `SELECT ` + txnSelectCols + txnJoins + `
 		WHERE t.user_id = (SELECT id FROM users WHERE uuid = $1)
 			AND t.account_id = (SELECT id FROM accounts WHERE uuid = $2)
-			AND t.category_id = (SELECT id FROM categories WHERE uuid = $3)
-			AND t.description = $4 AND t.is_recurring = false
+			AND ($3::uuid IS NULL OR t.category_id = (SELECT id FROM categories WHERE uuid = $3))
+			AND t.description = $4 AND t.is_recurring = false AND t.deleted_at IS NULL
 		ORDER BY t.date DESC
 		LIMIT 1
 	`
@@ -347,9 +353,239 @@ func (r *TransactionRepository) FindLatestByTemplate(ctx context.Context, userID
 	return t, nil
 }
 
-func (r *TransactionRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM transactions WHERE uuid = $1`
-	result, err := r.db.Exec(ctx, query, id)
+// ReplaceSplits removes the splits of a transaction and stores the given ones
+// in their place. The categories must belong to the transaction's household.
+func (r *TransactionRepository) ReplaceSplits(ctx context.Context, transactionID string, splits []model.TransactionSplitRequest) ([]*model.TransactionSplit, error) {
+	_, err := r.db.Exec(ctx, `DELETE FROM transaction_splits WHERE transaction_id = (SELECT id FROM transactions WHERE uuid = $1)`, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete transaction splits: %w", err)
+	}
+
+	query := `
+		INSERT INTO transaction_splits (transaction_id, category_id, amount, memo, tags)
+		SELECT t.id, c.id, $3, $4, $5
+		FROM transactions t
+		JOIN categories c ON c.household_id = t.household_id AND c.uuid = $2 AND c.deleted_at IS NULL
+		WHERE t.uuid = $1
+		RETURNING uuid
+	`
+
+	var created []*model.TransactionSplit
+	for _, s := range splits {
+		split := &model.TransactionSplit{CategoryID: s.CategoryID, Amount: s.Amount, Memo: s.Memo, Tags: s.Tags}
+		err := r.db.QueryRow(ctx, query, transactionID, s.CategoryID, s.Amount, s.Memo, s.Tags).Scan(&split.ID)
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("split category not found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transaction split: %w", err)
+		}
+		created = append(created, split)
+	}
+
+	return created, nil
+}
+
+// FindPendingApproval returns the household's transactions waiting for
+// approval, oldest first
+func (r *TransactionRepository) FindPendingApproval(ctx context.Context, householdID string) ([]*model.Transaction, error) {
+	query := `SELECT ` + txnSelectCols + txnJoins + `
+		WHERE t.household_id = (SELECT id FROM households WHERE uuid = $1)
+		AND t.status = 'pending_approval' AND t.deleted_at IS NULL
+		ORDER BY t.date, t.created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []*model.Transaction{}
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+
+	return transactions, rows.Err()
+}
+
+// Review moves a transaction waiting for approval to the given status. It
+// reports false if the transaction was not waiting for approval.
+func (r *TransactionRepository) Review(ctx context.Context, householdID, id, status string) (bool, error) {
+	query := `
+		UPDATE transactions SET status = $3, updated_at = NOW()
+		WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2) AND status = 'pending_approval' AND deleted_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, id, householdID, status)
+	if err != nil {
+		return false, fmt.Errorf("failed to review transaction: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// onAccount matches the transactions that move money on the account whose
+// uuid is in the given placeholder: its own and transfers into it
+func onAccount(placeholder string) string {
+	return `(t.account_id = (SELECT id FROM accounts WHERE uuid = ` + placeholder + `)
+		OR t.transfer_to_account_id = (SELECT id FROM accounts WHERE uuid = ` + placeholder + `))`
+}
+
+// FindForReconciliation returns the account's transactions up to upTo that
+// are not reconciled yet. Purchases waiting for approval or rejected are not
+// on the account and are left out.
+func (r *TransactionRepository) FindForReconciliation(ctx context.Context, accountID string, upTo time.Time) ([]*model.Transaction, error) {
+	query := `SELECT ` + txnSelectCols + txnJoins + `
+		WHERE ` + onAccount("$1") + `
+		AND t.status IN ('uncleared', 'cleared') AND t.date <= $2 AND t.deleted_at IS NULL
+		ORDER BY t.date, t.created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, accountID, upTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []*model.Transaction{}
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+
+	return transactions, rows.Err()
+}
+
+// SetStatus marks the given transactions on the account cleared or
+// uncleared. Reconciled transactions and those not approved are left alone;
+// the number of transactions changed is returned.
+func (r *TransactionRepository) SetStatus(ctx context.Context, accountID string, ids []string, status string) (int64, error) {
+	query := `
+		UPDATE transactions t SET status = $1, updated_at = NOW()
+		WHERE t.uuid = ANY($2::uuid[]) AND t.status IN ('uncleared', 'cleared') AND t.deleted_at IS NULL
+		AND ` + onAccount("$3")
+
+	result, err := r.db.Exec(ctx, query, status, ids, accountID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update transaction status: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// MarkReconciled locks every cleared transaction on the account up to the
+// statement date as part of the given reconciliation
+func (r *TransactionRepository) MarkReconciled(ctx context.Context, accountID, reconciliationID string, statementDate time.Time) error {
+	query := `
+		UPDATE transactions t
+		SET status = 'reconciled', reconciliation_id = (SELECT id FROM reconciliations WHERE uuid = $2), updated_at = NOW()
+		WHERE t.status = 'cleared' AND t.date <= $3 AND t.deleted_at IS NULL AND ` + onAccount("$1")
+
+	if _, err := r.db.Exec(ctx, query, accountID, reconciliationID, statementDate); err != nil {
+		return fmt.Errorf("failed to mark transactions reconciled: %w", err)
+	}
+
+	return nil
+}
+
+// Unlock turns a reconciled transaction back into a cleared one so it can be
+// edited again
+func (r *TransactionRepository) Unlock(ctx context.Context, householdID, id string) error {
+	query := `
+		UPDATE transactions SET status = 'cleared', reconciliation_id = NULL, updated_at = NOW()
+		WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2) AND status = 'reconciled' AND deleted_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, id, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to unlock transaction: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("transaction is not reconciled")
+	}
+
+	return nil
+}
+
+// FindUncategorized returns the household's transactions that have no
+// category yet, leaving out transfers which never need one, split
+// transactions whose splits carry the categories, and transactions that are
+// reconciled or awaiting or turned down for approval, which cannot be edited
+func (r *TransactionRepository) FindUncategorized(ctx context.Context, householdID string) ([]*model.Transaction, error) {
+	query := `SELECT ` + txnSelectCols + txnJoins + `
+		WHERE t.household_id = (SELECT id FROM households WHERE uuid = $1)
+		AND t.category_id IS NULL AND t.type <> 'transfer' AND t.deleted_at IS NULL
+		AND t.status IN ('uncleared', 'cleared')
+		AND NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id)
+		ORDER BY t.date, t.created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find uncategorized transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []*model.Transaction
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+
+	return transactions, rows.Err()
+}
+
+// FindExternalIDs returns which of externalIDs are already recorded on the
+// account, so a re-imported statement can skip lines it has seen before.
+// Transactions in the trash count too: a line deleted on purpose is not
+// brought back by importing the statement again.
+func (r *TransactionRepository) FindExternalIDs(ctx context.Context, householdID, accountID string, externalIDs []string) (map[string]bool, error) {
+	query := `
+		SELECT t.external_id
+		FROM transactions t
+		JOIN accounts acc ON acc.id = t.account_id
+		WHERE acc.uuid = $1
+		AND t.household_id = (SELECT id FROM households WHERE uuid = $2)
+		AND t.external_id = ANY($3)
+	`
+
+	rows, err := r.db.Query(ctx, query, accountID, householdID, externalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find external ids: %w", err)
+	}
+	defer rows.Close()
+
+	existing := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan external id: %w", err)
+		}
+		existing[id] = true
+	}
+
+	return existing, rows.Err()
+}
+
+// Delete moves a transaction to the trash
+func (r *TransactionRepository) Delete(ctx context.Context, householdID, id string) error {
+	query := `
+		UPDATE transactions SET deleted_at = NOW()
+		WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2) AND deleted_at IS NULL
+	`
+	result, err := r.db.Exec(ctx, query, id, householdID)
 	if err != nil {
 		return fmt.Errorf("failed to delete transaction: %w", err)
 	}
@@ -360,3 +596,22 @@ func (r *TransactionRepository) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// Restore takes a transaction back out of the trash. Balances are left to the
+// caller.
+func (r *TransactionRepository) Restore(ctx context.Context, householdID, id string) error {
+	query := `
+		UPDATE transactions SET deleted_at = NULL, updated_at = NOW()
+		WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2) AND deleted_at IS NOT NULL
+	`
+	result, err := r.db.Exec(ctx, query, id, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to restore transaction: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("transaction not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TrashRepository struct {
+	db DBTX
+}
+
+func NewTrashRepository(db *pgxpool.Pool) *TrashRepository {
+	return &TrashRepository{db: db}
+}
+
+// WithTx returns a copy of the repository that runs its queries inside tx
+func (r *TrashRepository) WithTx(tx pgx.Tx) *TrashRepository {
+	return &TrashRepository{db: tx}
+}
+
+// trashTables maps each kind of trash item to its table
+var trashTables = map[string]string{
+	model.TrashTransaction: "transactions",
+	model.TrashAccount:     "accounts",
+	model.TrashCategory:    "categories",
+	model.TrashBudget:      "budgets",
+	model.TrashSavingGoal:  "saving_goals",
+}
+
+// trashItems selects every deleted row as a trash item. Transactions deleted
+// along with their account are left out: they come back with the account.
+const trashItems = `(
+		SELECT 'transaction' AS type, t.uuid AS id, COALESCE(t.description, '') AS name, t.amount,
+			u.uuid AS user_id, t.deleted_at, t.household_id
+		FROM transactions t
+		JOIN users u ON u.id = t.user_id
+		JOIN accounts acc ON acc.id = t.account_id
+		WHERE t.deleted_at IS NOT NULL AND acc.deleted_at IS NULL
+		UNION ALL
+		SELECT 'account', a.uuid, a.name, a.balance, u.uuid, a.deleted_at, a.household_id
+		FROM accounts a JOIN users u ON u.id = a.user_id
+		WHERE a.deleted_at IS NOT NULL
+		UNION ALL
+		SELECT 'category', c.uuid, c.name, NULL, NULL, c.deleted_at, c.household_id
+		FROM categories c
+		WHERE c.deleted_at IS NOT NULL
+		UNION ALL
+		SELECT 'budget', b.uuid, c.name || ' ' || b.year || '-' || LPAD(b.month::text, 2, '0'), b.amount, NULL, b.deleted_at, b.household_id
+		FROM budgets b JOIN categories c ON c.id = b.category_id
+		WHERE b.deleted_at IS NOT NULL
+		UNION ALL
+		SELECT 'saving_goal', g.uuid, g.name, g.target_amount, NULL, g.deleted_at, g.household_id
+		FROM saving_goals g
+		WHERE g.deleted_at IS NOT NULL
+	)`
+
+func scanTrashItem(row interface{ Scan(dest ...any) error }) (*model.TrashItem, error) {
+	item := &model.TrashItem{}
+	err := row.Scan(&item.Type, &item.ID, &item.Name, &item.Amount, &item.UserID, &item.DeletedAt)
+	return item, err
+}
+
+// FindAll returns the household's trash, most recently deleted first. If
+// userID is set, only that user's transactions and accounts are returned.
+func (r *TrashRepository) FindAll(ctx context.Context, householdID string, userID *string) ([]*model.TrashItem, error) {
+	query := `
+		SELECT trash.type, trash.id, trash.name, trash.amount, trash.user_id, trash.deleted_at
+		FROM ` + trashItems + ` trash
+		WHERE trash.household_id = (SELECT id FROM households WHERE uuid = $1)
+			AND ($2::uuid IS NULL OR trash.user_id = $2)
+		ORDER BY trash.deleted_at DESC, trash.id
+	`
+
+	rows, err := r.db.Query(ctx, query, householdID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trash: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*model.TrashItem{}
+	for rows.Next() {
+		item, err := scanTrashItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trash item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// FindByID finds one item in the household's trash
+func (r *TrashRepository) FindByID(ctx context.Context, householdID, itemType, id string) (*model.TrashItem, error) {
+	query := `
+		SELECT trash.type, trash.id, trash.name, trash.amount, trash.user_id, trash.deleted_at
+		FROM ` + trashItems + ` trash
+		WHERE trash.household_id = (SELECT id FROM households WHERE uuid = $1)
+			AND trash.type = $2 AND trash.id = $3
+	`
+
+	item, err := scanTrashItem(r.db.QueryRow(ctx, query, householdID, itemType, id))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("trash item not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trash item: %w", err)
+	}
+
+	return item, nil
+}
+
+// IsDeleted reports whether the row of the given kind is in the trash
+func (r *TrashRepository) IsDeleted(ctx context.Context, itemType, id string) (bool, error) {
+	table, ok := trashTables[itemType]
+	if !ok {
+		return false, fmt.Errorf("unknown trash item type %q", itemType)
+	}
+
+	var deleted bool
+	err := r.db.QueryRow(ctx, `SELECT deleted_at IS NOT NULL FROM `+table+` WHERE uuid = $1`, id).Scan(&deleted)
+	if err == pgx.ErrNoRows {
+		return false, fmt.Errorf("%s not found", itemType)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s: %w", itemType, err)
+	}
+
+	return deleted, nil
+}
+
+// Purge removes for good every row that was moved to the trash before the
+// given time. Categories still referenced by a transaction, split, budget or
+// budget template, even one in the trash, are kept until those are gone.
+func (r *TrashRepository) Purge(ctx context.Context, before time.Time) (*model.PurgeResult, error) {
+	result := &model.PurgeResult{}
+
+	steps := []struct {
+		query string
+		count *int64
+	}{
+		{`DELETE FROM transactions WHERE deleted_at < $1`, &result.Transactions},
+		{`DELETE FROM budgets WHERE deleted_at < $1`, &result.Budgets},
+		{`DELETE FROM saving_goals WHERE deleted_at < $1`, &result.SavingGoals},
+		{`DELETE FROM accounts WHERE deleted_at < $1`, &result.Accounts},
+		{`
+			DELETE FROM categories c
+			WHERE c.deleted_at < $1
+				AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.category_id = c.id)
+				AND NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.category_id = c.id)
+				AND NOT EXISTS (SELECT 1 FROM budgets b WHERE b.category_id = c.id)
+				AND NOT EXISTS (SELECT 1 FROM budget_templates bt WHERE bt.category_id = c.id)
+		`, &result.Categories},
+	}
+
+	for _, step := range steps {
+		tag, err := r.db.Exec(ctx, step.query, before)
+		if err != nil {
+			return nil, fmt.Errorf("failed to purge trash: %w", err)
+		}
+		*step.count = tag.RowsAffected()
+	}
+
+	return result, nil
+}
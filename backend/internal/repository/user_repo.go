@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/jackc/pgx/v5"
@@ -12,38 +13,70 @@ import (
 )
 
 type UserRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
 func NewUserRepository(db *pgxpool.Pool) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-// Create creates a new admin user via self-registration
-func (r *UserRepository) Create(ctx context.Context, req *model.RegisterRequest) (*model.User, error) {
-	return r.createUser(ctx, req.Email, req.Password, req.Name, "admin")
+// WithTx returns a copy of the repository that runs its queries inside tx
+func (r *UserRepository) WithTx(tx pgx.Tx) *UserRepository {
+	return &UserRepository{db: tx}
 }
 
-// CreateWithRole creates a new user with the specified role
-func (r *UserRepository) CreateWithRole(ctx context.Context, req *model.CreateUserRequest) (*model.User, error) {
-	return r.createUser(ctx, req.Email, req.Password, req.Name, req.Role)
+// Create creates a new household and its admin user via self-registration
+func (r *UserRepository) Create(ctx context.Context, req *model.RegisterRequest, householdName, baseCurrency string) (*model.User, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &model.User{}
+	query := `
+		WITH household AS (
+			INSERT INTO households (name, base_currency)
+			VALUES ($1, $5)
+			RETURNING *
+		), inserted AS (
+			INSERT INTO users (household_id, email, password_hash, name, role)
+			SELECT h.id, $2, $3, $4, 'admin' FROM household h
+			RETURNING *
+		)
+		SELECT i.uuid, h.uuid, i.email, i.name, i.role, i.totp_enabled, i.approval_threshold, i.created_at, i.updated_at
+		FROM inserted i JOIN household h ON h.id = i.household_id
+	`
+
+	err = r.db.QueryRow(ctx, query, householdName, req.Email, string(hashedPassword), req.Name, baseCurrency).
+		Scan(&user.ID, &user.HouseholdID, &user.Email, &user.Name, &user.Role, &user.TwoFactorEnabled, &user.ApprovalThreshold, &user.CreatedAt, &user.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
 }
 
-func (r *UserRepository) createUser(ctx context.Context, email, password, name, role string) (*model.User, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// CreateWithRole creates a new user with the specified role in an existing household
+func (r *UserRepository) CreateWithRole(ctx context.Context, householdID string, req *model.CreateUserRequest) (*model.User, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	user := &model.User{}
 	query := `
-		INSERT INTO users (email, password_hash, name, role)
-		VALUES ($1, $2, $3, $4)
-		RETURNING uuid, email, name, role, created_at, updated_at
+		WITH inserted AS (
+			INSERT INTO users (household_id, email, password_hash, name, role)
+			VALUES ((SELECT id FROM households WHERE uuid = $1), $2, $3, $4, $5)
+			RETURNING *
+		)
+		SELECT i.uuid, h.uuid, i.email, i.name, i.role, i.totp_enabled, i.approval_threshold, i.created_at, i.updated_at
+		FROM inserted i JOIN households h ON h.id = i.household_id
 	`
 
-	err = r.db.QueryRow(ctx, query, email, string(hashedPassword), name, role).
-		Scan(&user.ID, &user.Email, &user.Name, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	err = r.db.QueryRow(ctx, query, householdID, req.Email, string(hashedPassword), req.Name, req.Role).
+		Scan(&user.ID, &user.HouseholdID, &user.Email, &user.Name, &user.Role, &user.TwoFactorEnabled, &user.ApprovalThreshold, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
@@ -52,15 +85,16 @@ func (r *UserRepository) createUser(ctx context.Context, email, password, name,
 	return user, nil
 }
 
-// ListAll returns all users
-func (r *UserRepository) ListAll(ctx context.Context) ([]*model.User, error) {
+// ListAll returns all users in a household
+func (r *UserRepository) ListAll(ctx context.Context, householdID string) ([]*model.User, error) {
 	query := `
-		SELECT uuid, email, name, role, created_at, updated_at
-		FROM users
-		ORDER BY created_at ASC
+		SELECT u.uuid, h.uuid, u.email, u.name, u.role, u.totp_enabled, u.approval_threshold, u.created_at, u.updated_at
+		FROM users u JOIN households h ON h.id = u.household_id
+		WHERE h.uuid = $1
+		ORDER BY u.created_at ASC
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, householdID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -69,7 +103,7 @@ func (r *UserRepository) ListAll(ctx context.Context) ([]*model.User, error) {
 	var users []*model.User
 	for rows.Next() {
 		u := &model.User{}
-		if err := rows.Scan(&u.ID, &u.Email, &u.Name, &u.Role, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		if err := rows.Scan(&u.ID, &u.HouseholdID, &u.Email, &u.Name, &u.Role, &u.TwoFactorEnabled, &u.ApprovalThreshold, &u.CreatedAt, &u.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
 		users = append(users, u)
@@ -79,7 +113,7 @@ func (r *UserRepository) ListAll(ctx context.Context) ([]*model.User, error) {
 }
 
 // Update updates a user's name and/or role
-func (r *UserRepository) Update(ctx context.Context, id string, req *model.UpdateUserRequest) (*model.User, error) {
+func (r *UserRepository) Update(ctx context.Context, householdID, id string, req *model.UpdateUserRequest) (*model.User, error) {
 	updates := []string{}
 	args := []interface{}{}
 	argPos := 1
@@ -102,17 +136,21 @@ func (r *UserRepository) Update(ctx context.Context, id string, req *model.Updat
 
 	updates = append(updates, "updated_at = NOW()")
 
-	args = append(args, id)
+	args = append(args, id, householdID)
 	query := fmt.Sprintf(`
-		UPDATE users
-		SET %s
-		WHERE uuid = $%d
-		RETURNING uuid, email, name, role, created_at, updated_at
-	`, strings.Join(updates, ", "), argPos)
+		WITH updated AS (
+			UPDATE users
+			SET %s
+			WHERE uuid = $%d AND household_id = (SELECT id FROM households WHERE uuid = $%d)
+			RETURNING *
+		)
+		SELECT up.uuid, h.uuid, up.email, up.name, up.role, up.totp_enabled, up.approval_threshold, up.created_at, up.updated_at
+		FROM updated up JOIN households h ON h.id = up.household_id
+	`, strings.Join(updates, ", "), argPos, argPos+1)
 
 	user := &model.User{}
 	err := r.db.QueryRow(ctx, query, args...).
-		Scan(&user.ID, &user.Email, &user.Name, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+		Scan(&user.ID, &user.HouseholdID, &user.Email, &user.Name, &user.Role, &user.TwoFactorEnabled, &user.ApprovalThreshold, &user.CreatedAt, &user.UpdatedAt)
 
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("user not found")
@@ -124,10 +162,38 @@ func (r *UserRepository) Update(ctx context.Context, id string, req *model.Updat
 	return user, nil
 }
 
+// SetApprovalThreshold sets or, when nil, removes the amount above which the
+// user's expenses need approval
+func (r *UserRepository) SetApprovalThreshold(ctx context.Context, householdID, id string, threshold *int64) (*model.User, error) {
+	query := `
+		WITH updated AS (
+			UPDATE users
+			SET approval_threshold = $3, updated_at = NOW()
+			WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2)
+			RETURNING *
+		)
+		SELECT up.uuid, h.uuid, up.email, up.name, up.role, up.totp_enabled, up.approval_threshold, up.created_at, up.updated_at
+		FROM updated up JOIN households h ON h.id = up.household_id
+	`
+
+	user := &model.User{}
+	err := r.db.QueryRow(ctx, query, id, householdID, threshold).
+		Scan(&user.ID, &user.HouseholdID, &user.Email, &user.Name, &user.Role, &user.TwoFactorEnabled, &user.ApprovalThreshold, &user.CreatedAt, &user.UpdatedAt)
+
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set approval threshold: %w", err)
+	}
+
+	return user, nil
+}
+
 // Delete deletes a user by ID
-func (r *UserRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM users WHERE uuid = $1`
-	result, err := r.db.Exec(ctx, query, id)
+func (r *UserRepository) Delete(ctx context.Context, householdID, id string) error {
+	query := `DELETE FROM users WHERE uuid = $1 AND household_id = (SELECT id FROM households WHERE uuid = $2)`
+	result, err := r.db.Exec(ctx, query, id, householdID)
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
@@ -143,13 +209,13 @@ func (r *UserRepository) Delete(ctx context.Context, id string) error {
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	user := &model.User{}
 	query := `
-		SELECT uuid, email, password_hash, name, role, created_at, updated_at
-		FROM users
-		WHERE email = $1
+		SELECT u.uuid, h.uuid, u.email, u.password_hash, u.name, u.role, u.totp_enabled, u.approval_threshold, u.created_at, u.updated_at
+		FROM users u JOIN households h ON h.id = u.household_id
+		WHERE u.email = $1
 	`
 
 	err := r.db.QueryRow(ctx, query, email).
-		Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+		Scan(&user.ID, &user.HouseholdID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &user.TwoFactorEnabled, &user.ApprovalThreshold, &user.CreatedAt, &user.UpdatedAt)
 
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("user not found")
@@ -165,13 +231,13 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.
 func (r *UserRepository) FindByID(ctx context.Context, id string) (*model.User, error) {
 	user := &model.User{}
 	query := `
-		SELECT uuid, email, name, role, created_at, updated_at
-		FROM users
-		WHERE uuid = $1
+		SELECT u.uuid, h.uuid, u.email, u.name, u.role, u.totp_enabled, u.approval_threshold, u.created_at, u.updated_at
+		FROM users u JOIN households h ON h.id = u.household_id
+		WHERE u.uuid = $1
 	`
 
 	err := r.db.QueryRow(ctx, query, id).
-		Scan(&user.ID, &user.Email, &user.Name, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+		Scan(&user.ID, &user.HouseholdID, &user.Email, &user.Name, &user.Role, &user.TwoFactorEnabled, &user.ApprovalThreshold, &user.CreatedAt, &user.UpdatedAt)
 
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("user not found")
@@ -183,6 +249,147 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*model.User,
 	return user, nil
 }
 
+// FindTOTP returns the user's TOTP secret, if one was set up, and whether
+// two-factor authentication is enabled
+func (r *UserRepository) FindTOTP(ctx context.Context, id string) (string, bool, error) {
+	var secret *string
+	var enabled bool
+	err := r.db.QueryRow(ctx, `SELECT totp_secret, totp_enabled FROM users WHERE uuid = $1`, id).Scan(&secret, &enabled)
+	if err == pgx.ErrNoRows {
+		return "", false, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if secret == nil {
+		return "", enabled, nil
+	}
+	return *secret, enabled, nil
+}
+
+// SetTOTPSecret stores a new secret for a user that has not enabled
+// two-factor authentication yet
+func (r *UserRepository) SetTOTPSecret(ctx context.Context, id, secret string) error {
+	query := `UPDATE users SET totp_secret = $1, updated_at = NOW() WHERE uuid = $2 AND NOT totp_enabled`
+	result, err := r.db.Exec(ctx, query, secret, id)
+	if err != nil {
+		return fmt.Errorf("failed to set up two-factor authentication: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	return nil
+}
+
+// EnableTOTP turns on two-factor authentication with the given recovery
+// code hashes, recording step as used
+func (r *UserRepository) EnableTOTP(ctx context.Context, id string, step int64, recoveryCodeHashes []string) error {
+	query := `
+		UPDATE users SET totp_enabled = true, totp_last_step = $1, totp_recovery_codes = $2, updated_at = NOW()
+		WHERE uuid = $3 AND totp_secret IS NOT NULL AND NOT totp_enabled
+	`
+	result, err := r.db.Exec(ctx, query, step, recoveryCodeHashes, id)
+	if err != nil {
+		return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	return nil
+}
+
+// DisableTOTP turns off two-factor authentication and forgets the secret
+func (r *UserRepository) DisableTOTP(ctx context.Context, id string) error {
+	query := `
+		UPDATE users SET totp_enabled = false, totp_secret = NULL, totp_last_step = NULL, totp_recovery_codes = '{}', updated_at = NOW()
+		WHERE uuid = $1
+	`
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+
+	return nil
+}
+
+// UseTOTPStep records step as used. It reports false if that step or a later
+// one was used already, so an intercepted code cannot be replayed.
+func (r *UserRepository) UseTOTPStep(ctx context.Context, id string, step int64) (bool, error) {
+	query := `
+		UPDATE users SET totp_last_step = $1
+		WHERE uuid = $2 AND (totp_last_step IS NULL OR totp_last_step < $1)
+	`
+	result, err := r.db.Exec(ctx, query, step, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to record two-factor code: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// FindTOTPLock returns until when the user's second factor is locked after
+// too many wrong codes, or nil if it is not locked
+func (r *UserRepository) FindTOTPLock(ctx context.Context, id string) (*time.Time, error) {
+	var lockedUntil *time.Time
+	query := `SELECT totp_locked_until FROM users WHERE uuid = $1 AND totp_locked_until > NOW()`
+	err := r.db.QueryRow(ctx, query, id).Scan(&lockedUntil)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find two-factor lock: %w", err)
+	}
+
+	return lockedUntil, nil
+}
+
+// RecordTOTPFailure counts a wrong two-factor code. The maxAttempts-th wrong
+// code in a row locks the second factor for lockout and starts the count
+// again.
+func (r *UserRepository) RecordTOTPFailure(ctx context.Context, id string, maxAttempts int, lockout time.Duration) error {
+	query := `
+		UPDATE users SET
+			totp_failed_attempts = CASE WHEN totp_failed_attempts + 1 >= $2 THEN 0 ELSE totp_failed_attempts + 1 END,
+			totp_locked_until = CASE WHEN totp_failed_attempts + 1 >= $2 THEN NOW() + make_interval(secs => $3) ELSE totp_locked_until END
+		WHERE uuid = $1
+	`
+	if _, err := r.db.Exec(ctx, query, id, maxAttempts, lockout.Seconds()); err != nil {
+		return fmt.Errorf("failed to record two-factor failure: %w", err)
+	}
+
+	return nil
+}
+
+// ResetTOTPFailures clears the count of wrong two-factor codes after a right
+// one
+func (r *UserRepository) ResetTOTPFailures(ctx context.Context, id string) error {
+	query := `UPDATE users SET totp_failed_attempts = 0, totp_locked_until = NULL WHERE uuid = $1`
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to reset two-factor failures: %w", err)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode removes a recovery code hash from the user. It reports
+// false if the user has no such unused code.
+func (r *UserRepository) UseRecoveryCode(ctx context.Context, id, codeHash string) (bool, error) {
+	query := `
+		UPDATE users SET totp_recovery_codes = array_remove(totp_recovery_codes, $1)
+		WHERE uuid = $2 AND $1 = ANY(totp_recovery_codes)
+	`
+	result, err := r.db.Exec(ctx, query, codeHash, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
 // VerifyPassword checks if the provided password matches the user's hashed password
 func (r *UserRepository) VerifyPassword(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
@@ -0,0 +1,212 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/asilingas/fambudg/backend/internal/service"
+)
+
+// AdvisoryLockKey is the Postgres advisory lock held while jobs run, so that
+// only one replica executes them at a time
+const AdvisoryLockKey int64 = 7_346_210_001
+
+const (
+	JobRecurringTransactions = "recurring_transactions"
+	JobOverdueBills          = "overdue_bills"
+	JobBillNotices           = "bill_notices"
+	JobAllowancePeriods      = "allowance_periods"
+	JobPurgeTrash            = "purge_trash"
+)
+
+// maxAttempts is how often a failing job is tried on one day before it is
+// left until the next
+const maxAttempts = 3
+
+// PartialError is returned by a job that did its work but failed for some
+// items, such as one bad recurring template. The run is recorded as
+// succeeded with the errors attached, so it is not repeated the same day.
+type PartialError struct {
+	Err error
+}
+
+func (e *PartialError) Error() string { return e.Err.Error() }
+
+func (e *PartialError) Unwrap() error { return e.Err }
+
+// partial marks err as a PartialError if the job got some work done, and
+// leaves it a failure otherwise so that the run is tried again
+func partial(done int, err error) error {
+	if err == nil || done == 0 {
+		return err
+	}
+	return &PartialError{Err: err}
+}
+
+// Job is a unit of daily background work. Run returns a short summary that is
+// stored in job_runs.
+type Job struct {
+	Name string
+	Run  func(ctx context.Context, today time.Time) (string, error)
+}
+
+// Scheduler runs each job once per day (UTC). It wakes up every interval and
+// runs the jobs that have not yet succeeded today, so a restart or a failed
+// run is picked up on the next tick.
+type Scheduler struct {
+	uow        *repository.UnitOfWork
+	jobRunRepo *repository.JobRunRepository
+	jobs       []Job
+	interval   time.Duration
+}
+
+func New(uow *repository.UnitOfWork, jobRunRepo *repository.JobRunRepository, interval time.Duration, jobs ...Job) *Scheduler {
+	return &Scheduler{
+		uow:        uow,
+		jobRunRepo: jobRunRepo,
+		jobs:       jobs,
+		interval:   interval,
+	}
+}
+
+// DefaultJobs returns the recurring transaction, overdue bill, bill notice,
+// allowance period and trash purge jobs
+func DefaultJobs(
+	transactionService *service.TransactionService,
+	billReminderService *service.BillReminderService,
+	allowanceService *service.AllowanceService,
+	notificationService *service.NotificationService,
+	trashService *service.TrashService,
+) []Job {
+	return []Job{
+		{
+			Name: JobRecurringTransactions,
+			Run: func(ctx context.Context, today time.Time) (string, error) {
+				result, err := transactionService.GenerateRecurringAll(ctx, today)
+				if err != nil {
+					return "", err
+				}
+				summary := fmt.Sprintf("generated %d transactions from %d templates", result.Generated, result.Templates)
+				if len(result.Errors) > 0 {
+					return summary, partial(result.Generated, fmt.Errorf("%d errors: %s", len(result.Errors), strings.Join(result.Errors, "; ")))
+				}
+				return summary, nil
+			},
+		},
+		{
+			Name: JobOverdueBills,
+			Run: func(ctx context.Context, today time.Time) (string, error) {
+				flagged, err := billReminderService.MarkOverdue(ctx, today)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("flagged %d overdue bills", flagged), nil
+			},
+		},
+		{
+			Name: JobBillNotices,
+			Run: func(ctx context.Context, today time.Time) (string, error) {
+				checked, err := notificationService.SendBillNotices(ctx, today)
+				return fmt.Sprintf("checked %d due bills", checked), partial(checked, err)
+			},
+		},
+		{
+			Name: JobAllowancePeriods,
+			Run: func(ctx context.Context, today time.Time) (string, error) {
+				started, toppedUp, err := allowanceService.StartPeriods(ctx, today)
+				return fmt.Sprintf("started %d allowance periods and made %d top-ups", started, toppedUp), partial(started+toppedUp, err)
+			},
+		},
+		{
+			Name: JobPurgeTrash,
+			Run: func(ctx context.Context, today time.Time) (string, error) {
+				purged, err := trashService.PurgeExpired(ctx, today)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("purged %d transactions, %d accounts, %d categories, %d budgets and %d saving goals",
+					purged.Transactions, purged.Accounts, purged.Categories, purged.Budgets, purged.SavingGoals), nil
+			},
+		},
+	}
+}
+
+// Start runs due jobs immediately and then on every tick until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunDue(ctx, time.Now()); err != nil {
+			log.Printf("Scheduler: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs every job that has not succeeded yet on now's date and has not
+// failed maxAttempts times already. It returns false without running
+// anything if another replica holds the lock.
+func (s *Scheduler) RunDue(ctx context.Context, now time.Time) (bool, error) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	return s.uow.TryAdvisoryLock(ctx, AdvisoryLockKey, func(ctx context.Context) error {
+		for _, job := range s.jobs {
+			done, err := s.jobRunRepo.HasSucceeded(ctx, job.Name, today)
+			if err != nil {
+				return err
+			}
+			if done {
+				continue
+			}
+
+			failed, err := s.jobRunRepo.CountFailed(ctx, job.Name, today)
+			if err != nil {
+				return err
+			}
+			if failed >= maxAttempts {
+				continue
+			}
+
+			if err := s.run(ctx, job, today); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// run executes a single job and records the outcome. A failing job is logged
+// and recorded but does not stop the jobs after it. A job that failed only
+// for some items is recorded as succeeded with its errors.
+func (s *Scheduler) run(ctx context.Context, job Job, today time.Time) error {
+	jobRun, err := s.jobRunRepo.Start(ctx, job.Name, today)
+	if err != nil {
+		return err
+	}
+
+	summary, jobErr := job.Run(ctx, today)
+	var partialErr *PartialError
+	succeeded := jobErr == nil || errors.As(jobErr, &partialErr)
+	switch {
+	case jobErr == nil:
+		log.Printf("Scheduler: job %s: %s", job.Name, summary)
+	case succeeded:
+		log.Printf("Scheduler: job %s: %s, with errors: %v", job.Name, summary, jobErr)
+	default:
+		log.Printf("Scheduler: job %s failed: %v", job.Name, jobErr)
+	}
+
+	return s.jobRunRepo.Finish(ctx, jobRun.ID, summary, succeeded, jobErr)
+}
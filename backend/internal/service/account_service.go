@@ -5,36 +5,89 @@ import (
 
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
 )
 
 type AccountService struct {
+	uow         *repository.UnitOfWork
 	accountRepo *repository.AccountRepository
+	auditRepo   *repository.AuditRepository
 }
 
-func NewAccountService(accountRepo *repository.AccountRepository) *AccountService {
-	return &AccountService{accountRepo: accountRepo}
+func NewAccountService(uow *repository.UnitOfWork, accountRepo *repository.AccountRepository, auditRepo *repository.AuditRepository) *AccountService {
+	return &AccountService{uow: uow, accountRepo: accountRepo, auditRepo: auditRepo}
 }
 
 func (s *AccountService) Create(ctx context.Context, userID string, req *model.CreateAccountRequest) (*model.Account, error) {
-	return s.accountRepo.Create(ctx, userID, req)
+	var account *model.Account
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		created, err := s.accountRepo.WithTx(tx).Create(ctx, userID, req)
+		if err != nil {
+			return err
+		}
+
+		account = created
+		return recordUserAudit(ctx, s.auditRepo.WithTx(tx), userID, model.AuditEntityAccount, created.ID, model.AuditActionCreate, nil, created)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
 }
 
-func (s *AccountService) GetByID(ctx context.Context, id string) (*model.Account, error) {
-	return s.accountRepo.FindByID(ctx, id)
+func (s *AccountService) GetByID(ctx context.Context, householdID, id string) (*model.Account, error) {
+	return s.accountRepo.FindByID(ctx, householdID, id)
 }
 
 func (s *AccountService) GetByUserID(ctx context.Context, userID string) ([]*model.Account, error) {
 	return s.accountRepo.FindByUserID(ctx, userID)
 }
 
-func (s *AccountService) GetAll(ctx context.Context) ([]*model.Account, error) {
-	return s.accountRepo.FindAll(ctx)
+func (s *AccountService) GetAll(ctx context.Context, householdID string) ([]*model.Account, error) {
+	return s.accountRepo.FindAll(ctx, householdID)
 }
 
-func (s *AccountService) Update(ctx context.Context, id string, req *model.UpdateAccountRequest) (*model.Account, error) {
-	return s.accountRepo.Update(ctx, id, req)
+func (s *AccountService) Update(ctx context.Context, householdID, id string, req *model.UpdateAccountRequest) (*model.Account, error) {
+	var account *model.Account
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		accountRepo := s.accountRepo.WithTx(tx)
+
+		before, err := accountRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		updated, err := accountRepo.Update(ctx, householdID, id, req)
+		if err != nil {
+			return err
+		}
+
+		account = updated
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityAccount, id, model.AuditActionUpdate, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
 }
 
-func (s *AccountService) Delete(ctx context.Context, id string) error {
-	return s.accountRepo.Delete(ctx, id)
+func (s *AccountService) Delete(ctx context.Context, householdID, id string) error {
+	return s.uow.Do(ctx, func(tx pgx.Tx) error {
+		accountRepo := s.accountRepo.WithTx(tx)
+
+		before, err := accountRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := accountRepo.Delete(ctx, householdID, id); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityAccount, id, model.AuditActionDelete, before, nil)
+	})
 }
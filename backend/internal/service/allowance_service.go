@@ -2,35 +2,73 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
 )
 
+// ErrAllowanceSameAccount is returned when an allowance would be topped up
+// from the account it is paid into
+var ErrAllowanceSameAccount = errors.New("an allowance cannot be topped up from the account it is paid into")
+
 type AllowanceService struct {
-	allowanceRepo *repository.AllowanceRepository
+	uow                *repository.UnitOfWork
+	allowanceRepo      *repository.AllowanceRepository
+	transactionService *TransactionService
+	accountRepo        *repository.AccountRepository
+	auditRepo          *repository.AuditRepository
 }
 
-func NewAllowanceService(allowanceRepo *repository.AllowanceRepository) *AllowanceService {
-	return &AllowanceService{allowanceRepo: allowanceRepo}
+func NewAllowanceService(
+	uow *repository.UnitOfWork,
+	allowanceRepo *repository.AllowanceRepository,
+	transactionService *TransactionService,
+	accountRepo *repository.AccountRepository,
+	auditRepo *repository.AuditRepository,
+) *AllowanceService {
+	return &AllowanceService{
+		uow:                uow,
+		allowanceRepo:      allowanceRepo,
+		transactionService: transactionService,
+		accountRepo:        accountRepo,
+		auditRepo:          auditRepo,
+	}
 }
 
-func (s *AllowanceService) Create(ctx context.Context, req *model.CreateAllowanceRequest) (*model.Allowance, error) {
-	allowance, err := s.allowanceRepo.Create(ctx, req)
+func (s *AllowanceService) Create(ctx context.Context, householdID string, req *model.CreateAllowanceRequest) (*model.Allowance, error) {
+	if req.FromAccountID != nil && req.ToAccountID != nil && *req.FromAccountID == *req.ToAccountID {
+		return nil, ErrAllowanceSameAccount
+	}
+
+	var allowance *model.Allowance
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		created, err := s.allowanceRepo.WithTx(tx).Create(ctx, householdID, req)
+		if err != nil {
+			return err
+		}
+
+		allowance = created
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityAllowance, created.ID, model.AuditActionCreate, nil, created)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return s.enrichWithSpending(ctx, allowance)
+	return enrichWithSpending(ctx, s.allowanceRepo, allowance)
 }
 
-func (s *AllowanceService) GetByID(ctx context.Context, id string) (*model.Allowance, error) {
-	allowance, err := s.allowanceRepo.FindByID(ctx, id)
+func (s *AllowanceService) GetByID(ctx context.Context, householdID, id string) (*model.Allowance, error) {
+	allowance, err := s.allowanceRepo.FindByID(ctx, householdID, id)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.enrichWithSpending(ctx, allowance)
+	return enrichWithSpending(ctx, s.allowanceRepo, allowance)
 }
 
 func (s *AllowanceService) GetByUserID(ctx context.Context, userID string) (*model.Allowance, error) {
@@ -39,17 +77,17 @@ func (s *AllowanceService) GetByUserID(ctx context.Context, userID string) (*mod
 		return nil, err
 	}
 
-	return s.enrichWithSpending(ctx, allowance)
+	return enrichWithSpending(ctx, s.allowanceRepo, allowance)
 }
 
-func (s *AllowanceService) GetAll(ctx context.Context) ([]*model.Allowance, error) {
-	allowances, err := s.allowanceRepo.ListAll(ctx)
+func (s *AllowanceService) GetAll(ctx context.Context, householdID string) ([]*model.Allowance, error) {
+	allowances, err := s.allowanceRepo.ListAll(ctx, householdID)
 	if err != nil {
 		return nil, err
 	}
 
 	for i, a := range allowances {
-		enriched, err := s.enrichWithSpending(ctx, a)
+		enriched, err := enrichWithSpending(ctx, s.allowanceRepo, a)
 		if err != nil {
 			return nil, err
 		}
@@ -59,23 +97,167 @@ func (s *AllowanceService) GetAll(ctx context.Context) ([]*model.Allowance, erro
 	return allowances, nil
 }
 
-func (s *AllowanceService) Update(ctx context.Context, id string, req *model.UpdateAllowanceRequest) (*model.Allowance, error) {
-	allowance, err := s.allowanceRepo.Update(ctx, id, req)
+func (s *AllowanceService) Update(ctx context.Context, householdID, id string, req *model.UpdateAllowanceRequest) (*model.Allowance, error) {
+	var allowance *model.Allowance
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		allowanceRepo := s.allowanceRepo.WithTx(tx)
+
+		before, err := allowanceRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		updated, err := allowanceRepo.Update(ctx, householdID, id, req)
+		if err != nil {
+			return err
+		}
+		if updated.FromAccountID != nil && updated.ToAccountID != nil && *updated.FromAccountID == *updated.ToAccountID {
+			return ErrAllowanceSameAccount
+		}
+
+		allowance = updated
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityAllowance, id, model.AuditActionUpdate, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return enrichWithSpending(ctx, s.allowanceRepo, allowance)
+}
+
+// GetPeriods returns the allowance's current and past periods, latest first
+func (s *AllowanceService) GetPeriods(ctx context.Context, householdID, id string) ([]*model.AllowancePeriod, error) {
+	periods, err := s.allowanceRepo.FindPeriods(ctx, householdID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range periods {
+		p.Remaining = p.Amount + p.CarriedOver - p.Spent
+	}
+	return periods, nil
+}
+
+// StartPeriods starts the next period of every allowance whose period ended
+// by today, carrying over what was left if it rolls over. The period that
+// includes today is topped up once, if the allowance has accounts to top up
+// from and to; periods missed in between are recorded but not topped up. It
+// returns the number of periods started and top-ups made.
+func (s *AllowanceService) StartPeriods(ctx context.Context, today time.Time) (int, int, error) {
+	due, err := s.allowanceRepo.FindDue(ctx, today)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var started, toppedUp int
+	var errs []error
+	for _, d := range due {
+		var periods int
+		var topUp *model.Transaction
+		err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+			var err error
+			periods, topUp, err = s.advance(ctx, tx, d, today)
+			return err
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("allowance %s: %w", d.Allowance.ID, err))
+			continue
+		}
+		started += periods
+		if topUp != nil {
+			s.transactionService.Booked(ctx, topUp)
+			toppedUp++
+		}
+	}
+
+	return started, toppedUp, errors.Join(errs...)
+}
+
+// advance brings one allowance up to the period that includes today and tops
+// that period up, returning the number of periods started and the top-up
+// transfer, if any. Periods that ended before today, e.g. while the scheduler
+// was down, are only recorded, so a catch-up never pays out a backlog.
+func (s *AllowanceService) advance(ctx context.Context, tx pgx.Tx, due *model.DueAllowance, today time.Time) (int, *model.Transaction, error) {
+	allowanceRepo := s.allowanceRepo.WithTx(tx)
+	allowance := due.Allowance
+	toppedUp := due.ToppedUp
+
+	var started int
+	for !allowance.PeriodEnd.After(today) {
+		var carriedOver int64
+		if allowance.Rollover {
+			spent, err := allowanceRepo.GetSpentInPeriod(ctx, allowance.UserID, allowance.PeriodStart, allowance.PeriodEnd)
+			if err != nil {
+				return 0, nil, err
+			}
+			carriedOver = max(allowance.Amount+allowance.CarriedOver-spent, 0)
+		}
+
+		if err := allowanceRepo.StartPeriod(ctx, allowance.ID, allowance.PeriodEnd, carriedOver); err != nil {
+			return 0, nil, err
+		}
+		next, err := allowanceRepo.FindByID(ctx, due.HouseholdID, allowance.ID)
+		if err != nil {
+			return 0, nil, err
+		}
+		allowance = next
+		toppedUp = false
+		started++
+	}
+
+	if toppedUp || allowance.PeriodStart.After(today) {
+		return started, nil, nil
+	}
+
+	topUp, err := s.topUp(ctx, tx, due.HouseholdID, allowance)
+	if err != nil {
+		return started, nil, err
+	}
+	return started, topUp, nil
+}
+
+// topUp transfers the allowance amount for the current period from the
+// parent's account to the child's, on behalf of the parent who owns the
+// account, and returns the transfer. It returns nil if the allowance has no
+// accounts to top up.
+func (s *AllowanceService) topUp(ctx context.Context, tx pgx.Tx, householdID string, allowance *model.Allowance) (*model.Transaction, error) {
+	if allowance.FromAccountID == nil || allowance.ToAccountID == nil {
+		return nil, nil
+	}
+
+	from, err := s.accountRepo.WithTx(tx).FindByID(ctx, householdID, *allowance.FromAccountID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.enrichWithSpending(ctx, allowance)
+	created, err := s.transactionService.CreateTx(ctx, tx, from.UserID, &model.CreateTransactionRequest{
+		AccountID:           from.ID,
+		Amount:              -allowance.Amount,
+		Type:                "transfer",
+		Description:         "Allowance",
+		Date:                allowance.PeriodStart.Format("2006-01-02"),
+		TransferToAccountID: allowance.ToAccountID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.allowanceRepo.WithTx(tx).SetTopUp(ctx, allowance.ID, created.ID); err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
-// enrichWithSpending calculates spent and remaining from transactions
-func (s *AllowanceService) enrichWithSpending(ctx context.Context, allowance *model.Allowance) (*model.Allowance, error) {
-	spent, err := s.allowanceRepo.GetSpentInPeriod(ctx, allowance.UserID, allowance.PeriodStart)
+// enrichWithSpending calculates spent and remaining in the current period
+// from transactions
+func enrichWithSpending(ctx context.Context, allowanceRepo *repository.AllowanceRepository, allowance *model.Allowance) (*model.Allowance, error) {
+	spent, err := allowanceRepo.GetSpentInPeriod(ctx, allowance.UserID, allowance.PeriodStart, allowance.PeriodEnd)
 	if err != nil {
 		return nil, err
 	}
 
 	allowance.Spent = spent
-	allowance.Remaining = allowance.Amount - spent
+	allowance.Remaining = allowance.Amount + allowance.CarriedOver - spent
 	return allowance, nil
 }
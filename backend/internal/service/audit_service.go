@@ -0,0 +1,90 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/asilingas/fambudg/backend/internal/audit"
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/repository"
+)
+
+type AuditService struct {
+	auditRepo *repository.AuditRepository
+}
+
+func NewAuditService(auditRepo *repository.AuditRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+func (s *AuditService) GetAll(ctx context.Context, householdID string, filters *model.AuditFilters) (*model.AuditPage, error) {
+	if filters.Limit < 0 || filters.Limit > model.MaxPageLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", model.MaxPageLimit)
+	}
+	return s.auditRepo.FindAll(ctx, householdID, filters)
+}
+
+// newAuditEvent describes a change to an entity made by the actor of ctx.
+// before is nil for creates and after for deletes.
+func newAuditEvent(ctx context.Context, entityType, entityID, action string, before, after any) (*model.AuditEvent, error) {
+	event := &model.AuditEvent{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+	}
+
+	if actor := audit.Actor(ctx); actor != "" {
+		event.ActorID = &actor
+	}
+	if requestID := audit.RequestID(ctx); requestID != "" {
+		event.RequestID = &requestID
+	}
+
+	var err error
+	if event.Before, err = auditSnapshot(before); err != nil {
+		return nil, err
+	}
+	if event.After, err = auditSnapshot(after); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// auditSnapshot returns v as JSON, or nothing for a nil entity
+func auditSnapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	if bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// recordAudit appends a change to the household's audit log. Callers pass the
+// repository bound to the transaction of the change, so the change and its
+// event are committed together.
+func recordAudit(ctx context.Context, auditRepo *repository.AuditRepository, householdID, entityType, entityID, action string, before, after any) error {
+	event, err := newAuditEvent(ctx, entityType, entityID, action, before, after)
+	if err != nil {
+		return err
+	}
+	return auditRepo.Create(ctx, householdID, event)
+}
+
+// recordUserAudit is recordAudit for changes made where only the user they
+// belong to is known
+func recordUserAudit(ctx context.Context, auditRepo *repository.AuditRepository, userID, entityType, entityID, action string, before, after any) error {
+	event, err := newAuditEvent(ctx, entityType, entityID, action, before, after)
+	if err != nil {
+		return err
+	}
+	return auditRepo.CreateForUser(ctx, userID, event)
+}
@@ -2,27 +2,84 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/asilingas/fambudg/backend/internal/totp"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
 )
 
+const (
+	// Access tokens are not checked against the database, so a revoked
+	// session or a changed role takes effect at the latest when they expire
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+
+	// A password-checked login waiting for its two-factor code
+	challengeTokenTTL  = 5 * time.Minute
+	challengeTokenType = "2fa_challenge"
+
+	totpIssuer        = "Fambudg"
+	recoveryCodeCount = 10
+
+	// Wrong two-factor codes in a row lock the second factor for a while
+	maxTOTPAttempts = 5
+	totpLockout     = 15 * time.Minute
+)
+
+// TwoFactorSetupClaim marks an access token issued to an admin who has to set
+// up two-factor authentication before using anything but /api/auth
+const TwoFactorSetupClaim = "two_factor_setup"
+
+// defaultBaseCurrency is the base currency of a household registered without
+// one
+const defaultBaseCurrency = "EUR"
+
+// ErrTwoFactorLocked is returned when checking a two-factor code for a user
+// who entered too many wrong ones
+var ErrTwoFactorLocked = errors.New("too many invalid two-factor codes, try again later")
+
+// ErrApprovalThresholdNotChild is returned when setting a purchase approval
+// threshold on an admin or member
+var ErrApprovalThresholdNotChild = errors.New("only children can have a purchase approval threshold")
+
 type AuthService struct {
-	userRepo  *repository.UserRepository
-	jwtSecret string
+	uow           *repository.UnitOfWork
+	userRepo      *repository.UserRepository
+	sessionRepo   *repository.SessionRepository
+	householdRepo *repository.HouseholdRepository
+	auditRepo     *repository.AuditRepository
+	jwtSecret     string
 }
 
-func NewAuthService(userRepo *repository.UserRepository, jwtSecret string) *AuthService {
+func NewAuthService(
+	uow *repository.UnitOfWork,
+	userRepo *repository.UserRepository,
+	sessionRepo *repository.SessionRepository,
+	householdRepo *repository.HouseholdRepository,
+	auditRepo *repository.AuditRepository,
+	jwtSecret string,
+) *AuthService {
 	return &AuthService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
+		uow:           uow,
+		userRepo:      userRepo,
+		sessionRepo:   sessionRepo,
+		householdRepo: householdRepo,
+		auditRepo:     auditRepo,
+		jwtSecret:     jwtSecret,
 	}
 }
 
-// Register creates a new user account
+// Register creates a new household with the registering user as its admin
 func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest) (*model.User, error) {
 	// Check if user already exists
 	existingUser, _ := s.userRepo.FindByEmail(ctx, req.Email)
@@ -30,16 +87,37 @@ func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest)
 		return nil, fmt.Errorf("user with this email already exists")
 	}
 
-	// Create user
-	user, err := s.userRepo.Create(ctx, req)
+	householdName := req.HouseholdName
+	if householdName == "" {
+		householdName = req.Name + "'s household"
+	}
+	baseCurrency := req.BaseCurrency
+	if baseCurrency == "" {
+		baseCurrency = defaultBaseCurrency
+	}
+
+	var user *model.User
+
+	// Create household and user
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		created, err := s.userRepo.WithTx(tx).Create(ctx, req, householdName, baseCurrency)
+		if err != nil {
+			return fmt.Errorf("failed to register user: %w", err)
+		}
+
+		user = created
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), created.HouseholdID, model.AuditEntityUser, created.ID, model.AuditActionCreate, nil, created)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to register user: %w", err)
+		return nil, err
 	}
 
 	return user, nil
 }
 
-// Login authenticates a user and returns a JWT token
+// Login authenticates a user and returns an access token and a refresh token.
+// Users with two-factor authentication get a challenge token instead, which
+// VerifyLogin exchanges for the tokens.
 func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (*model.LoginResponse, error) {
 	// Find user by email
 	user, err := s.userRepo.FindByEmail(ctx, req.Email)
@@ -52,72 +130,508 @@ func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (*mode
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
-	// Generate JWT token
-	token, err := s.generateToken(user)
+	// Remove password hash from response
+	user.PasswordHash = ""
+
+	if user.TwoFactorEnabled {
+		challenge, err := s.generateChallengeToken(user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate token: %w", err)
+		}
+		return &model.LoginResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+			User:              *user,
+		}, nil
+	}
+
+	refreshToken, err := s.startSession(ctx, s.sessionRepo, user.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, err
 	}
 
-	// Remove password hash from response
-	user.PasswordHash = ""
+	return s.loginResponse(ctx, user, refreshToken)
+}
 
-	return &model.LoginResponse{
-		Token: token,
-		User:  *user,
+// VerifyLogin completes a two-factor login with a TOTP code or a recovery
+// code
+func (s *AuthService) VerifyLogin(ctx context.Context, req *model.VerifyLoginRequest) (*model.LoginResponse, error) {
+	claims, err := s.parseToken(req.ChallengeToken)
+	if err != nil || claims["typ"] != challengeTokenType {
+		return nil, fmt.Errorf("invalid or expired challenge")
+	}
+	userID, _ := claims["user_id"].(string)
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired challenge")
+	}
+
+	if err := s.checkSecondFactor(ctx, user.ID, req.Code); err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := s.startSession(ctx, s.sessionRepo, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.loginResponse(ctx, user, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. The user is read again, so a changed role is picked up and a
+// deleted user cannot refresh. Presenting a refresh token that was already
+// used revokes all of the user's sessions, as it must have been copied.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*model.LoginResponse, error) {
+	session, err := s.sessionRepo.FindByTokenHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("invalid refresh token")
+	}
+
+	if session.RevokedAt != nil {
+		if _, err := s.sessionRepo.RevokeAllForUser(ctx, session.UserID); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("invalid refresh token")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, fmt.Errorf("refresh token expired")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid refresh token")
+	}
+
+	var next string
+	err = s.uow.Do(ctx, func(tx pgx.Tx) error {
+		sessionRepo := s.sessionRepo.WithTx(tx)
+
+		revoked, err := sessionRepo.Revoke(ctx, session.ID)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			return fmt.Errorf("invalid refresh token")
+		}
+
+		next, err = s.startSession(ctx, sessionRepo, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.loginResponse(ctx, user, next)
+}
+
+// Logout revokes the session of a refresh token. Access tokens already
+// issued stay valid until they expire.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	session, err := s.sessionRepo.FindByTokenHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return fmt.Errorf("invalid refresh token")
+	}
+
+	_, err = s.sessionRepo.Revoke(ctx, session.ID)
+	return err
+}
+
+// RevokeSessions signs a user of the household out everywhere
+func (s *AuthService) RevokeSessions(ctx context.Context, householdID, userID string) (*model.RevokeSessionsResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.HouseholdID != householdID {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	revoked, err := s.sessionRepo.RevokeAllForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.RevokeSessionsResponse{Revoked: revoked}, nil
+}
+
+// SetupTwoFactor generates a new TOTP secret for the user. It only takes
+// effect once EnableTwoFactor confirms a code from it.
+func (s *AuthService) SetupTwoFactor(ctx context.Context, userID string) (*model.TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetTOTPSecret(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(secret, totpIssuer, user.Email),
 	}, nil
 }
 
+// EnableTwoFactor turns on two-factor authentication once the user proves
+// their authenticator app works, and returns fresh recovery codes
+func (s *AuthService) EnableTwoFactor(ctx context.Context, userID, code string) (*model.TwoFactorEnableResponse, error) {
+	secret, enabled, err := s.userRepo.FindTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("set up two-factor authentication first")
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("invalid two-factor code")
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+		}
+		code := hex.EncodeToString(buf)
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	if err := s.userRepo.EnableTOTP(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorEnableResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor turns off two-factor authentication after checking a code.
+// Admins cannot turn it off while their household requires it.
+func (s *AuthService) DisableTwoFactor(ctx context.Context, userID, code string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	required, err := s.twoFactorRequired(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return fmt.Errorf("two-factor authentication is required for admins")
+	}
+
+	if err := s.checkSecondFactor(ctx, userID, code); err != nil {
+		return err
+	}
+
+	return s.userRepo.DisableTOTP(ctx, userID)
+}
+
+// checkSecondFactor accepts a current TOTP code that was not used before, or
+// an unused recovery code, which is then used up. After maxTOTPAttempts wrong
+// codes in a row no code is accepted for totpLockout.
+func (s *AuthService) checkSecondFactor(ctx context.Context, userID, code string) error {
+	secret, enabled, err := s.userRepo.FindTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	lockedUntil, err := s.userRepo.FindTOTPLock(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if lockedUntil != nil {
+		return ErrTwoFactorLocked
+	}
+
+	ok, err := s.useSecondFactor(ctx, userID, secret, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err := s.userRepo.RecordTOTPFailure(ctx, userID, maxTOTPAttempts, totpLockout); err != nil {
+			return err
+		}
+		return fmt.Errorf("invalid two-factor code")
+	}
+
+	return s.userRepo.ResetTOTPFailures(ctx, userID)
+}
+
+// useSecondFactor uses up the TOTP code or recovery code, reporting false if
+// it is neither
+func (s *AuthService) useSecondFactor(ctx context.Context, userID, secret, code string) (bool, error) {
+	if step, ok := totp.Validate(secret, code, time.Now()); ok {
+		return s.userRepo.UseTOTPStep(ctx, userID, step)
+	}
+
+	return s.userRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+}
+
+// twoFactorRequired reports whether the user's household requires two-factor
+// authentication for their role
+func (s *AuthService) twoFactorRequired(ctx context.Context, user *model.User) (bool, error) {
+	if user.Role != "admin" {
+		return false, nil
+	}
+
+	household, err := s.householdRepo.FindByID(ctx, user.HouseholdID)
+	if err != nil {
+		return false, err
+	}
+	return household.RequireAdminTwoFactor, nil
+}
+
+// hashRecoveryCode returns the form a recovery code is stored in. Case,
+// spaces and dashes are ignored.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+	return hashToken(code)
+}
+
 // GetUserByID retrieves a user by ID
 func (s *AuthService) GetUserByID(ctx context.Context, userID string) (*model.User, error) {
 	return s.userRepo.FindByID(ctx, userID)
 }
 
-// CreateUser creates a new user with the specified role (admin action)
-func (s *AuthService) CreateUser(ctx context.Context, req *model.CreateUserRequest) (*model.User, error) {
+// CreateUser creates a new user with the specified role in the admin's household
+func (s *AuthService) CreateUser(ctx context.Context, householdID string, req *model.CreateUserRequest) (*model.User, error) {
 	existingUser, _ := s.userRepo.FindByEmail(ctx, req.Email)
 	if existingUser != nil {
 		return nil, fmt.Errorf("user with this email already exists")
 	}
 
-	return s.userRepo.CreateWithRole(ctx, req)
+	var user *model.User
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		created, err := s.userRepo.WithTx(tx).CreateWithRole(ctx, householdID, req)
+		if err != nil {
+			return err
+		}
+
+		user = created
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityUser, created.ID, model.AuditActionCreate, nil, created)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
 }
 
-// ListUsers returns all users
-func (s *AuthService) ListUsers(ctx context.Context) ([]*model.User, error) {
-	return s.userRepo.ListAll(ctx)
+// ListUsers returns all users in a household
+func (s *AuthService) ListUsers(ctx context.Context, householdID string) ([]*model.User, error) {
+	return s.userRepo.ListAll(ctx, householdID)
 }
 
 // UpdateUser updates a user's name and/or role
-func (s *AuthService) UpdateUser(ctx context.Context, userID string, req *model.UpdateUserRequest) (*model.User, error) {
-	return s.userRepo.Update(ctx, userID, req)
+func (s *AuthService) UpdateUser(ctx context.Context, householdID, userID string, req *model.UpdateUserRequest) (*model.User, error) {
+	var user *model.User
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+
+		before, err := findHouseholdUser(ctx, userRepo, householdID, userID)
+		if err != nil {
+			return err
+		}
+
+		updated, err := userRepo.Update(ctx, householdID, userID, req)
+		if err != nil {
+			return err
+		}
+
+		user = updated
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityUser, userID, model.AuditActionUpdate, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SetApprovalThreshold sets the amount above which a child's expenses wait
+// for an admin's approval. A nil threshold removes it.
+func (s *AuthService) SetApprovalThreshold(ctx context.Context, householdID, userID string, threshold *int64) (*model.User, error) {
+	var user *model.User
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+
+		before, err := findHouseholdUser(ctx, userRepo, householdID, userID)
+		if err != nil {
+			return err
+		}
+		if before.Role != "child" && threshold != nil {
+			return ErrApprovalThresholdNotChild
+		}
+
+		updated, err := userRepo.SetApprovalThreshold(ctx, householdID, userID, threshold)
+		if err != nil {
+			return err
+		}
+
+		user = updated
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityUser, userID, model.AuditActionUpdate, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
 }
 
 // DeleteUser deletes a user
-func (s *AuthService) DeleteUser(ctx context.Context, userID string) error {
-	return s.userRepo.Delete(ctx, userID)
+func (s *AuthService) DeleteUser(ctx context.Context, householdID, userID string) error {
+	return s.uow.Do(ctx, func(tx pgx.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+
+		before, err := findHouseholdUser(ctx, userRepo, householdID, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := userRepo.Delete(ctx, householdID, userID); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityUser, userID, model.AuditActionDelete, before, nil)
+	})
+}
+
+// findHouseholdUser returns the user if they belong to the household
+func findHouseholdUser(ctx context.Context, userRepo *repository.UserRepository, householdID, userID string) (*model.User, error) {
+	user, err := userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.HouseholdID != householdID {
+		return nil, fmt.Errorf("user not found")
+	}
+	return user, nil
+}
+
+// startSession stores a new session for the user and returns its refresh token
+func (s *AuthService) startSession(ctx context.Context, sessionRepo *repository.SessionRepository, userID string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(buf)
+
+	if _, err := sessionRepo.Create(ctx, userID, hashToken(refreshToken), time.Now().Add(refreshTokenTTL)); err != nil {
+		return "", err
+	}
+
+	return refreshToken, nil
+}
+
+func (s *AuthService) loginResponse(ctx context.Context, user *model.User, refreshToken string) (*model.LoginResponse, error) {
+	required, err := s.twoFactorRequired(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	setupRequired := required && !user.TwoFactorEnabled
+
+	expiresAt := time.Now().Add(accessTokenTTL)
+	var token string
+	if setupRequired {
+		token, err = s.generateSetupToken(user)
+	} else {
+		token, err = s.generateToken(user)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return &model.LoginResponse{
+		Token:                  token,
+		ExpiresAt:              &expiresAt,
+		RefreshToken:           refreshToken,
+		User:                   *user,
+		TwoFactorSetupRequired: setupRequired,
+	}, nil
+}
+
+// hashToken returns the form a refresh token is stored in
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
-// generateToken creates a JWT token for the user
+func (s *AuthService) accessClaims(user *model.User) jwt.MapClaims {
+	return jwt.MapClaims{
+		"user_id":      user.ID,
+		"household_id": user.HouseholdID,
+		"email":        user.Email,
+		"role":         user.Role,
+		"exp":          time.Now().Add(accessTokenTTL).Unix(),
+		"iat":          time.Now().Unix(),
+	}
+}
+
+// generateToken creates a short-lived JWT access token for the user
 func (s *AuthService) generateToken(user *model.User) (string, error) {
-	claims := jwt.MapClaims{
+	return s.signToken(s.accessClaims(user))
+}
+
+// generateSetupToken creates an access token that only opens the auth
+// endpoints, for an admin who still has to set up two-factor authentication
+func (s *AuthService) generateSetupToken(user *model.User) (string, error) {
+	claims := s.accessClaims(user)
+	claims[TwoFactorSetupClaim] = true
+	return s.signToken(claims)
+}
+
+// generateChallengeToken creates the token that carries a password-checked
+// login over to its two-factor step. It is not an access token.
+func (s *AuthService) generateChallengeToken(user *model.User) (string, error) {
+	return s.signToken(jwt.MapClaims{
 		"user_id": user.ID,
-		"email":   user.Email,
-		"role":    user.Role,
-		"exp":     time.Now().Add(time.Hour * 24 * 7).Unix(), // 7 days
+		"typ":     challengeTokenType,
+		"exp":     time.Now().Add(challengeTokenTTL).Unix(),
 		"iat":     time.Now().Unix(),
-	}
+	})
+}
 
+func (s *AuthService) signToken(claims jwt.MapClaims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	return token.SignedString([]byte(s.jwtSecret))
+}
+
+// ValidateToken validates a JWT access token and returns the claims
+func (s *AuthService) ValidateToken(tokenString string) (jwt.MapClaims, error) {
+	claims, err := s.parseToken(tokenString)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return tokenString, nil
+	// Challenge tokens are signed with the same key but grant no access
+	if _, ok := claims["typ"]; ok {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	return claims, nil
 }
 
-// ValidateToken validates a JWT token and returns the claims
-func (s *AuthService) ValidateToken(tokenString string) (jwt.MapClaims, error) {
+// parseToken checks the signature and expiry of any token the service issues
+func (s *AuthService) parseToken(tokenString string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
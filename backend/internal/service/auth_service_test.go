@@ -12,10 +12,11 @@ func TestGenerateToken(t *testing.T) {
 	}
 
 	user := &model.User{
-		ID:    "test-user-id",
-		Email: "test@example.com",
-		Name:  "Test User",
-		Role:  "admin",
+		ID:          "test-user-id",
+		HouseholdID: "test-household-id",
+		Email:       "test@example.com",
+		Name:        "Test User",
+		Role:        "admin",
 	}
 
 	token, err := authService.generateToken(user)
@@ -36,6 +37,10 @@ func TestGenerateToken(t *testing.T) {
 		t.Errorf("Expected user_id %s, got %v", user.ID, claims["user_id"])
 	}
 
+	if claims["household_id"] != user.HouseholdID {
+		t.Errorf("Expected household_id %s, got %v", user.HouseholdID, claims["household_id"])
+	}
+
 	if claims["email"] != user.Email {
 		t.Errorf("Expected email %s, got %v", user.Email, claims["email"])
 	}
@@ -2,87 +2,347 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
 )
 
+// ErrDeferNotLater is returned when a bill is deferred to a date that is not
+// after its current due date
+var ErrDeferNotLater = errors.New("the new due date must be after the current one")
+
+// billForecastPeriods is how many of the latest paid periods the forecast of
+// a bill's next amount is based on
+const billForecastPeriods = 12
+
 type BillReminderService struct {
-	billReminderRepo *repository.BillReminderRepository
-	transactionRepo  *repository.TransactionRepository
-	accountRepo      *repository.AccountRepository
+	uow                *repository.UnitOfWork
+	billReminderRepo   *repository.BillReminderRepository
+	transactionService *TransactionService
+	auditRepo          *repository.AuditRepository
 }
 
 func NewBillReminderService(
+	uow *repository.UnitOfWork,
 	billReminderRepo *repository.BillReminderRepository,
-	transactionRepo *repository.TransactionRepository,
-	accountRepo *repository.AccountRepository,
+	transactionService *TransactionService,
+	auditRepo *repository.AuditRepository,
 ) *BillReminderService {
 	return &BillReminderService{
-		billReminderRepo: billReminderRepo,
-		transactionRepo:  transactionRepo,
-		accountRepo:      accountRepo,
+		uow:                uow,
+		billReminderRepo:   billReminderRepo,
+		transactionService: transactionService,
+		auditRepo:          auditRepo,
 	}
 }
 
-func (s *BillReminderService) Create(ctx context.Context, req *model.CreateBillReminderRequest) (*model.BillReminder, error) {
-	return s.billReminderRepo.Create(ctx, req)
+func (s *BillReminderService) Create(ctx context.Context, householdID string, req *model.CreateBillReminderRequest) (*model.BillReminder, error) {
+	if req.LeadDays == nil {
+		leadDays := model.DefaultBillLeadDays
+		req.LeadDays = &leadDays
+	}
+
+	var bill *model.BillReminder
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		billReminderRepo := s.billReminderRepo.WithTx(tx)
+
+		created, err := billReminderRepo.Create(ctx, householdID, req)
+		if err != nil {
+			return err
+		}
+
+		if len(req.RecipientIDs) > 0 {
+			if err := billReminderRepo.SetRecipients(ctx, householdID, created.ID, req.RecipientIDs); err != nil {
+				return err
+			}
+			if created, err = billReminderRepo.FindByID(ctx, householdID, created.ID); err != nil {
+				return err
+			}
+		}
+
+		bill = created
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityBillReminder, created.ID, model.AuditActionCreate, nil, created)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bill, nil
 }
 
-func (s *BillReminderService) GetByID(ctx context.Context, id string) (*model.BillReminder, error) {
-	return s.billReminderRepo.FindByID(ctx, id)
+func (s *BillReminderService) GetByID(ctx context.Context, householdID, id string) (*model.BillReminder, error) {
+	return s.billReminderRepo.FindByID(ctx, householdID, id)
 }
 
-func (s *BillReminderService) GetAll(ctx context.Context) ([]*model.BillReminder, error) {
-	return s.billReminderRepo.FindAll(ctx)
+func (s *BillReminderService) GetAll(ctx context.Context, householdID string) ([]*model.BillReminder, error) {
+	return s.billReminderRepo.FindAll(ctx, householdID)
 }
 
-func (s *BillReminderService) GetUpcoming(ctx context.Context, days int) ([]*model.BillReminder, error) {
-	return s.billReminderRepo.FindUpcoming(ctx, days)
+func (s *BillReminderService) GetUpcoming(ctx context.Context, householdID string, days int) ([]*model.BillReminder, error) {
+	return s.billReminderRepo.FindUpcoming(ctx, householdID, days)
 }
 
-func (s *BillReminderService) Update(ctx context.Context, id string, req *model.UpdateBillReminderRequest) (*model.BillReminder, error) {
-	return s.billReminderRepo.Update(ctx, id, req)
+func (s *BillReminderService) Update(ctx context.Context, householdID, id string, req *model.UpdateBillReminderRequest) (*model.BillReminder, error) {
+	var bill *model.BillReminder
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		billReminderRepo := s.billReminderRepo.WithTx(tx)
+
+		before, err := billReminderRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		updated, err := billReminderRepo.Update(ctx, householdID, id, req)
+		if err != nil {
+			return err
+		}
+
+		if req.RecipientIDs != nil {
+			if err := billReminderRepo.SetRecipients(ctx, householdID, id, *req.RecipientIDs); err != nil {
+				return err
+			}
+			if updated, err = billReminderRepo.FindByID(ctx, householdID, id); err != nil {
+				return err
+			}
+		}
+
+		bill = updated
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityBillReminder, id, model.AuditActionUpdate, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bill, nil
 }
 
-func (s *BillReminderService) Delete(ctx context.Context, id string) error {
-	return s.billReminderRepo.Delete(ctx, id)
+func (s *BillReminderService) Delete(ctx context.Context, householdID, id string) error {
+	return s.uow.Do(ctx, func(tx pgx.Tx) error {
+		billReminderRepo := s.billReminderRepo.WithTx(tx)
+
+		before, err := billReminderRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := billReminderRepo.Delete(ctx, householdID, id); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityBillReminder, id, model.AuditActionDelete, before, nil)
+	})
 }
 
-func (s *BillReminderService) Pay(ctx context.Context, userID string, id string, req *model.PayBillRequest) (*model.Transaction, error) {
-	bill, err := s.billReminderRepo.FindByID(ctx, id)
+func (s *BillReminderService) Pay(ctx context.Context, householdID, userID, id string, req *model.PayBillRequest) (*model.Transaction, error) {
+	paidOn, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date format: %w", err)
+	}
+
+	var transaction *model.Transaction
+
+	err = s.uow.Do(ctx, func(tx pgx.Tx) error {
+		billReminderRepo := s.billReminderRepo.WithTx(tx)
+
+		// Locked, so that paying the bill twice at once pays two periods
+		// instead of the same one twice
+		bill, err := billReminderRepo.FindByIDForUpdate(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		// Variable bills are paid with the amount of this period's bill
+		amount := bill.Amount
+		if req.Amount != nil {
+			amount = *req.Amount
+		}
+
+		// Book the payment like any other expense, so that categorization
+		// rules and budget alerts apply to it
+		txReq := &model.CreateTransactionRequest{
+			AccountID:   req.AccountID,
+			Amount:      -amount, // expense
+			Type:        "expense",
+			Description: "Bill payment: " + bill.Name,
+			Date:        req.Date,
+			IsShared:    true,
+		}
+		if bill.CategoryID != nil {
+			txReq.CategoryID = *bill.CategoryID
+		}
+
+		created, err := s.transactionService.CreateTx(ctx, tx, userID, txReq)
+		if err != nil {
+			return err
+		}
+
+		if err := billReminderRepo.CreatePayment(ctx, householdID, id, model.BillPaymentPaid, &created.ID, &amount, &paidOn); err != nil {
+			return err
+		}
+
+		// Advance the next due date
+		if err := billReminderRepo.AdvanceNextDueDate(ctx, householdID, id, bill.Frequency, periodDate(bill)); err != nil {
+			return err
+		}
+
+		paid, err := billReminderRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityBillReminder, id, model.AuditActionPay, bill, paid); err != nil {
+			return err
+		}
+
+		transaction = created
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Create a transaction for the payment
-	txReq := &model.CreateTransactionRequest{
-		AccountID:   req.AccountID,
-		Amount:      -bill.Amount, // expense
-		Type:        "expense",
-		Description: "Bill payment: " + bill.Name,
-		Date:        req.Date,
-		IsShared:    true,
+	s.transactionService.Booked(ctx, transaction)
+	return transaction, nil
+}
+
+// Skip settles the bill's current period without paying it, e.g. when the
+// provider waived it, and advances the due date
+func (s *BillReminderService) Skip(ctx context.Context, householdID, id string) (*model.BillReminder, error) {
+	var bill *model.BillReminder
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		billReminderRepo := s.billReminderRepo.WithTx(tx)
+
+		before, err := billReminderRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := billReminderRepo.CreatePayment(ctx, householdID, id, model.BillPaymentSkipped, nil, nil, nil); err != nil {
+			return err
+		}
+		if err := billReminderRepo.AdvanceNextDueDate(ctx, householdID, id, before.Frequency, periodDate(before)); err != nil {
+			return err
+		}
+
+		skipped, err := billReminderRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		bill = skipped
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityBillReminder, id, model.AuditActionSkip, before, skipped)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if bill.CategoryID != nil {
-		txReq.CategoryID = *bill.CategoryID
+	return bill, nil
+}
+
+// Defer puts the bill's current period off to a later date. Once it is paid
+// or skipped, the schedule carries on from the date it was originally due.
+func (s *BillReminderService) Defer(ctx context.Context, householdID, id string, req *model.DeferBillRequest) (*model.BillReminder, error) {
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date format: %w", err)
 	}
 
-	transaction, err := s.transactionRepo.Create(ctx, userID, txReq)
+	var bill *model.BillReminder
+
+	err = s.uow.Do(ctx, func(tx pgx.Tx) error {
+		billReminderRepo := s.billReminderRepo.WithTx(tx)
+
+		before, err := billReminderRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+		if !date.After(before.NextDueDate) {
+			return ErrDeferNotLater
+		}
+
+		if err := billReminderRepo.Defer(ctx, householdID, id, date); err != nil {
+			return err
+		}
+
+		deferred, err := billReminderRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		bill = deferred
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityBillReminder, id, model.AuditActionDefer, before, deferred)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Update account balance
-	if err := s.accountRepo.UpdateBalance(ctx, req.AccountID, -bill.Amount); err != nil {
+	return bill, nil
+}
+
+// GetHistory returns how each period of the bill was settled, with the
+// average and last paid amounts and the forecast of the next one
+func (s *BillReminderService) GetHistory(ctx context.Context, householdID, id string) (*model.BillHistory, error) {
+	bill, err := s.billReminderRepo.FindByID(ctx, householdID, id)
+	if err != nil {
 		return nil, err
 	}
 
-	// Advance the next due date
-	if err := s.billReminderRepo.AdvanceNextDueDate(ctx, id, bill.Frequency, bill.NextDueDate); err != nil {
+	payments, err := s.billReminderRepo.FindPayments(ctx, householdID, id)
+	if err != nil {
 		return nil, err
 	}
 
-	return transaction, nil
+	var paid []int64
+	for _, p := range payments {
+		if p.Status == model.BillPaymentPaid && p.Amount != nil && len(paid) < billForecastPeriods {
+			paid = append(paid, *p.Amount)
+		}
+	}
+
+	history := &model.BillHistory{Payments: payments, ForecastAmount: bill.Amount}
+	if len(paid) > 0 {
+		average, last := averageAmount(paid), paid[0]
+		history.AverageAmount = &average
+		history.LastPaidAmount = &last
+		history.ForecastAmount = forecastBillAmount(average, last)
+	}
+
+	return history, nil
+}
+
+// periodDate returns the date the bill's current period was scheduled for,
+// before any deferral
+func periodDate(bill *model.BillReminder) time.Time {
+	if bill.DeferredFrom != nil {
+		return *bill.DeferredFrom
+	}
+	return bill.NextDueDate
+}
+
+// averageAmount returns the mean of the amounts, rounded to the nearest cent
+func averageAmount(amounts []int64) int64 {
+	var total int64
+	for _, a := range amounts {
+		total += a
+	}
+	n := int64(len(amounts))
+	return (total + n/2) / n
+}
+
+// forecastBillAmount expects the next period of a bill halfway between its
+// average and its last paid amount, so a recent rise or drop counts for as
+// much as the long-run level
+func forecastBillAmount(average, last int64) int64 {
+	return (average + last + 1) / 2
+}
+
+// MarkOverdue flags bills across all households whose due date is before today
+func (s *BillReminderService) MarkOverdue(ctx context.Context, today time.Time) (int, error) {
+	return s.billReminderRepo.MarkOverdue(ctx, today)
 }
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+)
+
+func TestAverageAmount(t *testing.T) {
+	tests := []struct {
+		amounts []int64
+		want    int64
+	}{
+		{[]int64{8000}, 8000},
+		{[]int64{8000, 9000, 10000}, 9000},
+		{[]int64{8000, 8001}, 8001},
+		{[]int64{7999, 8000, 8000}, 8000},
+	}
+
+	for _, tt := range tests {
+		if got := averageAmount(tt.amounts); got != tt.want {
+			t.Errorf("averageAmount(%v) = %d, want %d", tt.amounts, got, tt.want)
+		}
+	}
+}
+
+func TestForecastBillAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		average int64
+		last    int64
+		want    int64
+	}{
+		{"steady", 8000, 8000, 8000},
+		{"last higher", 8000, 12000, 10000},
+		{"last lower", 9000, 6000, 7500},
+		{"rounds half up", 8000, 8001, 8001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := forecastBillAmount(tt.average, tt.last); got != tt.want {
+				t.Errorf("forecastBillAmount(%d, %d) = %d, want %d", tt.average, tt.last, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriodDate(t *testing.T) {
+	due := time.Date(2026, 3, 25, 0, 0, 0, 0, time.UTC)
+	original := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	if got := periodDate(&model.BillReminder{NextDueDate: due}); !got.Equal(due) {
+		t.Errorf("expected %v for a bill on schedule, got %v", due, got)
+	}
+	if got := periodDate(&model.BillReminder{NextDueDate: due, DeferredFrom: &original}); !got.Equal(original) {
+		t.Errorf("expected %v for a deferred bill, got %v", original, got)
+	}
+}
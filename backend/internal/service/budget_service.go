@@ -5,36 +5,156 @@ import (
 
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
 )
 
 type BudgetService struct {
+	uow        *repository.UnitOfWork
 	budgetRepo *repository.BudgetRepository
+	auditRepo  *repository.AuditRepository
 }
 
-func NewBudgetService(budgetRepo *repository.BudgetRepository) *BudgetService {
-	return &BudgetService{budgetRepo: budgetRepo}
+func NewBudgetService(uow *repository.UnitOfWork, budgetRepo *repository.BudgetRepository, auditRepo *repository.AuditRepository) *BudgetService {
+	return &BudgetService{uow: uow, budgetRepo: budgetRepo, auditRepo: auditRepo}
 }
 
-func (s *BudgetService) Create(ctx context.Context, req *model.CreateBudgetRequest) (*model.Budget, error) {
-	return s.budgetRepo.Create(ctx, req)
+func (s *BudgetService) Create(ctx context.Context, householdID string, req *model.CreateBudgetRequest) (*model.Budget, error) {
+	if req.Rollover == "" {
+		req.Rollover = model.RolloverNone
+	}
+
+	var budget *model.Budget
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		created, err := s.budgetRepo.WithTx(tx).Create(ctx, householdID, req)
+		if err != nil {
+			return err
+		}
+
+		budget = created
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityBudget, created.ID, model.AuditActionCreate, nil, created)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return budget, nil
 }
 
-func (s *BudgetService) GetByID(ctx context.Context, id string) (*model.Budget, error) {
-	return s.budgetRepo.FindByID(ctx, id)
+func (s *BudgetService) GetByID(ctx context.Context, householdID, id string) (*model.Budget, error) {
+	return s.budgetRepo.FindByID(ctx, householdID, id)
 }
 
-func (s *BudgetService) GetAll(ctx context.Context, filters *model.BudgetFilters) ([]*model.Budget, error) {
-	return s.budgetRepo.FindAll(ctx, filters)
+// GetAll lists budgets. Listing a single month includes the budgets its
+// templates call for.
+func (s *BudgetService) GetAll(ctx context.Context, householdID string, filters *model.BudgetFilters) ([]*model.Budget, error) {
+	return s.budgetRepo.FindAll(ctx, householdID, filters)
 }
 
-func (s *BudgetService) Update(ctx context.Context, id string, req *model.UpdateBudgetRequest) (*model.Budget, error) {
-	return s.budgetRepo.Update(ctx, id, req)
+func (s *BudgetService) Update(ctx context.Context, householdID, id string, req *model.UpdateBudgetRequest) (*model.Budget, error) {
+	var budget *model.Budget
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		budgetRepo := s.budgetRepo.WithTx(tx)
+
+		before, err := budgetRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		updated, err := budgetRepo.Update(ctx, householdID, id, req)
+		if err != nil {
+			return err
+		}
+
+		budget = updated
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityBudget, id, model.AuditActionUpdate, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return budget, nil
 }
 
-func (s *BudgetService) Delete(ctx context.Context, id string) error {
-	return s.budgetRepo.Delete(ctx, id)
+func (s *BudgetService) Delete(ctx context.Context, householdID, id string) error {
+	return s.uow.Do(ctx, func(tx pgx.Tx) error {
+		budgetRepo := s.budgetRepo.WithTx(tx)
+
+		before, err := budgetRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := budgetRepo.Delete(ctx, householdID, id); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityBudget, id, model.AuditActionDelete, before, nil)
+	})
 }
 
-func (s *BudgetService) GetSummary(ctx context.Context, month, year int) ([]*model.BudgetSummary, error) {
-	return s.budgetRepo.GetSummary(ctx, month, year)
+// GetSummary compares the month's budgets, including those its templates call
+// for, with what was spent
+func (s *BudgetService) GetSummary(ctx context.Context, householdID string, month, year int) ([]*model.BudgetSummary, error) {
+	summaries, err := s.budgetRepo.GetSummary(ctx, householdID, month, year)
+	return summaries, exchangeRateError(err)
+}
+
+// GetCategorySummary is GetSummary for just the budgets that count spending in
+// any of the given categories, including those of the categories above them
+func (s *BudgetService) GetCategorySummary(ctx context.Context, householdID string, month, year int, categoryIDs []string) ([]*model.BudgetSummary, error) {
+	summaries, err := s.budgetRepo.GetCategorySummary(ctx, householdID, month, year, categoryIDs)
+	return summaries, exchangeRateError(err)
+}
+
+// CopyFromPreviousMonth fills the month with the budgets of the month before,
+// for every category that has no budget in it yet, from a template or
+// otherwise. It returns the budgets it created.
+func (s *BudgetService) CopyFromPreviousMonth(ctx context.Context, householdID string, month, year int) ([]*model.Budget, error) {
+	var copied []*model.Budget
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		budgetRepo := s.budgetRepo.WithTx(tx)
+		auditRepo := s.auditRepo.WithTx(tx)
+
+		if err := materializeBudgetTemplates(ctx, budgetRepo, auditRepo, householdID, month, year); err != nil {
+			return err
+		}
+
+		created, err := budgetRepo.CopyFromPreviousMonth(ctx, householdID, month, year)
+		if err != nil {
+			return err
+		}
+
+		for _, budget := range created {
+			if err := recordAudit(ctx, auditRepo, householdID, model.AuditEntityBudget, budget.ID, model.AuditActionCreate, nil, budget); err != nil {
+				return err
+			}
+		}
+
+		copied = created
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copied, nil
+}
+
+// materializeBudgetTemplates creates the budgets the templates call for up to
+// the given month and records each in the audit log
+func materializeBudgetTemplates(ctx context.Context, budgetRepo *repository.BudgetRepository, auditRepo *repository.AuditRepository, householdID string, month, year int) error {
+	created, err := budgetRepo.MaterializeTemplates(ctx, householdID, month, year)
+	if err != nil {
+		return err
+	}
+
+	for _, budget := range created {
+		if err := recordAudit(ctx, auditRepo, householdID, model.AuditEntityBudget, budget.ID, model.AuditActionCreate, nil, budget); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+type BudgetTemplateService struct {
+	uow                *repository.UnitOfWork
+	budgetTemplateRepo *repository.BudgetTemplateRepository
+	budgetRepo         *repository.BudgetRepository
+	auditRepo          *repository.AuditRepository
+}
+
+func NewBudgetTemplateService(uow *repository.UnitOfWork, budgetTemplateRepo *repository.BudgetTemplateRepository, budgetRepo *repository.BudgetRepository, auditRepo *repository.AuditRepository) *BudgetTemplateService {
+	return &BudgetTemplateService{uow: uow, budgetTemplateRepo: budgetTemplateRepo, budgetRepo: budgetRepo, auditRepo: auditRepo}
+}
+
+func (s *BudgetTemplateService) Create(ctx context.Context, householdID string, req *model.CreateBudgetTemplateRequest) (*model.BudgetTemplate, error) {
+	if err := validateBudgetTemplate(req); err != nil {
+		return nil, err
+	}
+
+	var template *model.BudgetTemplate
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		created, err := s.budgetTemplateRepo.WithTx(tx).Create(ctx, householdID, req)
+		if err != nil {
+			return err
+		}
+
+		template = created
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityBudgetTemplate, created.ID, model.AuditActionCreate, nil, created)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (s *BudgetTemplateService) GetByID(ctx context.Context, householdID, id string) (*model.BudgetTemplate, error) {
+	return s.budgetTemplateRepo.FindByID(ctx, householdID, id)
+}
+
+func (s *BudgetTemplateService) GetAll(ctx context.Context, householdID string) ([]*model.BudgetTemplate, error) {
+	return s.budgetTemplateRepo.FindAll(ctx, householdID)
+}
+
+// Update replaces the template. The budgets it called for up to the current
+// month are stored first and keep their amounts; later months follow the
+// change.
+func (s *BudgetTemplateService) Update(ctx context.Context, householdID, id string, req *model.UpdateBudgetTemplateRequest) (*model.BudgetTemplate, error) {
+	if err := validateBudgetTemplate(req); err != nil {
+		return nil, err
+	}
+
+	var template *model.BudgetTemplate
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		budgetTemplateRepo := s.budgetTemplateRepo.WithTx(tx)
+
+		before, err := budgetTemplateRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.keepBudgetsSoFar(ctx, tx, householdID); err != nil {
+			return err
+		}
+
+		updated, err := budgetTemplateRepo.Update(ctx, householdID, id, req)
+		if err != nil {
+			return err
+		}
+
+		template = updated
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityBudgetTemplate, id, model.AuditActionUpdate, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// Delete removes the template. Like Update, it keeps the budgets it called for
+// up to the current month.
+func (s *BudgetTemplateService) Delete(ctx context.Context, householdID, id string) error {
+	return s.uow.Do(ctx, func(tx pgx.Tx) error {
+		budgetTemplateRepo := s.budgetTemplateRepo.WithTx(tx)
+
+		before, err := budgetTemplateRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.keepBudgetsSoFar(ctx, tx, householdID); err != nil {
+			return err
+		}
+
+		if err := budgetTemplateRepo.Delete(ctx, householdID, id); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityBudgetTemplate, id, model.AuditActionDelete, before, nil)
+	})
+}
+
+// keepBudgetsSoFar stores the budgets the templates call for up to the current
+// month, so that changing a template leaves the months gone by alone
+func (s *BudgetTemplateService) keepBudgetsSoFar(ctx context.Context, tx pgx.Tx, householdID string) error {
+	now := time.Now()
+	return materializeBudgetTemplates(ctx, s.budgetRepo.WithTx(tx), s.auditRepo.WithTx(tx), householdID, int(now.Month()), now.Year())
+}
+
+// validateBudgetTemplate checks the rules the request tags can't express and
+// fills in the default rollover
+func validateBudgetTemplate(req *model.CreateBudgetTemplateRequest) error {
+	if (req.Amount == nil) == (req.AnnualAmount == nil) {
+		return fmt.Errorf("either a monthly or an annual amount is required")
+	}
+
+	if (req.EndMonth == nil) != (req.EndYear == nil) {
+		return fmt.Errorf("end month and end year go together")
+	}
+	if req.EndMonth != nil && *req.EndYear*12+*req.EndMonth < req.StartYear*12+req.StartMonth {
+		return fmt.Errorf("end month is before start month")
+	}
+
+	if req.Rollover == "" {
+		req.Rollover = model.RolloverNone
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+type CategorizationRuleService struct {
+	uow             *repository.UnitOfWork
+	ruleRepo        *repository.CategorizationRuleRepository
+	transactionRepo *repository.TransactionRepository
+	accountRepo     *repository.AccountRepository
+	categoryRepo    *repository.CategoryRepository
+	auditRepo       *repository.AuditRepository
+}
+
+func NewCategorizationRuleService(
+	uow *repository.UnitOfWork,
+	ruleRepo *repository.CategorizationRuleRepository,
+	transactionRepo *repository.TransactionRepository,
+	accountRepo *repository.AccountRepository,
+	categoryRepo *repository.CategoryRepository,
+	auditRepo *repository.AuditRepository,
+) *CategorizationRuleService {
+	return &CategorizationRuleService{
+		uow:             uow,
+		ruleRepo:        ruleRepo,
+		transactionRepo: transactionRepo,
+		accountRepo:     accountRepo,
+		categoryRepo:    categoryRepo,
+		auditRepo:       auditRepo,
+	}
+}
+
+func (s *CategorizationRuleService) Create(ctx context.Context, householdID string, req *model.CreateCategorizationRuleRequest) (*model.CategorizationRule, error) {
+	if err := s.validate(ctx, householdID, req); err != nil {
+		return nil, err
+	}
+	return s.ruleRepo.Create(ctx, householdID, req)
+}
+
+func (s *CategorizationRuleService) GetByID(ctx context.Context, householdID, id string) (*model.CategorizationRule, error) {
+	return s.ruleRepo.FindByID(ctx, householdID, id)
+}
+
+func (s *CategorizationRuleService) GetAll(ctx context.Context, householdID string) ([]*model.CategorizationRule, error) {
+	return s.ruleRepo.FindAll(ctx, householdID)
+}
+
+func (s *CategorizationRuleService) Update(ctx context.Context, householdID, id string, req *model.UpdateCategorizationRuleRequest) (*model.CategorizationRule, error) {
+	if err := s.validate(ctx, householdID, req); err != nil {
+		return nil, err
+	}
+	return s.ruleRepo.Update(ctx, householdID, id, req)
+}
+
+func (s *CategorizationRuleService) Delete(ctx context.Context, householdID, id string) error {
+	return s.ruleRepo.Delete(ctx, householdID, id)
+}
+
+// ApplyToUncategorized runs the active rules over the household's
+// transactions that have no category yet. Each transaction a rule changes is
+// updated and audited on its own.
+func (s *CategorizationRuleService) ApplyToUncategorized(ctx context.Context, householdID string) (*model.ApplyRulesResponse, error) {
+	rules, err := s.ruleRepo.FindActive(ctx, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.transactionRepo.FindUncategorized(ctx, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ApplyRulesResponse{Checked: len(transactions)}
+	if len(rules) == 0 {
+		return result, nil
+	}
+
+	set := newRuleSet(rules)
+	for _, t := range transactions {
+		outcome := set.apply(ruleSubject{
+			AccountID:    t.AccountID,
+			Amount:       t.Amount,
+			Description:  t.Description,
+			Counterparty: t.Counterparty,
+		})
+		if !outcome.matched {
+			continue
+		}
+
+		update := &model.UpdateTransactionRequest{CategoryID: outcome.categoryID}
+		if len(outcome.tags) > 0 {
+			update.Tags = mergeTags(t.Tags, outcome.tags)
+		}
+		if outcome.isShared != nil && *outcome.isShared != t.IsShared {
+			update.IsShared = outcome.isShared
+		}
+		if update.CategoryID == nil && update.Tags == nil && update.IsShared == nil {
+			continue
+		}
+
+		err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+			transactionRepo := s.transactionRepo.WithTx(tx)
+
+			before, err := transactionRepo.FindByID(ctx, householdID, t.ID)
+			if err != nil {
+				return err
+			}
+
+			updated, err := transactionRepo.Update(ctx, householdID, t.ID, update)
+			if err != nil {
+				return err
+			}
+
+			return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityTransaction, t.ID, model.AuditActionUpdate, before, updated)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if update.CategoryID != nil {
+			result.Categorized++
+		}
+	}
+
+	return result, nil
+}
+
+func (s *CategorizationRuleService) validate(ctx context.Context, householdID string, req *model.CreateCategorizationRuleRequest) error {
+	if req.DescriptionPattern == nil && req.MinAmount == nil && req.MaxAmount == nil && req.AccountID == nil && req.Counterparty == nil {
+		return fmt.Errorf("at least one condition is required")
+	}
+	if req.CategoryID == nil && len(req.AddTags) == 0 && req.SetShared == nil {
+		return fmt.Errorf("at least one action is required")
+	}
+
+	if req.DescriptionMatch == "" {
+		req.DescriptionMatch = model.DescriptionMatchContains
+	}
+	if req.DescriptionPattern != nil && req.DescriptionMatch == model.DescriptionMatchRegex {
+		if _, err := regexp.Compile(*req.DescriptionPattern); err != nil {
+			return fmt.Errorf("invalid description pattern: %w", err)
+		}
+	}
+
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		return fmt.Errorf("minimum amount cannot be greater than maximum amount")
+	}
+
+	// Unknown IDs would otherwise be stored as no account or category at all
+	if req.AccountID != nil {
+		if _, err := s.accountRepo.FindByID(ctx, householdID, *req.AccountID); err != nil {
+			return err
+		}
+	}
+	if req.CategoryID != nil {
+		if _, err := s.categoryRepo.FindByID(ctx, householdID, *req.CategoryID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ruleSubject holds the transaction fields that rules match against
+type ruleSubject struct {
+	AccountID    string
+	Amount       int64
+	Description  string
+	Counterparty string
+}
+
+// ruleOutcome is what the matching rules of a ruleSet ask to change
+type ruleOutcome struct {
+	matched    bool
+	categoryID *string
+	tags       []string
+	isShared   *bool
+}
+
+// ruleSet holds a household's active rules in priority order, with their
+// description patterns compiled once
+type ruleSet struct {
+	rules    []*model.CategorizationRule
+	patterns map[string]*regexp.Regexp
+}
+
+func newRuleSet(rules []*model.CategorizationRule) *ruleSet {
+	set := &ruleSet{rules: rules, patterns: map[string]*regexp.Regexp{}}
+	for _, rule := range rules {
+		if rule.DescriptionPattern == nil || rule.DescriptionMatch != model.DescriptionMatchRegex {
+			continue
+		}
+		// Patterns are checked on save, but a rule that no longer compiles
+		// should not stop the others from running
+		if re, err := regexp.Compile(*rule.DescriptionPattern); err == nil {
+			set.patterns[rule.ID] = re
+		}
+	}
+	return set
+}
+
+// apply runs every rule against subj. The category and the shared flag come
+// from the first matching rule that sets them; tags from all matching rules
+// are collected.
+func (s *ruleSet) apply(subj ruleSubject) ruleOutcome {
+	var outcome ruleOutcome
+	for _, rule := range s.rules {
+		if !s.matches(rule, subj) {
+			continue
+		}
+		outcome.matched = true
+
+		if outcome.categoryID == nil && rule.CategoryID != nil {
+			outcome.categoryID = rule.CategoryID
+		}
+		if outcome.isShared == nil && rule.SetShared != nil {
+			outcome.isShared = rule.SetShared
+		}
+		outcome.tags = mergeTags(outcome.tags, rule.AddTags)
+	}
+	return outcome
+}
+
+func (s *ruleSet) matches(rule *model.CategorizationRule, subj ruleSubject) bool {
+	if rule.DescriptionPattern != nil {
+		if rule.DescriptionMatch == model.DescriptionMatchRegex {
+			re, ok := s.patterns[rule.ID]
+			if !ok || !re.MatchString(subj.Description) {
+				return false
+			}
+		} else if !containsFold(subj.Description, *rule.DescriptionPattern) {
+			return false
+		}
+	}
+
+	amount := absCents(subj.Amount)
+	if rule.MinAmount != nil && amount < *rule.MinAmount {
+		return false
+	}
+	if rule.MaxAmount != nil && amount > *rule.MaxAmount {
+		return false
+	}
+
+	if rule.AccountID != nil && *rule.AccountID != subj.AccountID {
+		return false
+	}
+
+	if rule.Counterparty != nil && !containsFold(subj.Counterparty, *rule.Counterparty) {
+		return false
+	}
+
+	return true
+}
+
+// applyTo fills in the fields of req the outcome sets. A category chosen by
+// the user is kept.
+func (o ruleOutcome) applyTo(req *model.CreateTransactionRequest) {
+	if req.CategoryID == "" && o.categoryID != nil {
+		req.CategoryID = *o.categoryID
+	}
+	if o.isShared != nil {
+		req.IsShared = *o.isShared
+	}
+	if len(o.tags) > 0 {
+		req.Tags = mergeTags(req.Tags, o.tags)
+	}
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// mergeTags appends the tags in extra that are not in tags yet
+func mergeTags(tags, extra []string) []string {
+	merged := append([]string(nil), tags...)
+	for _, tag := range extra {
+		found := false
+		for _, t := range merged {
+			if t == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
@@ -2,11 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/asilingas/fambudg/backend/internal/repository"
 )
 
+// ErrCategoryInUse is returned when deleting a category that transactions,
+// splits or budgets still use
+var ErrCategoryInUse = errors.New("category is in use by transactions or budgets")
+
 type CategoryService struct {
 	categoryRepo *repository.CategoryRepository
 }
@@ -15,22 +20,31 @@ func NewCategoryService(categoryRepo *repository.CategoryRepository) *CategorySe
 	return &CategoryService{categoryRepo: categoryRepo}
 }
 
-func (s *CategoryService) Create(ctx context.Context, req *model.CreateCategoryRequest) (*model.Category, error) {
-	return s.categoryRepo.Create(ctx, req)
+func (s *CategoryService) Create(ctx context.Context, householdID string, req *model.CreateCategoryRequest) (*model.Category, error) {
+	return s.categoryRepo.Create(ctx, householdID, req)
 }
 
-func (s *CategoryService) GetAll(ctx context.Context) ([]*model.Category, error) {
-	return s.categoryRepo.FindAll(ctx)
+func (s *CategoryService) GetAll(ctx context.Context, householdID string) ([]*model.Category, error) {
+	return s.categoryRepo.FindAll(ctx, householdID)
 }
 
-func (s *CategoryService) GetByID(ctx context.Context, id string) (*model.Category, error) {
-	return s.categoryRepo.FindByID(ctx, id)
+func (s *CategoryService) GetByID(ctx context.Context, householdID, id string) (*model.Category, error) {
+	return s.categoryRepo.FindByID(ctx, householdID, id)
 }
 
-func (s *CategoryService) Update(ctx context.Context, id string, req *model.UpdateCategoryRequest) (*model.Category, error) {
-	return s.categoryRepo.Update(ctx, id, req)
+func (s *CategoryService) Update(ctx context.Context, householdID, id string, req *model.UpdateCategoryRequest) (*model.Category, error) {
+	return s.categoryRepo.Update(ctx, householdID, id, req)
 }
 
-func (s *CategoryService) Delete(ctx context.Context, id string) error {
-	return s.categoryRepo.Delete(ctx, id)
+// Delete moves a category to the trash once nothing outside the trash uses it
+func (s *CategoryService) Delete(ctx context.Context, householdID, id string) error {
+	inUse, err := s.categoryRepo.IsInUse(ctx, householdID, id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrCategoryInUse
+	}
+
+	return s.categoryRepo.Delete(ctx, householdID, id)
 }
@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrChoreNotChild is returned when a chore is assigned to someone who
+	// is not a child of the household
+	ErrChoreNotChild = errors.New("chores can only be assigned to children")
+
+	// ErrChoreNotAssigned is returned when a child marks done a chore
+	// assigned to another child
+	ErrChoreNotAssigned = errors.New("this chore is not assigned to you")
+
+	// ErrChoreInactive is returned when marking done a chore that was retired
+	ErrChoreInactive = errors.New("this chore is no longer active")
+
+	// ErrChoreAlreadyDone is returned when the child already did the chore
+	// in the same day, week or month, or at all for a one-off chore
+	ErrChoreAlreadyDone = errors.New("this chore has already been done for this period")
+
+	// ErrChoreDateInFuture is returned when a chore is marked done for a date
+	// after today
+	ErrChoreDateInFuture = errors.New("a chore cannot be marked done for a future date")
+
+	// ErrChoreDateTooEarly is returned when a chore is marked done for a date
+	// before its current day, week or month, or more than a week ago for a
+	// one-off chore
+	ErrChoreDateTooEarly = errors.New("this chore can no longer be marked done for that date")
+
+	// ErrChoreReviewed is returned when approving or turning down a chore
+	// that was already reviewed
+	ErrChoreReviewed = errors.New("this chore has already been reviewed")
+
+	// ErrChoreAccountRequired is returned when approving a chore for a child
+	// with no account picked and no allowance paid into one
+	ErrChoreAccountRequired = errors.New("an account to pay the reward into is required")
+
+	// ErrChoreNotChildAccount is returned when a reward would be paid into an
+	// account that is not the child's
+	ErrChoreNotChildAccount = errors.New("the reward must be paid into one of the child's accounts")
+)
+
+// choreBackdateDays is how many days back a one-off chore can be marked done
+const choreBackdateDays = 7
+
+type ChoreService struct {
+	uow                *repository.UnitOfWork
+	choreRepo          *repository.ChoreRepository
+	userRepo           *repository.UserRepository
+	allowanceRepo      *repository.AllowanceRepository
+	transactionService *TransactionService
+	accountRepo        *repository.AccountRepository
+	auditRepo          *repository.AuditRepository
+}
+
+func NewChoreService(
+	uow *repository.UnitOfWork,
+	choreRepo *repository.ChoreRepository,
+	userRepo *repository.UserRepository,
+	allowanceRepo *repository.AllowanceRepository,
+	transactionService *TransactionService,
+	accountRepo *repository.AccountRepository,
+	auditRepo *repository.AuditRepository,
+) *ChoreService {
+	return &ChoreService{
+		uow:                uow,
+		choreRepo:          choreRepo,
+		userRepo:           userRepo,
+		allowanceRepo:      allowanceRepo,
+		transactionService: transactionService,
+		accountRepo:        accountRepo,
+		auditRepo:          auditRepo,
+	}
+}
+
+func (s *ChoreService) Create(ctx context.Context, householdID string, req *model.CreateChoreRequest) (*model.Chore, error) {
+	if req.AssignedTo != nil {
+		if err := s.requireChild(ctx, householdID, *req.AssignedTo); err != nil {
+			return nil, err
+		}
+	}
+
+	var chore *model.Chore
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		created, err := s.choreRepo.WithTx(tx).Create(ctx, householdID, req)
+		if err != nil {
+			return err
+		}
+
+		chore = created
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityChore, created.ID, model.AuditActionCreate, nil, created)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chore, nil
+}
+
+func (s *ChoreService) GetByID(ctx context.Context, householdID, id string) (*model.Chore, error) {
+	return s.choreRepo.FindByID(ctx, householdID, id)
+}
+
+// GetAll returns the household's chores. If userID is set, only the active
+// ones that child can do are returned.
+func (s *ChoreService) GetAll(ctx context.Context, householdID string, userID *string) ([]*model.Chore, error) {
+	return s.choreRepo.FindAll(ctx, householdID, userID)
+}
+
+func (s *ChoreService) Update(ctx context.Context, householdID, id string, req *model.UpdateChoreRequest) (*model.Chore, error) {
+	if req.AssignedTo != nil && *req.AssignedTo != "" {
+		if err := s.requireChild(ctx, householdID, *req.AssignedTo); err != nil {
+			return nil, err
+		}
+	}
+
+	var chore *model.Chore
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		choreRepo := s.choreRepo.WithTx(tx)
+
+		before, err := choreRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		updated, err := choreRepo.Update(ctx, householdID, id, req)
+		if err != nil {
+			return err
+		}
+
+		chore = updated
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityChore, id, model.AuditActionUpdate, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chore, nil
+}
+
+func (s *ChoreService) Delete(ctx context.Context, householdID, id string) error {
+	return s.uow.Do(ctx, func(tx pgx.Tx) error {
+		choreRepo := s.choreRepo.WithTx(tx)
+
+		before, err := choreRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := choreRepo.Delete(ctx, householdID, id); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityChore, id, model.AuditActionDelete, before, nil)
+	})
+}
+
+// Complete marks the chore done by the child, waiting for a parent to
+// approve it. A chore can be done once per day, week or month as set, and a
+// one-off chore only once; turned down attempts do not count. It can be
+// marked done for today or earlier in its current period, or up to a week
+// back for a one-off chore.
+func (s *ChoreService) Complete(ctx context.Context, householdID, userID, id string, req *model.CompleteChoreRequest, today time.Time) (*model.ChoreCompletion, error) {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	date := today
+	if req.Date != nil {
+		parsed, err := contributionDate(req.Date)
+		if err != nil {
+			return nil, err
+		}
+		date = parsed
+	}
+
+	var completion *model.ChoreCompletion
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		choreRepo := s.choreRepo.WithTx(tx)
+
+		// Locked, so that marking the chore done twice at once cannot record
+		// it twice for the same period
+		chore, err := choreRepo.FindByIDForUpdate(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+		if !chore.IsActive {
+			return ErrChoreInactive
+		}
+		if chore.AssignedTo != nil && *chore.AssignedTo != userID {
+			return ErrChoreNotAssigned
+		}
+		if err := checkChoreDate(chore.Frequency, date, today); err != nil {
+			return err
+		}
+
+		start, end := chorePeriod(chore.Frequency, date)
+		done, err := choreRepo.CountCompletions(ctx, chore.ID, userID, start, end)
+		if err != nil {
+			return err
+		}
+		if done > 0 {
+			return ErrChoreAlreadyDone
+		}
+
+		created, err := choreRepo.CreateCompletion(ctx, householdID, chore.ID, userID, date)
+		if err != nil {
+			return err
+		}
+
+		completion = created
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityChoreCompletion, created.ID, model.AuditActionCreate, nil, created)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return completion, nil
+}
+
+// GetCompletions returns the household's chores marked done, latest first
+func (s *ChoreService) GetCompletions(ctx context.Context, householdID string, filters *model.ChoreCompletionFilters) ([]*model.ChoreCompletion, error) {
+	return s.choreRepo.FindCompletions(ctx, householdID, filters)
+}
+
+func (s *ChoreService) GetCompletion(ctx context.Context, householdID, id string) (*model.ChoreCompletion, error) {
+	return s.choreRepo.FindCompletionByID(ctx, householdID, id)
+}
+
+// Approve accepts a chore marked done and pays its reward with an income
+// transaction on the child's account: the one picked, or else the one the
+// child's allowance is paid into
+func (s *ChoreService) Approve(ctx context.Context, householdID, reviewerID, id string, req *model.ApproveChoreRequest) (*model.ChoreCompletion, error) {
+	var completion *model.ChoreCompletion
+	var reward *model.Transaction
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		choreRepo := s.choreRepo.WithTx(tx)
+		accountRepo := s.accountRepo.WithTx(tx)
+		auditRepo := s.auditRepo.WithTx(tx)
+
+		before, err := choreRepo.FindCompletionByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+		if before.Status != model.ChorePending {
+			return ErrChoreReviewed
+		}
+
+		accountID := req.AccountID
+		if accountID == nil {
+			allowances, err := s.allowanceRepo.WithTx(tx).ListAll(ctx, householdID)
+			if err != nil {
+				return err
+			}
+			for _, a := range allowances {
+				if a.UserID == before.UserID {
+					accountID = a.ToAccountID
+				}
+			}
+		}
+		if accountID == nil {
+			return ErrChoreAccountRequired
+		}
+
+		account, err := accountRepo.FindByID(ctx, householdID, *accountID)
+		if err != nil {
+			return err
+		}
+		if account.UserID != before.UserID {
+			return ErrChoreNotChildAccount
+		}
+
+		created, err := s.transactionService.CreateTx(ctx, tx, before.UserID, &model.CreateTransactionRequest{
+			AccountID:   account.ID,
+			Amount:      before.Reward,
+			Type:        "income",
+			Description: "Chore: " + before.ChoreName,
+			Date:        before.Date.Format("2006-01-02"),
+		})
+		if err != nil {
+			return err
+		}
+		reward = created
+
+		reviewed, err := choreRepo.Review(ctx, householdID, id, reviewerID, model.ChoreApproved, &created.ID)
+		if err != nil {
+			return err
+		}
+		if !reviewed {
+			return ErrChoreReviewed
+		}
+
+		completion, err = choreRepo.FindCompletionByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, auditRepo, householdID, model.AuditEntityChoreCompletion, id, model.AuditActionApprove, before, completion)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.transactionService.Booked(ctx, reward)
+	return completion, nil
+}
+
+// Reject turns down a chore marked done. The child can mark it done again.
+func (s *ChoreService) Reject(ctx context.Context, householdID, reviewerID, id string) (*model.ChoreCompletion, error) {
+	var completion *model.ChoreCompletion
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		choreRepo := s.choreRepo.WithTx(tx)
+
+		before, err := choreRepo.FindCompletionByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		reviewed, err := choreRepo.Review(ctx, householdID, id, reviewerID, model.ChoreRejected, nil)
+		if err != nil {
+			return err
+		}
+		if !reviewed {
+			return ErrChoreReviewed
+		}
+
+		completion, err = choreRepo.FindCompletionByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityChoreCompletion, id, model.AuditActionReject, before, completion)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return completion, nil
+}
+
+// GetEarnings sums what each child earned with chores from start up to but
+// not including end, and what is still waiting for approval. If userID is
+// set, only that child is returned.
+func (s *ChoreService) GetEarnings(ctx context.Context, householdID string, start, end *time.Time, userID *string) ([]*model.ChoreEarnings, error) {
+	return s.choreRepo.GetEarnings(ctx, householdID, start, end, userID)
+}
+
+// requireChild returns ErrChoreNotChild unless the user is a child of the
+// household
+func (s *ChoreService) requireChild(ctx context.Context, householdID, userID string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.HouseholdID != householdID || user.Role != "child" {
+		return ErrChoreNotChild
+	}
+	return nil
+}
+
+// checkChoreDate checks that a chore marked done on today is done for a date
+// no later than today and within the chore's current period, or within the
+// last choreBackdateDays days for a one-off chore
+func checkChoreDate(frequency string, date, today time.Time) error {
+	if date.After(today) {
+		return ErrChoreDateInFuture
+	}
+
+	earliest := today.AddDate(0, 0, -choreBackdateDays)
+	if start, _ := chorePeriod(frequency, today); start != nil {
+		earliest = *start
+	}
+	if date.Before(earliest) {
+		return ErrChoreDateTooEarly
+	}
+	return nil
+}
+
+// chorePeriod returns the day, week (from Monday) or month a chore done on
+// date falls in, in which it can only be done once. A one-off chore has no
+// bounds.
+func chorePeriod(frequency string, date time.Time) (*time.Time, *time.Time) {
+	var start, end time.Time
+	switch frequency {
+	case model.ChoreDaily:
+		start = date
+		end = start.AddDate(0, 0, 1)
+	case model.ChoreWeekly:
+		start = date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+		end = start.AddDate(0, 0, 7)
+	case model.ChoreMonthly:
+		start = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+		end = start.AddDate(0, 1, 0)
+	default:
+		return nil, nil
+	}
+	return &start, &end
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+)
+
+func TestChorePeriod(t *testing.T) {
+	tests := []struct {
+		frequency string
+		date      string
+		wantStart string // empty for no bounds
+		wantEnd   string
+	}{
+		{model.ChoreDaily, "2026-03-11", "2026-03-11", "2026-03-12"},
+		{model.ChoreWeekly, "2026-03-11", "2026-03-09", "2026-03-16"}, // Wednesday
+		{model.ChoreWeekly, "2026-03-09", "2026-03-09", "2026-03-16"}, // Monday
+		{model.ChoreWeekly, "2026-03-15", "2026-03-09", "2026-03-16"}, // Sunday
+		{model.ChoreMonthly, "2026-03-31", "2026-03-01", "2026-04-01"},
+		{model.ChoreOnce, "2026-03-11", "", ""},
+	}
+
+	for _, tt := range tests {
+		start, end := chorePeriod(tt.frequency, date(tt.date))
+
+		var gotStart, gotEnd string
+		if start != nil {
+			gotStart = start.Format("2006-01-02")
+		}
+		if end != nil {
+			gotEnd = end.Format("2006-01-02")
+		}
+
+		if gotStart != tt.wantStart || gotEnd != tt.wantEnd {
+			t.Errorf("chorePeriod(%s, %s) = %q to %q, want %q to %q", tt.frequency, tt.date, gotStart, gotEnd, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
+
+func TestCheckChoreDate(t *testing.T) {
+	tests := []struct {
+		frequency string
+		date      string
+		today     string
+		want      error
+	}{
+		{model.ChoreDaily, "2026-03-11", "2026-03-11", nil},
+		{model.ChoreDaily, "2026-03-10", "2026-03-11", ErrChoreDateTooEarly},
+		{model.ChoreDaily, "2026-03-12", "2026-03-11", ErrChoreDateInFuture},
+		{model.ChoreWeekly, "2026-03-09", "2026-03-15", nil}, // Monday, marked on Sunday
+		{model.ChoreWeekly, "2026-03-08", "2026-03-09", ErrChoreDateTooEarly},
+		{model.ChoreMonthly, "2026-03-01", "2026-03-31", nil},
+		{model.ChoreMonthly, "2026-02-28", "2026-03-01", ErrChoreDateTooEarly},
+		{model.ChoreOnce, "2026-03-04", "2026-03-11", nil},
+		{model.ChoreOnce, "2026-03-03", "2026-03-11", ErrChoreDateTooEarly},
+		{model.ChoreOnce, "2026-03-12", "2026-03-11", ErrChoreDateInFuture},
+	}
+
+	for _, tt := range tests {
+		if got := checkChoreDate(tt.frequency, date(tt.date), date(tt.today)); got != tt.want {
+			t.Errorf("checkChoreDate(%s, %s, %s) = %v, want %v", tt.frequency, tt.date, tt.today, got, tt.want)
+		}
+	}
+}
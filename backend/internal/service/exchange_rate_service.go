@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrMissingExchangeRate is returned when a report or budget summary needs an
+// exchange rate that has not been loaded
+var ErrMissingExchangeRate = errors.New("exchange rate missing")
+
+type ExchangeRateService struct {
+	exchangeRateRepo *repository.ExchangeRateRepository
+}
+
+func NewExchangeRateService(exchangeRateRepo *repository.ExchangeRateRepository) *ExchangeRateService {
+	return &ExchangeRateService{exchangeRateRepo: exchangeRateRepo}
+}
+
+func (s *ExchangeRateService) GetAll(ctx context.Context, householdID string, filters *model.ExchangeRateFilters) ([]*model.ExchangeRate, error) {
+	return s.exchangeRateRepo.FindAll(ctx, householdID, filters)
+}
+
+// ImportCSV loads exchange rates from a CSV file. Two layouts are accepted:
+//
+//   - long: a "date,base,quote,rate" header and one rate per row
+//   - wide: a "Date" column followed by one column per quote currency, as in
+//     the ECB reference rate dumps; every rate is against base (EUR if empty)
+//
+// Rows that cannot be parsed are reported back and skipped; the rest are
+// stored, replacing any rate already loaded for the same date and pair.
+func (s *ExchangeRateService) ImportCSV(ctx context.Context, householdID string, r io.Reader, base string) (*model.ImportExchangeRatesResponse, error) {
+	if base == "" {
+		base = "EUR"
+	}
+
+	rates, errors, err := parseExchangeRatesCSV(r, strings.ToUpper(base))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.exchangeRateRepo.Upsert(ctx, householdID, rates); err != nil {
+		return nil, err
+	}
+
+	return &model.ImportExchangeRatesResponse{
+		Imported: len(rates),
+		Errors:   errors,
+	}, nil
+}
+
+func parseExchangeRatesCSV(r io.Reader, base string) ([]*model.ExchangeRate, []string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header")
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	if len(header) == 0 || header[0] != "date" {
+		return nil, nil, fmt.Errorf("first CSV column must be date")
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CSV")
+	}
+
+	long := len(header) >= 4 && header[1] == "base" && header[2] == "quote" && header[3] == "rate"
+
+	// Later rows win when the same date and pair appear twice
+	index := map[string]int{}
+	var rates []*model.ExchangeRate
+	var errors []string
+	add := func(rate *model.ExchangeRate) {
+		key := rate.Date.Format("2006-01-02") + rate.Base + rate.Quote
+		if i, ok := index[key]; ok {
+			rates[i] = rate
+			return
+		}
+		index[key] = len(rates)
+		rates = append(rates, rate)
+	}
+
+	for i, record := range records {
+		row := i + 2
+
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("row %d: invalid date", row))
+			continue
+		}
+
+		if long {
+			if len(record) < 4 {
+				errors = append(errors, fmt.Sprintf("row %d: insufficient columns", row))
+				continue
+			}
+			rate, err := newExchangeRate(date, record[1], record[2], record[3])
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("row %d: %s", row, err.Error()))
+				continue
+			}
+			add(rate)
+			continue
+		}
+
+		for col := 1; col < len(record) && col < len(header); col++ {
+			value := strings.TrimSpace(record[col])
+			// ECB dumps leave missing rates as N/A and end rows with a trailing comma
+			if header[col] == "" || value == "" || strings.EqualFold(value, "N/A") {
+				continue
+			}
+			rate, err := newExchangeRate(date, base, header[col], value)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("row %d: %s", row, err.Error()))
+				continue
+			}
+			add(rate)
+		}
+	}
+
+	return rates, errors, nil
+}
+
+func newExchangeRate(date time.Time, base, quote, value string) (*model.ExchangeRate, error) {
+	base = strings.ToUpper(strings.TrimSpace(base))
+	quote = strings.ToUpper(strings.TrimSpace(quote))
+	if len(base) != 3 || len(quote) != 3 {
+		return nil, fmt.Errorf("invalid currency code")
+	}
+	if base == quote {
+		return nil, fmt.Errorf("base and quote currency must differ")
+	}
+
+	rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || rate <= 0 {
+		return nil, fmt.Errorf("invalid rate for %s", quote)
+	}
+
+	return &model.ExchangeRate{
+		Date:  date,
+		Base:  base,
+		Quote: quote,
+		Rate:  rate,
+	}, nil
+}
+
+// exchangeRateError turns the error convert_amount raises when no rate covers
+// an amount into ErrMissingExchangeRate, keeping the message that says which
+// rate is missing. Other errors are returned as they are.
+func exchangeRateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "P0001" && strings.HasPrefix(pgErr.Message, "no exchange rate") {
+		return fmt.Errorf("%w: %s", ErrMissingExchangeRate, pgErr.Message)
+	}
+	return err
+}
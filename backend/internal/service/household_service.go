@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/repository"
+)
+
+type HouseholdService struct {
+	householdRepo *repository.HouseholdRepository
+}
+
+func NewHouseholdService(householdRepo *repository.HouseholdRepository) *HouseholdService {
+	return &HouseholdService{householdRepo: householdRepo}
+}
+
+func (s *HouseholdService) Get(ctx context.Context, householdID string) (*model.Household, error) {
+	return s.householdRepo.FindByID(ctx, householdID)
+}
+
+func (s *HouseholdService) Update(ctx context.Context, householdID string, req *model.UpdateHouseholdRequest) (*model.Household, error) {
+	return s.householdRepo.Update(ctx, householdID, req)
+}
@@ -0,0 +1,390 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/repository"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+type ImportProfileService struct {
+	importProfileRepo *repository.ImportProfileRepository
+}
+
+func NewImportProfileService(importProfileRepo *repository.ImportProfileRepository) *ImportProfileService {
+	return &ImportProfileService{importProfileRepo: importProfileRepo}
+}
+
+func (s *ImportProfileService) Create(ctx context.Context, householdID string, req *model.CreateImportProfileRequest) (*model.ImportProfile, error) {
+	p := &model.ImportProfile{
+		Name:             req.Name,
+		Delimiter:        req.Delimiter,
+		Encoding:         req.Encoding,
+		HeaderRow:        1,
+		DateLayout:       req.DateLayout,
+		DecimalSeparator: req.DecimalSeparator,
+		AmountSign:       req.AmountSign,
+		Columns:          req.Columns,
+	}
+	if req.HeaderRow != nil {
+		p.HeaderRow = *req.HeaderRow
+	}
+	if p.Delimiter == "" {
+		p.Delimiter = ","
+	}
+	if p.Encoding == "" {
+		p.Encoding = "utf-8"
+	}
+	if p.DateLayout == "" {
+		p.DateLayout = "2006-01-02"
+	}
+	if p.DecimalSeparator == "" {
+		p.DecimalSeparator = "."
+	}
+	if p.AmountSign == "" {
+		p.AmountSign = model.AmountSignSigned
+	}
+
+	if err := validateImportProfile(p); err != nil {
+		return nil, err
+	}
+
+	return s.importProfileRepo.Create(ctx, householdID, p)
+}
+
+func (s *ImportProfileService) GetByID(ctx context.Context, householdID, id string) (*model.ImportProfile, error) {
+	return s.importProfileRepo.FindByID(ctx, householdID, id)
+}
+
+func (s *ImportProfileService) GetAll(ctx context.Context, householdID string) ([]*model.ImportProfile, error) {
+	return s.importProfileRepo.FindAll(ctx, householdID)
+}
+
+// Update applies the given settings on top of the stored profile and checks
+// that the result still describes a readable file
+func (s *ImportProfileService) Update(ctx context.Context, householdID, id string, req *model.UpdateImportProfileRequest) (*model.ImportProfile, error) {
+	p, err := s.importProfileRepo.FindByID(ctx, householdID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		p.Name = *req.Name
+	}
+	if req.Delimiter != nil {
+		p.Delimiter = *req.Delimiter
+	}
+	if req.Encoding != nil {
+		p.Encoding = *req.Encoding
+	}
+	if req.HeaderRow != nil {
+		p.HeaderRow = *req.HeaderRow
+	}
+	if req.DateLayout != nil {
+		p.DateLayout = *req.DateLayout
+	}
+	if req.DecimalSeparator != nil {
+		p.DecimalSeparator = *req.DecimalSeparator
+	}
+	if req.AmountSign != nil {
+		p.AmountSign = *req.AmountSign
+	}
+	if req.Columns != nil {
+		p.Columns = *req.Columns
+	}
+
+	if err := validateImportProfile(p); err != nil {
+		return nil, err
+	}
+
+	return s.importProfileRepo.Update(ctx, householdID, id, p)
+}
+
+func (s *ImportProfileService) Delete(ctx context.Context, householdID, id string) error {
+	return s.importProfileRepo.Delete(ctx, householdID, id)
+}
+
+func validateImportProfile(p *model.ImportProfile) error {
+	if utf8.RuneCountInString(p.Delimiter) != 1 || strings.ContainsAny(p.Delimiter, "\"\r\n") {
+		return fmt.Errorf("invalid delimiter")
+	}
+
+	if _, err := htmlindex.Get(p.Encoding); err != nil {
+		return fmt.Errorf("unknown encoding %q", p.Encoding)
+	}
+
+	// The layout must read back a full date written with it
+	ref := time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC)
+	if parsed, err := time.Parse(p.DateLayout, ref.Format(p.DateLayout)); err != nil || !parsed.Equal(ref) {
+		return fmt.Errorf("invalid date layout %q", p.DateLayout)
+	}
+
+	if p.HeaderRow < 0 {
+		return fmt.Errorf("invalid header row")
+	}
+
+	c := p.Columns
+	if c.Date == "" {
+		return fmt.Errorf("date column is required")
+	}
+	switch p.AmountSign {
+	case model.AmountSignSigned, model.AmountSignInverted:
+		if c.Amount == "" {
+			return fmt.Errorf("amount column is required")
+		}
+	case model.AmountSignDebitCredit:
+		if c.Debit == "" || c.Credit == "" {
+			return fmt.Errorf("debit and credit columns are required")
+		}
+	default:
+		return fmt.Errorf("invalid amount sign %q", p.AmountSign)
+	}
+
+	for _, ref := range []string{c.Date, c.Amount, c.Debit, c.Credit, c.Description, c.Counterparty, c.Reference, c.Currency} {
+		if ref == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(ref); err == nil {
+			if n < 1 {
+				return fmt.Errorf("invalid column number %d", n)
+			}
+		} else if p.HeaderRow == 0 {
+			return fmt.Errorf("column %q must be a column number when the file has no header row", ref)
+		}
+	}
+
+	return nil
+}
+
+// parseProfileCSV reads a bank CSV export laid out as described by p. A
+// mapping that does not fit the file fails the whole parse; problems with
+// single rows are recorded on the row.
+func parseProfileCSV(p *model.ImportProfile, data []byte) ([]*model.StatementLine, error) {
+	enc, err := htmlindex.Get(p.Encoding)
+	if err != nil {
+		return nil, fmt.Errorf("unknown encoding %q", p.Encoding)
+	}
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("file is not valid %s", p.Encoding)
+	}
+	decoded = bytes.TrimPrefix(decoded, []byte("\uFEFF"))
+
+	reader := csv.NewReader(bytes.NewReader(decoded))
+	reader.Comma, _ = utf8.DecodeRuneInString(p.Delimiter)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	var header map[string]int
+	var cols *profileColumns
+	var lines []*model.StatementLine
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("failed to parse CSV at line %d", parseErr.Line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV file")
+		}
+		row, _ := reader.FieldPos(0)
+
+		// Banks often put account details above the header
+		if row < p.HeaderRow {
+			continue
+		}
+		if p.HeaderRow > 0 && header == nil {
+			header = map[string]int{}
+			for i, name := range record {
+				header[strings.ToLower(strings.TrimSpace(name))] = i
+			}
+			continue
+		}
+
+		if cols == nil {
+			if cols, err = resolveProfileColumns(p.Columns, header); err != nil {
+				return nil, err
+			}
+		}
+
+		if isBlankRecord(record) {
+			continue
+		}
+
+		lines = append(lines, cols.line(p, row, record))
+	}
+
+	if p.HeaderRow > 0 && header == nil {
+		return nil, fmt.Errorf("header row %d not found", p.HeaderRow)
+	}
+
+	return lines, nil
+}
+
+// profileColumns holds the 0-based positions of the mapped columns, -1 if
+// a field is not mapped
+type profileColumns struct {
+	date, amount, debit, credit, description, counterparty, reference, currency int
+}
+
+func resolveProfileColumns(m model.ImportColumnMapping, header map[string]int) (*profileColumns, error) {
+	resolve := func(ref string) (int, error) {
+		if ref == "" {
+			return -1, nil
+		}
+		if n, err := strconv.Atoi(ref); err == nil {
+			return n - 1, nil
+		}
+		if i, ok := header[strings.ToLower(strings.TrimSpace(ref))]; ok {
+			return i, nil
+		}
+		return 0, fmt.Errorf("column %q not found in header", ref)
+	}
+
+	c := &profileColumns{}
+	for _, f := range []struct {
+		ref string
+		pos *int
+	}{
+		{m.Date, &c.date},
+		{m.Amount, &c.amount},
+		{m.Debit, &c.debit},
+		{m.Credit, &c.credit},
+		{m.Description, &c.description},
+		{m.Counterparty, &c.counterparty},
+		{m.Reference, &c.reference},
+		{m.Currency, &c.currency},
+	} {
+		pos, err := resolve(f.ref)
+		if err != nil {
+			return nil, err
+		}
+		*f.pos = pos
+	}
+
+	return c, nil
+}
+
+func (c *profileColumns) line(p *model.ImportProfile, row int, record []string) *model.StatementLine {
+	field := func(pos int) string {
+		if pos < 0 || pos >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[pos])
+	}
+
+	line := &model.StatementLine{
+		Row:          row,
+		ExternalID:   field(c.reference),
+		Currency:     strings.ToUpper(field(c.currency)),
+		Description:  field(c.description),
+		Counterparty: field(c.counterparty),
+	}
+
+	date, err := time.Parse(p.DateLayout, field(c.date))
+	if err != nil {
+		line.Errors = append(line.Errors, fmt.Sprintf("invalid date %q", field(c.date)))
+	}
+	line.Date = date
+
+	switch p.AmountSign {
+	case model.AmountSignDebitCredit:
+		debit, credit := field(c.debit), field(c.credit)
+		if debit == "" && credit == "" {
+			line.Errors = append(line.Errors, "missing amount")
+			break
+		}
+		var debitCents, creditCents int64
+		if debit != "" {
+			if debitCents, err = parseProfileAmount(debit, p.DecimalSeparator); err != nil {
+				line.Errors = append(line.Errors, fmt.Sprintf("invalid debit %q", debit))
+			}
+		}
+		if credit != "" {
+			if creditCents, err = parseProfileAmount(credit, p.DecimalSeparator); err != nil {
+				line.Errors = append(line.Errors, fmt.Sprintf("invalid credit %q", credit))
+			}
+		}
+		// Some banks print debits with a minus sign, others without
+		line.Amount = absCents(creditCents) - absCents(debitCents)
+	default:
+		amount, err := parseProfileAmount(field(c.amount), p.DecimalSeparator)
+		if err != nil {
+			line.Errors = append(line.Errors, fmt.Sprintf("invalid amount %q", field(c.amount)))
+		}
+		if p.AmountSign == model.AmountSignInverted {
+			amount = -amount
+		}
+		line.Amount = amount
+	}
+
+	if line.Amount == 0 && len(line.Errors) == 0 {
+		line.Errors = append(line.Errors, "amount is zero")
+	}
+
+	return line
+}
+
+// parseProfileAmount reads an amount written with the given decimal
+// separator. The other separator, spaces and apostrophes are taken as
+// thousands separators; a trailing minus or parentheses mark a negative.
+func parseProfileAmount(s, decimalSeparator string) (int64, error) {
+	s = strings.TrimSpace(s)
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+	if strings.HasSuffix(s, "-") {
+		negative = true
+		s = strings.TrimSuffix(s, "-")
+	}
+
+	thousands := ","
+	if decimalSeparator == "," {
+		thousands = "."
+	}
+	s = strings.NewReplacer(thousands, "", " ", "", "\u00a0", "", "'", "").Replace(s)
+	s = strings.Replace(s, decimalSeparator, ".", 1)
+
+	amount, err := parseDecimalCents(s)
+	if err != nil {
+		return 0, err
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+func absCents(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func isBlankRecord(record []string) bool {
+	for _, f := range record {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,493 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/notify"
+	"github.com/asilingas/fambudg/backend/internal/repository"
+)
+
+// Budget alert levels, in percent of the budget spent
+const (
+	budgetAlertWarning = 80
+	budgetAlertOver    = 100
+)
+
+// Stages of a bill notice, by how far the bill is from its due date
+const (
+	billNoticeUpcoming = "upcoming"
+	billNoticeDue      = "due"
+	billNoticeOverdue  = "overdue"
+)
+
+// billOverdueRepeatDays is how often an overdue bill is brought up again
+const billOverdueRepeatDays = 7
+
+// notificationTimeout bounds the alerts raised after a transaction, so a slow
+// mail server does not hold up the request for long
+const notificationTimeout = 15 * time.Second
+
+// NotificationService raises alerts about a household's money and delivers
+// them to the in-app feed and to the channels each user picked
+type NotificationService struct {
+	notificationRepo *repository.NotificationRepository
+	userRepo         *repository.UserRepository
+	budgetService    *BudgetService
+	allowanceRepo    *repository.AllowanceRepository
+	billReminderRepo *repository.BillReminderRepository
+	channels         map[string]notify.Channel
+}
+
+func NewNotificationService(
+	notificationRepo *repository.NotificationRepository,
+	userRepo *repository.UserRepository,
+	budgetService *BudgetService,
+	allowanceRepo *repository.AllowanceRepository,
+	billReminderRepo *repository.BillReminderRepository,
+	channels ...notify.Channel,
+) *NotificationService {
+	byName := make(map[string]notify.Channel, len(channels))
+	for _, c := range channels {
+		byName[c.Name()] = c
+	}
+
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		budgetService:    budgetService,
+		allowanceRepo:    allowanceRepo,
+		billReminderRepo: billReminderRepo,
+		channels:         byName,
+	}
+}
+
+// GetFeed returns the user's notifications, newest first, optionally only the
+// unread ones
+func (s *NotificationService) GetFeed(ctx context.Context, userID string, unreadOnly bool) (*model.NotificationFeed, error) {
+	notifications, err := s.notificationRepo.FindByUserID(ctx, userID, unreadOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.NotificationFeed{Notifications: notifications, Unread: unread}, nil
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID, id string) error {
+	return s.notificationRepo.MarkRead(ctx, userID, id)
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) error {
+	return s.notificationRepo.MarkAllRead(ctx, userID)
+}
+
+// GetPreferences returns the user's preference for every kind of
+// notification, with the defaults for those never changed: enabled, in the
+// in-app feed only
+func (s *NotificationService) GetPreferences(ctx context.Context, userID string) ([]*model.NotificationPreference, error) {
+	saved, err := s.notificationRepo.FindPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string]*model.NotificationPreference, len(saved))
+	for _, p := range saved {
+		byType[p.Type] = p
+	}
+
+	prefs := make([]*model.NotificationPreference, 0, len(model.NotificationTypes))
+	for _, t := range model.NotificationTypes {
+		p, ok := byType[t]
+		if !ok {
+			p = &model.NotificationPreference{Type: t, Enabled: true, Channels: []string{}}
+		}
+		if t == model.NotificationLargeTransaction && p.Threshold == nil {
+			threshold := model.DefaultLargeTransactionThreshold
+			p.Threshold = &threshold
+		}
+		prefs = append(prefs, p)
+	}
+
+	return prefs, nil
+}
+
+// UpdatePreferences saves the given preferences. Only channels this server
+// is set up to deliver can be picked.
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID string, req *model.UpdateNotificationPreferencesRequest) ([]*model.NotificationPreference, error) {
+	for _, p := range req.Preferences {
+		for _, name := range p.Channels {
+			if _, ok := s.channels[name]; !ok {
+				return nil, fmt.Errorf("%s notifications are not available", name)
+			}
+		}
+	}
+
+	for i := range req.Preferences {
+		if err := s.notificationRepo.SavePreference(ctx, userID, &req.Preferences[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetPreferences(ctx, userID)
+}
+
+// TransactionChanged raises the alerts a new or changed transaction calls
+// for: its month's budgets passing 80% or 100%, its owner's allowance running
+// out, and large transactions. Each alert is raised once per user.
+// Notifications are best effort: failures are logged, not returned, so they
+// never undo the transaction.
+func (s *NotificationService) TransactionChanged(ctx context.Context, transaction *model.Transaction) {
+	ctx, cancel := context.WithTimeout(ctx, notificationTimeout)
+	defer cancel()
+
+	if err := s.checkTransaction(ctx, transaction); err != nil {
+		log.Printf("Notifications: transaction %s: %v", transaction.ID, err)
+	}
+}
+
+func (s *NotificationService) checkTransaction(ctx context.Context, transaction *model.Transaction) error {
+	if transaction.Type == "transfer" || transaction.Amount >= 0 {
+		return nil
+	}
+
+	owner, err := s.userRepo.FindByID(ctx, transaction.UserID)
+	if err != nil {
+		return err
+	}
+	members, err := s.userRepo.ListAll(ctx, owner.HouseholdID)
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(
+		s.checkBudgets(ctx, owner.HouseholdID, members, transaction),
+		s.checkAllowance(ctx, owner, members, transaction),
+		s.checkLargeTransaction(ctx, owner, members, transaction),
+	)
+}
+
+// checkBudgets alerts parents when a budget the transaction counts towards
+// passes 80% or 100% of its effective amount. Only the budgets of the
+// transaction's categories and the categories above them are checked.
+func (s *NotificationService) checkBudgets(ctx context.Context, householdID string, members []*model.User, transaction *model.Transaction) error {
+	var categoryIDs []string
+	if transaction.CategoryID != nil {
+		categoryIDs = append(categoryIDs, *transaction.CategoryID)
+	}
+	for _, split := range transaction.Splits {
+		categoryIDs = append(categoryIDs, split.CategoryID)
+	}
+
+	month, year := int(transaction.Date.Month()), transaction.Date.Year()
+	summaries, err := s.budgetService.GetCategorySummary(ctx, householdID, month, year, categoryIDs)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, summary := range summaries {
+		level := budgetAlertLevel(summary.ActualAmount, summary.EffectiveAmount)
+		if level == 0 {
+			continue
+		}
+
+		title := fmt.Sprintf("%s has used %d%% of its budget", summary.CategoryName, level)
+		if level == budgetAlertOver {
+			title = fmt.Sprintf("%s is over budget", summary.CategoryName)
+		}
+		categoryID := summary.CategoryID
+		n := &model.Notification{
+			Type:     model.NotificationBudgetThreshold,
+			Title:    title,
+			Body:     fmt.Sprintf("Spent %s of %s in %s.", formatCents(summary.ActualAmount), formatCents(summary.EffectiveAmount), transaction.Date.Format("January 2006")),
+			EntityID: &categoryID,
+		}
+		key := fmt.Sprintf("budget:%s:%d-%02d:%d", summary.CategoryID, year, month, level)
+
+		for _, u := range members {
+			if u.Role == "child" {
+				continue
+			}
+			errs = append(errs, s.send(ctx, u, n, key))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkAllowance alerts a child and the admins when the child's allowance for
+// the period of the transaction is spent
+func (s *NotificationService) checkAllowance(ctx context.Context, owner *model.User, members []*model.User, transaction *model.Transaction) error {
+	allowances, err := s.allowanceRepo.ListAll(ctx, owner.HouseholdID)
+	if err != nil {
+		return err
+	}
+
+	var allowance *model.Allowance
+	for _, a := range allowances {
+		if a.UserID == owner.ID {
+			allowance = a
+		}
+	}
+	if allowance == nil {
+		return nil
+	}
+	if allowance, err = enrichWithSpending(ctx, s.allowanceRepo, allowance); err != nil {
+		return err
+	}
+	if allowance.Remaining > 0 {
+		return nil
+	}
+	if transaction.Date.Before(allowance.PeriodStart) || !transaction.Date.Before(allowance.PeriodEnd) {
+		return nil
+	}
+
+	body := fmt.Sprintf("Spent %s of %s since %s.", formatCents(allowance.Spent), formatCents(allowance.Amount+allowance.CarriedOver), allowance.PeriodStart.Format("2 January 2006"))
+	key := fmt.Sprintf("allowance:%s:%s", allowance.ID, allowance.PeriodStart.Format("2006-01-02"))
+
+	var errs []error
+	for _, u := range members {
+		title := fmt.Sprintf("%s's allowance has run out", owner.Name)
+		if u.ID == owner.ID {
+			title = "Your allowance has run out"
+		} else if u.Role != "admin" {
+			continue
+		}
+
+		errs = append(errs, s.send(ctx, u, &model.Notification{
+			Type:     model.NotificationAllowanceDepleted,
+			Title:    title,
+			Body:     body,
+			EntityID: &allowance.ID,
+		}, key))
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkLargeTransaction alerts the admins, other than the one who made it,
+// of a transaction at or over their threshold
+func (s *NotificationService) checkLargeTransaction(ctx context.Context, owner *model.User, members []*model.User, transaction *model.Transaction) error {
+	amount := -transaction.Amount
+
+	var errs []error
+	for _, u := range members {
+		if u.Role != "admin" || u.ID == owner.ID {
+			continue
+		}
+
+		pref, err := s.preference(ctx, u.ID, model.NotificationLargeTransaction)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if pref.Threshold != nil && amount < *pref.Threshold {
+			continue
+		}
+
+		body := fmt.Sprintf("%s spent %s on %s", owner.Name, formatCents(amount), transaction.Date.Format("2 January 2006"))
+		if transaction.Description != "" {
+			body += ": " + transaction.Description
+		}
+		errs = append(errs, s.send(ctx, u, &model.Notification{
+			Type:     model.NotificationLargeTransaction,
+			Title:    fmt.Sprintf("Large transaction by %s", owner.Name),
+			Body:     body + ".",
+			EntityID: &transaction.ID,
+		}, "transaction:"+transaction.ID))
+	}
+
+	return errors.Join(errs...)
+}
+
+// SendBillNotices notifies about every active bill within its lead time of
+// today, due today or overdue, across all households, and returns how many
+// bills were checked. Each notice goes out once per due date, so paying a
+// bill, which moves its due date on, ends them. An overdue bill also goes to
+// the admins and is brought up again every week until paid.
+func (s *NotificationService) SendBillNotices(ctx context.Context, today time.Time) (int, error) {
+	due, err := s.billReminderRepo.FindDue(ctx, today)
+	if err != nil {
+		return 0, err
+	}
+
+	members := make(map[string][]*model.User)
+	var errs []error
+	for _, d := range due {
+		users, ok := members[d.HouseholdID]
+		if !ok {
+			if users, err = s.userRepo.ListAll(ctx, d.HouseholdID); err != nil {
+				return 0, err
+			}
+			members[d.HouseholdID] = users
+		}
+
+		if err := s.sendBillNotice(ctx, users, d.Bill, today); err != nil {
+			errs = append(errs, fmt.Errorf("bill %s: %w", d.Bill.ID, err))
+		}
+	}
+
+	return len(due), errors.Join(errs...)
+}
+
+func (s *NotificationService) sendBillNotice(ctx context.Context, members []*model.User, bill *model.BillReminder, today time.Time) error {
+	stage, days := billNoticeStage(bill.NextDueDate, today, bill.LeadDays)
+	dueDate := bill.NextDueDate.Format("2006-01-02")
+
+	var title, key string
+	switch stage {
+	case billNoticeUpcoming:
+		title = fmt.Sprintf("%s is due in %d days", bill.Name, days)
+		if days == 1 {
+			title = fmt.Sprintf("%s is due tomorrow", bill.Name)
+		}
+		key = fmt.Sprintf("bill:%s:%s:upcoming", bill.ID, dueDate)
+	case billNoticeDue:
+		title = fmt.Sprintf("%s is due today", bill.Name)
+		key = fmt.Sprintf("bill:%s:%s:due", bill.ID, dueDate)
+	case billNoticeOverdue:
+		title = fmt.Sprintf("%s is overdue", bill.Name)
+		if days > 1 {
+			title = fmt.Sprintf("%s is %d days overdue", bill.Name, days)
+		}
+		key = fmt.Sprintf("bill:%s:%s:overdue:%d", bill.ID, dueDate, (days-1)/billOverdueRepeatDays)
+	default:
+		return nil
+	}
+
+	n := &model.Notification{
+		Type:     model.NotificationBillDue,
+		Title:    title,
+		Body:     fmt.Sprintf("%s due on %s.", formatCents(bill.Amount), bill.NextDueDate.Format("2 January 2006")),
+		EntityID: &bill.ID,
+	}
+
+	var errs []error
+	for _, u := range billRecipients(members, bill, stage == billNoticeOverdue) {
+		errs = append(errs, s.send(ctx, u, n, key))
+	}
+
+	return errors.Join(errs...)
+}
+
+// billRecipients returns the bill's recipients, or every admin and member if
+// it has none. With escalate, the admins are added.
+func billRecipients(members []*model.User, bill *model.BillReminder, escalate bool) []*model.User {
+	picked := make(map[string]bool, len(bill.RecipientIDs))
+	for _, id := range bill.RecipientIDs {
+		picked[id] = true
+	}
+
+	var recipients []*model.User
+	for _, u := range members {
+		switch {
+		case picked[u.ID]:
+		case len(picked) == 0 && u.Role != "child":
+		case escalate && u.Role == "admin":
+		default:
+			continue
+		}
+		recipients = append(recipients, u)
+	}
+
+	return recipients
+}
+
+// billNoticeStage returns which notice a bill due on the given date calls
+// for today, and how many days it is until or past due. A bill further out
+// than its lead time calls for none.
+func billNoticeStage(dueDate, today time.Time, leadDays int) (string, int) {
+	due := time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(due.Sub(day).Hours() / 24)
+
+	switch {
+	case days < 0:
+		return billNoticeOverdue, -days
+	case days == 0:
+		return billNoticeDue, 0
+	case days <= leadDays:
+		return billNoticeUpcoming, days
+	default:
+		return "", days
+	}
+}
+
+// send adds the notification to the user's feed and delivers it through the
+// channels the user picked, unless the user turned this kind off or already
+// has it
+func (s *NotificationService) send(ctx context.Context, user *model.User, n *model.Notification, dedupeKey string) error {
+	pref, err := s.preference(ctx, user.ID, n.Type)
+	if err != nil {
+		return err
+	}
+	if !pref.Enabled {
+		return nil
+	}
+
+	created, err := s.notificationRepo.Create(ctx, user.ID, n, dedupeKey)
+	if err != nil || created == nil {
+		return err
+	}
+
+	var errs []error
+	for _, name := range pref.Channels {
+		channel, ok := s.channels[name]
+		if !ok {
+			continue
+		}
+		if err := channel.Send(ctx, notify.Message{Type: n.Type, To: user.Email, Subject: n.Title, Body: n.Body}); err != nil {
+			errs = append(errs, fmt.Errorf("%s to %s: %w", name, user.Email, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *NotificationService) preference(ctx context.Context, userID, notificationType string) (*model.NotificationPreference, error) {
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range prefs {
+		if p.Type == notificationType {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown notification type %q", notificationType)
+}
+
+// budgetAlertLevel returns the highest alert level the spending has reached,
+// or 0 for none. A budget with nothing left to spend is over once anything
+// is spent.
+func budgetAlertLevel(actual, effective int64) int {
+	switch {
+	case actual <= 0:
+		return 0
+	case actual >= effective:
+		return budgetAlertOver
+	case actual*100 >= effective*budgetAlertWarning:
+		return budgetAlertWarning
+	default:
+		return 0
+	}
+}
+
+// formatCents formats an amount in cents as units with two decimals
+func formatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
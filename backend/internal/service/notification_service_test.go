@@ -0,0 +1,76 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBudgetAlertLevel(t *testing.T) {
+	tests := []struct {
+		name      string
+		actual    int64
+		effective int64
+		want      int
+	}{
+		{"nothing spent", 0, 10000, 0},
+		{"under 80%", 7999, 10000, 0},
+		{"exactly 80%", 8000, 10000, budgetAlertWarning},
+		{"between 80% and 100%", 9999, 10000, budgetAlertWarning},
+		{"exactly 100%", 10000, 10000, budgetAlertOver},
+		{"over budget", 12000, 10000, budgetAlertOver},
+		{"nothing left after rollover", 100, 0, budgetAlertOver},
+		{"overspent last month", 100, -500, budgetAlertOver},
+		{"refund", -500, 10000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := budgetAlertLevel(tt.actual, tt.effective); got != tt.want {
+				t.Errorf("budgetAlertLevel(%d, %d) = %d, want %d", tt.actual, tt.effective, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatCents(t *testing.T) {
+	tests := map[int64]string{
+		0:      "0.00",
+		5:      "0.05",
+		12345:  "123.45",
+		-60000: "-600.00",
+	}
+
+	for cents, want := range tests {
+		if got := formatCents(cents); got != want {
+			t.Errorf("formatCents(%d) = %q, want %q", cents, got, want)
+		}
+	}
+}
+
+func TestBillNoticeStage(t *testing.T) {
+	due := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		today     string
+		leadDays  int
+		wantStage string
+		wantDays  int
+	}{
+		{"2026-03-10", 3, "", 5},
+		{"2026-03-12", 3, billNoticeUpcoming, 3},
+		{"2026-03-14", 3, billNoticeUpcoming, 1},
+		{"2026-03-14", 0, "", 1},
+		{"2026-03-15", 3, billNoticeDue, 0},
+		{"2026-03-15", 0, billNoticeDue, 0},
+		{"2026-03-16", 3, billNoticeOverdue, 1},
+		{"2026-04-01", 3, billNoticeOverdue, 17},
+	}
+
+	for _, tt := range tests {
+		today, _ := time.Parse("2006-01-02", tt.today)
+		stage, days := billNoticeStage(due, today, tt.leadDays)
+		if stage != tt.wantStage || days != tt.wantDays {
+			t.Errorf("billNoticeStage(%s, lead %d) = %q, %d, want %q, %d", tt.today, tt.leadDays, stage, days, tt.wantStage, tt.wantDays)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+type ReconciliationService struct {
+	uow                *repository.UnitOfWork
+	reconciliationRepo *repository.ReconciliationRepository
+	transactionRepo    *repository.TransactionRepository
+	transactionService *TransactionService
+	accountRepo        *repository.AccountRepository
+}
+
+func NewReconciliationService(
+	uow *repository.UnitOfWork,
+	reconciliationRepo *repository.ReconciliationRepository,
+	transactionRepo *repository.TransactionRepository,
+	transactionService *TransactionService,
+	accountRepo *repository.AccountRepository,
+) *ReconciliationService {
+	return &ReconciliationService{
+		uow:                uow,
+		reconciliationRepo: reconciliationRepo,
+		transactionRepo:    transactionRepo,
+		transactionService: transactionService,
+		accountRepo:        accountRepo,
+	}
+}
+
+// Start opens a reconciliation of the account against a statement. An
+// account has at most one open reconciliation at a time.
+func (s *ReconciliationService) Start(ctx context.Context, householdID string, req *model.CreateReconciliationRequest) (*model.Reconciliation, error) {
+	if _, err := s.accountRepo.FindByID(ctx, householdID, req.AccountID); err != nil {
+		return nil, err
+	}
+
+	statementDate, err := time.Parse("2006-01-02", req.StatementDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid statement date: %w", err)
+	}
+
+	rec, err := s.reconciliationRepo.Create(ctx, householdID, req.AccountID, statementDate, req.ClosingBalance)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("account already has an open reconciliation")
+		}
+		return nil, err
+	}
+
+	return s.withProgress(ctx, rec)
+}
+
+// GetByID returns a reconciliation. While it is open, the cleared balance,
+// the difference to the closing balance and the transactions left to clear
+// are filled in.
+func (s *ReconciliationService) GetByID(ctx context.Context, householdID, id string) (*model.Reconciliation, error) {
+	rec, err := s.reconciliationRepo.FindByID(ctx, householdID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withProgress(ctx, rec)
+}
+
+func (s *ReconciliationService) GetByAccountID(ctx context.Context, householdID, accountID string) ([]*model.Reconciliation, error) {
+	return s.reconciliationRepo.FindByAccountID(ctx, householdID, accountID)
+}
+
+// Clear marks transactions of the reconciled account cleared, or uncleared
+// again. Either all of them change or none do.
+func (s *ReconciliationService) Clear(ctx context.Context, householdID, id string, req *model.ClearTransactionsRequest) (*model.Reconciliation, error) {
+	rec, err := s.reconciliationRepo.FindByID(ctx, householdID, id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Status != model.ReconciliationOpen {
+		return nil, fmt.Errorf("reconciliation is not open")
+	}
+
+	status := model.TransactionUncleared
+	if req.Cleared {
+		status = model.TransactionCleared
+	}
+
+	err = s.uow.Do(ctx, func(tx pgx.Tx) error {
+		changed, err := s.transactionRepo.WithTx(tx).SetStatus(ctx, rec.AccountID, req.TransactionIDs, status)
+		if err != nil {
+			return err
+		}
+		if changed != int64(len(req.TransactionIDs)) {
+			return fmt.Errorf("transactions must belong to the account and not be reconciled yet")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withProgress(ctx, rec)
+}
+
+// Complete closes a reconciliation and locks its cleared transactions. When
+// the cleared balance is off, it fails unless asked to book the difference as
+// an adjustment transaction.
+func (s *ReconciliationService) Complete(ctx context.Context, householdID, userID, id string, req *model.CompleteReconciliationRequest) (*model.Reconciliation, error) {
+	var adjustment *model.Transaction
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		reconciliationRepo := s.reconciliationRepo.WithTx(tx)
+		transactionRepo := s.transactionRepo.WithTx(tx)
+
+		rec, err := reconciliationRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+		if rec.Status != model.ReconciliationOpen {
+			return fmt.Errorf("reconciliation is not open")
+		}
+
+		cleared, err := reconciliationRepo.ClearedBalance(ctx, rec.AccountID, rec.StatementDate)
+		if err != nil {
+			return err
+		}
+
+		var adjustmentID *string
+		if difference := rec.ClosingBalance - cleared; difference != 0 {
+			if !req.Adjust {
+				return fmt.Errorf("cleared balance does not match the closing balance")
+			}
+			if adjustment, err = s.bookAdjustment(ctx, tx, userID, rec, difference, req.CategoryID); err != nil {
+				return err
+			}
+			adjustmentID = &adjustment.ID
+		}
+
+		if err := transactionRepo.MarkReconciled(ctx, rec.AccountID, rec.ID, rec.StatementDate); err != nil {
+			return err
+		}
+		return reconciliationRepo.Complete(ctx, householdID, id, adjustmentID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if adjustment != nil {
+		s.transactionService.Booked(ctx, adjustment)
+	}
+
+	return s.reconciliationRepo.FindByID(ctx, householdID, id)
+}
+
+// Cancel discards an open reconciliation. Transactions keep their cleared
+// status.
+func (s *ReconciliationService) Cancel(ctx context.Context, householdID, id string) error {
+	return s.reconciliationRepo.Delete(ctx, householdID, id)
+}
+
+// bookAdjustment books a cleared transaction for the amount the account is
+// off from the statement, on the statement date
+func (s *ReconciliationService) bookAdjustment(ctx context.Context, tx pgx.Tx, userID string, rec *model.Reconciliation, amount int64, categoryID string) (*model.Transaction, error) {
+	req := &model.CreateTransactionRequest{
+		AccountID:   rec.AccountID,
+		CategoryID:  categoryID,
+		Amount:      amount,
+		Type:        "expense",
+		Description: "Reconciliation adjustment",
+		Date:        rec.StatementDate.Format("2006-01-02"),
+		IsShared:    true,
+		Status:      model.TransactionCleared,
+	}
+	if amount > 0 {
+		req.Type = "income"
+	}
+
+	return s.transactionService.CreateTx(ctx, tx, userID, req)
+}
+
+// withProgress fills in how far an open reconciliation is from balancing
+func (s *ReconciliationService) withProgress(ctx context.Context, rec *model.Reconciliation) (*model.Reconciliation, error) {
+	if rec.Status != model.ReconciliationOpen {
+		return rec, nil
+	}
+
+	cleared, err := s.reconciliationRepo.ClearedBalance(ctx, rec.AccountID, rec.StatementDate)
+	if err != nil {
+		return nil, err
+	}
+	difference := rec.ClosingBalance - cleared
+	rec.ClearedBalance = &cleared
+	rec.Difference = &difference
+
+	if rec.Transactions, err = s.transactionRepo.FindForReconciliation(ctx, rec.AccountID, rec.StatementDate); err != nil {
+		return nil, err
+	}
+
+	return rec, nil
+}
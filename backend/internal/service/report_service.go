@@ -27,7 +27,7 @@ func (s *ReportService) GetDashboard(ctx context.Context, userID string, month,
 
 	monthSummary, err := s.reportRepo.GetMonthSummary(ctx, userID, month, year)
 	if err != nil {
-		return nil, err
+		return nil, exchangeRateError(err)
 	}
 
 	recentTransactions, err := s.reportRepo.GetRecentTransactions(ctx, userID, 10)
@@ -42,19 +42,19 @@ func (s *ReportService) GetDashboard(ctx context.Context, userID string, month,
 	}, nil
 }
 
-// GetDashboardAll returns dashboard for all users (admin)
-func (s *ReportService) GetDashboardAll(ctx context.Context, month, year int) (*model.DashboardResponse, error) {
-	accounts, err := s.accountRepo.FindAll(ctx)
+// GetDashboardAll returns dashboard for all users in a household (admin)
+func (s *ReportService) GetDashboardAll(ctx context.Context, householdID string, month, year int) (*model.DashboardResponse, error) {
+	accounts, err := s.accountRepo.FindAll(ctx, householdID)
 	if err != nil {
 		return nil, err
 	}
 
-	monthSummary, err := s.reportRepo.GetMonthSummaryAll(ctx, month, year)
+	monthSummary, err := s.reportRepo.GetMonthSummaryAll(ctx, householdID, month, year)
 	if err != nil {
-		return nil, err
+		return nil, exchangeRateError(err)
 	}
 
-	recentTransactions, err := s.reportRepo.GetRecentTransactionsAll(ctx, 10)
+	recentTransactions, err := s.reportRepo.GetRecentTransactionsAll(ctx, householdID, 10)
 	if err != nil {
 		return nil, err
 	}
@@ -67,41 +67,99 @@ func (s *ReportService) GetDashboardAll(ctx context.Context, month, year int) (*
 }
 
 func (s *ReportService) GetMonthlySummary(ctx context.Context, userID string, month, year int) (*model.MonthSummary, error) {
-	return s.reportRepo.GetMonthSummary(ctx, userID, month, year)
+	summary, err := s.reportRepo.GetMonthSummary(ctx, userID, month, year)
+	return summary, exchangeRateError(err)
 }
 
-// GetMonthlySummaryAll returns monthly summary for all users (admin)
-func (s *ReportService) GetMonthlySummaryAll(ctx context.Context, month, year int) (*model.MonthSummary, error) {
-	return s.reportRepo.GetMonthSummaryAll(ctx, month, year)
+// GetMonthlySummaryAll returns monthly summary for all users in a household (admin)
+func (s *ReportService) GetMonthlySummaryAll(ctx context.Context, householdID string, month, year int) (*model.MonthSummary, error) {
+	summary, err := s.reportRepo.GetMonthSummaryAll(ctx, householdID, month, year)
+	return summary, exchangeRateError(err)
 }
 
 func (s *ReportService) GetSpendingByCategory(ctx context.Context, userID string, month, year int) ([]*model.CategorySpending, error) {
-	return s.reportRepo.GetSpendingByCategory(ctx, userID, month, year)
+	spending, err := s.reportRepo.GetSpendingByCategory(ctx, userID, month, year)
+	return spending, exchangeRateError(err)
+}
+
+// GetSpendingByCategoryAll returns spending by category for all users in a household (admin)
+func (s *ReportService) GetSpendingByCategoryAll(ctx context.Context, householdID string, month, year int) ([]*model.CategorySpending, error) {
+	spending, err := s.reportRepo.GetSpendingByCategoryAll(ctx, householdID, month, year)
+	return spending, exchangeRateError(err)
+}
+
+// GetSpendingTree returns spending as a tree of categories with subtotals at
+// each level. If userID is set, only that user's spending is counted. A depth
+// above 0 collapses the tree below that level into the subtotals.
+func (s *ReportService) GetSpendingTree(ctx context.Context, householdID string, userID *string, month, year, depth int) ([]*model.CategorySpendingNode, error) {
+	nodes, err := s.reportRepo.GetSpendingTree(ctx, householdID, userID, month, year)
+	if err != nil {
+		return nil, exchangeRateError(err)
+	}
+	return buildSpendingTree(nodes, depth), nil
 }
 
-// GetSpendingByCategoryAll returns spending by category for all users (admin)
-func (s *ReportService) GetSpendingByCategoryAll(ctx context.Context, month, year int) ([]*model.CategorySpending, error) {
-	return s.reportRepo.GetSpendingByCategoryAll(ctx, month, year)
+// buildSpendingTree links the nodes to their parents, keeping their order,
+// and cuts off the levels below depth
+func buildSpendingTree(nodes []*model.CategorySpendingNode, depth int) []*model.CategorySpendingNode {
+	byID := make(map[string]*model.CategorySpendingNode, len(nodes))
+	for _, n := range nodes {
+		byID[n.CategoryID] = n
+	}
+
+	var roots []*model.CategorySpendingNode
+	var grandTotal int64
+	for _, n := range nodes {
+		if n.ParentID != nil {
+			if parent, ok := byID[*n.ParentID]; ok {
+				parent.Children = append(parent.Children, n)
+				continue
+			}
+		}
+		roots = append(roots, n)
+		grandTotal += n.TotalAmount
+	}
+
+	var walk func(level []*model.CategorySpendingNode, d int)
+	walk = func(level []*model.CategorySpendingNode, d int) {
+		for _, n := range level {
+			n.Depth = d
+			if grandTotal > 0 {
+				n.Percentage = float64(n.TotalAmount) / float64(grandTotal) * 100
+			}
+			if depth > 0 && d >= depth {
+				n.Children = nil
+				continue
+			}
+			walk(n.Children, d+1)
+		}
+	}
+	walk(roots, 1)
+
+	return roots
 }
 
-func (s *ReportService) GetSpendingByMember(ctx context.Context, month, year int) ([]*model.MemberSpending, error) {
-	return s.reportRepo.GetSpendingByMember(ctx, month, year)
+func (s *ReportService) GetSpendingByMember(ctx context.Context, householdID string, month, year int) ([]*model.MemberSpending, error) {
+	spending, err := s.reportRepo.GetSpendingByMember(ctx, householdID, month, year)
+	return spending, exchangeRateError(err)
 }
 
 func (s *ReportService) SearchTransactions(ctx context.Context, filters *model.SearchFilters) (*model.SearchResult, error) {
 	return s.reportRepo.SearchTransactions(ctx, filters)
 }
 
-// SearchTransactionsAll searches all transactions without user filter (admin)
-func (s *ReportService) SearchTransactionsAll(ctx context.Context, filters *model.SearchFilters) (*model.SearchResult, error) {
-	return s.reportRepo.SearchTransactionsAll(ctx, filters)
+// SearchTransactionsAll searches all transactions in a household without user filter (admin)
+func (s *ReportService) SearchTransactionsAll(ctx context.Context, householdID string, filters *model.SearchFilters) (*model.SearchResult, error) {
+	return s.reportRepo.SearchTransactionsAll(ctx, householdID, filters)
 }
 
 func (s *ReportService) GetTrends(ctx context.Context, userID string, months int) ([]*model.TrendPoint, error) {
-	return s.reportRepo.GetTrends(ctx, userID, months)
+	trends, err := s.reportRepo.GetTrends(ctx, userID, months)
+	return trends, exchangeRateError(err)
 }
 
-// GetTrendsAll returns trends for all users (admin)
-func (s *ReportService) GetTrendsAll(ctx context.Context, months int) ([]*model.TrendPoint, error) {
-	return s.reportRepo.GetTrendsAll(ctx, months)
+// GetTrendsAll returns trends for all users in a household (admin)
+func (s *ReportService) GetTrendsAll(ctx context.Context, householdID string, months int) ([]*model.TrendPoint, error) {
+	trends, err := s.reportRepo.GetTrendsAll(ctx, householdID, months)
+	return trends, exchangeRateError(err)
 }
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+)
+
+func spendingNode(id, parentID string, own, total int64) *model.CategorySpendingNode {
+	n := &model.CategorySpendingNode{CategoryID: id, CategoryName: id, OwnAmount: own, TotalAmount: total}
+	if parentID != "" {
+		n.ParentID = &parentID
+	}
+	return n
+}
+
+// Food 10000 (Restaurants 6000 (Sushi 2500)), Transport 5000
+func spendingNodes() []*model.CategorySpendingNode {
+	return []*model.CategorySpendingNode{
+		spendingNode("food", "", 1500, 10000),
+		spendingNode("restaurants", "food", 3500, 6000),
+		spendingNode("transport", "", 5000, 5000),
+		spendingNode("sushi", "restaurants", 2500, 2500),
+		spendingNode("groceries", "food", 2500, 2500),
+	}
+}
+
+func TestBuildSpendingTree(t *testing.T) {
+	roots := buildSpendingTree(spendingNodes(), 0)
+
+	if len(roots) != 2 {
+		t.Fatalf("Expected 2 top-level categories, got %d", len(roots))
+	}
+
+	food := roots[0]
+	if food.CategoryID != "food" || food.Depth != 1 {
+		t.Fatalf("Expected food at depth 1 first, got %s at depth %d", food.CategoryID, food.Depth)
+	}
+	if len(food.Children) != 2 || food.Children[0].CategoryID != "restaurants" || food.Children[1].CategoryID != "groceries" {
+		t.Fatalf("Expected restaurants and groceries under food in order, got %v", food.Children)
+	}
+
+	sushi := food.Children[0].Children[0]
+	if sushi.CategoryID != "sushi" || sushi.Depth != 3 {
+		t.Errorf("Expected sushi at depth 3, got %s at depth %d", sushi.CategoryID, sushi.Depth)
+	}
+
+	// Percentages are of all spending, counted once at the top level
+	if food.Percentage < 66.6 || food.Percentage > 66.7 {
+		t.Errorf("Expected food at 66.67%%, got %.2f", food.Percentage)
+	}
+	if sushi.Percentage < 16.6 || sushi.Percentage > 16.7 {
+		t.Errorf("Expected sushi at 16.67%%, got %.2f", sushi.Percentage)
+	}
+}
+
+func TestBuildSpendingTreeCollapsesToDepth(t *testing.T) {
+	roots := buildSpendingTree(spendingNodes(), 1)
+
+	for _, n := range roots {
+		if n.Children != nil {
+			t.Errorf("Expected %s collapsed, got %d children", n.CategoryID, len(n.Children))
+		}
+	}
+	if roots[0].TotalAmount != 10000 {
+		t.Errorf("Expected the collapsed subtotal to stay 10000, got %d", roots[0].TotalAmount)
+	}
+}
@@ -2,57 +2,434 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
 )
 
+var (
+	// ErrGoalAccountRequired is returned when money is moved in or out of a
+	// goal bound to an account without saying which account it comes from or
+	// goes to
+	ErrGoalAccountRequired = errors.New("an account to transfer from or to is required")
+
+	// ErrGoalNotLinked is returned when an account to transfer from or to is
+	// given for a goal that is not bound to an account
+	ErrGoalNotLinked = errors.New("the saving goal is not linked to an account")
+
+	// ErrGoalSameAccount is returned when the account to transfer from or to
+	// is the goal's own account
+	ErrGoalSameAccount = errors.New("cannot transfer to the same account")
+
+	// ErrGoalInsufficient is returned when withdrawing more than a goal holds
+	ErrGoalInsufficient = errors.New("cannot withdraw more than the saving goal holds")
+)
+
+// goalPaceMonths is how many recent months of contributions a goal's pace is
+// measured over
+const goalPaceMonths = 6
+
+// daysPerMonth is the length of an average month
+const daysPerMonth = 365.25 / 12
+
 type SavingGoalService struct {
-	savingGoalRepo *repository.SavingGoalRepository
+	uow                *repository.UnitOfWork
+	savingGoalRepo     *repository.SavingGoalRepository
+	transactionService *TransactionService
+	auditRepo          *repository.AuditRepository
 }
 
-func NewSavingGoalService(savingGoalRepo *repository.SavingGoalRepository) *SavingGoalService {
-	return &SavingGoalService{savingGoalRepo: savingGoalRepo}
+func NewSavingGoalService(
+	uow *repository.UnitOfWork,
+	savingGoalRepo *repository.SavingGoalRepository,
+	transactionService *TransactionService,
+	auditRepo *repository.AuditRepository,
+) *SavingGoalService {
+	return &SavingGoalService{
+		uow:                uow,
+		savingGoalRepo:     savingGoalRepo,
+		transactionService: transactionService,
+		auditRepo:          auditRepo,
+	}
+}
+
+func (s *SavingGoalService) Create(ctx context.Context, householdID string, req *model.CreateSavingGoalRequest) (*model.SavingGoal, error) {
+	var goal *model.SavingGoal
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		created, err := s.savingGoalRepo.WithTx(tx).Create(ctx, householdID, req)
+		if err != nil {
+			return err
+		}
+		if req.AccountID != nil && created.AccountID == nil {
+			return fmt.Errorf("account not found")
+		}
+
+		goal = created
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntitySavingGoal, created.ID, model.AuditActionCreate, nil, created)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return goal, nil
 }
 
-func (s *SavingGoalService) Create(ctx context.Context, req *model.CreateSavingGoalRequest) (*model.SavingGoal, error) {
-	return s.savingGoalRepo.Create(ctx, req)
+func (s *SavingGoalService) GetByID(ctx context.Context, householdID, id string) (*model.SavingGoal, error) {
+	return s.savingGoalRepo.FindByID(ctx, householdID, id)
 }
 
-func (s *SavingGoalService) GetByID(ctx context.Context, id string) (*model.SavingGoal, error) {
-	return s.savingGoalRepo.FindByID(ctx, id)
+// GetAll returns the household's goals, each with its projection as of today
+func (s *SavingGoalService) GetAll(ctx context.Context, householdID string, today time.Time) ([]*model.SavingGoal, error) {
+	goals, err := s.savingGoalRepo.FindAll(ctx, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, goal := range goals {
+		if goal.Status == "cancelled" {
+			continue
+		}
+		contributions, err := s.savingGoalRepo.FindContributions(ctx, householdID, goal.ID)
+		if err != nil {
+			return nil, err
+		}
+		goal.Projection = projectGoal(goal, contributions, today)
+	}
+
+	return goals, nil
 }
 
-func (s *SavingGoalService) GetAll(ctx context.Context) ([]*model.SavingGoal, error) {
-	return s.savingGoalRepo.FindAll(ctx)
+// GetProjection forecasts the goal as of today: when it completes at the pace
+// of its recent contributions, what it needs per month to reach its target
+// date, and whether it is on track
+func (s *SavingGoalService) GetProjection(ctx context.Context, householdID, id string, today time.Time) (*model.GoalProjection, error) {
+	goal, err := s.savingGoalRepo.FindByID(ctx, householdID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	contributions, err := s.savingGoalRepo.FindContributions(ctx, householdID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return projectGoal(goal, contributions, today), nil
 }
 
-func (s *SavingGoalService) Update(ctx context.Context, id string, req *model.UpdateSavingGoalRequest) (*model.SavingGoal, error) {
-	return s.savingGoalRepo.Update(ctx, id, req)
+func (s *SavingGoalService) Update(ctx context.Context, householdID, id string, req *model.UpdateSavingGoalRequest) (*model.SavingGoal, error) {
+	var goal *model.SavingGoal
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		savingGoalRepo := s.savingGoalRepo.WithTx(tx)
+
+		before, err := savingGoalRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		updated, err := savingGoalRepo.Update(ctx, householdID, id, req)
+		if err != nil {
+			return err
+		}
+		if req.AccountID != nil && updated.AccountID == nil {
+			return fmt.Errorf("account not found")
+		}
+
+		goal = updated
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntitySavingGoal, id, model.AuditActionUpdate, before, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return goal, nil
 }
 
-func (s *SavingGoalService) Contribute(ctx context.Context, id string, req *model.ContributeRequest) (*model.SavingGoal, error) {
-	goal, err := s.savingGoalRepo.FindByID(ctx, id)
+// Contribute puts money towards a goal. For a goal bound to an account, the
+// money is transferred there from the given account. A goal that reaches its
+// target is completed.
+func (s *SavingGoalService) Contribute(ctx context.Context, householdID, userID, id string, req *model.ContributeRequest) (*model.SavingGoal, error) {
+	date, err := contributionDate(req.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	var goal *model.SavingGoal
+	var transfer *model.Transaction
+
+	err = s.uow.Do(ctx, func(tx pgx.Tx) error {
+		savingGoalRepo := s.savingGoalRepo.WithTx(tx)
+
+		before, err := savingGoalRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		if before.Status != "active" {
+			return fmt.Errorf("cannot contribute to a %s goal", before.Status)
+		}
+
+		transferred, err := s.transferGoalMoney(ctx, tx, userID, before, req.Amount, req.FromAccountID, date)
+		if err != nil {
+			return err
+		}
+		var transactionID *string
+		if transferred != nil {
+			transactionID = &transferred.ID
+		}
+		transfer = transferred
+		if _, err := savingGoalRepo.CreateContribution(ctx, householdID, id, userID, transactionID, req.Amount, date); err != nil {
+			return err
+		}
+
+		updated, err := savingGoalRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		// Auto-complete if target reached
+		if updated.CurrentAmount >= updated.TargetAmount {
+			completedStatus := "completed"
+			updateReq := &model.UpdateSavingGoalRequest{Status: &completedStatus}
+			if updated, err = savingGoalRepo.Update(ctx, householdID, id, updateReq); err != nil {
+				return err
+			}
+		}
+
+		goal = updated
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntitySavingGoal, id, model.AuditActionContribute, before, updated)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if goal.Status != "active" {
-		return nil, fmt.Errorf("cannot contribute to a %s goal", goal.Status)
+	if transfer != nil {
+		s.transactionService.Booked(ctx, transfer)
 	}
+	return goal, nil
+}
+
+// Withdraw takes money out of a goal, up to what it holds. For a goal bound
+// to an account, the money is transferred from there to the given account.
+// A completed goal that drops below its target is active again.
+func (s *SavingGoalService) Withdraw(ctx context.Context, householdID, userID, id string, req *model.WithdrawRequest) (*model.SavingGoal, error) {
+	date, err := contributionDate(req.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	var goal *model.SavingGoal
+	var transfer *model.Transaction
+
+	err = s.uow.Do(ctx, func(tx pgx.Tx) error {
+		savingGoalRepo := s.savingGoalRepo.WithTx(tx)
 
-	updated, err := s.savingGoalRepo.Contribute(ctx, id, req.Amount)
+		before, err := savingGoalRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		if req.Amount > before.CurrentAmount {
+			return ErrGoalInsufficient
+		}
+
+		transferred, err := s.transferGoalMoney(ctx, tx, userID, before, -req.Amount, req.ToAccountID, date)
+		if err != nil {
+			return err
+		}
+		var transactionID *string
+		if transferred != nil {
+			transactionID = &transferred.ID
+		}
+		transfer = transferred
+		if _, err := savingGoalRepo.CreateContribution(ctx, householdID, id, userID, transactionID, -req.Amount, date); err != nil {
+			return err
+		}
+
+		updated, err := savingGoalRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		if updated.Status == "completed" && updated.CurrentAmount < updated.TargetAmount {
+			activeStatus := "active"
+			updateReq := &model.UpdateSavingGoalRequest{Status: &activeStatus}
+			if updated, err = savingGoalRepo.Update(ctx, householdID, id, updateReq); err != nil {
+				return err
+			}
+		}
+
+		goal = updated
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntitySavingGoal, id, model.AuditActionWithdraw, before, updated)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Auto-complete if target reached
-	if updated.CurrentAmount >= updated.TargetAmount {
-		completedStatus := "completed"
-		updateReq := &model.UpdateSavingGoalRequest{Status: &completedStatus}
-		return s.savingGoalRepo.Update(ctx, id, updateReq)
+	if transfer != nil {
+		s.transactionService.Booked(ctx, transfer)
+	}
+	return goal, nil
+}
+
+// GetContributions returns the goal's contributions and withdrawals, newest
+// first
+func (s *SavingGoalService) GetContributions(ctx context.Context, householdID, id string) ([]*model.GoalContribution, error) {
+	if _, err := s.savingGoalRepo.FindByID(ctx, householdID, id); err != nil {
+		return nil, err
+	}
+
+	return s.savingGoalRepo.FindContributions(ctx, householdID, id)
+}
+
+// transferGoalMoney moves amount into the goal's account from the other
+// account, or out of it for a negative amount, and returns the transfer. A
+// goal not bound to an account moves no money.
+func (s *SavingGoalService) transferGoalMoney(ctx context.Context, tx pgx.Tx, userID string, goal *model.SavingGoal, amount int64, otherAccountID *string, date time.Time) (*model.Transaction, error) {
+	if goal.AccountID == nil {
+		if otherAccountID != nil {
+			return nil, ErrGoalNotLinked
+		}
+		return nil, nil
+	}
+	if otherAccountID == nil {
+		return nil, ErrGoalAccountRequired
+	}
+	if *otherAccountID == *goal.AccountID {
+		return nil, ErrGoalSameAccount
+	}
+
+	from, to := *otherAccountID, *goal.AccountID
+	description := "Saving goal: " + goal.Name
+	if amount < 0 {
+		from, to, amount = to, from, -amount
+		description = "Withdrawal from saving goal: " + goal.Name
+	}
+
+	return s.transactionService.CreateTx(ctx, tx, userID, &model.CreateTransactionRequest{
+		AccountID:           from,
+		Amount:              -amount,
+		Type:                "transfer",
+		Description:         description,
+		Date:                date.Format("2006-01-02"),
+		IsShared:            true,
+		TransferToAccountID: &to,
+	})
+}
+
+// contributionDate parses the date of a contribution or withdrawal, which
+// defaults to today
+func contributionDate(date *string) (time.Time, error) {
+	if date == nil {
+		now := time.Now().UTC()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+
+	parsed, err := time.Parse("2006-01-02", *date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date format: %w", err)
+	}
+	return parsed, nil
+}
+
+func (s *SavingGoalService) Delete(ctx context.Context, householdID, id string) error {
+	return s.uow.Do(ctx, func(tx pgx.Tx) error {
+		savingGoalRepo := s.savingGoalRepo.WithTx(tx)
+
+		before, err := savingGoalRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := savingGoalRepo.Delete(ctx, householdID, id); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntitySavingGoal, id, model.AuditActionDelete, before, nil)
+	})
+}
+
+// projectGoal forecasts the goal from its contributions, newest first. The
+// pace is the net amount saved per month over the last six months, or since
+// the first contribution if that is more recent. A goal is on track when that
+// pace reaches its target by the target date.
+func projectGoal(goal *model.SavingGoal, contributions []*model.GoalContribution, today time.Time) *model.GoalProjection {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	projection := &model.GoalProjection{
+		Remaining:   max(goal.TargetAmount-goal.CurrentAmount, 0),
+		MonthlyPace: goalPace(contributions, today),
+	}
+
+	if projection.Remaining == 0 {
+		projection.Status = model.GoalCompleted
+		return projection
+	}
+
+	if projection.MonthlyPace > 0 {
+		days := math.Ceil(float64(projection.Remaining) / float64(projection.MonthlyPace) * daysPerMonth)
+		completion := today.AddDate(0, 0, int(days))
+		projection.ProjectedCompletion = &completion
+	}
+
+	if goal.TargetDate == nil {
+		projection.Status = model.GoalNoTargetDate
+		return projection
+	}
+
+	required := projection.Remaining
+	if months := monthsUntil(today, *goal.TargetDate); months > 0 {
+		required = (projection.Remaining + months - 1) / months
+	}
+	projection.RequiredMonthly = &required
+
+	projection.Status = model.GoalBehind
+	if projection.ProjectedCompletion != nil && !projection.ProjectedCompletion.After(*goal.TargetDate) {
+		projection.Status = model.GoalOnTrack
+	}
+
+	return projection
+}
+
+// goalPace returns the net amount contributed per month over the pace
+// window ending today
+func goalPace(contributions []*model.GoalContribution, today time.Time) int64 {
+	if len(contributions) == 0 {
+		return 0
+	}
+
+	start := today.AddDate(0, -goalPaceMonths, 0)
+	if first := contributions[len(contributions)-1].Date; first.After(start) {
+		start = first
 	}
 
-	return updated, nil
+	var net int64
+	for _, c := range contributions {
+		if !c.Date.Before(start) && !c.Date.After(today) {
+			net += c.Amount
+		}
+	}
+
+	months := max(today.Sub(start).Hours()/24/daysPerMonth, 1)
+	return int64(math.Round(float64(net) / months))
+}
+
+// monthsUntil returns how many monthly contributions can still be made
+// before the target date, counting a part month as a whole one
+func monthsUntil(today, target time.Time) int64 {
+	if !target.After(today) {
+		return 0
+	}
+
+	months := int64(target.Year()-today.Year())*12 + int64(target.Month()-today.Month())
+	if target.Day() > today.Day() {
+		months++
+	}
+	return max(months, 1)
 }
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// monthlyContributions returns amount contributed on the first of each month
+// from the first date through the last, newest first
+func monthlyContributions(from, through string, amount int64) []*model.GoalContribution {
+	var contributions []*model.GoalContribution
+	for d := date(through); !d.Before(date(from)); d = d.AddDate(0, -1, 0) {
+		contributions = append(contributions, &model.GoalContribution{Amount: amount, Date: d})
+	}
+	return contributions
+}
+
+func TestProjectGoal(t *testing.T) {
+	targetDate := func(s string) *time.Time {
+		d := date(s)
+		return &d
+	}
+
+	tests := []struct {
+		name           string
+		goal           *model.SavingGoal
+		contributions  []*model.GoalContribution
+		wantStatus     string
+		wantPace       int64
+		wantCompletion string // empty for none
+		wantRequired   *int64
+	}{
+		{
+			name:           "on track",
+			goal:           &model.SavingGoal{TargetAmount: 120000, CurrentAmount: 60000, TargetDate: targetDate("2026-12-31")},
+			contributions:  monthlyContributions("2026-01-01", "2026-06-01", 10000),
+			wantStatus:     model.GoalOnTrack,
+			wantPace:       10090,
+			wantCompletion: "2026-12-29",
+			wantRequired:   ptr(int64(10000)),
+		},
+		{
+			name:           "behind",
+			goal:           &model.SavingGoal{TargetAmount: 120000, CurrentAmount: 60000, TargetDate: targetDate("2026-10-31")},
+			contributions:  monthlyContributions("2026-01-01", "2026-06-01", 10000),
+			wantStatus:     model.GoalBehind,
+			wantPace:       10090,
+			wantCompletion: "2026-12-29",
+			wantRequired:   ptr(int64(15000)),
+		},
+		{
+			name:          "pace only counts the last six months",
+			goal:          &model.SavingGoal{TargetAmount: 500000, CurrentAmount: 120000},
+			contributions: monthlyContributions("2025-07-01", "2026-06-01", 10000),
+			wantStatus:    model.GoalNoTargetDate,
+			wantPace:      10090,
+			// 380000 left at 10090 a month
+			wantCompletion: "2029-08-21",
+		},
+		{
+			name:          "withdrawals slow the pace",
+			goal:          &model.SavingGoal{TargetAmount: 100000, CurrentAmount: 10000, TargetDate: targetDate("2026-12-31")},
+			contributions: append([]*model.GoalContribution{{Amount: -30000, Date: date("2026-06-15")}}, monthlyContributions("2026-01-01", "2026-06-01", 10000)...),
+			wantStatus:    model.GoalBehind,
+			wantPace:      5045,
+			// 90000 left at 5045 a month
+			wantCompletion: "2027-12-26",
+			wantRequired:   ptr(int64(15000)),
+		},
+		{
+			name:         "no contributions",
+			goal:         &model.SavingGoal{TargetAmount: 60000, TargetDate: targetDate("2026-12-31")},
+			wantStatus:   model.GoalBehind,
+			wantRequired: ptr(int64(10000)),
+		},
+		{
+			name:          "target date passed",
+			goal:          &model.SavingGoal{TargetAmount: 60000, CurrentAmount: 50000, TargetDate: targetDate("2026-06-30")},
+			contributions: monthlyContributions("2026-02-01", "2026-06-01", 10000),
+			wantStatus:    model.GoalBehind,
+			wantPace:      10146,
+			// 10000 left at 10146 a month
+			wantCompletion: "2026-07-31",
+			wantRequired:   ptr(int64(10000)),
+		},
+		{
+			name:          "completed",
+			goal:          &model.SavingGoal{TargetAmount: 60000, CurrentAmount: 60000, TargetDate: targetDate("2026-12-31")},
+			contributions: monthlyContributions("2026-01-01", "2026-06-01", 10000),
+			wantStatus:    model.GoalCompleted,
+			wantPace:      10090,
+		},
+	}
+
+	today := date("2026-07-01")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := projectGoal(tt.goal, tt.contributions, today)
+
+			if p.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", p.Status, tt.wantStatus)
+			}
+			if p.MonthlyPace != tt.wantPace {
+				t.Errorf("monthly pace = %d, want %d", p.MonthlyPace, tt.wantPace)
+			}
+
+			var completion string
+			if p.ProjectedCompletion != nil {
+				completion = p.ProjectedCompletion.Format("2006-01-02")
+			}
+			if completion != tt.wantCompletion {
+				t.Errorf("projected completion = %q, want %q", completion, tt.wantCompletion)
+			}
+
+			switch {
+			case tt.wantRequired == nil && p.RequiredMonthly != nil:
+				t.Errorf("required monthly = %d, want none", *p.RequiredMonthly)
+			case tt.wantRequired != nil && (p.RequiredMonthly == nil || *p.RequiredMonthly != *tt.wantRequired):
+				t.Errorf("required monthly = %v, want %d", p.RequiredMonthly, *tt.wantRequired)
+			}
+		})
+	}
+}
+
+func TestMonthsUntil(t *testing.T) {
+	tests := []struct {
+		today  string
+		target string
+		want   int64
+	}{
+		{"2026-07-01", "2026-12-31", 6},
+		{"2026-07-15", "2026-08-10", 1},
+		{"2026-07-15", "2026-07-20", 1},
+		{"2026-07-15", "2026-09-15", 2},
+		{"2026-07-15", "2026-07-15", 0},
+		{"2026-07-15", "2026-06-30", 0},
+	}
+
+	for _, tt := range tests {
+		if got := monthsUntil(date(tt.today), date(tt.target)); got != tt.want {
+			t.Errorf("monthsUntil(%s, %s) = %d, want %d", tt.today, tt.target, got, tt.want)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
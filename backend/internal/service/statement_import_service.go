@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type StatementImportService struct {
+	transactionService *TransactionService
+	transactionRepo    *repository.TransactionRepository
+	accountRepo        *repository.AccountRepository
+	importProfileRepo  *repository.ImportProfileRepository
+	ruleRepo           *repository.CategorizationRuleRepository
+}
+
+func NewStatementImportService(
+	transactionService *TransactionService,
+	transactionRepo *repository.TransactionRepository,
+	accountRepo *repository.AccountRepository,
+	importProfileRepo *repository.ImportProfileRepository,
+	ruleRepo *repository.CategorizationRuleRepository,
+) *StatementImportService {
+	return &StatementImportService{
+		transactionService: transactionService,
+		transactionRepo:    transactionRepo,
+		accountRepo:        accountRepo,
+		importProfileRepo:  importProfileRepo,
+		ruleRepo:           ruleRepo,
+	}
+}
+
+// Import books the lines of an OFX/QFX, CAMT.053 or profiled CSV statement on
+// the target account. Each transaction keeps the bank's reference as its
+// external ID, so lines already imported into the account are counted as
+// duplicates instead of being booked twice.
+func (s *StatementImportService) Import(ctx context.Context, householdID, userID string, req *model.ImportStatementRequest, r io.Reader) (*model.ImportStatementResponse, error) {
+	lines, seen, err := s.prepare(ctx, householdID, req, r)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.rules(ctx, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ImportStatementResponse{Errors: []string{}}
+
+	for _, line := range lines {
+		if len(line.Errors) > 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: %s", line.Row, strings.Join(line.Errors, "; ")))
+			continue
+		}
+		if line.ExternalID != "" && seen[line.ExternalID] {
+			result.Duplicates++
+			continue
+		}
+
+		// Rules already ran above, so the line is booked as categorized there
+		if _, err := s.transactionService.create(ctx, userID, transactionRequestForLine(req, line, rules)); err != nil {
+			// Another import of the same statement may have won the race
+			if isUniqueViolation(err) {
+				result.Duplicates++
+				continue
+			}
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: %s", line.Row, err.Error()))
+			continue
+		}
+
+		if line.ExternalID != "" {
+			seen[line.ExternalID] = true
+		}
+		result.Imported++
+	}
+
+	return result, nil
+}
+
+// Preview parses a statement the same way Import does and reports what each
+// line would become, without booking anything
+func (s *StatementImportService) Preview(ctx context.Context, householdID string, req *model.ImportStatementRequest, r io.Reader) (*model.ImportPreviewResponse, error) {
+	lines, seen, err := s.prepare(ctx, householdID, req, r)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.rules(ctx, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ImportPreviewResponse{Rows: []*model.ImportPreviewRow{}}
+
+	// Repeated references within the file are duplicates too
+	inFile := map[string]bool{}
+
+	for _, line := range lines {
+		row := &model.ImportPreviewRow{
+			Row:         line.Row,
+			Amount:      line.Amount,
+			Description: line.Description,
+			ExternalID:  line.ExternalID,
+			Errors:      line.Errors,
+		}
+		if !line.Date.IsZero() {
+			row.Date = line.Date.Format("2006-01-02")
+		}
+
+		switch {
+		case len(line.Errors) > 0:
+			row.Status = model.ImportRowInvalid
+			result.Invalid++
+		case line.ExternalID != "" && (seen[line.ExternalID] || inFile[line.ExternalID]):
+			row.Status = model.ImportRowDuplicate
+			result.Duplicates++
+		default:
+			create := transactionRequestForLine(req, line, rules)
+			row.Status = model.ImportRowValid
+			row.Type = create.Type
+			row.CategoryID = create.CategoryID
+			result.Valid++
+		}
+
+		if line.ExternalID != "" {
+			inFile[line.ExternalID] = true
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, nil
+}
+
+// prepare parses the statement, checks its lines against the target account
+// and looks up which of their external IDs the account already has
+func (s *StatementImportService) prepare(ctx context.Context, householdID string, req *model.ImportStatementRequest, r io.Reader) ([]*model.StatementLine, map[string]bool, error) {
+	account, err := s.accountRepo.FindByID(ctx, householdID, req.AccountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	format := req.Format
+	var profile *model.ImportProfile
+	if req.ProfileID != "" {
+		if format == "" {
+			format = model.StatementFormatCSV
+		}
+		if profile, err = s.importProfileRepo.FindByID(ctx, householdID, req.ProfileID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	lines, err := parseStatement(format, profile, r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var externalIDs []string
+	for _, line := range lines {
+		if line.Currency != "" && line.Currency != account.Currency {
+			line.Errors = append(line.Errors, fmt.Sprintf("currency %s does not match account currency %s", line.Currency, account.Currency))
+		}
+		if line.ExternalID != "" {
+			externalIDs = append(externalIDs, line.ExternalID)
+		}
+	}
+
+	seen, err := s.transactionRepo.FindExternalIDs(ctx, householdID, req.AccountID, externalIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return lines, seen, nil
+}
+
+func (s *StatementImportService) rules(ctx context.Context, householdID string) (*ruleSet, error) {
+	rules, err := s.ruleRepo.FindActive(ctx, householdID)
+	if err != nil {
+		return nil, err
+	}
+	return newRuleSet(rules), nil
+}
+
+// transactionRequestForLine builds the transaction for a statement line. The
+// categorization rules go first; the request's categories only fill in what
+// no rule did.
+func transactionRequestForLine(req *model.ImportStatementRequest, line *model.StatementLine, rules *ruleSet) *model.CreateTransactionRequest {
+	create := &model.CreateTransactionRequest{
+		AccountID:    req.AccountID,
+		Amount:       line.Amount,
+		Type:         "expense",
+		Description:  line.Description,
+		Counterparty: line.Counterparty,
+		Date:         line.Date.Format("2006-01-02"),
+		IsShared:     true,
+		Status:       model.TransactionCleared, // the bank has already booked it
+	}
+	if line.Amount > 0 {
+		create.Type = "income"
+	}
+
+	rules.apply(ruleSubject{
+		AccountID:    create.AccountID,
+		Amount:       create.Amount,
+		Description:  create.Description,
+		Counterparty: create.Counterparty,
+	}).applyTo(create)
+
+	if create.CategoryID == "" {
+		create.CategoryID = req.CategoryID
+		if create.Type == "income" && req.IncomeCategoryID != "" {
+			create.CategoryID = req.IncomeCategoryID
+		}
+	}
+	if line.ExternalID != "" {
+		externalID := line.ExternalID
+		create.ExternalID = &externalID
+	}
+	return create
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+)
+
+// detectStatementFormat guesses the format of a statement file from its
+// first bytes: OFX files carry an OFXHEADER or <OFX> tag, CAMT.053 files a
+// BkToCstmrStmt element
+func detectStatementFormat(data []byte) (string, error) {
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	upper := bytes.ToUpper(head)
+
+	switch {
+	case bytes.Contains(head, []byte("BkToCstmrStmt")):
+		return model.StatementFormatCAMT053, nil
+	case bytes.Contains(upper, []byte("OFXHEADER")), bytes.Contains(upper, []byte("<OFX>")):
+		return model.StatementFormatOFX, nil
+	}
+	return "", fmt.Errorf("unrecognised statement format")
+}
+
+// parseStatement reads every line of a statement file. The profile is only
+// used for CSV files, which need one to be understood.
+func parseStatement(format string, profile *model.ImportProfile, r io.Reader) ([]*model.StatementLine, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read statement file")
+	}
+
+	if format == "" {
+		if format, err = detectStatementFormat(data); err != nil {
+			return nil, err
+		}
+	}
+
+	switch format {
+	case model.StatementFormatOFX:
+		return parseOFX(data)
+	case model.StatementFormatCAMT053:
+		return parseCAMT053(data)
+	case model.StatementFormatCSV:
+		if profile == nil {
+			return nil, fmt.Errorf("an import profile is required for CSV files")
+		}
+		return parseProfileCSV(profile, data)
+	}
+	return nil, fmt.Errorf("unsupported statement format %q", format)
+}
+
+var (
+	ofxTxnStart      = regexp.MustCompile(`(?i)<STMTTRN>`)
+	ofxTxnEnd        = regexp.MustCompile(`(?i)</STMTTRN>|</BANKTRANLIST>`)
+	ofxCurDefPattern = regexp.MustCompile(`(?i)<CURDEF>\s*([A-Za-z]{3})`)
+)
+
+// ofxField returns the value of the first <tag> in block. OFX 1.x is SGML
+// and leaves leaf elements unclosed, so the value runs to the next tag.
+func ofxField(block, tag string) string {
+	re := regexp.MustCompile(`(?is)<` + tag + `>([^<]*)`)
+	m := re.FindStringSubmatch(block)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(html.UnescapeString(m[1]))
+}
+
+// parseOFX reads the STMTTRN records of an OFX or QFX file. Records without
+// a FITID, amount or posting date come back with errors set.
+func parseOFX(data []byte) ([]*model.StatementLine, error) {
+	body := string(data)
+	if !strings.Contains(strings.ToUpper(body), "<OFX>") {
+		return nil, fmt.Errorf("not an OFX file")
+	}
+
+	var currency string
+	if m := ofxCurDefPattern.FindStringSubmatch(body); m != nil {
+		currency = strings.ToUpper(m[1])
+	}
+
+	var lines []*model.StatementLine
+
+	for i, block := range splitOFXTransactions(body) {
+		line := &model.StatementLine{Row: i + 1, Currency: currency}
+		lines = append(lines, line)
+
+		line.ExternalID = ofxField(block, "FITID")
+		if line.ExternalID == "" {
+			line.Errors = append(line.Errors, "missing FITID")
+		}
+
+		amount, err := parseDecimalCents(ofxField(block, "TRNAMT"))
+		if err != nil {
+			line.Errors = append(line.Errors, "invalid amount")
+		}
+		line.Amount = amount
+
+		date, err := parseOFXDate(ofxField(block, "DTPOSTED"))
+		if err != nil {
+			line.Errors = append(line.Errors, "invalid date")
+		}
+		line.Date = date
+
+		line.Description = ofxField(block, "NAME")
+		line.Counterparty = line.Description
+		if memo := ofxField(block, "MEMO"); memo != "" && memo != line.Description {
+			if line.Description == "" {
+				line.Description = memo
+			} else {
+				line.Description += " - " + memo
+			}
+		}
+	}
+
+	return lines, nil
+}
+
+// splitOFXTransactions returns the contents of each STMTTRN aggregate.
+// Splitting on the opening tag copes with SGML files that omit closing tags.
+func splitOFXTransactions(body string) []string {
+	parts := ofxTxnStart.Split(body, -1)
+	if len(parts) < 2 {
+		return nil
+	}
+
+	blocks := make([]string, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		if loc := ofxTxnEnd.FindStringIndex(part); loc != nil {
+			part = part[:loc[0]]
+		}
+		blocks = append(blocks, part)
+	}
+	return blocks
+}
+
+// parseOFXDate reads the date part of an OFX datetime
+// (YYYYMMDD[HHMMSS[.XXX]][[gmt offset:tz name]])
+func parseOFXDate(s string) (time.Time, error) {
+	if len(s) < 8 {
+		return time.Time{}, fmt.Errorf("invalid OFX date %q", s)
+	}
+	return time.Parse("20060102", s[:8])
+}
+
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	Currency string      `xml:"Acct>Ccy"`
+	Entries  []camtEntry `xml:"Ntry"`
+}
+
+type camtEntry struct {
+	NtryRef string `xml:"NtryRef"`
+	Amount  struct {
+		Value    string `xml:",chardata"`
+		Currency string `xml:"Ccy,attr"`
+	} `xml:"Amt"`
+	CreditDebit string `xml:"CdtDbtInd"`
+	Status      struct {
+		Value string `xml:",chardata"`
+		Code  string `xml:"Cd"` // camt.053.001.08 and later
+	} `xml:"Sts"`
+	BookingDate    camtDate        `xml:"BookgDt"`
+	ValueDate      camtDate        `xml:"ValDt"`
+	AcctSvcrRef    string          `xml:"AcctSvcrRef"`
+	AdditionalInfo string          `xml:"AddtlNtryInf"`
+	Details        []camtTxDetails `xml:"NtryDtls>TxDtls"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+func (d camtDate) value() string {
+	if d.Date != "" {
+		return d.Date
+	}
+	if len(d.DateTime) >= 10 {
+		return d.DateTime[:10]
+	}
+	return ""
+}
+
+type camtTxDetails struct {
+	AcctSvcrRef     string   `xml:"Refs>AcctSvcrRef"`
+	Unstructured    []string `xml:"RmtInf>Ustrd"`
+	CreditorName    string   `xml:"RltdPties>Cdtr>Nm"`
+	CreditorPtyName string   `xml:"RltdPties>Cdtr>Pty>Nm"`
+	DebtorName      string   `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPtyName   string   `xml:"RltdPties>Dbtr>Pty>Nm"`
+}
+
+// counterparty is the other side of the entry: the creditor of a debit or
+// the debtor of a credit
+func (d camtTxDetails) counterparty(debit bool) string {
+	if debit {
+		return firstNonEmpty(d.CreditorName, d.CreditorPtyName)
+	}
+	return firstNonEmpty(d.DebtorName, d.DebtorPtyName)
+}
+
+// parseCAMT053 reads the booked entries of an ISO 20022 camt.053 statement.
+// Pending entries are left out since the bank may still change them; entries
+// without a bank reference, amount or booking date come back with errors set.
+func parseCAMT053(data []byte) ([]*model.StatementLine, error) {
+	var doc camtDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse CAMT.053 file")
+	}
+	if len(doc.Statements) == 0 {
+		return nil, fmt.Errorf("not a CAMT.053 file")
+	}
+
+	var lines []*model.StatementLine
+	n := 0
+
+	for _, stmt := range doc.Statements {
+		for _, e := range stmt.Entries {
+			n++
+
+			status := strings.TrimSpace(firstNonEmpty(e.Status.Code, e.Status.Value))
+			if status != "" && status != "BOOK" {
+				continue
+			}
+
+			line := &model.StatementLine{
+				Row:      n,
+				Currency: strings.ToUpper(firstNonEmpty(e.Amount.Currency, stmt.Currency)),
+			}
+			lines = append(lines, line)
+
+			externalID := e.AcctSvcrRef
+			if externalID == "" && len(e.Details) > 0 {
+				externalID = e.Details[0].AcctSvcrRef
+			}
+			if externalID == "" {
+				externalID = e.NtryRef
+			}
+			line.ExternalID = strings.TrimSpace(externalID)
+			if line.ExternalID == "" {
+				line.Errors = append(line.Errors, "missing AcctSvcrRef")
+			}
+
+			amount, err := parseDecimalCents(e.Amount.Value)
+			if err != nil || amount < 0 {
+				line.Errors = append(line.Errors, "invalid amount")
+			}
+
+			var debit bool
+			switch strings.TrimSpace(e.CreditDebit) {
+			case "DBIT":
+				debit = true
+				amount = -amount
+			case "CRDT":
+			default:
+				line.Errors = append(line.Errors, "invalid credit/debit indicator")
+			}
+			line.Amount = amount
+
+			date, err := time.Parse("2006-01-02", firstNonEmpty(e.BookingDate.value(), e.ValueDate.value()))
+			if err != nil {
+				line.Errors = append(line.Errors, "invalid date")
+			}
+			line.Date = date
+
+			var parts []string
+			for _, d := range e.Details {
+				if name := strings.TrimSpace(d.counterparty(debit)); name != "" {
+					parts = append(parts, name)
+					if line.Counterparty == "" {
+						line.Counterparty = name
+					}
+				}
+				for _, u := range d.Unstructured {
+					if u = strings.TrimSpace(u); u != "" {
+						parts = append(parts, u)
+					}
+				}
+			}
+			line.Description = strings.Join(parts, " - ")
+			if line.Description == "" {
+				line.Description = strings.TrimSpace(e.AdditionalInfo)
+			}
+		}
+	}
+
+	return lines, nil
+}
+
+// parseDecimalCents converts a decimal amount such as "-12.5" or "1234,56"
+// to cents without going through floating point
+func parseDecimalCents(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, ".") {
+		s = strings.Replace(s, ",", ".", 1)
+	}
+
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	// Sub-cent digits must be zero; rounding them away would change the amount
+	if len(frac) > 2 {
+		if strings.Trim(frac[2:], "0") != "" {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		frac = frac[:2]
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+
+	units, err := strconv.ParseUint(whole, 10, 63)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	cents, err := strconv.ParseUint(frac, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	// Anything beyond int64 cents cannot be stored and would wrap around
+	if units > (math.MaxInt64-cents)/100 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	amount := int64(units)*100 + int64(cents)
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -2,83 +2,263 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrTransactionReconciled is returned when changing a transaction that has
+	// been reconciled against a bank statement and not unlocked since
+	ErrTransactionReconciled = errors.New("transaction is reconciled; unlock it first")
+
+	// ErrTransactionPendingApproval is returned when changing a child's
+	// purchase that still waits for approval
+	ErrTransactionPendingApproval = errors.New("transaction is waiting for approval")
+
+	// ErrTransactionRejected is returned when changing a child's purchase that
+	// was rejected
+	ErrTransactionRejected = errors.New("transaction was rejected")
+
+	// ErrTransactionNotPending is returned when approving or rejecting a
+	// transaction that is not waiting for approval
+	ErrTransactionNotPending = errors.New("transaction is not waiting for approval")
+
+	// ErrTransactionAmountSign is returned when a child books an expense with
+	// a positive amount or income with a negative one, which would move money
+	// out of their account without counting as a purchase
+	ErrTransactionAmountSign = errors.New("expenses need a negative amount and income a positive one")
+
+	// ErrChildTransfer is returned when a child books a transfer. Children
+	// only spend through expenses, which their approval threshold applies to.
+	ErrChildTransfer = errors.New("children cannot make transfers")
 )
 
 type TransactionService struct {
+	uow             *repository.UnitOfWork
 	transactionRepo *repository.TransactionRepository
 	accountRepo     *repository.AccountRepository
+	userRepo        *repository.UserRepository
+	ruleRepo        *repository.CategorizationRuleRepository
+	auditRepo       *repository.AuditRepository
+	notifications   *NotificationService
 }
 
-func NewTransactionService(transactionRepo *repository.TransactionRepository, accountRepo *repository.AccountRepository) *TransactionService {
+func NewTransactionService(uow *repository.UnitOfWork, transactionRepo *repository.TransactionRepository, accountRepo *repository.AccountRepository, userRepo *repository.UserRepository, ruleRepo *repository.CategorizationRuleRepository, auditRepo *repository.AuditRepository, notifications *NotificationService) *TransactionService {
 	return &TransactionService{
+		uow:             uow,
 		transactionRepo: transactionRepo,
 		accountRepo:     accountRepo,
+		userRepo:        userRepo,
+		ruleRepo:        ruleRepo,
+		auditRepo:       auditRepo,
+		notifications:   notifications,
 	}
 }
 
+// Create books a transaction after running the household's categorization
+// rules over it. Transfers are left alone.
 func (s *TransactionService) Create(ctx context.Context, userID string, req *model.CreateTransactionRequest) (*model.Transaction, error) {
-	// Create transaction
-	transaction, err := s.transactionRepo.Create(ctx, userID, req)
+	req, err := s.categorize(ctx, userID, req)
 	if err != nil {
 		return nil, err
 	}
 
-	// Update account balance
-	if err := s.accountRepo.UpdateBalance(ctx, req.AccountID, req.Amount); err != nil {
-		return nil, err
-	}
+	return s.create(ctx, userID, req)
+}
 
-	// If it's a transfer, update the destination account as well
-	if req.Type == "transfer" && req.TransferToAccountID != nil {
-		if err := s.accountRepo.UpdateBalance(ctx, *req.TransferToAccountID, -req.Amount); err != nil {
-			return nil, err
-		}
+// CreateTx books a transaction inside tx the way Create does, for callers
+// that book it as part of a larger change. Once tx is committed they pass the
+// transaction to Booked.
+func (s *TransactionService) CreateTx(ctx context.Context, tx pgx.Tx, userID string, req *model.CreateTransactionRequest) (*model.Transaction, error) {
+	req, err := s.categorize(ctx, userID, req)
+	if err != nil {
+		return nil, err
 	}
 
-	return transaction, nil
+	return s.book(ctx, tx, userID, req)
 }
 
-func (s *TransactionService) GetByID(ctx context.Context, id string) (*model.Transaction, error) {
-	return s.transactionRepo.FindByID(ctx, id)
+// Booked raises the notifications for a transaction booked with CreateTx
+func (s *TransactionService) Booked(ctx context.Context, transaction *model.Transaction) {
+	if transaction.AffectsBalance() {
+		s.notifications.TransactionChanged(ctx, transaction)
+	}
 }
 
-func (s *TransactionService) GetByUserID(ctx context.Context, userID string, filters *model.TransactionFilters) ([]*model.Transaction, error) {
-	return s.transactionRepo.FindByUserID(ctx, userID, filters)
+// categorize returns req with the household's categorization rules applied
+func (s *TransactionService) categorize(ctx context.Context, userID string, req *model.CreateTransactionRequest) (*model.CreateTransactionRequest, error) {
+	if req.Type == "transfer" {
+		return req, nil
+	}
+
+	rules, err := s.ruleRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return req, nil
+	}
+
+	categorized := *req
+	newRuleSet(rules).apply(ruleSubject{
+		AccountID:    req.AccountID,
+		Amount:       req.Amount,
+		Description:  req.Description,
+		Counterparty: req.Counterparty,
+	}).applyTo(&categorized)
+	return &categorized, nil
 }
 
-func (s *TransactionService) GetAll(ctx context.Context, filters *model.TransactionFilters) ([]*model.Transaction, error) {
-	return s.transactionRepo.FindAll(ctx, filters)
+// create books req as given, without running categorization rules
+func (s *TransactionService) create(ctx context.Context, userID string, req *model.CreateTransactionRequest) (*model.Transaction, error) {
+	var transaction *model.Transaction
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		created, err := s.book(ctx, tx, userID, req)
+		if err != nil {
+			return err
+		}
+
+		transaction = created
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Booked(ctx, transaction)
+	return transaction, nil
 }
 
-func (s *TransactionService) Update(ctx context.Context, id string, req *model.UpdateTransactionRequest) (*model.Transaction, error) {
-	// Get original transaction to calculate balance difference
-	original, err := s.transactionRepo.FindByID(ctx, id)
+// book inserts req inside tx with its splits, balance change and audit
+// record. A child's expense above their approval threshold is held for
+// approval and leaves the balance alone until it is approved.
+func (s *TransactionService) book(ctx context.Context, tx pgx.Tx, userID string, req *model.CreateTransactionRequest) (*model.Transaction, error) {
+	if err := validateSplits(req.Type, req.Amount, req.Splits); err != nil {
+		return nil, err
+	}
+
+	held, err := s.needsApproval(ctx, userID, req.Type, req.Amount)
 	if err != nil {
 		return nil, err
 	}
+	if held {
+		pending := *req
+		pending.Status = model.TransactionPendingApproval
+		req = &pending
+	}
+
+	transactionRepo := s.transactionRepo.WithTx(tx)
 
-	// Update transaction
-	updated, err := s.transactionRepo.Update(ctx, id, req)
+	created, err := transactionRepo.Create(ctx, userID, req)
 	if err != nil {
 		return nil, err
 	}
 
-	// If amount or account changed, adjust balances
-	if req.Amount != nil || req.AccountID != nil {
-		// Reverse original balance change
-		if err := s.accountRepo.UpdateBalance(ctx, original.AccountID, -original.Amount); err != nil {
+	// The destination account is resolved within the user's household;
+	// an unknown one leaves the column empty rather than failing the insert
+	if req.TransferToAccountID != nil && created.TransferToAccountID == nil {
+		return nil, fmt.Errorf("transfer account not found")
+	}
+	if req.CategoryID != "" && created.CategoryID == nil {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	if len(req.Splits) > 0 {
+		if created.Splits, err = transactionRepo.ReplaceSplits(ctx, created.ID, req.Splits); err != nil {
 			return nil, err
 		}
+	}
+
+	if created.AffectsBalance() {
+		if err := applyBalance(ctx, s.accountRepo.WithTx(tx), created, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := recordUserAudit(ctx, s.auditRepo.WithTx(tx), userID, model.AuditEntityTransaction, created.ID, model.AuditActionCreate, nil, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// needsApproval reports whether the transaction is a child's purchase above
+// their approval threshold. So that the threshold covers all the money a
+// child spends, their transfers fail with ErrChildTransfer and amounts of the
+// wrong sign with ErrTransactionAmountSign.
+func (s *TransactionService) needsApproval(ctx context.Context, userID, transactionType string, amount int64) (bool, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if user.Role != "child" {
+		return false, nil
+	}
+	switch {
+	case transactionType == "transfer":
+		return false, ErrChildTransfer
+	case transactionType == "expense" && amount > 0, transactionType == "income" && amount < 0:
+		return false, ErrTransactionAmountSign
+	}
+	if transactionType != "expense" || user.ApprovalThreshold == nil {
+		return false, nil
+	}
+
+	return -amount > *user.ApprovalThreshold, nil
+}
+
+// applyBalance adds the transaction's amount, times sign, to its account, and
+// for a transfer takes it from the destination account
+func applyBalance(ctx context.Context, accountRepo *repository.AccountRepository, transaction *model.Transaction, sign int64) error {
+	if err := accountRepo.UpdateBalance(ctx, transaction.AccountID, sign*transaction.Amount); err != nil {
+		return err
+	}
+
+	if transaction.Type == "transfer" && transaction.TransferToAccountID != nil {
+		if err := accountRepo.UpdateBalance(ctx, *transaction.TransferToAccountID, -sign*transaction.Amount); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *TransactionService) GetByID(ctx context.Context, householdID, id string) (*model.Transaction, error) {
+	return s.transactionRepo.FindByID(ctx, householdID, id)
+}
+
+func (s *TransactionService) GetByUserID(ctx context.Context, userID string, filters *model.TransactionFilters) (*model.TransactionPage, error) {
+	return s.transactionRepo.FindByUserID(ctx, userID, filters)
+}
+
+func (s *TransactionService) GetAll(ctx context.Context, householdID string, filters *model.TransactionFilters) (*model.TransactionPage, error) {
+	return s.transactionRepo.FindAll(ctx, householdID, filters)
+}
+
+func (s *TransactionService) Update(ctx context.Context, householdID, id string, req *model.UpdateTransactionRequest) (*model.Transaction, error) {
+	var updated *model.Transaction
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		transactionRepo := s.transactionRepo.WithTx(tx)
+		accountRepo := s.accountRepo.WithTx(tx)
 
-		// Apply new balance change
-		newAccountID := original.AccountID
-		if req.AccountID != nil {
-			newAccountID = *req.AccountID
+		// Get original transaction to calculate balance difference
+		original, err := transactionRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+		switch original.Status {
+		case model.TransactionReconciled:
+			return ErrTransactionReconciled
+		case model.TransactionPendingApproval:
+			return ErrTransactionPendingApproval
+		case model.TransactionRejected:
+			return ErrTransactionRejected
 		}
 
 		newAmount := original.Amount
@@ -86,35 +266,197 @@ func (s *TransactionService) Update(ctx context.Context, id string, req *model.U
 			newAmount = *req.Amount
 		}
 
-		if err := s.accountRepo.UpdateBalance(ctx, newAccountID, newAmount); err != nil {
-			return nil, err
+		// Existing splits must still add up when only the amount changes
+		splits := req.Splits
+		if splits == nil && len(original.Splits) > 0 && newAmount != original.Amount {
+			return fmt.Errorf("split amounts must add up to the transaction amount")
+		}
+		if splits != nil {
+			if err := validateSplits(original.Type, newAmount, *splits); err != nil {
+				return err
+			}
+		}
+
+		// Raising a child's purchase above their threshold sends it back for
+		// approval
+		held := false
+		if newAmount != original.Amount {
+			if held, err = s.needsApproval(ctx, original.UserID, original.Type, newAmount); err != nil {
+				return err
+			}
+		}
+		if held {
+			status := model.TransactionPendingApproval
+			pending := *req
+			pending.Status = &status
+			req = &pending
+		}
+
+		// Update transaction
+		updated, err = transactionRepo.Update(ctx, householdID, id, req)
+		if err != nil {
+			return err
+		}
+		if req.CategoryID != nil && updated.CategoryID == nil {
+			return fmt.Errorf("category not found")
+		}
+
+		if splits != nil {
+			if updated.Splits, err = transactionRepo.ReplaceSplits(ctx, id, *splits); err != nil {
+				return err
+			}
 		}
+
+		// If amount or account changed, adjust balances, on both accounts of
+		// a transfer
+		if req.Amount != nil || req.AccountID != nil {
+			// Reverse original balance change
+			if err := applyBalance(ctx, accountRepo, original, -1); err != nil {
+				return err
+			}
+
+			// Apply new balance change, unless it waits for approval now
+			if updated.AffectsBalance() {
+				if err := applyBalance(ctx, accountRepo, updated, 1); err != nil {
+					return err
+				}
+			}
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityTransaction, id, model.AuditActionUpdate, original, updated)
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	if updated.AffectsBalance() {
+		s.notifications.TransactionChanged(ctx, updated)
+	}
 	return updated, nil
 }
 
-func (s *TransactionService) Delete(ctx context.Context, id string) error {
-	// Get transaction to reverse balance change
-	transaction, err := s.transactionRepo.FindByID(ctx, id)
+func (s *TransactionService) Delete(ctx context.Context, householdID, id string) error {
+	return s.uow.Do(ctx, func(tx pgx.Tx) error {
+		transactionRepo := s.transactionRepo.WithTx(tx)
+		accountRepo := s.accountRepo.WithTx(tx)
+
+		// Get transaction to reverse balance change
+		transaction, err := transactionRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+		if transaction.Status == model.TransactionReconciled {
+			return ErrTransactionReconciled
+		}
+
+		// Delete transaction
+		if err := transactionRepo.Delete(ctx, householdID, id); err != nil {
+			return err
+		}
+
+		// Reverse balance change, unless it never counted
+		if transaction.AffectsBalance() {
+			if err := applyBalance(ctx, accountRepo, transaction, -1); err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityTransaction, id, model.AuditActionDelete, transaction, nil)
+	})
+}
+
+// Unlock makes a reconciled transaction editable again. It goes back to
+// cleared, so it is locked again by the next reconciliation of its account.
+func (s *TransactionService) Unlock(ctx context.Context, householdID, id string) (*model.Transaction, error) {
+	var transaction *model.Transaction
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		transactionRepo := s.transactionRepo.WithTx(tx)
+
+		before, err := transactionRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := transactionRepo.Unlock(ctx, householdID, id); err != nil {
+			return err
+		}
+
+		if transaction, err = transactionRepo.FindByID(ctx, householdID, id); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityTransaction, id, model.AuditActionUnlock, before, transaction)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Reverse balance change
-	if err := s.accountRepo.UpdateBalance(ctx, transaction.AccountID, -transaction.Amount); err != nil {
-		return err
+	return transaction, nil
+}
+
+// GetPendingApproval returns the household's purchases waiting for approval,
+// oldest first
+func (s *TransactionService) GetPendingApproval(ctx context.Context, householdID string) ([]*model.Transaction, error) {
+	return s.transactionRepo.FindPendingApproval(ctx, householdID)
+}
+
+// Approve books a child's purchase that waited for approval, applying it to
+// the account balance
+func (s *TransactionService) Approve(ctx context.Context, householdID, id string) (*model.Transaction, error) {
+	transaction, err := s.review(ctx, householdID, id, model.TransactionUncleared, model.AuditActionApprove)
+	if err != nil {
+		return nil, err
 	}
 
-	// If it's a transfer, reverse the destination account balance too
-	if transaction.Type == "transfer" && transaction.TransferToAccountID != nil {
-		if err := s.accountRepo.UpdateBalance(ctx, *transaction.TransferToAccountID, transaction.Amount); err != nil {
+	s.notifications.TransactionChanged(ctx, transaction)
+	return transaction, nil
+}
+
+// Reject turns down a child's purchase that waited for approval. It stays in
+// the child's transactions but never counts towards the balance.
+func (s *TransactionService) Reject(ctx context.Context, householdID, id string) (*model.Transaction, error) {
+	return s.review(ctx, householdID, id, model.TransactionRejected, model.AuditActionReject)
+}
+
+// review moves a transaction waiting for approval to status, applying it to
+// the balance if that makes it count
+func (s *TransactionService) review(ctx context.Context, householdID, id, status, action string) (*model.Transaction, error) {
+	var transaction *model.Transaction
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		transactionRepo := s.transactionRepo.WithTx(tx)
+
+		before, err := transactionRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		reviewed, err := transactionRepo.Review(ctx, householdID, id, status)
+		if err != nil {
+			return err
+		}
+		if !reviewed {
+			return ErrTransactionNotPending
+		}
+
+		if transaction, err = transactionRepo.FindByID(ctx, householdID, id); err != nil {
 			return err
 		}
+
+		if transaction.AffectsBalance() {
+			if err := applyBalance(ctx, s.accountRepo.WithTx(tx), transaction, 1); err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityTransaction, id, action, before, transaction)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Delete transaction
-	return s.transactionRepo.Delete(ctx, id)
+	return transaction, nil
 }
 
 func (s *TransactionService) GenerateRecurring(ctx context.Context, userID string, upTo time.Time) (*model.GenerateRecurringResponse, error) {
@@ -144,19 +486,26 @@ func (s *TransactionService) GenerateRecurring(ctx context.Context, userID strin
 			startDate = latest.Date
 		}
 
+		var categoryID string
+		if tmpl.CategoryID != nil {
+			categoryID = *tmpl.CategoryID
+		}
+
 		// Generate occurrences from startDate to upTo
 		nextDate := nextOccurrence(startDate, tmpl.RecurringRule)
 		for !nextDate.After(upTo) {
 			req := &model.CreateTransactionRequest{
-				AccountID:   tmpl.AccountID,
-				CategoryID:  tmpl.CategoryID,
-				Amount:      tmpl.Amount,
-				Type:        tmpl.Type,
-				Description: tmpl.Description,
-				Date:        nextDate.Format("2006-01-02"),
-				IsShared:    tmpl.IsShared,
-				IsRecurring: false, // generated copies are not recurring
-				Tags:        tmpl.Tags,
+				AccountID:    tmpl.AccountID,
+				CategoryID:   categoryID,
+				Amount:       tmpl.Amount,
+				Type:         tmpl.Type,
+				Description:  tmpl.Description,
+				Counterparty: tmpl.Counterparty,
+				Date:         nextDate.Format("2006-01-02"),
+				IsShared:     tmpl.IsShared,
+				IsRecurring:  false, // generated copies are not recurring
+				Tags:         tmpl.Tags,
+				Splits:       splitRequests(tmpl.Splits),
 			}
 
 			_, err := s.Create(ctx, userID, req)
@@ -177,6 +526,67 @@ func (s *TransactionService) GenerateRecurring(ctx context.Context, userID strin
 	}, nil
 }
 
+// GenerateRecurringAll generates recurring transactions up to upTo for every
+// user that has recurring templates, across all households
+func (s *TransactionService) GenerateRecurringAll(ctx context.Context, upTo time.Time) (*model.GenerateRecurringResponse, error) {
+	userIDs, err := s.transactionRepo.FindRecurringUserIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	total := &model.GenerateRecurringResponse{}
+	for _, userID := range userIDs {
+		result, err := s.GenerateRecurring(ctx, userID, upTo)
+		if err != nil {
+			total.Errors = append(total.Errors, fmt.Sprintf("user %s: %s", userID, err.Error()))
+			continue
+		}
+
+		total.Generated += result.Generated
+		total.Templates += result.Templates
+		total.Errors = append(total.Errors, result.Errors...)
+	}
+
+	return total, nil
+}
+
+// validateSplits checks that the splits of a transaction add up to its
+// amount, each with the same sign
+func validateSplits(transactionType string, amount int64, splits []model.TransactionSplitRequest) error {
+	if len(splits) == 0 {
+		return nil
+	}
+	if transactionType == "transfer" {
+		return fmt.Errorf("transfers cannot be split")
+	}
+
+	var total int64
+	for _, split := range splits {
+		if split.Amount == 0 || (split.Amount < 0) != (amount < 0) {
+			return fmt.Errorf("split amounts must have the same sign as the transaction amount")
+		}
+		total += split.Amount
+	}
+	if total != amount {
+		return fmt.Errorf("split amounts must add up to the transaction amount")
+	}
+
+	return nil
+}
+
+func splitRequests(splits []*model.TransactionSplit) []model.TransactionSplitRequest {
+	var reqs []model.TransactionSplitRequest
+	for _, s := range splits {
+		reqs = append(reqs, model.TransactionSplitRequest{
+			CategoryID: s.CategoryID,
+			Amount:     s.Amount,
+			Memo:       s.Memo,
+			Tags:       s.Tags,
+		})
+	}
+	return reqs
+}
+
 func nextOccurrence(from time.Time, rule *model.RecurringRule) time.Time {
 	switch rule.Frequency {
 	case "daily":
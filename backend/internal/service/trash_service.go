@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrRestoreAccountFirst is returned when restoring a transaction whose
+	// account is still in the trash
+	ErrRestoreAccountFirst = errors.New("the account is in the trash; restore it first")
+
+	// ErrRestoreCategoryFirst is returned when restoring a transaction or
+	// budget whose category is still in the trash
+	ErrRestoreCategoryFirst = errors.New("the category is in the trash; restore it first")
+
+	// ErrBudgetExists is returned when restoring a budget for a category and
+	// month that has been budgeted again since
+	ErrBudgetExists = errors.New("a budget for this category and month already exists")
+)
+
+type TrashService struct {
+	uow             *repository.UnitOfWork
+	trashRepo       *repository.TrashRepository
+	transactionRepo *repository.TransactionRepository
+	accountRepo     *repository.AccountRepository
+	categoryRepo    *repository.CategoryRepository
+	budgetRepo      *repository.BudgetRepository
+	savingGoalRepo  *repository.SavingGoalRepository
+	auditRepo       *repository.AuditRepository
+	retention       time.Duration
+}
+
+func NewTrashService(
+	uow *repository.UnitOfWork,
+	trashRepo *repository.TrashRepository,
+	transactionRepo *repository.TransactionRepository,
+	accountRepo *repository.AccountRepository,
+	categoryRepo *repository.CategoryRepository,
+	budgetRepo *repository.BudgetRepository,
+	savingGoalRepo *repository.SavingGoalRepository,
+	auditRepo *repository.AuditRepository,
+	retention time.Duration,
+) *TrashService {
+	return &TrashService{
+		uow:             uow,
+		trashRepo:       trashRepo,
+		transactionRepo: transactionRepo,
+		accountRepo:     accountRepo,
+		categoryRepo:    categoryRepo,
+		budgetRepo:      budgetRepo,
+		savingGoalRepo:  savingGoalRepo,
+		auditRepo:       auditRepo,
+		retention:       retention,
+	}
+}
+
+// GetAll returns the household's trash. If userID is set, only that user's
+// transactions and accounts are returned.
+func (s *TrashService) GetAll(ctx context.Context, householdID string, userID *string) ([]*model.TrashItem, error) {
+	items, err := s.trashRepo.FindAll(ctx, householdID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		item.PurgeAt = item.DeletedAt.Add(s.retention)
+	}
+	return items, nil
+}
+
+func (s *TrashService) GetByID(ctx context.Context, householdID, itemType, id string) (*model.TrashItem, error) {
+	item, err := s.trashRepo.FindByID(ctx, householdID, itemType, id)
+	if err != nil {
+		return nil, err
+	}
+
+	item.PurgeAt = item.DeletedAt.Add(s.retention)
+	return item, nil
+}
+
+// Restore takes an item back out of the trash. A restored transaction moves
+// its account balances again, as when it was first booked.
+func (s *TrashService) Restore(ctx context.Context, householdID, itemType, id string) error {
+	switch itemType {
+	case model.TrashTransaction:
+		return s.restoreTransaction(ctx, householdID, id)
+	case model.TrashAccount:
+		return s.restoreAccount(ctx, householdID, id)
+	case model.TrashCategory:
+		return s.categoryRepo.Restore(ctx, householdID, id)
+	case model.TrashBudget:
+		return s.restoreBudget(ctx, householdID, id)
+	case model.TrashSavingGoal:
+		return s.restoreSavingGoal(ctx, householdID, id)
+	default:
+		return fmt.Errorf("unknown trash item type %q", itemType)
+	}
+}
+
+func (s *TrashService) restoreTransaction(ctx context.Context, householdID, id string) error {
+	return s.uow.Do(ctx, func(tx pgx.Tx) error {
+		trashRepo := s.trashRepo.WithTx(tx)
+		transactionRepo := s.transactionRepo.WithTx(tx)
+		accountRepo := s.accountRepo.WithTx(tx)
+
+		transaction, err := transactionRepo.FindDeletedByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		accounts := []string{transaction.AccountID}
+		if transaction.TransferToAccountID != nil {
+			accounts = append(accounts, *transaction.TransferToAccountID)
+		}
+		if err := requireRestored(ctx, trashRepo, model.TrashAccount, accounts, ErrRestoreAccountFirst); err != nil {
+			return err
+		}
+
+		var categories []string
+		if transaction.CategoryID != nil {
+			categories = append(categories, *transaction.CategoryID)
+		}
+		for _, split := range transaction.Splits {
+			categories = append(categories, split.CategoryID)
+		}
+		if err := requireRestored(ctx, trashRepo, model.TrashCategory, categories, ErrRestoreCategoryFirst); err != nil {
+			return err
+		}
+
+		if err := transactionRepo.Restore(ctx, householdID, id); err != nil {
+			return err
+		}
+
+		// Re-apply the balance changes reversed when it was deleted
+		if transaction.AffectsBalance() {
+			if err := applyBalance(ctx, accountRepo, transaction, 1); err != nil {
+				return err
+			}
+		}
+
+		restored, err := transactionRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityTransaction, id, model.AuditActionRestore, nil, restored)
+	})
+}
+
+// restoreAccount brings back the account and the transactions deleted with
+// it. Deleting the account left balances alone, so nothing is re-applied.
+func (s *TrashService) restoreAccount(ctx context.Context, householdID, id string) error {
+	return s.uow.Do(ctx, func(tx pgx.Tx) error {
+		accountRepo := s.accountRepo.WithTx(tx)
+
+		if err := accountRepo.Restore(ctx, householdID, id); err != nil {
+			return err
+		}
+
+		restored, err := accountRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityAccount, id, model.AuditActionRestore, nil, restored)
+	})
+}
+
+func (s *TrashService) restoreBudget(ctx context.Context, householdID, id string) error {
+	return s.uow.Do(ctx, func(tx pgx.Tx) error {
+		budgetRepo := s.budgetRepo.WithTx(tx)
+
+		if err := budgetRepo.Restore(ctx, householdID, id); err != nil {
+			if isUniqueViolation(err) {
+				return ErrBudgetExists
+			}
+			return err
+		}
+
+		restored, err := budgetRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+		// Checked once restored, which rolls back if its category is missing
+		if err := requireRestored(ctx, s.trashRepo.WithTx(tx), model.TrashCategory, []string{restored.CategoryID}, ErrRestoreCategoryFirst); err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntityBudget, id, model.AuditActionRestore, nil, restored)
+	})
+}
+
+func (s *TrashService) restoreSavingGoal(ctx context.Context, householdID, id string) error {
+	return s.uow.Do(ctx, func(tx pgx.Tx) error {
+		savingGoalRepo := s.savingGoalRepo.WithTx(tx)
+
+		if err := savingGoalRepo.Restore(ctx, householdID, id); err != nil {
+			return err
+		}
+
+		restored, err := savingGoalRepo.FindByID(ctx, householdID, id)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, s.auditRepo.WithTx(tx), householdID, model.AuditEntitySavingGoal, id, model.AuditActionRestore, nil, restored)
+	})
+}
+
+// PurgeExpired removes for good everything that has been in the trash longer
+// than the retention period
+func (s *TrashService) PurgeExpired(ctx context.Context, now time.Time) (*model.PurgeResult, error) {
+	var result *model.PurgeResult
+
+	err := s.uow.Do(ctx, func(tx pgx.Tx) error {
+		purged, err := s.trashRepo.WithTx(tx).Purge(ctx, now.Add(-s.retention))
+		result = purged
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// requireRestored returns errInTrash if any of the rows of the given kind is
+// still in the trash
+func requireRestored(ctx context.Context, trashRepo *repository.TrashRepository, itemType string, ids []string, errInTrash error) error {
+	for _, id := range ids {
+		deleted, err := trashRepo.IsDeleted(ctx, itemType, id)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			return errInTrash
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors, "12345678901234567890"
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes; the last 6 digits are the 6-digit codes
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, v := range vectors {
+		code, err := Code(rfcSecret, Step(time.Unix(v.unix, 0)))
+		if err != nil {
+			t.Fatalf("Failed to generate code: %v", err)
+		}
+		if code != v.code {
+			t.Errorf("At %d expected code %s, got %s", v.unix, v.code, code)
+		}
+	}
+}
+
+func TestValidate_AcceptsAdjacentSteps(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	previous, _ := Code(rfcSecret, Step(now)-1)
+	step, ok := Validate(rfcSecret, previous, now)
+	if !ok {
+		t.Fatal("Expected the previous step's code to be accepted")
+	}
+	if step != Step(now)-1 {
+		t.Errorf("Expected step %d, got %d", Step(now)-1, step)
+	}
+
+	stale, _ := Code(rfcSecret, Step(now)-2)
+	if _, ok := Validate(rfcSecret, stale, now); ok {
+		t.Error("Expected a code two steps old to be rejected")
+	}
+}
+
+func TestValidate_RejectsMalformedCode(t *testing.T) {
+	if _, ok := Validate(rfcSecret, "12345", time.Now()); ok {
+		t.Error("Expected a 5-digit code to be rejected")
+	}
+	if _, ok := Validate("not base32!", "123456", time.Now()); ok {
+		t.Error("Expected an invalid secret to be rejected")
+	}
+}
@@ -0,0 +1,65 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS households (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_households_uuid ON households(uuid);
+
+-- Existing data belongs to a single family: move it into one household
+INSERT INTO households (name)
+SELECT 'Family'
+WHERE EXISTS (SELECT 1 FROM users)
+   OR EXISTS (SELECT 1 FROM categories)
+   OR EXISTS (SELECT 1 FROM saving_goals)
+   OR EXISTS (SELECT 1 FROM bill_reminders);
+
+ALTER TABLE users ADD COLUMN household_id BIGINT REFERENCES households(id) ON DELETE CASCADE;
+ALTER TABLE accounts ADD COLUMN household_id BIGINT REFERENCES households(id) ON DELETE CASCADE;
+ALTER TABLE categories ADD COLUMN household_id BIGINT REFERENCES households(id) ON DELETE CASCADE;
+ALTER TABLE transactions ADD COLUMN household_id BIGINT REFERENCES households(id) ON DELETE CASCADE;
+ALTER TABLE budgets ADD COLUMN household_id BIGINT REFERENCES households(id) ON DELETE CASCADE;
+ALTER TABLE saving_goals ADD COLUMN household_id BIGINT REFERENCES households(id) ON DELETE CASCADE;
+ALTER TABLE bill_reminders ADD COLUMN household_id BIGINT REFERENCES households(id) ON DELETE CASCADE;
+ALTER TABLE allowances ADD COLUMN household_id BIGINT REFERENCES households(id) ON DELETE CASCADE;
+
+UPDATE users SET household_id = (SELECT MIN(id) FROM households);
+UPDATE accounts SET household_id = (SELECT MIN(id) FROM households);
+UPDATE categories SET household_id = (SELECT MIN(id) FROM households);
+UPDATE transactions SET household_id = (SELECT MIN(id) FROM households);
+UPDATE budgets SET household_id = (SELECT MIN(id) FROM households);
+UPDATE saving_goals SET household_id = (SELECT MIN(id) FROM households);
+UPDATE bill_reminders SET household_id = (SELECT MIN(id) FROM households);
+UPDATE allowances SET household_id = (SELECT MIN(id) FROM households);
+
+ALTER TABLE users ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE accounts ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE categories ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE transactions ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE budgets ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE saving_goals ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE bill_reminders ALTER COLUMN household_id SET NOT NULL;
+ALTER TABLE allowances ALTER COLUMN household_id SET NOT NULL;
+
+CREATE INDEX idx_users_household_id ON users(household_id);
+CREATE INDEX idx_accounts_household_id ON accounts(household_id);
+CREATE INDEX idx_categories_household_id ON categories(household_id);
+CREATE INDEX idx_transactions_household_id ON transactions(household_id);
+CREATE INDEX idx_budgets_household_id ON budgets(household_id);
+CREATE INDEX idx_saving_goals_household_id ON saving_goals(household_id);
+CREATE INDEX idx_bill_reminders_household_id ON bill_reminders(household_id);
+CREATE INDEX idx_allowances_household_id ON allowances(household_id);
+
+-- +goose Down
+ALTER TABLE allowances DROP COLUMN IF EXISTS household_id;
+ALTER TABLE bill_reminders DROP COLUMN IF EXISTS household_id;
+ALTER TABLE saving_goals DROP COLUMN IF EXISTS household_id;
+ALTER TABLE budgets DROP COLUMN IF EXISTS household_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS household_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS household_id;
+ALTER TABLE accounts DROP COLUMN IF EXISTS household_id;
+ALTER TABLE users DROP COLUMN IF EXISTS household_id;
+DROP TABLE IF EXISTS households;
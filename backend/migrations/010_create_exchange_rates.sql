@@ -0,0 +1,86 @@
+-- +goose Up
+ALTER TABLE households ADD COLUMN base_currency VARCHAR(3) NOT NULL DEFAULT 'EUR';
+
+-- Existing households report in the currency most of their accounts use
+UPDATE households h
+SET base_currency = (
+    SELECT a.currency FROM accounts a
+    WHERE a.household_id = h.id
+    GROUP BY a.currency
+    ORDER BY COUNT(*) DESC, a.currency
+    LIMIT 1
+)
+WHERE EXISTS (SELECT 1 FROM accounts a WHERE a.household_id = h.id);
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    household_id BIGINT NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    base VARCHAR(3) NOT NULL,
+    quote VARCHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (household_id, date, base, quote)
+);
+
+CREATE INDEX idx_exchange_rates_uuid ON exchange_rates(uuid);
+CREATE INDEX idx_exchange_rates_lookup ON exchange_rates(household_id, base, quote, date DESC);
+
+-- convert_amount converts an amount in cents between two currencies using the
+-- latest rate on or before the given date. A rate row means 1 base = rate quote.
+-- Direct, inverse and cross rates through a common base (e.g. EUR for ECB data)
+-- are tried in that order.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION convert_amount(
+    p_household_id BIGINT,
+    p_amount BIGINT,
+    p_from VARCHAR,
+    p_to VARCHAR,
+    p_date DATE
+) RETURNS BIGINT AS $$
+DECLARE
+    v_rate NUMERIC;
+BEGIN
+    IF p_from = p_to THEN
+        RETURN p_amount;
+    END IF;
+
+    SELECT rate INTO v_rate
+    FROM exchange_rates
+    WHERE household_id = p_household_id AND base = p_from AND quote = p_to AND date <= p_date
+    ORDER BY date DESC
+    LIMIT 1;
+    IF FOUND THEN
+        RETURN ROUND(p_amount * v_rate)::BIGINT;
+    END IF;
+
+    SELECT 1 / rate INTO v_rate
+    FROM exchange_rates
+    WHERE household_id = p_household_id AND base = p_to AND quote = p_from AND date <= p_date
+    ORDER BY date DESC
+    LIMIT 1;
+    IF FOUND THEN
+        RETURN ROUND(p_amount * v_rate)::BIGINT;
+    END IF;
+
+    SELECT t.rate / f.rate INTO v_rate
+    FROM exchange_rates f
+    JOIN exchange_rates t
+        ON t.household_id = f.household_id AND t.base = f.base AND t.date = f.date
+    WHERE f.household_id = p_household_id AND f.quote = p_from AND t.quote = p_to AND f.date <= p_date
+    ORDER BY f.date DESC
+    LIMIT 1;
+    IF FOUND THEN
+        RETURN ROUND(p_amount * v_rate)::BIGINT;
+    END IF;
+
+    RAISE EXCEPTION 'no exchange rate from % to % on or before %', p_from, p_to, p_date;
+END;
+$$ LANGUAGE plpgsql STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION IF EXISTS convert_amount(BIGINT, BIGINT, VARCHAR, VARCHAR, DATE);
+DROP TABLE IF EXISTS exchange_rates;
+ALTER TABLE households DROP COLUMN IF EXISTS base_currency;
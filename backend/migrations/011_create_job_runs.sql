@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS job_runs (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    job_name VARCHAR(100) NOT NULL,
+    run_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'succeeded', 'failed')),
+    summary TEXT NOT NULL DEFAULT '',
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_job_runs_uuid ON job_runs(uuid);
+CREATE INDEX idx_job_runs_job_name_run_date ON job_runs(job_name, run_date);
+
+ALTER TABLE bill_reminders ADD COLUMN is_overdue BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE bill_reminders SET is_overdue = true WHERE is_active AND next_due_date < CURRENT_DATE;
+
+-- +goose Down
+ALTER TABLE bill_reminders DROP COLUMN IF EXISTS is_overdue;
+DROP TABLE IF EXISTS job_runs;
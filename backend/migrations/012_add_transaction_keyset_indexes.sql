@@ -0,0 +1,8 @@
+-- +goose Up
+-- Support keyset pagination on (date, created_at, uuid) for user and household lists
+CREATE INDEX idx_transactions_user_keyset ON transactions(user_id, date DESC, created_at DESC, uuid DESC);
+CREATE INDEX idx_transactions_household_keyset ON transactions(household_id, date DESC, created_at DESC, uuid DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_household_keyset;
+DROP INDEX IF EXISTS idx_transactions_user_keyset;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Bank-assigned ID (OFX FITID, CAMT.053 AcctSvcrRef) of imported statement lines
+ALTER TABLE transactions ADD COLUMN external_id VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_transactions_account_external_id ON transactions(account_id, external_id) WHERE external_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_account_external_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS external_id;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS import_profiles (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    household_id BIGINT NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    delimiter VARCHAR(1) NOT NULL DEFAULT ',',
+    encoding VARCHAR(50) NOT NULL DEFAULT 'utf-8',
+    header_row INT NOT NULL DEFAULT 1 CHECK (header_row >= 0),
+    date_layout VARCHAR(50) NOT NULL DEFAULT '2006-01-02',
+    decimal_separator VARCHAR(1) NOT NULL DEFAULT '.' CHECK (decimal_separator IN ('.', ',')),
+    amount_sign VARCHAR(20) NOT NULL DEFAULT 'signed' CHECK (amount_sign IN ('signed', 'inverted', 'debit_credit')),
+    columns JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (household_id, name)
+);
+
+CREATE INDEX idx_import_profiles_household_id ON import_profiles(household_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS import_profiles;
@@ -0,0 +1,42 @@
+-- +goose Up
+-- Transactions may be left for categorization rules (or a person) to categorize later
+ALTER TABLE transactions ALTER COLUMN category_id DROP NOT NULL;
+ALTER TABLE transactions ADD COLUMN counterparty VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_transactions_uncategorized ON transactions(household_id) WHERE category_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS categorization_rules (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    household_id BIGINT NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    priority INT NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    -- Conditions (all that are set must match)
+    description_pattern VARCHAR(255),
+    description_match VARCHAR(20) NOT NULL DEFAULT 'contains' CHECK (description_match IN ('contains', 'regex')),
+    min_amount BIGINT,
+    max_amount BIGINT,
+    account_id BIGINT REFERENCES accounts(id) ON DELETE CASCADE,
+    counterparty VARCHAR(255),
+    -- Actions
+    category_id BIGINT REFERENCES categories(id) ON DELETE CASCADE,
+    add_tags TEXT[],
+    set_shared BOOLEAN,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_categorization_rules_household_id ON categorization_rules(household_id, priority);
+
+-- +goose Down
+DROP TABLE IF EXISTS categorization_rules;
+DROP INDEX IF EXISTS idx_transactions_uncategorized;
+ALTER TABLE transactions DROP COLUMN IF EXISTS counterparty;
+-- Give uncategorized transactions a category of the right type before restoring the constraint
+UPDATE transactions t SET category_id = (
+    SELECT MIN(c.id) FROM categories c
+    WHERE c.household_id = t.household_id
+    AND c.type = CASE WHEN t.amount > 0 THEN 'income' ELSE 'expense' END
+) WHERE t.category_id IS NULL;
+ALTER TABLE transactions ALTER COLUMN category_id SET NOT NULL;
@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS reconciliations (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    household_id BIGINT NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    statement_date DATE NOT NULL,
+    closing_balance BIGINT NOT NULL, -- in cents, as printed on the bank statement
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'completed')),
+    adjustment_transaction_id BIGINT REFERENCES transactions(id) ON DELETE SET NULL,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reconciliations_account_id ON reconciliations(account_id, statement_date);
+-- An account has at most one reconciliation in progress
+CREATE UNIQUE INDEX idx_reconciliations_open ON reconciliations(account_id) WHERE status = 'open';
+
+ALTER TABLE transactions ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'uncleared'
+    CHECK (status IN ('uncleared', 'cleared', 'reconciled'));
+ALTER TABLE transactions ADD COLUMN reconciliation_id BIGINT REFERENCES reconciliations(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_transactions_status ON transactions(account_id, status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_status;
+ALTER TABLE transactions DROP COLUMN IF EXISTS reconciliation_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS status;
+DROP TABLE IF EXISTS reconciliations;
@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN totp_secret VARCHAR(64);
+ALTER TABLE users ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT; -- last accepted time step, so a code works only once
+ALTER TABLE users ADD COLUMN totp_recovery_codes TEXT[] NOT NULL DEFAULT '{}'; -- hex SHA-256 of the unused codes
+
+ALTER TABLE households ADD COLUMN require_admin_two_factor BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE households DROP COLUMN IF EXISTS require_admin_two_factor;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_recovery_codes;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_last_step;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_enabled;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
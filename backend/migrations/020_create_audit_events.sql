@@ -0,0 +1,36 @@
+-- +goose Up
+-- Append-only record of every change to the household's money: who did what
+-- to which row, with the row as it was before and after. Entity and actor are
+-- kept as plain UUIDs so the history survives deleting them.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    household_id BIGINT NOT NULL REFERENCES households(id),
+    actor_id UUID, -- NULL for changes made by the scheduler
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id UUID NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    before JSONB,
+    after JSONB,
+    request_id VARCHAR(64),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_events_household ON audit_events(household_id, created_at DESC, id DESC);
+CREATE INDEX idx_audit_events_entity ON audit_events(entity_type, entity_id);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit events cannot be changed or deleted';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
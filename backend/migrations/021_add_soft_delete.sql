@@ -0,0 +1,41 @@
+-- +goose Up
+-- Deleting moves a row to the trash instead of removing it. Trashed rows are
+-- left out of every query, can be restored, and are purged for good once the
+-- retention period has passed.
+ALTER TABLE transactions ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE accounts ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE categories ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE budgets ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE saving_goals ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_transactions_deleted_at ON transactions(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_accounts_deleted_at ON accounts(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_categories_deleted_at ON categories(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_budgets_deleted_at ON budgets(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_saving_goals_deleted_at ON saving_goals(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- A budget in the trash must not block creating a new one for the same month
+ALTER TABLE budgets DROP CONSTRAINT budgets_category_id_month_year_key;
+CREATE UNIQUE INDEX idx_budgets_category_month_year ON budgets(category_id, month, year) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DELETE FROM transactions WHERE deleted_at IS NOT NULL;
+DELETE FROM budgets WHERE deleted_at IS NOT NULL;
+DELETE FROM saving_goals WHERE deleted_at IS NOT NULL;
+DELETE FROM accounts WHERE deleted_at IS NOT NULL;
+DELETE FROM categories WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_budgets_category_month_year;
+ALTER TABLE budgets ADD CONSTRAINT budgets_category_id_month_year_key UNIQUE (category_id, month, year);
+
+DROP INDEX IF EXISTS idx_saving_goals_deleted_at;
+DROP INDEX IF EXISTS idx_budgets_deleted_at;
+DROP INDEX IF EXISTS idx_categories_deleted_at;
+DROP INDEX IF EXISTS idx_accounts_deleted_at;
+DROP INDEX IF EXISTS idx_transactions_deleted_at;
+
+ALTER TABLE saving_goals DROP COLUMN deleted_at;
+ALTER TABLE budgets DROP COLUMN deleted_at;
+ALTER TABLE categories DROP COLUMN deleted_at;
+ALTER TABLE accounts DROP COLUMN deleted_at;
+ALTER TABLE transactions DROP COLUMN deleted_at;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- What a budget's leftover does at the end of the month: nothing, carry only
+-- unspent money into next month's budget, or carry overspending as well
+ALTER TABLE budgets ADD COLUMN rollover VARCHAR(20) NOT NULL DEFAULT 'none'
+    CHECK (rollover IN ('none', 'surplus', 'full'));
+
+-- +goose Down
+ALTER TABLE budgets DROP COLUMN IF EXISTS rollover;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- A budget template sets the same budget for a category every month from its
+-- start month until its optional end month. Budgets are created from it when
+-- a month is first looked at.
+CREATE TABLE IF NOT EXISTS budget_templates (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    household_id BIGINT NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    category_id BIGINT NOT NULL REFERENCES categories(id) ON DELETE RESTRICT,
+    amount BIGINT CHECK (amount > 0),               -- monthly limit in cents
+    annual_amount BIGINT CHECK (annual_amount > 0), -- or a yearly limit spread over the months
+    start_month INT NOT NULL CHECK (start_month BETWEEN 1 AND 12),
+    start_year INT NOT NULL CHECK (start_year >= 2000),
+    end_month INT CHECK (end_month BETWEEN 1 AND 12),
+    end_year INT CHECK (end_year >= 2000),
+    rollover VARCHAR(20) NOT NULL DEFAULT 'none' CHECK (rollover IN ('none', 'surplus', 'full')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CHECK ((amount IS NULL) <> (annual_amount IS NULL)),
+    CHECK ((end_month IS NULL) = (end_year IS NULL))
+);
+
+CREATE INDEX idx_budget_templates_household_id ON budget_templates(household_id);
+CREATE INDEX idx_budget_templates_category_id ON budget_templates(category_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS budget_templates;
@@ -0,0 +1,38 @@
+-- +goose Up
+-- In-app feed of alerts raised for a user, e.g. a budget passing 80% or a
+-- child's allowance running out. The dedupe key makes each alert fire once.
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    entity_id UUID, -- the budgeted category, allowance or transaction it is about
+    dedupe_key VARCHAR(255) NOT NULL,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (user_id, dedupe_key)
+);
+
+CREATE INDEX idx_notifications_user ON notifications(user_id, created_at DESC, id DESC);
+
+-- A user's choice per kind of alert: whether it is raised at all, which
+-- channels besides the in-app feed deliver it, and for large transactions
+-- the amount from which they count as large. Kinds without a row use the
+-- defaults.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    channels TEXT[] NOT NULL DEFAULT '{}',
+    threshold BIGINT CHECK (threshold > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, type)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
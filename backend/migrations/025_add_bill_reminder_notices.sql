@@ -0,0 +1,18 @@
+-- +goose Up
+-- Bills send notices ahead of their due date: "due in N days" once they are
+-- within lead_days, "due today" and then "overdue". The notices go to the
+-- bill's recipients, or to every admin and member if it has none.
+ALTER TABLE bill_reminders ADD COLUMN lead_days INT NOT NULL DEFAULT 3 CHECK (lead_days BETWEEN 0 AND 60);
+
+CREATE TABLE IF NOT EXISTS bill_reminder_recipients (
+    bill_reminder_id BIGINT NOT NULL REFERENCES bill_reminders(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    PRIMARY KEY (bill_reminder_id, user_id)
+);
+
+CREATE INDEX idx_bill_reminder_recipients_user_id ON bill_reminder_recipients(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS bill_reminder_recipients;
+ALTER TABLE bill_reminders DROP COLUMN IF EXISTS lead_days;
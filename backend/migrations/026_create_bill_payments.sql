@@ -0,0 +1,26 @@
+-- +goose Up
+-- A bill can be put off to a later date. deferred_from keeps the date it was
+-- originally due, so its schedule carries on from there once it is settled.
+ALTER TABLE bill_reminders ADD COLUMN deferred_from DATE;
+
+-- How each period of a bill was settled: paid, with the amount actually paid
+-- and its transaction, or skipped. period_date is the date the period was
+-- scheduled for, due_date the date it was due after any deferral.
+CREATE TABLE IF NOT EXISTS bill_payments (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    bill_reminder_id BIGINT NOT NULL REFERENCES bill_reminders(id) ON DELETE CASCADE,
+    transaction_id BIGINT REFERENCES transactions(id) ON DELETE SET NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('paid', 'skipped')),
+    period_date DATE NOT NULL,
+    due_date DATE NOT NULL,
+    amount BIGINT CHECK (amount > 0), -- set when paid
+    paid_on DATE,                     -- set when paid
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_bill_payments_bill ON bill_payments(bill_reminder_id, period_date DESC, id DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS bill_payments;
+ALTER TABLE bill_reminders DROP COLUMN IF EXISTS deferred_from;
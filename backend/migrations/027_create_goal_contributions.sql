@@ -0,0 +1,42 @@
+-- +goose Up
+-- A saving goal can be bound to the savings account its money is kept in.
+ALTER TABLE saving_goals ADD COLUMN account_id BIGINT REFERENCES accounts(id) ON DELETE SET NULL;
+
+-- Money put towards or taken out of a saving goal: positive amounts are
+-- contributions, negative ones withdrawals. For a goal bound to an account,
+-- each one is a transfer, and goes with it when the transfer is purged. A
+-- goal's current amount is the sum of its contributions.
+CREATE TABLE IF NOT EXISTS goal_contributions (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    saving_goal_id BIGINT NOT NULL REFERENCES saving_goals(id) ON DELETE CASCADE,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    transaction_id BIGINT REFERENCES transactions(id) ON DELETE CASCADE,
+    amount BIGINT NOT NULL CHECK (amount <> 0),
+    date DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_goal_contributions_goal ON goal_contributions(saving_goal_id, date DESC, id DESC);
+CREATE INDEX idx_goal_contributions_transaction_id ON goal_contributions(transaction_id);
+
+-- Amounts saved so far become an opening contribution
+INSERT INTO goal_contributions (saving_goal_id, amount, date, created_at)
+SELECT id, current_amount, created_at::date, created_at
+FROM saving_goals
+WHERE current_amount <> 0;
+
+ALTER TABLE saving_goals DROP COLUMN current_amount;
+
+-- +goose Down
+ALTER TABLE saving_goals ADD COLUMN current_amount BIGINT NOT NULL DEFAULT 0;
+
+UPDATE saving_goals g SET current_amount = (
+    SELECT COALESCE(SUM(gc.amount), 0)
+    FROM goal_contributions gc
+    LEFT JOIN transactions t ON t.id = gc.transaction_id
+    WHERE gc.saving_goal_id = g.id AND (t.id IS NULL OR t.deleted_at IS NULL)
+);
+
+DROP TABLE IF EXISTS goal_contributions;
+ALTER TABLE saving_goals DROP COLUMN IF EXISTS account_id;
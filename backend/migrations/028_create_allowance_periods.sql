@@ -0,0 +1,37 @@
+-- +goose Up
+-- An allowance is paid weekly, every two weeks or monthly. Money left at the
+-- end of a period can roll over into the next one, and each period can be
+-- topped up with a transfer from a parent's account to the child's.
+ALTER TABLE allowances ADD COLUMN frequency TEXT NOT NULL DEFAULT 'monthly' CHECK (frequency IN ('weekly', 'biweekly', 'monthly'));
+ALTER TABLE allowances ADD COLUMN rollover BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE allowances ADD COLUMN from_account_id BIGINT REFERENCES accounts(id) ON DELETE SET NULL;
+ALTER TABLE allowances ADD COLUMN to_account_id BIGINT REFERENCES accounts(id) ON DELETE SET NULL;
+
+-- Every period of an allowance, with the amount set for it and what was
+-- carried over from the one before. period_end is the day the next period
+-- starts; allowances.period_start is the start of the latest one.
+CREATE TABLE IF NOT EXISTS allowance_periods (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    allowance_id BIGINT NOT NULL REFERENCES allowances(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL CHECK (period_end > period_start),
+    amount BIGINT NOT NULL,
+    carried_over BIGINT NOT NULL DEFAULT 0,
+    transaction_id BIGINT REFERENCES transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_allowance_periods_allowance ON allowance_periods(allowance_id, period_start DESC);
+
+-- Existing allowances were monthly
+INSERT INTO allowance_periods (allowance_id, period_start, period_end, amount)
+SELECT id, period_start, (period_start + INTERVAL '1 month')::date, amount
+FROM allowances;
+
+-- +goose Down
+DROP TABLE IF EXISTS allowance_periods;
+ALTER TABLE allowances DROP COLUMN IF EXISTS to_account_id;
+ALTER TABLE allowances DROP COLUMN IF EXISTS from_account_id;
+ALTER TABLE allowances DROP COLUMN IF EXISTS rollover;
+ALTER TABLE allowances DROP COLUMN IF EXISTS frequency;
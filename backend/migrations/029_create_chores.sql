@@ -0,0 +1,44 @@
+-- +goose Up
+-- Tasks children can do to earn a reward, once or again every day, week or
+-- month. A chore can be assigned to one child or left open to all of them.
+CREATE TABLE IF NOT EXISTS chores (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    household_id BIGINT NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    name VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    reward BIGINT NOT NULL CHECK (reward > 0),
+    frequency TEXT NOT NULL DEFAULT 'once' CHECK (frequency IN ('once', 'daily', 'weekly', 'monthly')),
+    assigned_to BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_chores_household_id ON chores(household_id);
+
+-- A chore a child marked done, waiting for a parent or reviewed. The name and
+-- reward are kept as they were, so earnings outlive changes to the chore. An
+-- approved one is paid with an income transaction on the child's account.
+CREATE TABLE IF NOT EXISTS chore_completions (
+    id BIGSERIAL PRIMARY KEY,
+    uuid UUID UNIQUE NOT NULL DEFAULT gen_random_uuid(),
+    household_id BIGINT NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    chore_id BIGINT REFERENCES chores(id) ON DELETE SET NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chore_name VARCHAR(200) NOT NULL,
+    reward BIGINT NOT NULL,
+    date DATE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    reviewed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    transaction_id BIGINT REFERENCES transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_chore_completions_household ON chore_completions(household_id, date DESC);
+CREATE INDEX idx_chore_completions_chore_user ON chore_completions(chore_id, user_id, date);
+
+-- +goose Down
+DROP TABLE IF EXISTS chore_completions;
+DROP TABLE IF EXISTS chores;
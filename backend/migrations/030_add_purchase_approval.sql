@@ -0,0 +1,20 @@
+-- +goose Up
+-- A child's expenses above their approval threshold wait for an admin to
+-- approve them before they count towards the account balance. NULL means the
+-- child's purchases never need approval.
+ALTER TABLE users ADD COLUMN approval_threshold BIGINT CHECK (approval_threshold >= 0);
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check
+    CHECK (status IN ('uncleared', 'cleared', 'reconciled', 'pending_approval', 'rejected'));
+
+-- The approval queue
+CREATE INDEX idx_transactions_pending_approval ON transactions(household_id, date) WHERE status = 'pending_approval';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_pending_approval;
+DELETE FROM transactions WHERE status IN ('pending_approval', 'rejected');
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check
+    CHECK (status IN ('uncleared', 'cleared', 'reconciled'));
+ALTER TABLE users DROP COLUMN IF EXISTS approval_threshold;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Wrong two-factor codes in a row. Too many lock the second factor for a
+-- while, so that codes cannot be guessed by trying them all.
+ALTER TABLE users ADD COLUMN totp_failed_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN totp_locked_until TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS totp_locked_until;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_failed_attempts;
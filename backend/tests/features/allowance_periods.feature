@@ -0,0 +1,75 @@
+Feature: Allowance schedules
+  As a parent
+  I want allowances paid weekly, every two weeks or monthly and topped up automatically
+  So that children get their money on time and can look back at past periods
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And an account "Main Account" of type "checking" exists
+    And a child user "kid@family.com" exists with an account and a category
+
+  Scenario: A weekly allowance starts a new period every week
+    Given a "weekly" allowance of 2000 for the child starting "2026-02-02"
+    And the allowance period should run from "2026-02-02" to "2026-02-09"
+    And the child has a transaction of -500 on "2026-02-03"
+    When allowance periods are started on "2026-02-17"
+    Then the allowance period should run from "2026-02-16" to "2026-02-23"
+    When I get the allowance periods
+    Then the allowance should have 3 periods
+    And the allowance period starting "2026-02-02" should have spent 500 and remaining 1500
+    And the allowance period starting "2026-02-16" should have spent 0 and remaining 2000
+
+  Scenario: A biweekly allowance runs for two weeks
+    Given a "biweekly" allowance of 4000 for the child starting "2026-02-02"
+    Then the allowance period should run from "2026-02-02" to "2026-02-16"
+
+  Scenario: Unspent money rolls over into the next period
+    Given an allowance of 10000 for the child starting "2026-02-01" that rolls over
+    And the child has a transaction of -3000 on "2026-02-10"
+    When allowance periods are started on "2026-03-01"
+    Then the allowance carried over should be 7000
+    When I get allowances
+    Then the allowance remaining should be 17000
+
+  Scenario: Without rollover every period starts afresh
+    Given an allowance of 10000 for the child starting "2026-02-01"
+    And the child has a transaction of -3000 on "2026-02-10"
+    When allowance periods are started on "2026-03-01"
+    Then the allowance carried over should be 0
+    When I get allowances
+    Then the allowance remaining should be 10000
+
+  Scenario: Overspending is not carried over
+    Given an allowance of 10000 for the child starting "2026-02-01" that rolls over
+    And the child has a transaction of -12000 on "2026-02-10"
+    When allowance periods are started on "2026-03-01"
+    Then the allowance carried over should be 0
+
+  Scenario: Each period is topped up once from a parent's account
+    Given a "weekly" allowance of 2000 for the child starting "2026-02-02" topped up from the current account
+    When allowance periods are started on "2026-02-02"
+    And allowance periods are started on "2026-02-02"
+    Then the account balance should be -2000
+    And the child account balance should be 2000
+    When allowance periods are started on "2026-02-09"
+    Then the account balance should be -4000
+    And the child account balance should be 4000
+    When I get the allowance periods
+    Then the allowance period starting "2026-02-09" should have a top-up
+    And the allowance period starting "2026-02-02" should have spent 0 and remaining 2000
+
+  Scenario: Catching up on missed periods tops up only the current one
+    Given a "weekly" allowance of 2000 for the child starting "2026-02-02" topped up from the current account
+    When allowance periods are started on "2026-02-02"
+    And allowance periods are started on "2026-02-24"
+    Then the account balance should be -4000
+    And the child account balance should be 4000
+    When I get the allowance periods
+    Then the allowance should have 4 periods
+    And the allowance period starting "2026-02-09" should have no top-up
+    And the allowance period starting "2026-02-16" should have no top-up
+    And the allowance period starting "2026-02-23" should have a top-up
+
+  Scenario: An allowance cannot be topped up from the child's own account
+    When I create an allowance of 2000 for the child starting "2026-02-02" topped up from the child's own account
+    Then the request should fail with error "an allowance cannot be topped up from the account it is paid into"
@@ -0,0 +1,59 @@
+Feature: Audit Log
+  As an admin
+  I want every change to our money recorded
+  So that I can tell who changed what when a balance looks wrong
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And a category "Groceries" of type "expense" exists
+    And an account "Chase Checking" of type "checking" exists
+    And a transaction exists with amount -5000
+
+  Scenario: Creating a transaction is recorded
+    When I get the audit log for "transaction" entities
+    Then I should see 1 audit events
+    And the latest audit event should be a "create" of the transaction
+    And the latest audit event should have amount -5000 after
+
+  Scenario: An update keeps the transaction as it was before
+    Given I change the transaction amount to -7500
+    When I get the audit log for "transaction" entities
+    Then I should see 2 audit events
+    And the latest audit event should be a "update" of the transaction
+    And the latest audit event should have amount -5000 before
+    And the latest audit event should have amount -7500 after
+
+  Scenario: A deleted transaction stays in the audit log
+    Given I delete the transaction
+    When I get the audit log for "transaction" entities
+    Then the latest audit event should be a "delete" of the transaction
+    And the latest audit event should have amount -5000 before
+    And the latest audit event should have nothing after
+
+  Scenario: Events record who made the change and in which request
+    Given I update the transaction description to "Weekly shop" in request "req-42"
+    When I get the audit log for "transaction" entities
+    Then the latest audit event should be a "update" of the transaction
+    And the latest audit event should be made by me in request "req-42"
+
+  Scenario: Filter the audit log by entity
+    Given a budget exists with amount 20000 for month 1 and year 2026
+    And I update the budget with amount 25000
+    When I get the audit log for "budget" entities
+    Then I should see 2 audit events
+    When I get the audit log of the transaction
+    Then I should see 1 audit events
+
+  Scenario: Page through the audit log
+    Given I change the transaction amount to -6000
+    And I change the transaction amount to -7000
+    When I get the audit log for "transaction" entities 2 at a time
+    Then I should see 2 audit events
+    And there should be another page of audit events
+    When I get the next page of the audit log
+    Then I should see 1 audit events
+    And the latest audit event should be a "create" of the transaction
+
+  Scenario: Audit events cannot be changed
+    When I try to delete the audit log
+    Then the audit log should refuse the change
@@ -7,6 +7,12 @@ Feature: User Authentication
     When I register with email "test@example.com" password "password123" and name "Test User"
     Then the registration should succeed
     And I should receive a user object with email "test@example.com"
+    And my household's base currency should be "EUR"
+
+  Scenario: User registration with a base currency
+    When I register with email "dollar@example.com" password "password123" name "Test User" and base currency "USD"
+    Then the registration should succeed
+    And my household's base currency should be "USD"
 
   Scenario: User login with valid credentials
     Given a user exists with email "john@example.com" and password "password123"
@@ -0,0 +1,77 @@
+Feature: Bill notices
+  As a family
+  I want to be told when a bill is coming up, due or overdue
+  So that no bill is paid late
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And an account "Main Account" of type "checking" exists
+    And a category "Utilities" of type "expense" exists
+
+  Scenario: A bill within its lead time is announced once
+    Given a bill reminder "Electricity" of 15000 due on "2026-03-15" with 3 lead days exists
+    When bill notices are sent on "2026-03-11"
+    Then my notification feed should have 0 notifications
+    When bill notices are sent on "2026-03-12"
+    And bill notices are sent on "2026-03-13"
+    Then my notification feed should have 1 notification
+    And my notification feed should include "Electricity is due in 3 days"
+
+  Scenario: A bill due today is announced on the day
+    Given a bill reminder "Electricity" of 15000 due on "2026-03-15" with 3 lead days exists
+    When bill notices are sent on "2026-03-14"
+    And bill notices are sent on "2026-03-15"
+    And bill notices are sent on "2026-03-15"
+    Then my notification feed should have 2 notifications
+    And my notification feed should include "Electricity is due tomorrow"
+    And my notification feed should include "Electricity is due today"
+
+  Scenario: An overdue bill is brought up again every week
+    Given a bill reminder "Electricity" of 15000 due on "2026-03-15" with 0 lead days exists
+    When bill notices are sent on "2026-03-16"
+    And bill notices are sent on "2026-03-20"
+    Then my notification feed should have 1 notification
+    And my notification feed should include "Electricity is overdue"
+    When bill notices are sent on "2026-03-23"
+    Then my notification feed should have 2 notifications
+    And my notification feed should include "Electricity is 8 days overdue"
+
+  Scenario: Bills go to every admin and member but not to children
+    Given a user exists with email "member@family.com" password "password123" name "Member" and role "member"
+    And a child user "kid@family.com" exists
+    And a bill reminder "Internet" of 3000 due on "2026-03-15" with 3 lead days exists
+    When bill notices are sent on "2026-03-15"
+    Then the notification feed of "member@family.com" should include "Internet is due today"
+    And the notification feed of "kid@family.com" should have 0 notifications
+
+  Scenario: Overdue bills escalate from the recipients to the admins
+    Given a user exists with email "member@family.com" password "password123" name "Member" and role "member"
+    And a bill reminder "Internet" of 3000 due on "2026-03-15" with 3 lead days exists
+    And the bill reminder is sent only to "member@family.com"
+    When bill notices are sent on "2026-03-15"
+    Then the notification feed of "member@family.com" should include "Internet is due today"
+    And my notification feed should have 0 notifications
+    When bill notices are sent on "2026-03-16"
+    Then the notification feed of "member@family.com" should include "Internet is overdue"
+    And my notification feed should include "Internet is overdue"
+
+  Scenario: Paying a bill stops its notices
+    Given a bill reminder "Electricity" exists with amount 15000 and account
+    When bill notices are sent on "2026-03-13"
+    And I pay the bill reminder on "2026-03-13"
+    And bill notices are sent on "2026-03-15"
+    And bill notices are sent on "2026-03-20"
+    Then my notification feed should have 1 notification
+    And my notification feed should include "Electricity is due in 2 days"
+
+  Scenario: Bill notices can be sent by email
+    Given I turn on email for "bill_due" notifications
+    And a bill reminder "Electricity" of 15000 due on "2026-03-15" with 3 lead days exists
+    When bill notices are sent on "2026-03-15"
+    Then an email "Electricity is due today" should have been sent to "admin@family.com"
+
+  Scenario: Turning off bill notices
+    Given I turn off "bill_due" notifications
+    And a bill reminder "Electricity" of 15000 due on "2026-03-15" with 3 lead days exists
+    When bill notices are sent on "2026-03-15"
+    Then my notification feed should have 0 notifications
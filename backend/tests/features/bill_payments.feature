@@ -0,0 +1,75 @@
+Feature: Bill payment history
+  As a family
+  I want to record what each bill actually cost and when it was paid
+  So that I can see late payments and what the next bill will likely be
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And a category "Utilities" of type "expense" exists
+    And an account "Chase Checking" of type "checking" exists
+    And a bill reminder "Electricity" exists with amount 15000 and account
+
+  Scenario: Paying a bill with the amount actually billed
+    When I pay the bill reminder on "2026-03-14" with amount 17350
+    Then the transaction should have amount -17350
+    And the account balance should be -17350
+    And the bill next due date should be "2026-04-15"
+    When I get the bill reminder history
+    Then the bill history should have 1 period
+    And the bill history period "2026-03-15" should be "paid"
+
+  Scenario: Paying without an amount uses the bill's amount
+    When I pay the bill reminder on "2026-03-14"
+    Then the transaction should have amount -15000
+    And the account balance should be -15000
+
+  Scenario: Paying after the due date is recorded as late
+    When I pay the bill reminder on "2026-03-20"
+    And I get the bill reminder history
+    Then the bill history period "2026-03-15" should be paid late
+
+  Scenario: Paying a bill twice at once pays two periods
+    When I pay the bill reminder twice at once on "2026-03-14"
+    Then the account balance should be -30000
+    And the bill next due date should be "2026-05-15"
+    When I get the bill reminder history
+    Then the bill history should have 2 periods
+
+  Scenario: A bill payment raises budget alerts like any other expense
+    Given a budget exists with amount 10000 for month 3 and year 2026
+    When I pay the bill reminder on "2026-03-14"
+    Then my notification feed should include "Utilities is over budget"
+
+  Scenario: Skipping a period
+    When I skip the bill reminder
+    Then the bill next due date should be "2026-04-15"
+    And the account balance should be 0
+    When I get the bill reminder history
+    Then the bill history period "2026-03-15" should be "skipped"
+
+  Scenario: A deferred bill keeps its schedule
+    When I defer the bill reminder to "2026-03-25"
+    Then the bill next due date should be "2026-03-25"
+    When I pay the bill reminder on "2026-03-24"
+    Then the bill next due date should be "2026-04-15"
+    When I get the bill reminder history
+    Then the bill history period "2026-03-15" should be "paid"
+
+  Scenario: A bill cannot be deferred to an earlier date
+    When I defer the bill reminder to "2026-03-10"
+    Then the request should fail with error "the new due date must be after the current one"
+
+  Scenario: The next amount is forecast from the paid amounts
+    When I pay the bill reminder on "2026-03-14" with amount 12000
+    And I pay the bill reminder on "2026-04-14" with amount 14000
+    And I skip the bill reminder
+    And I pay the bill reminder on "2026-06-14" with amount 19000
+    And I get the bill reminder history
+    Then the bill history should have 4 periods
+    And the bill history period "2026-05-15" should be "skipped"
+    And the bill history should have average 15000, last paid 19000 and forecast 17000
+
+  Scenario: Without payments the bill's amount is forecast
+    When I get the bill reminder history
+    Then the bill history should have 0 periods
+    And the bill history should forecast 15000 with no payments
@@ -0,0 +1,67 @@
+Feature: Budget templates
+  As an admin
+  I want to set a category's budget once for many months
+  So that I don't have to enter the same budgets every month
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And a category "Groceries" of type "expense" exists
+
+  Scenario: A template fills in the budgets of the months it covers
+    Given a budget template exists with amount 40000 from month 1 of 2026
+    When I get the budget summary for month 3 and year 2026
+    Then I should see 1 budget summaries
+    And the budget summary for "Groceries" should have budget 40000 and actual 0
+    When I list budgets for month 2 and year 2026
+    Then I should see 1 budgets
+    And 0 budgets should be stored
+
+  Scenario: A template stops at its end month
+    Given a budget template exists with amount 40000 from month 1 of 2026 to month 2 of 2026
+    When I get the budget summary for month 3 and year 2026
+    Then I should see 0 budget summaries
+
+  Scenario: An annual amount is spread over the months
+    Given a budget template exists with annual amount 100000 from month 1 of 2026
+    When I get the budget summary for month 1 and year 2026
+    Then the budget summary for "Groceries" should have budget 8334 and actual 0
+    When I get the budget summary for month 12 and year 2026
+    Then the budget summary for "Groceries" should have budget 8333 and actual 0
+
+  Scenario: A budget set for the month wins over the template
+    Given a budget exists with amount 50000 for month 2 and year 2026
+    And a budget template exists with amount 40000 from month 1 of 2026
+    When I get the budget summary for month 2 and year 2026
+    Then the budget summary for "Groceries" should have budget 50000 and actual 0
+
+  Scenario: Budgets from a template roll over
+    Given a budget template exists with amount 10000 from month 1 of 2026 rolling over "surplus"
+    And an account "Chase Checking" of type "checking" exists
+    And the following transactions exist:
+      | amount | description | date       |
+      | -4000  | Oil change  | 2026-01-10 |
+    When I get the budget summary for month 2 and year 2026
+    Then the budget summary for "Groceries" should have carried in 6000, effective amount 16000 and remaining 16000
+
+  Scenario: Deleting a template keeps its budgets up to the current month
+    Given a budget template exists with amount 40000 from month 1 of 2026
+    When I delete the budget template
+    And I get the budget summary for month 1 and year 2026
+    Then I should see 1 budget summaries
+    When I get the budget summary for month 1 and year 2099
+    Then I should see 0 budget summaries
+
+  Scenario: A template has either a monthly or an annual amount
+    When I try to create a budget template with amount 40000 and annual amount 480000
+    Then the request should fail with error "either a monthly or an annual amount is required"
+
+  Scenario: Copy last month's budgets
+    Given the following budgets exist:
+      | month | year | amount |
+      | 1     | 2026 | 40000  |
+    When I copy last month's budgets into month 2 of 2026
+    Then 1 budgets should have been copied
+    When I get the budget summary for month 2 and year 2026
+    Then the budget summary for "Groceries" should have budget 40000 and actual 0
+    When I copy last month's budgets into month 2 of 2026
+    Then 0 budgets should have been copied
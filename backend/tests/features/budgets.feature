@@ -43,3 +43,67 @@ Feature: Budget management
     When I get the budget summary for month 2 and year 2026
     Then I should see 1 budget summaries
     And the budget summary for "Groceries" should have budget 50000 and actual 25000
+
+  Scenario: Budgets do not roll over by default
+    Given a budget exists with amount 50000 for month 2 and year 2026
+    Then the budget should roll over "none"
+    When I set the budget rollover to "full"
+    Then the budget should be updated successfully
+    And the budget should roll over "full"
+    And the budget should have amount 50000
+
+  Scenario: Unspent money carries forward month after month
+    Given the following budgets exist:
+      | month | year | amount | rollover |
+      | 1     | 2026 | 10000  | surplus  |
+      | 2     | 2026 | 10000  | surplus  |
+      | 3     | 2026 | 10000  | surplus  |
+    And an account "Chase Checking" of type "checking" exists
+    And the following transactions exist:
+      | amount | description   | date       |
+      | -4000  | Oil change    | 2026-01-10 |
+      | -15000 | New tyres     | 2026-02-10 |
+    When I get the budget summary for month 2 and year 2026
+    Then the budget summary for "Groceries" should have carried in 6000, effective amount 16000 and remaining 1000
+    When I get the budget summary for month 3 and year 2026
+    Then the budget summary for "Groceries" should have carried in 1000, effective amount 11000 and remaining 11000
+
+  Scenario: Carrying only the surplus forgives overspending
+    Given the following budgets exist:
+      | month | year | amount | rollover |
+      | 1     | 2026 | 10000  | surplus  |
+      | 2     | 2026 | 10000  | surplus  |
+    And an account "Chase Checking" of type "checking" exists
+    And the following transactions exist:
+      | amount | description | date       |
+      | -15000 | New tyres   | 2026-01-10 |
+    When I get the budget summary for month 2 and year 2026
+    Then the budget summary for "Groceries" should have carried in 0, effective amount 10000 and remaining 10000
+
+  Scenario: Full rollover carries overspending into next month
+    Given the following budgets exist:
+      | month | year | amount | rollover |
+      | 1     | 2026 | 10000  | full     |
+      | 2     | 2026 | 10000  | full     |
+    And an account "Chase Checking" of type "checking" exists
+    And the following transactions exist:
+      | amount | description | date       |
+      | -15000 | New tyres   | 2026-01-10 |
+    When I get the budget summary for month 2 and year 2026
+    Then the budget summary for "Groceries" should have carried in -5000, effective amount 5000 and remaining 5000
+
+  Scenario: Nothing carries over a month without a budget
+    Given the following budgets exist:
+      | month | year | amount | rollover |
+      | 1     | 2026 | 10000  | surplus  |
+      | 3     | 2026 | 10000  | surplus  |
+    When I get the budget summary for month 3 and year 2026
+    Then the budget summary for "Groceries" should have carried in 0, effective amount 10000 and remaining 10000
+
+  Scenario: A budget without rollover keeps its leftover
+    Given the following budgets exist:
+      | month | year | amount |
+      | 1     | 2026 | 10000  |
+      | 2     | 2026 | 10000  |
+    When I get the budget summary for month 2 and year 2026
+    Then the budget summary for "Groceries" should have carried in 0, effective amount 10000 and remaining 10000
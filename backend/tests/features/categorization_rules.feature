@@ -0,0 +1,145 @@
+Feature: Categorization Rules
+  As a user
+  I want transactions to be categorized by rules I set up once
+  So that I don't have to pick a category for every purchase
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And a category "Fuel" of type "expense" exists
+    And a category "Groceries" of type "expense" exists
+    And an account "Chase Checking" of type "checking" exists
+
+  Scenario: A rule categorizes a new transaction
+    Given a categorization rule for category "Fuel" exists with:
+      """
+      {
+        "name": "Fuel stations",
+        "descriptionPattern": "(?i)^(shell|circle k)\\b",
+        "descriptionMatch": "regex",
+        "addTags": ["car"],
+        "setShared": false
+      }
+      """
+    When I create an uncategorized transaction with:
+      | field       | value         |
+      | amount      | -4500         |
+      | description | Shell Vilnius |
+      | date        | 2026-01-10    |
+    Then the transaction should be in category "Fuel"
+    And the transaction should have tag "car"
+    And the transaction should not be shared
+
+  Scenario: A category chosen by hand is kept
+    Given a categorization rule for category "Fuel" exists with:
+      """
+      { "name": "Shell", "descriptionPattern": "shell" }
+      """
+    When I create a transaction with:
+      | field       | value        |
+      | amount      | -300         |
+      | description | Shell snacks |
+      | date        | 2026-01-10   |
+    Then the transaction should be in category "Groceries"
+
+  Scenario: Rules are tried in priority order
+    Given a categorization rule for category "Fuel" exists with:
+      """
+      { "name": "Shell", "priority": 10, "descriptionPattern": "shell" }
+      """
+    And a categorization rule for category "Groceries" exists with:
+      """
+      { "name": "Small Shell purchases", "priority": 1, "descriptionPattern": "shell", "maxAmount": 1000 }
+      """
+    When I create an uncategorized transaction with:
+      | field       | value      |
+      | amount      | -800       |
+      | description | SHELL 0042 |
+      | date        | 2026-01-10 |
+    Then the transaction should be in category "Groceries"
+    When I create an uncategorized transaction with:
+      | field       | value      |
+      | amount      | -5200      |
+      | description | SHELL 0042 |
+      | date        | 2026-01-11 |
+    Then the transaction should be in category "Fuel"
+
+  Scenario: A rule matches on counterparty
+    Given a categorization rule for category "Groceries" exists with:
+      """
+      { "name": "Supermarket", "counterparty": "maxima" }
+      """
+    When I create an uncategorized transaction with:
+      | field        | value      |
+      | amount       | -2340      |
+      | description  | Card 1234  |
+      | counterparty | MAXIMA LT  |
+      | date         | 2026-01-12 |
+    Then the transaction should be in category "Groceries"
+
+  Scenario: A transaction no rule matches stays uncategorized
+    Given a categorization rule for category "Fuel" exists with:
+      """
+      { "name": "Shell", "descriptionPattern": "shell" }
+      """
+    When I create an uncategorized transaction with:
+      | field       | value      |
+      | amount      | -1200      |
+      | description | Cinema     |
+      | date        | 2026-01-10 |
+    Then the transaction should be uncategorized
+
+  Scenario: Rules categorize imported statement lines
+    Given a categorization rule for category "Fuel" exists with:
+      """
+      { "name": "Shell", "descriptionPattern": "shell" }
+      """
+    When I import the following "ofx" statement:
+      """
+      OFXHEADER:100
+      DATA:OFXSGML
+      VERSION:102
+
+      <OFX>
+      <BANKMSGSRSV1><STMTTRNRS><STMTRS>
+      <CURDEF>EUR
+      <BANKTRANLIST>
+      <STMTTRN>
+      <TRNTYPE>DEBIT
+      <DTPOSTED>20260110
+      <TRNAMT>-45.20
+      <FITID>2026011001
+      <NAME>Shell Vilnius
+      <STMTTRN>
+      <TRNTYPE>DEBIT
+      <DTPOSTED>20260111
+      <TRNAMT>-12.00
+      <FITID>2026011102
+      <NAME>Weekly shop
+      </BANKTRANLIST>
+      </STMTRS></STMTTRNRS></BANKMSGSRSV1>
+      </OFX>
+      """
+    Then 2 statement lines should be imported
+    And the transaction "Shell Vilnius" should be in category "Fuel"
+    And the transaction "Weekly shop" should be in category "Groceries"
+
+  Scenario: Re-run rules over uncategorized transactions
+    Given I create an uncategorized transaction with:
+      | field       | value           |
+      | amount      | -3000           |
+      | description | Circle K Kaunas |
+      | date        | 2026-01-10      |
+    And a categorization rule for category "Fuel" exists with:
+      """
+      { "name": "Circle K", "descriptionPattern": "circle k" }
+      """
+    When I apply the categorization rules
+    Then 1 transactions should be categorized
+    And the transaction should be in category "Fuel"
+
+  Scenario: A rule needs a condition
+    When I create a categorization rule with:
+      """
+      { "name": "Everything", "addTags": ["misc"] }
+      """
+    Then the request should fail with error "at least one condition is required"
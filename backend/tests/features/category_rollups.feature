@@ -0,0 +1,55 @@
+Feature: Category rollups
+  As a family
+  I want spending in subcategories to count towards their parents
+  So that "Food" shows everything we spent on food, restaurants included
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And an account "Chase Checking" of type "checking" exists
+    And a category "Food" of type "expense" exists
+
+  Scenario: The category tree has subtotals at each level
+    Given the following transactions exist:
+      | amount | description  | date       |
+      | -2000  | Corner shop  | 2026-02-03 |
+    And a subcategory "Restaurants" of the category exists
+    And the following transactions exist:
+      | amount | description  | date       |
+      | -3000  | Pizza        | 2026-02-05 |
+    And a subcategory "Sushi" of the category exists
+    And the following transactions exist:
+      | amount | description  | date       |
+      | -1500  | Sushi bar    | 2026-02-07 |
+    And a category "Transport" of type "expense" exists
+    And the following transactions exist:
+      | amount | description  | date       |
+      | -4000  | Fuel         | 2026-02-10 |
+    When I get the category tree for month 2 and year 2026
+    Then the category tree should have 2 top-level categories
+    And the category tree entry "Food" should have own amount 2000 and subtotal 6500
+    And the category tree entry "Restaurants" should have own amount 3000 and subtotal 4500
+    And the category tree entry "Sushi" should have own amount 1500 and subtotal 1500
+    And the category tree entry "Food" should have 1 subcategories
+
+  Scenario: The tree can be collapsed to a depth
+    Given a subcategory "Restaurants" of the category exists
+    And the following transactions exist:
+      | amount | description | date       |
+      | -3000  | Pizza       | 2026-02-05 |
+    When I get the category tree for month 2 and year 2026 down to depth 1
+    Then the category tree should have 1 top-level categories
+    And the category tree entry "Food" should have own amount 0 and subtotal 3000
+    And the category tree entry "Food" should have 0 subcategories
+
+  Scenario: A parent budget covers its subcategories
+    Given a budget exists with amount 50000 for month 2 and year 2026
+    And a subcategory "Restaurants" of the category exists
+    And the following transactions exist:
+      | amount | description | date       |
+      | -15000 | Dinner out  | 2026-02-05 |
+    And the current category is "Food"
+    And the following transactions exist:
+      | amount | description | date       |
+      | -10000 | Groceries   | 2026-02-06 |
+    When I get the budget summary for month 2 and year 2026
+    Then the budget summary for "Food" should have budget 50000 and actual 25000
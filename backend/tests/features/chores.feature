@@ -0,0 +1,115 @@
+Feature: Chores
+  As a parent
+  I want children to earn rewards for chores I approve
+  So that they can earn pocket money on top of their allowance
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And an account "Main Account" of type "checking" exists
+    And a child user "kid@family.com" exists with an account and a category
+
+  Scenario: An approved chore pays its reward into the child's account
+    Given a "once" chore "Wash the car" with a reward of 500 exists
+    When the child marks the chore done on "2026-03-02"
+    Then the chore should be waiting for approval
+    When I approve the chore into the child's account
+    Then the chore should be "approved"
+    And the child account balance should be 500
+
+  Scenario: The reward goes into the account the allowance is paid into
+    Given a "weekly" allowance of 2000 for the child starting "2026-03-02" topped up from the current account
+    And a "weekly" chore "Take out the trash" with a reward of 200 exists
+    When the child marks the chore done on "2026-03-03"
+    And I approve the chore
+    Then the chore should be "approved"
+    And the child account balance should be 200
+
+  Scenario: A reward needs an account when the child has no allowance
+    Given a "once" chore "Wash the car" with a reward of 500 exists
+    When the child marks the chore done on "2026-03-02"
+    And I approve the chore
+    Then the request should fail with error "an account to pay the reward into is required"
+
+  Scenario: A reward cannot be paid into a parent's account
+    Given a "once" chore "Wash the car" with a reward of 500 exists
+    When the child marks the chore done on "2026-03-02"
+    And I approve the chore into the current account
+    Then the request should fail with error "the reward must be paid into one of the child's accounts"
+
+  Scenario: A rejected chore pays nothing and can be done again
+    Given a "once" chore "Tidy the room" with a reward of 300 exists
+    When the child marks the chore done on "2026-03-02"
+    And I reject the chore
+    Then the chore should be "rejected"
+    And the child account balance should be 0
+    When the child marks the chore done on "2026-03-03"
+    Then the chore should be waiting for approval
+
+  Scenario: A chore cannot be reviewed twice
+    Given a "once" chore "Wash the car" with a reward of 500 exists
+    When the child marks the chore done on "2026-03-02"
+    And I approve the chore into the child's account
+    And I reject the chore
+    Then the request should fail with error "this chore has already been reviewed"
+    And the child account balance should be 500
+
+  Scenario: A weekly chore can be done once a week
+    Given a "weekly" chore "Take out the trash" with a reward of 200 exists
+    When the child marks the chore done on "2026-03-02"
+    And the child marks the chore done on "2026-03-08"
+    Then the request should fail with error "this chore has already been done for this period"
+    When the child marks the chore done on "2026-03-09"
+    Then the chore should be waiting for approval
+
+  Scenario: A one-off chore can only be done once
+    Given a "once" chore "Paint the fence" with a reward of 1000 exists
+    When the child marks the chore done on "2026-03-02"
+    And the child marks the chore done on "2026-05-02"
+    Then the request should fail with error "this chore has already been done for this period"
+
+  Scenario: A chore cannot be marked done for a future date
+    Given a "once" chore "Wash the car" with a reward of 500 exists
+    When the child marks the chore done for "2026-03-03" on "2026-03-02"
+    Then the request should fail with error "a chore cannot be marked done for a future date"
+
+  Scenario: A weekly chore can only be marked done for the current week
+    Given a "weekly" chore "Take out the trash" with a reward of 200 exists
+    When the child marks the chore done for "2026-03-08" on "2026-03-09"
+    Then the request should fail with error "this chore can no longer be marked done for that date"
+    When the child marks the chore done for "2026-03-09" on "2026-03-11"
+    Then the chore should be waiting for approval
+
+  Scenario: A one-off chore can be marked done up to a week back
+    Given a "once" chore "Paint the fence" with a reward of 1000 exists
+    When the child marks the chore done for "2026-03-01" on "2026-03-09"
+    Then the request should fail with error "this chore can no longer be marked done for that date"
+    When the child marks the chore done for "2026-03-02" on "2026-03-09"
+    Then the chore should be waiting for approval
+
+  Scenario: Only the assigned child can do a chore
+    Given a "daily" chore "Feed the cat" with a reward of 100 assigned to the child exists
+    And a user exists with email "sibling@family.com" password "password123" name "Sibling" and role "child"
+    When "sibling@family.com" marks the chore done on "2026-03-02"
+    Then the request should fail with error "this chore is not assigned to you"
+
+  Scenario: Chores can only be assigned to children
+    Given a "daily" chore "Feed the cat" with a reward of 100 exists
+    When I assign the chore to "admin@family.com"
+    Then the request should fail with error "chores can only be assigned to children"
+
+  Scenario: A retired chore cannot be done
+    Given a "daily" chore "Feed the cat" with a reward of 100 exists
+    And the chore is retired
+    When the child marks the chore done on "2026-03-02"
+    Then the request should fail with error "this chore is no longer active"
+
+  Scenario: Earnings count approved rewards and those waiting for approval
+    Given a "daily" chore "Feed the cat" with a reward of 100 exists
+    When the child marks the chore done on "2026-03-02"
+    And I approve the chore into the child's account
+    And the child marks the chore done on "2026-03-03"
+    And I approve the chore into the child's account
+    And the child marks the chore done on "2026-03-04"
+    And the child marks the chore done on "2026-04-01"
+    And I get chore earnings from "2026-03-01" to "2026-03-31"
+    Then the child should have earned 200 for 2 chores with 1 pending
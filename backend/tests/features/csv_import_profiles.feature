@@ -0,0 +1,89 @@
+Feature: CSV Import Profiles
+  As a user
+  I want to describe my bank's CSV layout once
+  So that I can import its exports without reformatting them
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And a category "Groceries" of type "expense" exists
+    And an account "Chase Checking" of type "checking" exists
+    And an import profile exists with:
+      """
+      {
+        "name": "Local bank",
+        "delimiter": ";",
+        "headerRow": 4,
+        "dateLayout": "02.01.2006",
+        "decimalSeparator": ",",
+        "amountSign": "debit_credit",
+        "columns": {
+          "date": "Data",
+          "debit": "Debetas",
+          "credit": "Kreditas",
+          "description": "Paskirtis",
+          "reference": "Nr"
+        }
+      }
+      """
+
+  Scenario: Preview a bank CSV export
+    When I preview the following "csv" statement:
+      """
+      Account;LT00 0000 0000 0000 0000
+      Period;2026-01
+
+      Nr;Data;Paskirtis;Debetas;Kreditas
+      101;10.01.2026;Weekly shop;1.234,50;
+      102;15.01.2026;Salary;;2.000,00
+      103;32.01.2026;Broken row;5,00;
+      """
+    Then the preview should show 2 valid, 0 duplicate and 1 invalid rows
+    And preview row 1 should have amount -123450 and description "Weekly shop"
+    And preview row 2 should have amount 200000 and description "Salary"
+    And preview row 3 should have an error containing "invalid date"
+    When I list transactions from "2026-01-01" to "2026-12-31"
+    Then I should see 0 transactions
+
+  Scenario: Import a bank CSV export with a profile
+    When I import the following "csv" statement:
+      """
+      Account;LT00 0000 0000 0000 0000
+      Period;2026-01
+
+      Nr;Data;Paskirtis;Debetas;Kreditas
+      101;10.01.2026;Weekly shop;1.234,50;
+      102;15.01.2026;Salary;;2.000,00
+      """
+    Then 2 statement lines should be imported
+    And the account balance should be 76550
+
+  Scenario: Preview marks rows that were already imported
+    Given I import the following "csv" statement:
+      """
+      Account;LT00 0000 0000 0000 0000
+      Period;2026-01
+
+      Nr;Data;Paskirtis;Debetas;Kreditas
+      101;10.01.2026;Weekly shop;12,50;
+      """
+    When I preview the following "csv" statement:
+      """
+      Account;LT00 0000 0000 0000 0000
+      Period;2026-01
+
+      Nr;Data;Paskirtis;Debetas;Kreditas
+      101;10.01.2026;Weekly shop;12,50;
+      102;11.01.2026;Bakery;3,20;
+      """
+    Then the preview should show 1 valid, 1 duplicate and 0 invalid rows
+
+  Scenario: A profile must map the amount columns
+    When I create an import profile with:
+      """
+      {
+        "name": "Broken",
+        "amountSign": "debit_credit",
+        "columns": { "date": "Date", "debit": "Out" }
+      }
+      """
+    Then the request should fail with error "debit and credit columns are required"
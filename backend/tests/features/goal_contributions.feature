@@ -0,0 +1,58 @@
+Feature: Saving goal contributions
+  As a parent
+  I want money put towards a saving goal to actually move to its savings account
+  So that the goal matches what is in the bank
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And an account "Main Account" of type "checking" exists
+    And a second account "Savings" of type "savings" exists
+    And a saving goal "Holiday" exists with target 100000 in the second account
+
+  Scenario: A contribution is a transfer to the goal's account
+    When I contribute 30000 to the saving goal from the current account
+    Then the saving goal current amount should be 30000
+    And the account balance should be -30000
+    And the second account balance should be 30000
+    And the saving goal should have 1 contribution
+    And the latest goal contribution should have amount 30000 and a transfer
+
+  Scenario: A goal bound to an account needs an account to transfer from
+    When I contribute 30000 to the saving goal
+    Then the request should fail with error "an account to transfer from or to is required"
+
+  Scenario: Withdrawing moves money back out of the goal
+    Given I contribute 30000 to the saving goal from the current account
+    When I withdraw 10000 from the saving goal to the current account
+    Then the saving goal current amount should be 20000
+    And the account balance should be -20000
+    And the second account balance should be 20000
+    And the saving goal should have 2 contributions
+    And the latest goal contribution should have amount -10000 and a transfer
+
+  Scenario: Cannot withdraw more than the goal holds
+    Given I contribute 30000 to the saving goal from the current account
+    When I withdraw 40000 from the saving goal to the current account
+    Then the request should fail with error "cannot withdraw more than the saving goal holds"
+
+  Scenario: A completed goal is active again after a withdrawal
+    Given I contribute 100000 to the saving goal from the current account
+    And the saving goal status should be "completed"
+    When I withdraw 5000 from the saving goal to the current account
+    Then the saving goal status should be "active"
+
+  Scenario: Deleting the transfer takes the contribution out of the goal
+    Given I contribute 30000 to the saving goal from the current account
+    And I contribute 20000 to the saving goal from the current account
+    When I delete the transfer of the latest goal contribution
+    Then the saving goal current amount should be 30000
+    And the saving goal should have 1 contribution
+    And the second account balance should be 30000
+
+  Scenario: Goals without an account keep a history without moving money
+    Given a saving goal "Piggy Bank" exists with target 10000
+    When I contribute 2500 to the saving goal
+    And I withdraw 500 from the saving goal
+    Then the saving goal current amount should be 2000
+    And the saving goal should have 2 contributions
+    And the account balance should be 0
@@ -0,0 +1,65 @@
+Feature: Saving goal projections
+  As a parent
+  I want to see when a saving goal will be reached at the current pace
+  So that I know how much to put aside each month to reach it in time
+
+  Background:
+    Given I am logged in as "admin@family.com"
+
+  Scenario: A goal saving fast enough is on track
+    Given a saving goal "Holiday" exists with target 120000 due on "2026-12-31"
+    And I contribute 10000 to the saving goal on "2026-01-01"
+    And I contribute 10000 to the saving goal on "2026-02-01"
+    And I contribute 10000 to the saving goal on "2026-03-01"
+    And I contribute 10000 to the saving goal on "2026-04-01"
+    And I contribute 10000 to the saving goal on "2026-05-01"
+    And I contribute 10000 to the saving goal on "2026-06-01"
+    When I get the saving goal projection on "2026-07-01"
+    Then the goal projection status should be "on_track"
+    And the goal projection monthly pace should be 10090
+    And the goal should be projected to complete on "2026-12-29"
+    And the goal projection should require 10000 a month
+
+  Scenario: A goal that will not be reached by its target date is behind
+    Given a saving goal "Holiday" exists with target 120000 due on "2026-10-31"
+    And I contribute 10000 to the saving goal on "2026-01-01"
+    And I contribute 10000 to the saving goal on "2026-02-01"
+    And I contribute 10000 to the saving goal on "2026-03-01"
+    And I contribute 10000 to the saving goal on "2026-04-01"
+    And I contribute 10000 to the saving goal on "2026-05-01"
+    And I contribute 10000 to the saving goal on "2026-06-01"
+    When I get the saving goal projection on "2026-07-01"
+    Then the goal projection status should be "behind"
+    And the goal should be projected to complete on "2026-12-29"
+    And the goal projection should require 15000 a month
+
+  Scenario: A goal nobody has saved towards is behind
+    Given a saving goal "Bike" exists with target 60000 due on "2026-12-31"
+    When I get the saving goal projection on "2026-07-01"
+    Then the goal projection status should be "behind"
+    And the goal projection monthly pace should be 0
+    And the goal should have no projected completion
+    And the goal projection should require 60000 a month
+
+  Scenario: A goal without a target date only gets a projected completion
+    Given a saving goal "Car" exists with target 120000
+    And I contribute 20000 to the saving goal on "2026-03-01"
+    And I contribute 20000 to the saving goal on "2026-05-01"
+    When I get the saving goal projection on "2026-07-01"
+    Then the goal projection status should be "no_target_date"
+    And the goal projection should not require a monthly amount
+
+  Scenario: A reached goal is completed
+    Given a saving goal "Bike" exists with target 20000 due on "2026-12-31"
+    And I contribute 20000 to the saving goal on "2026-06-01"
+    When I get the saving goal projection on "2026-07-01"
+    Then the goal projection status should be "completed"
+    And the goal projection should not require a monthly amount
+
+  Scenario: Listed goals include their projection
+    Given a saving goal "Bike" exists with target 60000 due on "2026-12-31"
+    And a saving goal "Holiday" exists with target 20000 due on "2026-12-31"
+    And I contribute 20000 to the saving goal on "2026-06-01"
+    When I list saving goals on "2026-07-01"
+    Then the listed saving goal "Bike" should have projection status "behind"
+    And the listed saving goal "Holiday" should have projection status "completed"
@@ -0,0 +1,41 @@
+Feature: Household isolation
+  As a self-hosted instance serving several families
+  I want every household's data to be private
+  So that no family can see or change another family's finances
+
+  Background:
+    Given another household exists with admin "admin@smith.com" and a transaction of -2500
+    And I am logged in as "admin@jones.com"
+
+  Scenario: Registration creates a separate household
+    Then my household should be different from the other household
+
+  Scenario: Admin lists only users of their own household
+    When I list all users
+    Then I should see 1 users
+
+  Scenario: Admin does not see another household's accounts
+    When I list all accounts
+    Then I should see 0 accounts
+
+  Scenario: Admin does not see another household's categories
+    When I list categories
+    Then I should see 0 categories
+
+  Scenario: Admin does not see another household's transactions
+    When I list all transactions
+    Then I should see 0 transactions
+
+  Scenario: Admin cannot read another household's account
+    When I try to get the other household's account
+    Then the request should fail with error "account not found"
+
+  Scenario: Admin cannot change another household's transaction
+    When I try to update the other household's transaction amount to -1
+    Then the request should fail with error "transaction not found"
+    And the other household's transaction should still have amount -2500
+
+  Scenario: Transactions cannot be booked to another household's account
+    Given a category "Groceries" of type "expense" exists
+    When I try to create a transaction on the other household's account
+    Then the ledger operation should fail
@@ -0,0 +1,45 @@
+Feature: Atomic ledger writes
+  As a family member
+  I want transactions and account balances to change together
+  So that a failure never leaves balances out of sync with the ledger
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And a category "Groceries" of type "expense" exists
+    And an account "Chase Checking" of type "checking" exists
+    And a transaction exists with amount -1000
+
+  Scenario: Failed balance update rolls back a new transaction
+    Given account balance updates are failing
+    When I try to create a transaction with amount -2500
+    Then the ledger operation should fail
+    And the account balance should be -1000
+    And the account should have 1 transactions
+
+  Scenario: Failed balance update rolls back a transaction edit
+    Given account balance updates are failing
+    When I try to update the transaction amount to -2500
+    Then the ledger operation should fail
+    And the account balance should be -1000
+    And the transaction should still have amount -1000
+
+  Scenario: Failed balance update rolls back a transaction delete
+    Given account balance updates are failing
+    When I delete the transaction
+    Then the ledger operation should fail
+    And the account balance should be -1000
+    And the account should have 1 transactions
+
+  Scenario: Failed balance update rolls back a bill payment
+    Given a bill reminder "Gas" exists with amount 12000 and account
+    And account balance updates are failing
+    When I pay the bill reminder on "2026-02-15"
+    Then the ledger operation should fail
+    And the account balance should be -1000
+    And the account should have 1 transactions
+    And the bill next due date should still be "2026-03-15"
+
+  Scenario: Successful writes update the balance
+    When I try to create a transaction with amount -2500
+    Then the account balance should be -3500
+    And the account should have 2 transactions
@@ -0,0 +1,84 @@
+Feature: Multi-currency reports
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And a category "Groceries" of type "expense" exists
+    And the following exchange rates are imported:
+      """
+      Date,USD,GBP,
+      2026-02-02,1.2000,0.8000,
+      2026-02-10,1.2500,N/A,
+      """
+
+  Scenario: Import ECB-style rates
+    Then 3 exchange rates should be imported
+    And the exchange rate import should report 0 errors
+
+  Scenario: Import long-format rates and skip invalid rows
+    When I import the following exchange rates:
+      """
+      date,base,quote,rate
+      2026-02-03,USD,JPY,150.5
+      2026-02-03,USD,CHF,abc
+      not-a-date,USD,JPY,151
+      """
+    Then 1 exchange rates should be imported
+    And the exchange rate import should report 2 errors
+
+  Scenario: Monthly report converts each transaction at its date's rate
+    Given an account "Euro Checking" of type "checking" exists
+    And the following transactions exist:
+      | amount | description | date       |
+      | -10000 | Groceries   | 2026-02-03 |
+    And an account "US Checking" of type "checking" in currency "USD" exists
+    And the following transactions exist:
+      | amount | description | date       |
+      | 120000 | Salary      | 2026-02-05 |
+      | -25000 | Groceries   | 2026-02-12 |
+    When I get the monthly report for month 2 and year 2026
+    Then the monthly report should have income 100000 and expense 30000
+
+  Scenario: Spending by category is converted
+    Given an account "US Checking" of type "checking" in currency "USD" exists
+    And the following transactions exist:
+      | amount | description | date       |
+      | -12000 | Groceries   | 2026-02-05 |
+    When I get the category report for month 2 and year 2026
+    Then the category "Groceries" should have total 10000
+
+  Scenario: Budget summary is converted
+    Given a budget exists with amount 50000 for month 2 and year 2026
+    And an account "US Checking" of type "checking" in currency "USD" exists
+    And the following transactions exist:
+      | amount | description | date       |
+      | -12000 | Groceries   | 2026-02-05 |
+      | -25000 | Groceries   | 2026-02-12 |
+    When I get the budget summary for month 2 and year 2026
+    Then the budget summary for "Groceries" should have budget 50000 and actual 30000
+
+  Scenario: Cross rates are used when no direct rate exists
+    Given the household base currency is "GBP"
+    And an account "US Checking" of type "checking" in currency "USD" exists
+    And the following transactions exist:
+      | amount | description | date       |
+      | -12000 | Groceries   | 2026-02-05 |
+    When I get the monthly report for month 2 and year 2026
+    Then the monthly report should have income 0 and expense 8000
+
+  Scenario: Report fails when no rate is available
+    Given an account "US Checking" of type "checking" in currency "USD" exists
+    And the following transactions exist:
+      | amount | description | date       |
+      | -12000 | Groceries   | 2026-01-15 |
+    When I get the monthly report for month 1 and year 2026
+    Then the report should fail with an error containing "no exchange rate from USD to EUR"
+    And the report should fail for a missing exchange rate
+
+  Scenario: Budget summary fails when no rate is available
+    Given a budget exists with amount 50000 for month 1 and year 2026
+    And an account "US Checking" of type "checking" in currency "USD" exists
+    And the following transactions exist:
+      | amount | description | date       |
+      | -12000 | Groceries   | 2026-01-15 |
+    When I get the budget summary for month 1 and year 2026
+    Then the report should fail for a missing exchange rate
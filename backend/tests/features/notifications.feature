@@ -0,0 +1,110 @@
+Feature: Notifications
+  As a parent
+  I want to be told when a budget runs low, an allowance runs out or a large amount is spent
+  So that I can act before the month is over
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And an account "Chase Checking" of type "checking" exists
+    And a category "Food" of type "expense" exists
+
+  Scenario: A budget passing 80% and then 100% raises one alert each
+    Given a budget exists with amount 10000 for month 2 and year 2026
+    And a user exists with email "member@family.com" password "password123" name "Member" and role "member"
+    When the following transactions exist:
+      | amount | description | date       |
+      | -8500  | Groceries   | 2026-02-03 |
+    Then my notification feed should have 1 notification
+    And my notification feed should include "Food has used 80% of its budget"
+    When the following transactions exist:
+      | amount | description | date       |
+      | -500   | Bakery      | 2026-02-04 |
+      | -2000  | Market      | 2026-02-05 |
+      | -300   | Coffee      | 2026-02-06 |
+    Then my notification feed should have 2 notifications
+    And my notification feed should include "Food is over budget"
+    And the notification feed of "member@family.com" should have 2 notifications
+
+  Scenario: Changing a transaction does not repeat an alert
+    Given a budget exists with amount 10000 for month 2 and year 2026
+    And a transaction exists with amount -9000
+    When I update the transaction with description "Weekly shop"
+    Then my notification feed should have 1 notification
+
+  Scenario: Raising a transaction's amount can push a budget over
+    Given a budget exists with amount 10000 for month 2 and year 2026
+    And a transaction exists with amount -5000
+    When I change the transaction amount to -12000
+    Then my notification feed should include "Food is over budget"
+
+  Scenario: Spending in a subcategory alerts the budget above it
+    Given a budget exists with amount 10000 for month 2 and year 2026
+    And a subcategory "Bakery" of the category exists
+    When a transaction exists with amount -9000
+    Then my notification feed should include "Food has used 80% of its budget"
+
+  Scenario: Only the budgets a transaction counts towards are checked
+    Given a transaction exists with amount -9000
+    And a budget exists with amount 10000 for month 2 and year 2026
+    And a category "Transport" of type "expense" exists
+    When a transaction exists with amount -100
+    Then my notification feed should have 0 notifications
+
+  Scenario: Children do not get budget alerts
+    Given a budget exists with amount 10000 for month 2 and year 2026
+    And a child user "kid@family.com" exists
+    When a transaction exists with amount -10000
+    Then the notification feed of "kid@family.com" should have 0 notifications
+
+  Scenario: A child's allowance running out alerts the child and the parents
+    Given a child user "kid@family.com" exists with an account and a category
+    And an allowance of 5000 for the child starting "2026-02-01"
+    When the child has a transaction of -3000 on "2026-02-10"
+    Then the notification feed of "kid@family.com" should have 0 notifications
+    When the child has a transaction of -2500 on "2026-02-12"
+    Then the notification feed of "kid@family.com" should include "Your allowance has run out"
+    And my notification feed should include "Child's allowance has run out"
+
+  Scenario: Spending outside the allowance period does not alert
+    Given a child user "kid@family.com" exists with an account and a category
+    And an allowance of 5000 for the child starting "2026-02-01"
+    When the child has a transaction of -6000 on "2026-03-05"
+    Then the notification feed of "kid@family.com" should have 0 notifications
+
+  Scenario: A large transaction by another family member alerts the parents
+    Given a child user "kid@family.com" exists with an account and a category
+    When the child has a transaction of -60000 on "2026-02-10"
+    Then my notification feed should include "Large transaction by Child"
+
+  Scenario: Large transactions are measured against each parent's threshold
+    Given I set my large transaction threshold to 100000
+    And a child user "kid@family.com" exists with an account and a category
+    When the child has a transaction of -60000 on "2026-02-10"
+    Then my notification feed should have 0 notifications
+
+  Scenario: A parent is not alerted of their own large transactions
+    When a transaction exists with amount -90000
+    Then my notification feed should have 0 notifications
+
+  Scenario: A kind of notification can be turned off
+    Given I turn off "budget_threshold" notifications
+    And a budget exists with amount 10000 for month 2 and year 2026
+    When a transaction exists with amount -12000
+    Then my notification feed should have 0 notifications
+
+  Scenario: Alerts are emailed to users who opted in
+    Given I turn on email for "budget_threshold" notifications
+    And a user exists with email "member@family.com" password "password123" name "Member" and role "member"
+    And a budget exists with amount 10000 for month 2 and year 2026
+    When a transaction exists with amount -12000
+    Then 1 email should have been sent
+    And an email "Food is over budget" should have been sent to "admin@family.com"
+    And the notification feed of "member@family.com" should have 1 notification
+
+  Scenario: Marking notifications as read
+    Given a budget exists with amount 10000 for month 2 and year 2026
+    And a transaction exists with amount -12000
+    Then I should have 1 unread notification
+    When I mark all my notifications as read
+    Then I should have 0 unread notifications
+    And my notification feed should have 1 notification
@@ -0,0 +1,53 @@
+Feature: Transaction pagination and sorting
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And a category "Groceries" of type "expense" exists
+    And an account "Chase Checking" of type "checking" exists
+    And the following transactions exist:
+      | amount | description      | date       |
+      | -1000  | A groceries      | 2026-01-01 |
+      | -5000  | B groceries      | 2026-01-02 |
+      | -3000  | C dinner         | 2026-01-03 |
+      | -2000  | D groceries      | 2026-01-03 |
+      | -4000  | E dinner         | 2026-01-05 |
+
+  Scenario: Page through transactions newest first
+    When I list my transactions with limit 2
+    Then the page should contain "E dinner, D groceries"
+    And the total count should be 5
+    When I request the next page
+    Then the page should contain "C dinner, B groceries"
+    When I request the next page
+    Then the page should contain "A groceries"
+    And there should be no next page
+
+  Scenario: Sort by amount ascending
+    When I list my transactions sorted by "amount" "asc" with limit 3
+    Then the page should contain "B groceries, E dinner, C dinner"
+    When I request the next page
+    Then the page should contain "D groceries, A groceries"
+    And there should be no next page
+
+  Scenario: Every transaction is seen exactly once across pages
+    When I page through my transactions with limit 1
+    Then I should have seen 5 distinct transactions
+
+  Scenario: Search is paginated with a real total count
+    When I search transactions with description "groceries" and limit 2
+    Then the page should contain "D groceries, B groceries"
+    And the total count should be 3
+    When I request the next page
+    Then the page should contain "A groceries"
+    And the total count should be 3
+    And there should be no next page
+
+  Scenario: A cursor for a different sort is rejected
+    When I list my transactions with limit 2
+    And I request the next page sorted by "amount" "asc"
+    Then the request should fail with error "cursor does not match sort order"
+
+  Scenario: A cursor with a value of the wrong kind is rejected
+    When I list my transactions with limit 2
+    And I request a page sorted by "amount" "asc" after the value "abc"
+    Then the request should fail with error "invalid cursor"
@@ -0,0 +1,89 @@
+Feature: Purchase approval
+  As a parent
+  I want a child's larger purchases to wait for my approval
+  So that they only come off the child's balance once I agree
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And a child user "kid@family.com" exists with an account and a category
+    And the child needs approval for purchases above 2000
+
+  Scenario: A purchase up to the threshold is booked straight away
+    When the child spends 2000 on "2026-03-02"
+    Then the purchase should be "uncleared"
+    And the child account balance should be -2000
+    And there should be 0 purchases waiting for approval
+
+  Scenario: A purchase above the threshold waits for approval
+    When the child spends 2500 on "2026-03-02"
+    Then the purchase should be "pending_approval"
+    And the child account balance should be 0
+    And there should be 1 purchase waiting for approval
+
+  Scenario: An approved purchase comes off the balance
+    When the child spends 2500 on "2026-03-02"
+    And I approve the purchase
+    Then the purchase should be "uncleared"
+    And the child account balance should be -2500
+    And there should be 0 purchases waiting for approval
+
+  Scenario: A rejected purchase never comes off the balance
+    When the child spends 2500 on "2026-03-02"
+    And I reject the purchase
+    Then the purchase should be "rejected"
+    And the child account balance should be 0
+    And there should be 0 purchases waiting for approval
+
+  Scenario: A purchase cannot be reviewed twice
+    When the child spends 2500 on "2026-03-02"
+    And I approve the purchase
+    And I reject the purchase
+    Then the request should fail with error "transaction is not waiting for approval"
+    And the child account balance should be -2500
+
+  Scenario: A purchase waiting for approval cannot be changed
+    When the child spends 2500 on "2026-03-02"
+    And the child changes the purchase amount to -1000
+    Then the request should fail with error "transaction is waiting for approval"
+
+  Scenario: Raising a purchase above the threshold sends it for approval
+    When the child spends 1000 on "2026-03-02"
+    And the child changes the purchase amount to -3000
+    Then the purchase should be "pending_approval"
+    And the child account balance should be 0
+    And there should be 1 purchase waiting for approval
+
+  Scenario: A child cannot move money out with a transfer
+    Given an account "Main Account" of type "checking" exists
+    When the child books a "transfer" of -50000 on "2026-03-02"
+    Then the request should fail with error "children cannot make transfers"
+    And the child account balance should be 0
+
+  Scenario: An expense cannot have a positive amount
+    When the child books an "expense" of 2500 on "2026-03-02"
+    Then the request should fail with error "expenses need a negative amount and income a positive one"
+
+  Scenario: A purchase cannot be turned into income
+    When the child spends 1000 on "2026-03-02"
+    And the child changes the purchase amount to 3000
+    Then the request should fail with error "expenses need a negative amount and income a positive one"
+    And the child account balance should be -1000
+
+  Scenario: A purchase waiting for approval does not count as allowance spending
+    Given an allowance of 10000 for the child starting "2026-03-01"
+    When the child spends 2500 on "2026-03-02"
+    And I get allowances
+    Then the allowance spent should be 0
+    When I approve the purchase
+    And I get allowances
+    Then the allowance spent should be 2500
+
+  Scenario: Without a threshold no purchase needs approval
+    When I remove the child's approval threshold
+    And the child spends 50000 on "2026-03-02"
+    Then the purchase should be "uncleared"
+    And the child account balance should be -50000
+
+  Scenario: Only children can have an approval threshold
+    When I set an approval threshold of 5000 for "admin@family.com"
+    Then the request should fail with error "only children can have a purchase approval threshold"
@@ -0,0 +1,57 @@
+Feature: Account Reconciliation
+  As a user
+  I want to check my accounts against bank statements
+  So that I know the balances in the app are the balances in the bank
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And a category "Groceries" of type "expense" exists
+    And an account "Chase Checking" of type "checking" exists
+    And I record a transaction "Coffee" of -500 on "2026-01-05"
+    And I record a transaction "Rent" of -50000 on "2026-01-10"
+    And I record a transaction "Bakery" of -700 on "2026-02-03"
+
+  Scenario: Reconcile an account that matches the statement
+    When I start a reconciliation on "2026-01-31" with closing balance -50500
+    Then the reconciliation difference should be -50500
+    And the reconciliation should list 2 transactions
+    When I clear the transaction "Coffee"
+    And I clear the transaction "Rent"
+    Then the reconciliation difference should be 0
+    When I complete the reconciliation
+    Then the reconciliation should be completed
+    And the transaction "Coffee" should be "reconciled"
+    And the transaction "Rent" should be "reconciled"
+    And the transaction "Bakery" should be "uncleared"
+
+  Scenario: A reconciliation with a difference cannot be completed
+    Given I start a reconciliation on "2026-01-31" with closing balance -50500
+    And I clear the transaction "Coffee"
+    When I complete the reconciliation
+    Then the request should fail with error "cleared balance does not match the closing balance"
+    And the transaction "Coffee" should be "cleared"
+
+  Scenario: Book an adjustment for the difference
+    Given I start a reconciliation on "2026-01-31" with closing balance -50650
+    And I clear the transaction "Coffee"
+    And I clear the transaction "Rent"
+    When I complete the reconciliation with an adjustment
+    Then the reconciliation should be completed
+    And the transaction "Reconciliation adjustment" should be "reconciled"
+    And the account balance should be -51350
+
+  Scenario: Reconciled transactions are locked until unlocked
+    Given I start a reconciliation on "2026-01-31" with closing balance -500
+    And I clear the transaction "Coffee"
+    And I complete the reconciliation
+    When I change the description of transaction "Coffee" to "Espresso"
+    Then the request should fail with error "transaction is reconciled; unlock it first"
+    When I unlock the transaction "Coffee"
+    And I change the description of transaction "Coffee" to "Espresso"
+    Then the transaction should be updated successfully
+    And the transaction "Espresso" should be "cleared"
+
+  Scenario: An account has one open reconciliation at a time
+    Given I start a reconciliation on "2026-01-31" with closing balance -50500
+    When I start a reconciliation on "2026-02-28" with closing balance -51200
+    Then the request should fail with error "account already has an open reconciliation"
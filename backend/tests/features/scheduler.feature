@@ -0,0 +1,52 @@
+Feature: Background scheduler
+  As a family
+  I want recurring transactions, overdue bills and the trash handled daily
+  So that nobody has to trigger them by hand
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And an account "Main Account" of type "checking" exists
+    And a category "Rent" of type "expense" exists
+
+  Scenario: Recurring transactions are generated for every user
+    Given I have a recurring transaction of -150000 on "2025-10-01" with frequency "monthly"
+    And member "partner@family.com" has a recurring transaction of -5000 on "2025-12-01" with frequency "monthly"
+    When the scheduler runs on "2026-01-15"
+    Then the last "recurring_transactions" job run should have status "succeeded" and summary "generated 4 transactions from 2 templates"
+
+  Scenario: Jobs run once per day
+    Given I have a recurring transaction of -5000 on "2025-12-01" with frequency "monthly"
+    When the scheduler runs on "2026-01-15"
+    And the scheduler runs on "2026-01-15"
+    Then 5 job runs should be recorded
+    When the scheduler runs on "2026-01-16"
+    Then 10 job runs should be recorded
+    And the last "recurring_transactions" job run should have status "succeeded" and summary "generated 0 transactions from 1 templates"
+
+  Scenario: A failing job is tried at most three times a day
+    Given I have a recurring transaction of -5000 on "2025-12-01" with frequency "monthly"
+    And account balance updates are failing
+    When the scheduler runs on "2026-01-15"
+    And the scheduler runs on "2026-01-15"
+    And the scheduler runs on "2026-01-15"
+    And the scheduler runs on "2026-01-15"
+    Then 3 "recurring_transactions" job runs should be recorded
+    And the last "recurring_transactions" job run should have status "failed" and summary "generated 0 transactions from 1 templates"
+    When the scheduler runs on "2026-01-16"
+    Then 4 "recurring_transactions" job runs should be recorded
+
+  Scenario: Overdue bills are flagged
+    Given the following bill reminders exist:
+      | name        | amount | dueDay | frequency | nextDueDate |
+      | Electricity | 8000   | 10     | monthly   | 2026-01-10  |
+      | Internet    | 3000   | 20     | monthly   | 2026-01-20  |
+    When the scheduler runs on "2026-01-15"
+    Then the bill reminder "Electricity" should be overdue
+    And the bill reminder "Internet" should not be overdue
+    And the last "overdue_bills" job run should have status "succeeded" and summary "flagged 1 overdue bills"
+
+  Scenario: Another replica holding the lock skips the run
+    Given another replica holds the scheduler lock
+    When the scheduler runs on "2026-01-15"
+    Then the scheduler run should have been skipped
+    And 0 job runs should be recorded
@@ -0,0 +1,49 @@
+Feature: Sessions
+  As a user
+  I want to stay signed in with short-lived tokens that can be revoked
+  So that a lost device or a changed role does not keep old access
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And a user exists with email "kid@family.com" password "password123" name "Kid" and role "member"
+
+  Scenario: Refreshing rotates the refresh token
+    Given I login with email "kid@family.com" and password "password123"
+    When I refresh my session
+    Then the refresh should succeed
+    And I should receive a new refresh token
+
+  Scenario: A refresh token can only be used once
+    Given I login with email "kid@family.com" and password "password123"
+    And I refresh my session
+    When I refresh my session with the used refresh token
+    Then the request should fail with error "invalid refresh token"
+    When I refresh my session
+    Then the request should fail with error "invalid refresh token"
+
+  Scenario: Logging out ends the session
+    Given I login with email "kid@family.com" and password "password123"
+    When I log out
+    And I refresh my session
+    Then the request should fail with error "invalid refresh token"
+
+  Scenario: A changed role is picked up on refresh
+    Given I login with email "kid@family.com" and password "password123"
+    And I update the user role to "child"
+    When I refresh my session
+    Then the refresh should succeed
+    And my access token should have role "child"
+
+  Scenario: An admin revokes all sessions of a user
+    Given I login with email "kid@family.com" and password "password123"
+    And I login with email "kid@family.com" and password "password123"
+    When I revoke all sessions of the user
+    Then 2 sessions should be revoked
+    When I refresh my session
+    Then the request should fail with error "invalid refresh token"
+
+  Scenario: A deleted user cannot refresh
+    Given I login with email "kid@family.com" and password "password123"
+    And I delete the user
+    When I refresh my session
+    Then the request should fail with error "invalid refresh token"
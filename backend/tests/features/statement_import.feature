@@ -0,0 +1,102 @@
+Feature: Bank Statement Import
+  As a user
+  I want to import OFX and CAMT.053 statements from my bank
+  So that I don't have to type in transactions by hand
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And a category "Groceries" of type "expense" exists
+    And an account "Chase Checking" of type "checking" exists
+
+  Scenario: Import an OFX statement
+    When I import the following "ofx" statement:
+      """
+      OFXHEADER:100
+      DATA:OFXSGML
+      VERSION:102
+
+      <OFX>
+      <BANKMSGSRSV1><STMTTRNRS><STMTRS>
+      <CURDEF>EUR
+      <BANKTRANLIST>
+      <STMTTRN>
+      <TRNTYPE>DEBIT
+      <DTPOSTED>20260110120000[0:GMT]
+      <TRNAMT>-45.20
+      <FITID>2026011001
+      <NAME>Weekly shop
+      <STMTTRN>
+      <TRNTYPE>CREDIT
+      <DTPOSTED>20260115
+      <TRNAMT>1500.00
+      <FITID>2026011502
+      <NAME>Salary
+      </BANKTRANLIST>
+      </STMTRS></STMTTRNRS></BANKMSGSRSV1>
+      </OFX>
+      """
+    Then 2 statement lines should be imported
+    And the account balance should be 145480
+    When I list transactions from "2026-01-01" to "2026-12-31"
+    Then I should see 2 transactions
+
+  Scenario: Import a CAMT.053 statement
+    When I import the following "camt053" statement:
+      """
+      <?xml version="1.0" encoding="UTF-8"?>
+      <Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+        <BkToCstmrStmt>
+          <Stmt>
+            <Acct><Ccy>EUR</Ccy></Acct>
+            <Ntry>
+              <Amt Ccy="EUR">12.50</Amt>
+              <CdtDbtInd>DBIT</CdtDbtInd>
+              <Sts>BOOK</Sts>
+              <BookgDt><Dt>2026-02-03</Dt></BookgDt>
+              <AcctSvcrRef>REF-0001</AcctSvcrRef>
+              <NtryDtls><TxDtls>
+                <RltdPties><Cdtr><Nm>Bakery</Nm></Cdtr></RltdPties>
+                <RmtInf><Ustrd>Bread</Ustrd></RmtInf>
+              </TxDtls></NtryDtls>
+            </Ntry>
+            <Ntry>
+              <Amt Ccy="EUR">3.00</Amt>
+              <CdtDbtInd>DBIT</CdtDbtInd>
+              <Sts>PDNG</Sts>
+              <BookgDt><Dt>2026-02-04</Dt></BookgDt>
+              <AcctSvcrRef>REF-0002</AcctSvcrRef>
+            </Ntry>
+          </Stmt>
+        </BkToCstmrStmt>
+      </Document>
+      """
+    Then 1 statement lines should be imported
+    And the account balance should be -1250
+
+  Scenario: Re-importing a statement skips lines already imported
+    Given I import the following "ofx" statement:
+      """
+      <OFX><BANKTRANLIST>
+      <STMTTRN><DTPOSTED>20260110</DTPOSTED><TRNAMT>-10.00</TRNAMT><FITID>A1</FITID><NAME>Coffee</NAME></STMTTRN>
+      </BANKTRANLIST></OFX>
+      """
+    When I import the following "ofx" statement:
+      """
+      <OFX><BANKTRANLIST>
+      <STMTTRN><DTPOSTED>20260110</DTPOSTED><TRNAMT>-10.00</TRNAMT><FITID>A1</FITID><NAME>Coffee</NAME></STMTTRN>
+      <STMTTRN><DTPOSTED>20260111</DTPOSTED><TRNAMT>-4.00</TRNAMT><FITID>A2</FITID><NAME>Bus fare</NAME></STMTTRN>
+      </BANKTRANLIST></OFX>
+      """
+    Then 1 statement lines should be imported
+    And 1 statement lines should be reported as duplicates
+    And the account balance should be -1400
+
+  Scenario: Lines in another currency are rejected
+    When I import the following "ofx" statement:
+      """
+      <OFX><CURDEF>USD</CURDEF><BANKTRANLIST>
+      <STMTTRN><DTPOSTED>20260110</DTPOSTED><TRNAMT>-10.00</TRNAMT><FITID>U1</FITID></STMTTRN>
+      </BANKTRANLIST></OFX>
+      """
+    Then 0 statement lines should be imported
+    And the statement import should report 1 errors
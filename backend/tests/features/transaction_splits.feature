@@ -0,0 +1,76 @@
+Feature: Split Transactions
+  As a user
+  I want to split one receipt across several categories
+  So that reports and budgets show what the money was really spent on
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And a category "Groceries" of type "expense" exists
+    And a category "Kids Clothes" of type "expense" exists
+    And a category "Household" of type "expense" exists
+    And an account "Chase Checking" of type "checking" exists
+
+  Scenario: Split a supermarket receipt
+    When I create a transaction of -10000 on "2026-01-10" split as:
+      | category     | amount | memo           |
+      | Groceries    | -6000  |                |
+      | Household    | -2500  | Cleaning spray |
+      | Kids Clothes | -1500  | Socks          |
+    Then the transaction should have 3 splits
+    And the account balance should be -10000
+
+  Scenario: Splits must add up to the transaction amount
+    When I create a transaction of -10000 on "2026-01-10" split as:
+      | category  | amount |
+      | Groceries | -6000  |
+      | Household | -2500  |
+    Then the request should fail with error "split amounts must add up to the transaction amount"
+
+  Scenario: Splits must have the sign of the transaction
+    When I create a transaction of -10000 on "2026-01-10" split as:
+      | category  | amount |
+      | Groceries | -12000 |
+      | Household | 2000   |
+    Then the request should fail with error "split amounts must have the same sign as the transaction amount"
+
+  Scenario: Spending by category counts each split
+    Given I create a transaction of -10000 on "2026-01-10" split as:
+      | category     | amount |
+      | Groceries    | -6000  |
+      | Household    | -2500  |
+      | Kids Clothes | -1500  |
+    When I get the category report for month 1 and year 2026
+    Then I should see 3 category entries
+    And the category "Groceries" should have total 6000
+    And the category "Household" should have total 2500
+    And the category "Kids Clothes" should have total 1500
+
+  Scenario: Budgets count the split for their category
+    Given a budget exists with amount 5000 for month 1 and year 2026
+    And I create a transaction of -10000 on "2026-01-10" split as:
+      | category  | amount |
+      | Groceries | -6000  |
+      | Household | -4000  |
+    When I get the budget summary for month 1 and year 2026
+    Then the budget summary for "Household" should have budget 5000 and actual 4000
+
+  Scenario: Replace the splits of a transaction
+    Given I create a transaction of -10000 on "2026-01-10" split as:
+      | category  | amount |
+      | Groceries | -6000  |
+      | Household | -4000  |
+    When I change the transaction splits to:
+      | category  | amount |
+      | Groceries | -9000  |
+      | Household | -1000  |
+    Then the transaction should have 2 splits
+    When I get the category report for month 1 and year 2026
+    Then the category "Household" should have total 1000
+
+  Scenario: The amount of a split transaction cannot change on its own
+    Given I create a transaction of -10000 on "2026-01-10" split as:
+      | category  | amount |
+      | Groceries | -6000  |
+      | Household | -4000  |
+    When I change the transaction amount to -12000
+    Then the request should fail with error "split amounts must add up to the transaction amount"
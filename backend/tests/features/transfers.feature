@@ -10,6 +10,14 @@ Feature: Account transfers
     When I transfer 50000 from "Chase Checking" to "Savings Account" with description "Monthly savings"
     Then the transfer should be created successfully
     And the transfer transaction should have amount -50000
+    And the account balance should be -50000
+    And the second account balance should be 50000
+
+  Scenario: Changing a transfer's amount moves both balances
+    Given I transfer 50000 from "Chase Checking" to "Savings Account" with description "Monthly savings"
+    When I try to update the transaction amount to -30000
+    Then the account balance should be -30000
+    And the second account balance should be 30000
 
   Scenario: Cannot transfer to same account
     When I transfer 10000 from "Chase Checking" to "Chase Checking" with description "Invalid"
@@ -0,0 +1,74 @@
+Feature: Trash
+  As a family
+  I want deleted items kept in a trash for a while
+  So that a misclick can be undone
+
+  Background:
+    Given I am logged in as "admin@family.com"
+    And a category "Groceries" of type "expense" exists
+    And an account "Chase Checking" of type "checking" exists
+
+  Scenario: A deleted transaction can be restored
+    Given a transaction exists with amount -5000
+    When I delete the transaction
+    Then the account balance should be 0
+    And the trash should contain the transaction
+    When I restore the transaction from the trash
+    Then the account balance should be -5000
+    And the account should have 1 transactions
+    And the trash should be empty
+
+  Scenario: Deleting an account keeps its transactions with it
+    Given a transaction exists with amount -5000
+    And a transaction exists with amount -2500
+    When I delete the account
+    Then the trash should contain the account
+    And the trash should not contain the transaction
+    When I restore the account from the trash
+    Then the account should have 2 transactions
+    And the account balance should be -7500
+    And the trash should be empty
+
+  Scenario: A transaction cannot be restored while its account is in the trash
+    Given a transaction exists with amount -5000
+    And I delete the transaction
+    And I delete the account
+    When I restore the transaction from the trash
+    Then the request should fail with error "the account is in the trash; restore it first"
+
+  Scenario: A category in use cannot be deleted
+    Given a transaction exists with amount -5000
+    When I delete the category
+    Then the request should fail with error "category is in use by transactions or budgets"
+
+  Scenario: An unused category can be deleted and restored
+    When I delete the category
+    Then the trash should contain the category
+    When I restore the category from the trash
+    Then the trash should be empty
+
+  Scenario: A budget cannot be restored over a newer one
+    Given a budget exists with amount 50000 for month 1 and year 2026
+    And I delete the budget
+    And the following budgets exist:
+      | month | year | amount |
+      | 1     | 2026 | 60000  |
+    When I restore the budget from the trash
+    Then the request should fail with error "a budget for this category and month already exists"
+
+  Scenario: A saving goal can be deleted and restored
+    Given a saving goal "Vacation" exists with target 500000
+    When I delete the saving goal
+    And I list saving goals
+    Then I should see 0 saving goals
+    When I restore the saving goal from the trash
+    And I list saving goals
+    Then I should see 1 saving goals
+
+  Scenario: The trash is purged after the retention period
+    Given a transaction exists with amount -5000
+    And I delete the transaction
+    When the trash is purged 29 days from now
+    Then the trash should contain the transaction
+    When the trash is purged 31 days from now
+    Then the trash should be empty
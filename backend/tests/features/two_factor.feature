@@ -0,0 +1,64 @@
+Feature: Two-Factor Authentication
+  As an admin
+  I want to protect my account with an authenticator app
+  So that a leaked password alone does not open the family's finances
+
+  Background:
+    Given I am logged in as "admin@family.com"
+
+  Scenario: Enroll with an authenticator app
+    When I set up two-factor authentication
+    Then I should receive a provisioning URI for "admin@family.com"
+    When I enable two-factor authentication with a valid code
+    Then I should receive 10 recovery codes
+
+  Scenario: Enrollment needs a code from the app
+    Given I set up two-factor authentication
+    When I enable two-factor authentication with code "12345"
+    Then the request should fail with error "invalid two-factor code"
+
+  Scenario: Login asks for a code once two-factor authentication is enabled
+    Given two-factor authentication is enabled for me
+    When I login with email "admin@family.com" and password "password123"
+    Then a two-factor challenge should be returned
+    When I verify the login with a valid code
+    Then the login should succeed
+    And I should receive a JWT token
+
+  Scenario: A code works only once
+    Given two-factor authentication is enabled for me
+    And I login with email "admin@family.com" and password "password123"
+    And I verify the login with a valid code
+    And I login with email "admin@family.com" and password "password123"
+    When I verify the login with the same code again
+    Then the request should fail with error "invalid two-factor code"
+
+  Scenario: A recovery code signs in once
+    Given two-factor authentication is enabled for me
+    And I login with email "admin@family.com" and password "password123"
+    When I verify the login with a recovery code
+    Then the login should succeed
+    When I login with email "admin@family.com" and password "password123"
+    And I verify the login with the same code again
+    Then the request should fail with error "invalid two-factor code"
+
+  Scenario: Too many wrong codes lock the second factor
+    Given two-factor authentication is enabled for me
+    And I login with email "admin@family.com" and password "password123"
+    And I verify the login with code "12345" 5 times
+    When I verify the login with a valid code
+    Then the request should fail with error "too many invalid two-factor codes, try again later"
+
+  Scenario: The household requires two-factor authentication for admins
+    Given my household requires two-factor authentication for admins
+    When I login with email "admin@family.com" and password "password123"
+    Then two-factor setup should be required
+    When two-factor authentication is enabled for me
+    And I refresh my session
+    Then two-factor setup should not be required
+
+  Scenario: Admins cannot turn off required two-factor authentication
+    Given two-factor authentication is enabled for me
+    And my household requires two-factor authentication for admins
+    When I disable two-factor authentication with a valid code
+    Then the request should fail with error "two-factor authentication is required for admins"
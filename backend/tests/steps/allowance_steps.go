@@ -3,6 +3,7 @@ package steps
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/cucumber/godog"
@@ -21,6 +22,19 @@ func registerAllowanceSteps(ctx *godog.ScenarioContext, tc *TestContext) {
 	ctx.Step(`^I update the allowance amount to (\d+)$`, tc.iUpdateAllowanceAmountTo)
 	ctx.Step(`^the allowance should be updated successfully$`, tc.theAllowanceShouldBeUpdated)
 	ctx.Step(`^I should see (\d+) allowances$`, tc.iShouldSeeNAllowances)
+	ctx.Step(`^a "([^"]*)" allowance of (\d+) for the child starting "([^"]*)"$`, tc.anAllowanceWithFrequencyExistsForChild)
+	ctx.Step(`^an allowance of (\d+) for the child starting "([^"]*)" that rolls over$`, tc.anAllowanceThatRollsOverExistsForChild)
+	ctx.Step(`^a "([^"]*)" allowance of (\d+) for the child starting "([^"]*)" topped up from the current account$`, tc.anAllowanceToppedUpFromTheCurrentAccountExistsForChild)
+	ctx.Step(`^I create an allowance of (\d+) for the child starting "([^"]*)" topped up from the child's own account$`, tc.iCreateAllowanceToppedUpFromTheChildsOwnAccount)
+	ctx.Step(`^allowance periods are started on "([^"]*)"$`, tc.allowancePeriodsAreStartedOn)
+	ctx.Step(`^the allowance period should run from "([^"]*)" to "([^"]*)"$`, tc.theAllowancePeriodShouldRunFromTo)
+	ctx.Step(`^the allowance carried over should be (\d+)$`, tc.theAllowanceCarriedOverShouldBe)
+	ctx.Step(`^I get the allowance periods$`, tc.iGetTheAllowancePeriods)
+	ctx.Step(`^the allowance should have (\d+) periods?$`, tc.theAllowanceShouldHaveNPeriods)
+	ctx.Step(`^the allowance period starting "([^"]*)" should have spent (\d+) and remaining (-?\d+)$`, tc.theAllowancePeriodStartingShouldHaveSpentAndRemaining)
+	ctx.Step(`^the allowance period starting "([^"]*)" should have a top-up$`, tc.theAllowancePeriodStartingShouldHaveATopUp)
+	ctx.Step(`^the allowance period starting "([^"]*)" should have no top-up$`, tc.theAllowancePeriodStartingShouldHaveNoTopUp)
+	ctx.Step(`^the child account balance should be (-?\d+)$`, tc.theChildAccountBalanceShouldBe)
 }
 
 func (tc *TestContext) iCreateAllowanceForChild(amount int64, periodStart string) error {
@@ -35,7 +49,7 @@ func (tc *TestContext) iCreateAllowanceForChild(amount int64, periodStart string
 		PeriodStart: periodStart,
 	}
 
-	allowance, err := tc.AllowanceService.Create(context.Background(), req)
+	allowance, err := tc.AllowanceService.Create(context.Background(), tc.householdID(), req)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -95,7 +109,7 @@ func (tc *TestContext) aChildUserExistsWithAccountAndCategory(email string) erro
 		Name: "Kid Spending",
 		Type: "expense",
 	}
-	category, err := tc.CategoryService.Create(context.Background(), catReq)
+	category, err := tc.CategoryService.Create(context.Background(), tc.householdID(), catReq)
 	if err != nil {
 		return fmt.Errorf("failed to create child category: %w", err)
 	}
@@ -116,7 +130,7 @@ func (tc *TestContext) anAllowanceExistsForChild(amount int64, periodStart strin
 		PeriodStart: periodStart,
 	}
 
-	allowance, err := tc.AllowanceService.Create(context.Background(), req)
+	allowance, err := tc.AllowanceService.Create(context.Background(), tc.householdID(), req)
 	if err != nil {
 		return fmt.Errorf("failed to create allowance: %w", err)
 	}
@@ -158,7 +172,7 @@ func (tc *TestContext) theChildHasTransactionOn(amount int64, date string) error
 }
 
 func (tc *TestContext) iGetAllowances() error {
-	allowances, err := tc.AllowanceService.GetAll(context.Background())
+	allowances, err := tc.AllowanceService.GetAll(context.Background(), tc.householdID())
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -210,7 +224,7 @@ func (tc *TestContext) iUpdateAllowanceAmountTo(newAmount int64) error {
 		Amount: &newAmount,
 	}
 
-	updated, err := tc.AllowanceService.Update(context.Background(), allowance.ID, req)
+	updated, err := tc.AllowanceService.Update(context.Background(), tc.householdID(), allowance.ID, req)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -235,3 +249,222 @@ func (tc *TestContext) iShouldSeeNAllowances(expected int) error {
 	}
 	return nil
 }
+
+// createChildAllowance creates an allowance for the child user and makes it
+// the current one
+func (tc *TestContext) createChildAllowance(req *model.CreateAllowanceRequest) error {
+	childUser, ok := tc.ChildUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no child user set")
+	}
+	req.UserID = childUser.ID
+
+	allowance, err := tc.AllowanceService.Create(context.Background(), tc.householdID(), req)
+	if err != nil {
+		return fmt.Errorf("failed to create allowance: %w", err)
+	}
+
+	tc.CurrentAllowance = allowance
+	return nil
+}
+
+func (tc *TestContext) anAllowanceWithFrequencyExistsForChild(frequency string, amount int64, periodStart string) error {
+	return tc.createChildAllowance(&model.CreateAllowanceRequest{
+		Amount:      amount,
+		Frequency:   frequency,
+		PeriodStart: periodStart,
+	})
+}
+
+func (tc *TestContext) anAllowanceThatRollsOverExistsForChild(amount int64, periodStart string) error {
+	return tc.createChildAllowance(&model.CreateAllowanceRequest{
+		Amount:      amount,
+		Rollover:    true,
+		PeriodStart: periodStart,
+	})
+}
+
+func (tc *TestContext) anAllowanceToppedUpFromTheCurrentAccountExistsForChild(frequency string, amount int64, periodStart string) error {
+	from, ok := tc.CurrentAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no current account")
+	}
+	to, ok := tc.ChildAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no child account set")
+	}
+
+	return tc.createChildAllowance(&model.CreateAllowanceRequest{
+		Amount:        amount,
+		Frequency:     frequency,
+		FromAccountID: &from.ID,
+		ToAccountID:   &to.ID,
+		PeriodStart:   periodStart,
+	})
+}
+
+func (tc *TestContext) iCreateAllowanceToppedUpFromTheChildsOwnAccount(amount int64, periodStart string) error {
+	childUser, ok := tc.ChildUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no child user set")
+	}
+	account, ok := tc.ChildAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no child account set")
+	}
+
+	allowance, err := tc.AllowanceService.Create(context.Background(), tc.householdID(), &model.CreateAllowanceRequest{
+		UserID:        childUser.ID,
+		Amount:        amount,
+		FromAccountID: &account.ID,
+		ToAccountID:   &account.ID,
+		PeriodStart:   periodStart,
+	})
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentAllowance = allowance
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) allowancePeriodsAreStartedOn(date string) error {
+	today, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := tc.AllowanceService.StartPeriods(context.Background(), today); err != nil {
+		return fmt.Errorf("failed to start allowance periods: %w", err)
+	}
+	return nil
+}
+
+// currentAllowance reloads the current allowance
+func (tc *TestContext) currentAllowance() (*model.Allowance, error) {
+	allowance, ok := tc.CurrentAllowance.(*model.Allowance)
+	if !ok {
+		return nil, fmt.Errorf("no current allowance")
+	}
+
+	current, err := tc.AllowanceService.GetByID(context.Background(), tc.householdID(), allowance.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload allowance: %w", err)
+	}
+
+	tc.CurrentAllowance = current
+	return current, nil
+}
+
+func (tc *TestContext) theAllowancePeriodShouldRunFromTo(start, end string) error {
+	allowance, err := tc.currentAllowance()
+	if err != nil {
+		return err
+	}
+
+	actualStart, actualEnd := allowance.PeriodStart.Format("2006-01-02"), allowance.PeriodEnd.Format("2006-01-02")
+	if actualStart != start || actualEnd != end {
+		return fmt.Errorf("expected period from %s to %s, got %s to %s", start, end, actualStart, actualEnd)
+	}
+	return nil
+}
+
+func (tc *TestContext) theAllowanceCarriedOverShouldBe(expected int64) error {
+	allowance, err := tc.currentAllowance()
+	if err != nil {
+		return err
+	}
+
+	if allowance.CarriedOver != expected {
+		return fmt.Errorf("expected carried over %d, got %d", expected, allowance.CarriedOver)
+	}
+	return nil
+}
+
+func (tc *TestContext) iGetTheAllowancePeriods() error {
+	allowance, ok := tc.CurrentAllowance.(*model.Allowance)
+	if !ok {
+		return fmt.Errorf("no current allowance")
+	}
+
+	periods, err := tc.AllowanceService.GetPeriods(context.Background(), tc.householdID(), allowance.ID)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.AllowancePeriods = periods
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) theAllowanceShouldHaveNPeriods(expected int) error {
+	if len(tc.AllowancePeriods) != expected {
+		return fmt.Errorf("expected %d allowance periods, got %d", expected, len(tc.AllowancePeriods))
+	}
+	return nil
+}
+
+func (tc *TestContext) allowancePeriodStarting(start string) (*model.AllowancePeriod, error) {
+	for _, p := range tc.AllowancePeriods {
+		if p.PeriodStart.Format("2006-01-02") == start {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("no allowance period starting %s", start)
+}
+
+func (tc *TestContext) theAllowancePeriodStartingShouldHaveSpentAndRemaining(start string, spent, remaining int64) error {
+	period, err := tc.allowancePeriodStarting(start)
+	if err != nil {
+		return err
+	}
+
+	if period.Spent != spent || period.Remaining != remaining {
+		return fmt.Errorf("expected spent %d and remaining %d, got %d and %d", spent, remaining, period.Spent, period.Remaining)
+	}
+	return nil
+}
+
+func (tc *TestContext) theAllowancePeriodStartingShouldHaveATopUp(start string) error {
+	period, err := tc.allowancePeriodStarting(start)
+	if err != nil {
+		return err
+	}
+
+	if period.TransactionID == nil {
+		return fmt.Errorf("expected the allowance period starting %s to have a top-up", start)
+	}
+	return nil
+}
+
+func (tc *TestContext) theAllowancePeriodStartingShouldHaveNoTopUp(start string) error {
+	period, err := tc.allowancePeriodStarting(start)
+	if err != nil {
+		return err
+	}
+
+	if period.TransactionID != nil {
+		return fmt.Errorf("expected the allowance period starting %s to have no top-up", start)
+	}
+	return nil
+}
+
+func (tc *TestContext) theChildAccountBalanceShouldBe(expected int64) error {
+	account, ok := tc.ChildAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no child account set")
+	}
+
+	current, err := tc.AccountService.GetByID(context.Background(), tc.householdID(), account.ID)
+	if err != nil {
+		return fmt.Errorf("failed to reload account: %w", err)
+	}
+
+	if current.Balance != expected {
+		return fmt.Errorf("expected balance %d, got %d", expected, current.Balance)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/asilingas/fambudg/backend/internal/audit"
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerAuditSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^I update the transaction description to "([^"]*)" in request "([^"]*)"$`, tc.iUpdateTheTransactionDescriptionInRequest)
+	ctx.Step(`^I get the audit log for "([^"]*)" entities$`, tc.iGetTheAuditLogForEntities)
+	ctx.Step(`^I get the audit log for "([^"]*)" entities (\d+) at a time$`, tc.iGetTheAuditLogForEntitiesNAtATime)
+	ctx.Step(`^I get the audit log of the transaction$`, tc.iGetTheAuditLogOfTheTransaction)
+	ctx.Step(`^I get the next page of the audit log$`, tc.iGetTheNextPageOfTheAuditLog)
+	ctx.Step(`^I try to delete the audit log$`, tc.iTryToDeleteTheAuditLog)
+	ctx.Step(`^I should see (\d+) audit events$`, tc.iShouldSeeNAuditEvents)
+	ctx.Step(`^there should be another page of audit events$`, tc.thereShouldBeAnotherPageOfAuditEvents)
+	ctx.Step(`^the latest audit event should be a "([^"]*)" of the transaction$`, tc.theLatestAuditEventShouldBeAnActionOfTheTransaction)
+	ctx.Step(`^the latest audit event should have amount (-?\d+) (before|after)$`, tc.theLatestAuditEventShouldHaveAmount)
+	ctx.Step(`^the latest audit event should have nothing (before|after)$`, tc.theLatestAuditEventShouldHaveNothing)
+	ctx.Step(`^the latest audit event should be made by me in request "([^"]*)"$`, tc.theLatestAuditEventShouldBeMadeByMeInRequest)
+	ctx.Step(`^the audit log should refuse the change$`, tc.theAuditLogShouldRefuseTheChange)
+}
+
+func (tc *TestContext) iUpdateTheTransactionDescriptionInRequest(description, requestID string) error {
+	user, ok := tc.CurrentUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no current user")
+	}
+
+	transaction, ok := tc.CurrentTransaction.(*model.Transaction)
+	if !ok {
+		return fmt.Errorf("no current transaction")
+	}
+
+	// What the auth and request ID middleware put in the context of a request
+	ctx := audit.WithRequestID(audit.WithActor(context.Background(), user.ID), requestID)
+
+	updated, err := tc.TransactionService.Update(ctx, tc.householdID(), transaction.ID, &model.UpdateTransactionRequest{
+		Description: &description,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	tc.CurrentTransaction = updated
+	return nil
+}
+
+func (tc *TestContext) getAuditLog(filters *model.AuditFilters) error {
+	page, err := tc.AuditService.GetAll(context.Background(), tc.householdID(), filters)
+	if err != nil {
+		return fmt.Errorf("failed to get audit log: %w", err)
+	}
+
+	tc.AuditFilters = filters
+	tc.AuditPage = page
+	return nil
+}
+
+func (tc *TestContext) iGetTheAuditLogForEntities(entityType string) error {
+	return tc.getAuditLog(&model.AuditFilters{EntityType: entityType})
+}
+
+func (tc *TestContext) iGetTheAuditLogForEntitiesNAtATime(entityType string, limit int) error {
+	return tc.getAuditLog(&model.AuditFilters{EntityType: entityType, Limit: limit})
+}
+
+func (tc *TestContext) iGetTheAuditLogOfTheTransaction() error {
+	transaction, ok := tc.CurrentTransaction.(*model.Transaction)
+	if !ok {
+		return fmt.Errorf("no current transaction")
+	}
+	return tc.getAuditLog(&model.AuditFilters{EntityID: transaction.ID})
+}
+
+func (tc *TestContext) iGetTheNextPageOfTheAuditLog() error {
+	if tc.AuditPage == nil || tc.AuditPage.NextCursor == "" {
+		return fmt.Errorf("no next page of the audit log")
+	}
+
+	filters := *tc.AuditFilters
+	filters.Cursor = tc.AuditPage.NextCursor
+	return tc.getAuditLog(&filters)
+}
+
+func (tc *TestContext) iTryToDeleteTheAuditLog() error {
+	_, tc.LastError = tc.Pool.Exec(context.Background(), "DELETE FROM audit_events")
+	return nil
+}
+
+func (tc *TestContext) iShouldSeeNAuditEvents(expected int) error {
+	if tc.AuditPage == nil {
+		return fmt.Errorf("no audit log loaded")
+	}
+	if len(tc.AuditPage.Events) != expected {
+		return fmt.Errorf("expected %d audit events, got %d", expected, len(tc.AuditPage.Events))
+	}
+	return nil
+}
+
+func (tc *TestContext) thereShouldBeAnotherPageOfAuditEvents() error {
+	if tc.AuditPage == nil || tc.AuditPage.NextCursor == "" {
+		return fmt.Errorf("expected another page of audit events")
+	}
+	return nil
+}
+
+func (tc *TestContext) latestAuditEvent() (*model.AuditEvent, error) {
+	if tc.AuditPage == nil || len(tc.AuditPage.Events) == 0 {
+		return nil, fmt.Errorf("no audit events")
+	}
+	// Events are listed newest first
+	return tc.AuditPage.Events[0], nil
+}
+
+func (tc *TestContext) theLatestAuditEventShouldBeAnActionOfTheTransaction(action string) error {
+	transaction, ok := tc.CurrentTransaction.(*model.Transaction)
+	if !ok {
+		return fmt.Errorf("no current transaction")
+	}
+
+	event, err := tc.latestAuditEvent()
+	if err != nil {
+		return err
+	}
+
+	if event.Action != action {
+		return fmt.Errorf("expected action %q, got %q", action, event.Action)
+	}
+	if event.EntityType != model.AuditEntityTransaction || event.EntityID != transaction.ID {
+		return fmt.Errorf("expected transaction %s, got %s %s", transaction.ID, event.EntityType, event.EntityID)
+	}
+	return nil
+}
+
+func (tc *TestContext) auditSnapshot(which string) (json.RawMessage, error) {
+	event, err := tc.latestAuditEvent()
+	if err != nil {
+		return nil, err
+	}
+	if which == "before" {
+		return event.Before, nil
+	}
+	return event.After, nil
+}
+
+func (tc *TestContext) theLatestAuditEventShouldHaveAmount(expected int64, which string) error {
+	snapshot, err := tc.auditSnapshot(which)
+	if err != nil {
+		return err
+	}
+	if len(snapshot) == 0 {
+		return fmt.Errorf("expected a snapshot %s the change, got none", which)
+	}
+
+	var transaction model.Transaction
+	if err := json.Unmarshal(snapshot, &transaction); err != nil {
+		return fmt.Errorf("invalid snapshot: %w", err)
+	}
+	if transaction.Amount != expected {
+		return fmt.Errorf("expected amount %d %s the change, got %d", expected, which, transaction.Amount)
+	}
+	return nil
+}
+
+func (tc *TestContext) theLatestAuditEventShouldHaveNothing(which string) error {
+	snapshot, err := tc.auditSnapshot(which)
+	if err != nil {
+		return err
+	}
+	if len(snapshot) != 0 {
+		return fmt.Errorf("expected no snapshot %s the change, got %s", which, snapshot)
+	}
+	return nil
+}
+
+func (tc *TestContext) theLatestAuditEventShouldBeMadeByMeInRequest(requestID string) error {
+	user, ok := tc.CurrentUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no current user")
+	}
+
+	event, err := tc.latestAuditEvent()
+	if err != nil {
+		return err
+	}
+
+	if event.ActorID == nil || *event.ActorID != user.ID {
+		return fmt.Errorf("expected the change to be made by %s, got %v", user.ID, event.ActorID)
+	}
+	if event.ActorName == nil || *event.ActorName != user.Name {
+		return fmt.Errorf("expected actor name %q, got %v", user.Name, event.ActorName)
+	}
+	if event.RequestID == nil || *event.RequestID != requestID {
+		return fmt.Errorf("expected request ID %q, got %v", requestID, event.RequestID)
+	}
+	return nil
+}
+
+func (tc *TestContext) theAuditLogShouldRefuseTheChange() error {
+	if tc.LastError == nil {
+		return fmt.Errorf("expected the audit log to refuse the change")
+	}
+	if !strings.Contains(tc.LastError.Error(), "audit events cannot be changed or deleted") {
+		return fmt.Errorf("unexpected error: %v", tc.LastError)
+	}
+	return nil
+}
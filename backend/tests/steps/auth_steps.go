@@ -10,7 +10,9 @@ import (
 
 func registerAuthSteps(ctx *godog.ScenarioContext, tc *TestContext) {
 	ctx.Step(`^I register with email "([^"]*)" password "([^"]*)" and name "([^"]*)"$`, tc.iRegisterWithCredentials)
+	ctx.Step(`^I register with email "([^"]*)" password "([^"]*)" name "([^"]*)" and base currency "([^"]*)"$`, tc.iRegisterWithBaseCurrency)
 	ctx.Step(`^the registration should succeed$`, tc.theRegistrationShouldSucceed)
+	ctx.Step(`^my household's base currency should be "([^"]*)"$`, tc.myHouseholdsBaseCurrencyShouldBe)
 	ctx.Step(`^I should receive a user object with email "([^"]*)"$`, tc.iShouldReceiveUserWithEmail)
 	ctx.Step(`^a user exists with email "([^"]*)" and password "([^"]*)"$`, tc.aUserExistsWithCredentials)
 	ctx.Step(`^I login with email "([^"]*)" and password "([^"]*)"$`, tc.iLoginWithCredentials)
@@ -39,6 +41,25 @@ func (tc *TestContext) iRegisterWithCredentials(email, password, name string) er
 	return nil
 }
 
+func (tc *TestContext) iRegisterWithBaseCurrency(email, password, name, currency string) error {
+	req := &model.RegisterRequest{
+		Email:        email,
+		Password:     password,
+		Name:         name,
+		BaseCurrency: currency,
+	}
+
+	user, err := tc.AuthService.Register(context.Background(), req)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentUser = user
+	tc.LastError = nil
+	return nil
+}
+
 func (tc *TestContext) theRegistrationShouldSucceed() error {
 	if tc.LastError != nil {
 		return fmt.Errorf("expected registration to succeed, got error: %v", tc.LastError)
@@ -89,7 +110,9 @@ func (tc *TestContext) iLoginWithCredentials(email, password string) error {
 		return nil
 	}
 
+	tc.LoginResult = loginResp
 	tc.CurrentToken = loginResp.Token
+	tc.CurrentRefreshToken = loginResp.RefreshToken
 	tc.CurrentUser = &loginResp.User
 	tc.LastError = nil
 	return nil
@@ -143,3 +166,15 @@ func (tc *TestContext) iAmLoggedInAs(email string) error {
 	tc.CurrentUser = user
 	return nil
 }
+
+func (tc *TestContext) myHouseholdsBaseCurrencyShouldBe(expected string) error {
+	household, err := tc.HouseholdService.Get(context.Background(), tc.householdID())
+	if err != nil {
+		return fmt.Errorf("failed to get household: %w", err)
+	}
+
+	if household.BaseCurrency != expected {
+		return fmt.Errorf("expected base currency %s, got %s", expected, household.BaseCurrency)
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/cucumber/godog"
@@ -27,6 +29,20 @@ func registerBillReminderSteps(ctx *godog.ScenarioContext, tc *TestContext) {
 	ctx.Step(`^the bill next due date should be advanced$`, tc.theBillNextDueDateShouldBeAdvanced)
 	ctx.Step(`^I get upcoming bill reminders for the next (\d+) days$`, tc.iGetUpcomingBillReminders)
 	ctx.Step(`^I should see (\d+) upcoming bill reminder$`, tc.iShouldSeeNUpcomingBillReminders)
+	ctx.Step(`^a bill reminder "([^"]*)" of (\d+) due on "([^"]*)" with (\d+) lead days exists$`, tc.aBillReminderDueOnWithLeadDaysExists)
+	ctx.Step(`^the bill reminder is sent only to "([^"]*)"$`, tc.theBillReminderIsSentOnlyTo)
+	ctx.Step(`^bill notices are sent on "([^"]*)"$`, tc.billNoticesAreSentOn)
+	ctx.Step(`^I pay the bill reminder on "([^"]*)" with amount (\d+)$`, tc.iPayTheBillReminderWithAmount)
+	ctx.Step(`^I pay the bill reminder twice at once on "([^"]*)"$`, tc.iPayTheBillReminderTwiceAtOnce)
+	ctx.Step(`^I skip the bill reminder$`, tc.iSkipTheBillReminder)
+	ctx.Step(`^I defer the bill reminder to "([^"]*)"$`, tc.iDeferTheBillReminderTo)
+	ctx.Step(`^the bill next due date should be "([^"]*)"$`, tc.theBillNextDueDateShouldBe)
+	ctx.Step(`^I get the bill reminder history$`, tc.iGetTheBillReminderHistory)
+	ctx.Step(`^the bill history should have (\d+) periods?$`, tc.theBillHistoryShouldHaveNPeriods)
+	ctx.Step(`^the bill history period "([^"]*)" should be "([^"]*)"$`, tc.theBillHistoryPeriodShouldBe)
+	ctx.Step(`^the bill history period "([^"]*)" should be paid late$`, tc.theBillHistoryPeriodShouldBePaidLate)
+	ctx.Step(`^the bill history should have average (\d+), last paid (\d+) and forecast (\d+)$`, tc.theBillHistoryShouldHaveAverageLastPaidAndForecast)
+	ctx.Step(`^the bill history should forecast (\d+) with no payments$`, tc.theBillHistoryShouldForecastWithNoPayments)
 }
 
 func (tc *TestContext) iCreateBillReminderWith(table *godog.Table) error {
@@ -46,7 +62,7 @@ func (tc *TestContext) iCreateBillReminderWith(table *godog.Table) error {
 		NextDueDate: data["nextDueDate"],
 	}
 
-	bill, err := tc.BillReminderService.Create(context.Background(), req)
+	bill, err := tc.BillReminderService.Create(context.Background(), tc.householdID(), req)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -92,7 +108,7 @@ func (tc *TestContext) theFollowingBillRemindersExist(table *godog.Table) error
 			NextDueDate: row.Cells[4].Value,
 		}
 
-		_, err := tc.BillReminderService.Create(context.Background(), req)
+		_, err := tc.BillReminderService.Create(context.Background(), tc.householdID(), req)
 		if err != nil {
 			return fmt.Errorf("failed to create bill reminder: %w", err)
 		}
@@ -102,7 +118,7 @@ func (tc *TestContext) theFollowingBillRemindersExist(table *godog.Table) error
 }
 
 func (tc *TestContext) iListBillReminders() error {
-	bills, err := tc.BillReminderService.GetAll(context.Background())
+	bills, err := tc.BillReminderService.GetAll(context.Background(), tc.householdID())
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -134,7 +150,7 @@ func (tc *TestContext) aBillReminderExistsWithAmount(name string, amount int64)
 		NextDueDate: "2026-03-15",
 	}
 
-	bill, err := tc.BillReminderService.Create(context.Background(), req)
+	bill, err := tc.BillReminderService.Create(context.Background(), tc.householdID(), req)
 	if err != nil {
 		return fmt.Errorf("failed to create bill reminder: %w", err)
 	}
@@ -153,7 +169,7 @@ func (tc *TestContext) iUpdateBillReminderWithAmount(newAmount int64) error {
 		Amount: &newAmount,
 	}
 
-	updated, err := tc.BillReminderService.Update(context.Background(), bill.ID, req)
+	updated, err := tc.BillReminderService.Update(context.Background(), tc.householdID(), bill.ID, req)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -177,7 +193,7 @@ func (tc *TestContext) iDeleteTheBillReminder() error {
 		return fmt.Errorf("no current bill reminder")
 	}
 
-	err := tc.BillReminderService.Delete(context.Background(), bill.ID)
+	err := tc.BillReminderService.Delete(context.Background(), tc.householdID(), bill.ID)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -215,7 +231,7 @@ func (tc *TestContext) aBillReminderExistsWithAmountAndAccount(name string, amou
 		NextDueDate: "2026-03-15",
 	}
 
-	bill, err := tc.BillReminderService.Create(context.Background(), req)
+	bill, err := tc.BillReminderService.Create(context.Background(), tc.householdID(), req)
 	if err != nil {
 		return fmt.Errorf("failed to create bill reminder: %w", err)
 	}
@@ -225,6 +241,14 @@ func (tc *TestContext) aBillReminderExistsWithAmountAndAccount(name string, amou
 }
 
 func (tc *TestContext) iPayTheBillReminder(date string) error {
+	return tc.payBillReminder(date, nil)
+}
+
+func (tc *TestContext) iPayTheBillReminderWithAmount(date string, amount int64) error {
+	return tc.payBillReminder(date, &amount)
+}
+
+func (tc *TestContext) payBillReminder(date string, amount *int64) error {
 	user, ok := tc.CurrentUser.(*model.User)
 	if !ok {
 		return fmt.Errorf("no current user")
@@ -243,9 +267,10 @@ func (tc *TestContext) iPayTheBillReminder(date string) error {
 	req := &model.PayBillRequest{
 		AccountID: account.ID,
 		Date:      date,
+		Amount:    amount,
 	}
 
-	transaction, err := tc.BillReminderService.Pay(context.Background(), user.ID, bill.ID, req)
+	transaction, err := tc.BillReminderService.Pay(context.Background(), tc.householdID(), user.ID, bill.ID, req)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -256,6 +281,42 @@ func (tc *TestContext) iPayTheBillReminder(date string) error {
 	return nil
 }
 
+func (tc *TestContext) iPayTheBillReminderTwiceAtOnce(date string) error {
+	user, ok := tc.CurrentUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no current user")
+	}
+	bill, ok := tc.CurrentBillReminder.(*model.BillReminder)
+	if !ok {
+		return fmt.Errorf("no current bill reminder")
+	}
+	account, ok := tc.CurrentAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no current account")
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = tc.BillReminderService.Pay(context.Background(), tc.householdID(), user.ID, bill.ID, &model.PayBillRequest{
+				AccountID: account.ID,
+				Date:      date,
+			})
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return fmt.Errorf("failed to pay bill: %w", err)
+		}
+	}
+	return nil
+}
+
 func (tc *TestContext) theBillPaymentShouldCreateTransaction() error {
 	if tc.LastError != nil {
 		return fmt.Errorf("expected bill payment to succeed, got error: %v", tc.LastError)
@@ -273,7 +334,7 @@ func (tc *TestContext) theBillNextDueDateShouldBeAdvanced() error {
 	}
 
 	// Re-fetch the bill to get updated next_due_date
-	updated, err := tc.BillReminderService.GetByID(context.Background(), bill.ID)
+	updated, err := tc.BillReminderService.GetByID(context.Background(), tc.householdID(), bill.ID)
 	if err != nil {
 		return fmt.Errorf("failed to fetch updated bill: %w", err)
 	}
@@ -286,7 +347,7 @@ func (tc *TestContext) theBillNextDueDateShouldBeAdvanced() error {
 }
 
 func (tc *TestContext) iGetUpcomingBillReminders(days int) error {
-	bills, err := tc.BillReminderService.GetUpcoming(context.Background(), days)
+	bills, err := tc.BillReminderService.GetUpcoming(context.Background(), tc.householdID(), days)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -308,3 +369,210 @@ func (tc *TestContext) iShouldSeeNUpcomingBillReminders(expectedCount int) error
 	}
 	return nil
 }
+
+func (tc *TestContext) aBillReminderDueOnWithLeadDaysExists(name string, amount int64, dueDate string, leadDays int) error {
+	due, err := time.Parse("2006-01-02", dueDate)
+	if err != nil {
+		return err
+	}
+
+	req := &model.CreateBillReminderRequest{
+		Name:        name,
+		Amount:      amount,
+		DueDay:      due.Day(),
+		Frequency:   "monthly",
+		NextDueDate: dueDate,
+		LeadDays:    &leadDays,
+	}
+
+	bill, err := tc.BillReminderService.Create(context.Background(), tc.householdID(), req)
+	if err != nil {
+		return fmt.Errorf("failed to create bill reminder: %w", err)
+	}
+
+	tc.CurrentBillReminder = bill
+	return nil
+}
+
+func (tc *TestContext) theBillReminderIsSentOnlyTo(email string) error {
+	bill, ok := tc.CurrentBillReminder.(*model.BillReminder)
+	if !ok {
+		return fmt.Errorf("no current bill reminder")
+	}
+
+	user, err := tc.UserRepo.FindByEmail(context.Background(), email)
+	if err != nil {
+		return err
+	}
+
+	recipients := []string{user.ID}
+	updated, err := tc.BillReminderService.Update(context.Background(), tc.householdID(), bill.ID, &model.UpdateBillReminderRequest{RecipientIDs: &recipients})
+	if err != nil {
+		return fmt.Errorf("failed to set bill reminder recipients: %w", err)
+	}
+
+	tc.CurrentBillReminder = updated
+	return nil
+}
+
+func (tc *TestContext) billNoticesAreSentOn(date string) error {
+	today, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return err
+	}
+
+	_, err = tc.NotificationService.SendBillNotices(context.Background(), today)
+	return err
+}
+
+func (tc *TestContext) iSkipTheBillReminder() error {
+	bill, ok := tc.CurrentBillReminder.(*model.BillReminder)
+	if !ok {
+		return fmt.Errorf("no current bill reminder")
+	}
+
+	skipped, err := tc.BillReminderService.Skip(context.Background(), tc.householdID(), bill.ID)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentBillReminder = skipped
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) iDeferTheBillReminderTo(date string) error {
+	bill, ok := tc.CurrentBillReminder.(*model.BillReminder)
+	if !ok {
+		return fmt.Errorf("no current bill reminder")
+	}
+
+	deferred, err := tc.BillReminderService.Defer(context.Background(), tc.householdID(), bill.ID, &model.DeferBillRequest{Date: date})
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentBillReminder = deferred
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) theBillNextDueDateShouldBe(expected string) error {
+	bill, ok := tc.CurrentBillReminder.(*model.BillReminder)
+	if !ok {
+		return fmt.Errorf("no current bill reminder")
+	}
+
+	current, err := tc.BillReminderService.GetByID(context.Background(), tc.householdID(), bill.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch bill reminder: %w", err)
+	}
+
+	if got := current.NextDueDate.Format("2006-01-02"); got != expected {
+		return fmt.Errorf("expected next due date %s, got %s", expected, got)
+	}
+	return nil
+}
+
+func (tc *TestContext) iGetTheBillReminderHistory() error {
+	bill, ok := tc.CurrentBillReminder.(*model.BillReminder)
+	if !ok {
+		return fmt.Errorf("no current bill reminder")
+	}
+
+	history, err := tc.BillReminderService.GetHistory(context.Background(), tc.householdID(), bill.ID)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.BillHistory = history
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) theBillHistoryShouldHaveNPeriods(expected int) error {
+	if tc.BillHistory == nil {
+		return fmt.Errorf("no bill history")
+	}
+
+	if len(tc.BillHistory.Payments) != expected {
+		return fmt.Errorf("expected %d periods, got %d", expected, len(tc.BillHistory.Payments))
+	}
+	return nil
+}
+
+func (tc *TestContext) billHistoryPeriod(periodDate string) (*model.BillPayment, error) {
+	if tc.BillHistory == nil {
+		return nil, fmt.Errorf("no bill history")
+	}
+
+	for _, p := range tc.BillHistory.Payments {
+		if p.PeriodDate.Format("2006-01-02") == periodDate {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("no period %s in the bill history", periodDate)
+}
+
+func (tc *TestContext) theBillHistoryPeriodShouldBe(periodDate, status string) error {
+	p, err := tc.billHistoryPeriod(periodDate)
+	if err != nil {
+		return err
+	}
+
+	if p.Status != status {
+		return fmt.Errorf("expected period %s to be %s, got %s", periodDate, status, p.Status)
+	}
+	if p.IsLate {
+		return fmt.Errorf("expected period %s not to be late", periodDate)
+	}
+	return nil
+}
+
+func (tc *TestContext) theBillHistoryPeriodShouldBePaidLate(periodDate string) error {
+	p, err := tc.billHistoryPeriod(periodDate)
+	if err != nil {
+		return err
+	}
+
+	if p.Status != model.BillPaymentPaid || !p.IsLate {
+		return fmt.Errorf("expected period %s to be paid late, got %s (late: %v)", periodDate, p.Status, p.IsLate)
+	}
+	return nil
+}
+
+func (tc *TestContext) theBillHistoryShouldHaveAverageLastPaidAndForecast(average, last, forecast int64) error {
+	h := tc.BillHistory
+	if h == nil {
+		return fmt.Errorf("no bill history")
+	}
+
+	if h.AverageAmount == nil || *h.AverageAmount != average {
+		return fmt.Errorf("expected average %d, got %v", average, h.AverageAmount)
+	}
+	if h.LastPaidAmount == nil || *h.LastPaidAmount != last {
+		return fmt.Errorf("expected last paid %d, got %v", last, h.LastPaidAmount)
+	}
+	if h.ForecastAmount != forecast {
+		return fmt.Errorf("expected forecast %d, got %d", forecast, h.ForecastAmount)
+	}
+	return nil
+}
+
+func (tc *TestContext) theBillHistoryShouldForecastWithNoPayments(forecast int64) error {
+	h := tc.BillHistory
+	if h == nil {
+		return fmt.Errorf("no bill history")
+	}
+
+	if h.AverageAmount != nil || h.LastPaidAmount != nil {
+		return fmt.Errorf("expected no paid amounts, got average %v and last paid %v", h.AverageAmount, h.LastPaidAmount)
+	}
+	if h.ForecastAmount != forecast {
+		return fmt.Errorf("expected forecast %d, got %d", forecast, h.ForecastAmount)
+	}
+	return nil
+}
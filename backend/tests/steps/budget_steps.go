@@ -24,6 +24,9 @@ func registerBudgetSteps(ctx *godog.ScenarioContext, tc *TestContext) {
 	ctx.Step(`^I get the budget summary for month (\d+) and year (\d+)$`, tc.iGetBudgetSummary)
 	ctx.Step(`^I should see (\d+) budget summaries$`, tc.iShouldSeeNBudgetSummaries)
 	ctx.Step(`^the budget summary for "([^"]*)" should have budget (\d+) and actual (\d+)$`, tc.theBudgetSummaryShouldHave)
+	ctx.Step(`^I set the budget rollover to "([^"]*)"$`, tc.iSetTheBudgetRolloverTo)
+	ctx.Step(`^the budget should roll over "([^"]*)"$`, tc.theBudgetShouldRollOver)
+	ctx.Step(`^the budget summary for "([^"]*)" should have carried in (-?\d+), effective amount (-?\d+) and remaining (-?\d+)$`, tc.theBudgetSummaryShouldHaveCarriedIn)
 }
 
 func (tc *TestContext) iCreateBudgetWith(table *godog.Table) error {
@@ -48,7 +51,7 @@ func (tc *TestContext) iCreateBudgetWith(table *godog.Table) error {
 		Year:       year,
 	}
 
-	budget, err := tc.BudgetService.Create(context.Background(), req)
+	budget, err := tc.BudgetService.Create(context.Background(), tc.householdID(), req)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -98,8 +101,12 @@ func (tc *TestContext) theFollowingBudgetsExist(table *godog.Table) error {
 			Month:      month,
 			Year:       year,
 		}
+		// Optional fourth column
+		if len(row.Cells) > 3 {
+			req.Rollover = row.Cells[3].Value
+		}
 
-		_, err := tc.BudgetService.Create(context.Background(), req)
+		_, err := tc.BudgetService.Create(context.Background(), tc.householdID(), req)
 		if err != nil {
 			return fmt.Errorf("failed to create budget: %w", err)
 		}
@@ -114,7 +121,7 @@ func (tc *TestContext) iListBudgetsForMonthAndYear(month, year int) error {
 		Year:  year,
 	}
 
-	budgets, err := tc.BudgetService.GetAll(context.Background(), filters)
+	budgets, err := tc.BudgetService.GetAll(context.Background(), tc.householdID(), filters)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -150,7 +157,7 @@ func (tc *TestContext) aBudgetExistsWithAmount(amount int64, month, year int) er
 		Year:       year,
 	}
 
-	budget, err := tc.BudgetService.Create(context.Background(), req)
+	budget, err := tc.BudgetService.Create(context.Background(), tc.householdID(), req)
 	if err != nil {
 		return fmt.Errorf("failed to create budget: %w", err)
 	}
@@ -169,7 +176,7 @@ func (tc *TestContext) iUpdateBudgetWithAmount(newAmount int64) error {
 		Amount: &newAmount,
 	}
 
-	updated, err := tc.BudgetService.Update(context.Background(), budget.ID, req)
+	updated, err := tc.BudgetService.Update(context.Background(), tc.householdID(), budget.ID, req)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -193,7 +200,7 @@ func (tc *TestContext) iDeleteTheBudget() error {
 		return fmt.Errorf("no current budget")
 	}
 
-	err := tc.BudgetService.Delete(context.Background(), budget.ID)
+	err := tc.BudgetService.Delete(context.Background(), tc.householdID(), budget.ID)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -211,7 +218,7 @@ func (tc *TestContext) theBudgetShouldBeDeleted() error {
 }
 
 func (tc *TestContext) iGetBudgetSummary(month, year int) error {
-	summaries, err := tc.BudgetService.GetSummary(context.Background(), month, year)
+	summaries, err := tc.BudgetService.GetSummary(context.Background(), tc.householdID(), month, year)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -252,3 +259,54 @@ func (tc *TestContext) theBudgetSummaryShouldHave(categoryName string, expectedB
 	}
 	return fmt.Errorf("budget summary for %q not found", categoryName)
 }
+
+func (tc *TestContext) iSetTheBudgetRolloverTo(rollover string) error {
+	budget, ok := tc.CurrentBudget.(*model.Budget)
+	if !ok {
+		return fmt.Errorf("no current budget")
+	}
+
+	updated, err := tc.BudgetService.Update(context.Background(), tc.householdID(), budget.ID, &model.UpdateBudgetRequest{
+		Rollover: &rollover,
+	})
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentBudget = updated
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) theBudgetShouldRollOver(expected string) error {
+	budget, ok := tc.CurrentBudget.(*model.Budget)
+	if !ok {
+		return fmt.Errorf("no current budget")
+	}
+
+	if budget.Rollover != expected {
+		return fmt.Errorf("expected rollover %q, got %q", expected, budget.Rollover)
+	}
+	return nil
+}
+
+func (tc *TestContext) theBudgetSummaryShouldHaveCarriedIn(categoryName string, expectedCarriedIn, expectedEffective, expectedRemaining int64) error {
+	for _, item := range tc.BudgetSummaryList {
+		summary, ok := item.(*model.BudgetSummary)
+		if !ok || summary.CategoryName != categoryName {
+			continue
+		}
+		if summary.CarriedIn != expectedCarriedIn {
+			return fmt.Errorf("expected carried in %d for %q, got %d", expectedCarriedIn, categoryName, summary.CarriedIn)
+		}
+		if summary.EffectiveAmount != expectedEffective {
+			return fmt.Errorf("expected effective amount %d for %q, got %d", expectedEffective, categoryName, summary.EffectiveAmount)
+		}
+		if summary.Remaining != expectedRemaining {
+			return fmt.Errorf("expected remaining %d for %q, got %d", expectedRemaining, categoryName, summary.Remaining)
+		}
+		return nil
+	}
+	return fmt.Errorf("budget summary for %q not found", categoryName)
+}
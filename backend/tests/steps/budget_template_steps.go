@@ -0,0 +1,125 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerBudgetTemplateSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^a budget template exists with amount (\d+) from month (\d+) of (\d+)$`, tc.aBudgetTemplateExistsWithAmount)
+	ctx.Step(`^a budget template exists with amount (\d+) from month (\d+) of (\d+) to month (\d+) of (\d+)$`, tc.aBudgetTemplateExistsWithAmountUntil)
+	ctx.Step(`^a budget template exists with amount (\d+) from month (\d+) of (\d+) rolling over "([^"]*)"$`, tc.aBudgetTemplateExistsWithAmountRollingOver)
+	ctx.Step(`^a budget template exists with annual amount (\d+) from month (\d+) of (\d+)$`, tc.aBudgetTemplateExistsWithAnnualAmount)
+	ctx.Step(`^I try to create a budget template with amount (\d+) and annual amount (\d+)$`, tc.iTryToCreateABudgetTemplateWithBothAmounts)
+	ctx.Step(`^I delete the budget template$`, tc.iDeleteTheBudgetTemplate)
+	ctx.Step(`^(\d+) budgets should be stored$`, tc.nBudgetsShouldBeStored)
+	ctx.Step(`^I copy last month's budgets into month (\d+) of (\d+)$`, tc.iCopyLastMonthsBudgetsInto)
+	ctx.Step(`^(\d+) budgets should have been copied$`, tc.nBudgetsShouldHaveBeenCopied)
+}
+
+func (tc *TestContext) createBudgetTemplate(req *model.CreateBudgetTemplateRequest) error {
+	category, ok := tc.CurrentCategory.(*model.Category)
+	if !ok {
+		return fmt.Errorf("no current category")
+	}
+	req.CategoryID = category.ID
+
+	template, err := tc.BudgetTemplateService.Create(context.Background(), tc.householdID(), req)
+	if err != nil {
+		return fmt.Errorf("failed to create budget template: %w", err)
+	}
+
+	tc.CurrentBudgetTemplate = template
+	return nil
+}
+
+func (tc *TestContext) aBudgetTemplateExistsWithAmount(amount int64, month, year int) error {
+	return tc.createBudgetTemplate(&model.CreateBudgetTemplateRequest{
+		Amount:     &amount,
+		StartMonth: month,
+		StartYear:  year,
+	})
+}
+
+func (tc *TestContext) aBudgetTemplateExistsWithAmountUntil(amount int64, month, year, endMonth, endYear int) error {
+	return tc.createBudgetTemplate(&model.CreateBudgetTemplateRequest{
+		Amount:     &amount,
+		StartMonth: month,
+		StartYear:  year,
+		EndMonth:   &endMonth,
+		EndYear:    &endYear,
+	})
+}
+
+func (tc *TestContext) aBudgetTemplateExistsWithAmountRollingOver(amount int64, month, year int, rollover string) error {
+	return tc.createBudgetTemplate(&model.CreateBudgetTemplateRequest{
+		Amount:     &amount,
+		StartMonth: month,
+		StartYear:  year,
+		Rollover:   rollover,
+	})
+}
+
+func (tc *TestContext) aBudgetTemplateExistsWithAnnualAmount(annualAmount int64, month, year int) error {
+	return tc.createBudgetTemplate(&model.CreateBudgetTemplateRequest{
+		AnnualAmount: &annualAmount,
+		StartMonth:   month,
+		StartYear:    year,
+	})
+}
+
+func (tc *TestContext) iTryToCreateABudgetTemplateWithBothAmounts(amount, annualAmount int64) error {
+	category, ok := tc.CurrentCategory.(*model.Category)
+	if !ok {
+		return fmt.Errorf("no current category")
+	}
+
+	_, tc.LastError = tc.BudgetTemplateService.Create(context.Background(), tc.householdID(), &model.CreateBudgetTemplateRequest{
+		CategoryID:   category.ID,
+		Amount:       &amount,
+		AnnualAmount: &annualAmount,
+		StartMonth:   1,
+		StartYear:    2026,
+	})
+	return nil
+}
+
+func (tc *TestContext) iDeleteTheBudgetTemplate() error {
+	if tc.CurrentBudgetTemplate == nil {
+		return fmt.Errorf("no current budget template")
+	}
+
+	return tc.BudgetTemplateService.Delete(context.Background(), tc.householdID(), tc.CurrentBudgetTemplate.ID)
+}
+
+func (tc *TestContext) nBudgetsShouldBeStored(expected int) error {
+	// Without a month the list holds only the budgets stored so far
+	budgets, err := tc.BudgetService.GetAll(context.Background(), tc.householdID(), &model.BudgetFilters{})
+	if err != nil {
+		return fmt.Errorf("failed to list budgets: %w", err)
+	}
+	if len(budgets) != expected {
+		return fmt.Errorf("expected %d stored budgets, got %d", expected, len(budgets))
+	}
+	return nil
+}
+
+func (tc *TestContext) iCopyLastMonthsBudgetsInto(month, year int) error {
+	copied, err := tc.BudgetService.CopyFromPreviousMonth(context.Background(), tc.householdID(), month, year)
+	if err != nil {
+		return fmt.Errorf("failed to copy budgets: %w", err)
+	}
+
+	tc.CopiedBudgets = copied
+	return nil
+}
+
+func (tc *TestContext) nBudgetsShouldHaveBeenCopied(expected int) error {
+	if len(tc.CopiedBudgets) != expected {
+		return fmt.Errorf("expected %d budgets copied, got %d", expected, len(tc.CopiedBudgets))
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerCategorizationRuleSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^a categorization rule for category "([^"]*)" exists with:$`, tc.aCategorizationRuleForCategoryExistsWith)
+	ctx.Step(`^I create a categorization rule with:$`, tc.iCreateACategorizationRuleWith)
+	ctx.Step(`^I create an uncategorized transaction with:$`, tc.iCreateAnUncategorizedTransactionWith)
+	ctx.Step(`^I apply the categorization rules$`, tc.iApplyTheCategorizationRules)
+	ctx.Step(`^(\d+) transactions should be categorized$`, tc.nTransactionsShouldBeCategorized)
+	ctx.Step(`^the transaction should be in category "([^"]*)"$`, tc.theTransactionShouldBeInCategory)
+	ctx.Step(`^the transaction should be uncategorized$`, tc.theTransactionShouldBeUncategorized)
+	ctx.Step(`^the transaction should have tag "([^"]*)"$`, tc.theTransactionShouldHaveTag)
+	ctx.Step(`^the transaction should not be shared$`, tc.theTransactionShouldNotBeShared)
+	ctx.Step(`^the transaction "([^"]*)" should be in category "([^"]*)"$`, tc.theTransactionDescribedShouldBeInCategory)
+}
+
+func (tc *TestContext) categoryByName(name string) (*model.Category, error) {
+	categories, err := tc.CategoryService.GetAll(context.Background(), tc.householdID())
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range categories {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("category %q not found", name)
+}
+
+func (tc *TestContext) createCategorizationRule(doc *godog.DocString, categoryID *string) error {
+	var req model.CreateCategorizationRuleRequest
+	if err := json.Unmarshal([]byte(doc.Content), &req); err != nil {
+		return fmt.Errorf("invalid categorization rule JSON: %w", err)
+	}
+	if categoryID != nil {
+		req.CategoryID = categoryID
+	}
+
+	_, err := tc.CategorizationRuleService.Create(context.Background(), tc.householdID(), &req)
+	tc.LastError = err
+	return nil
+}
+
+func (tc *TestContext) aCategorizationRuleForCategoryExistsWith(categoryName string, doc *godog.DocString) error {
+	category, err := tc.categoryByName(categoryName)
+	if err != nil {
+		return err
+	}
+
+	if err := tc.createCategorizationRule(doc, &category.ID); err != nil {
+		return err
+	}
+	if tc.LastError != nil {
+		return fmt.Errorf("failed to create categorization rule: %w", tc.LastError)
+	}
+	return nil
+}
+
+func (tc *TestContext) iCreateACategorizationRuleWith(doc *godog.DocString) error {
+	return tc.createCategorizationRule(doc, nil)
+}
+
+func (tc *TestContext) iCreateAnUncategorizedTransactionWith(table *godog.Table) error {
+	user, ok := tc.CurrentUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no current user")
+	}
+
+	account, ok := tc.CurrentAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no current account")
+	}
+
+	data := make(map[string]string)
+	for _, row := range table.Rows[1:] { // Skip header
+		data[row.Cells[0].Value] = row.Cells[1].Value
+	}
+
+	amount, _ := strconv.ParseInt(data["amount"], 10, 64)
+
+	req := &model.CreateTransactionRequest{
+		AccountID:    account.ID,
+		Amount:       amount,
+		Type:         "expense",
+		Description:  data["description"],
+		Counterparty: data["counterparty"],
+		Date:         data["date"],
+		IsShared:     true,
+	}
+	if amount > 0 {
+		req.Type = "income"
+	}
+
+	transaction, err := tc.TransactionService.Create(context.Background(), user.ID, req)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	tc.CurrentTransaction = transaction
+	return nil
+}
+
+func (tc *TestContext) iApplyTheCategorizationRules() error {
+	result, err := tc.CategorizationRuleService.ApplyToUncategorized(context.Background(), tc.householdID())
+	if err != nil {
+		return fmt.Errorf("failed to apply categorization rules: %w", err)
+	}
+
+	tc.ApplyRulesResult = result
+	return nil
+}
+
+func (tc *TestContext) nTransactionsShouldBeCategorized(expected int) error {
+	if tc.ApplyRulesResult == nil {
+		return fmt.Errorf("categorization rules were not applied")
+	}
+	if tc.ApplyRulesResult.Categorized != expected {
+		return fmt.Errorf("expected %d categorized transactions, got %d", expected, tc.ApplyRulesResult.Categorized)
+	}
+	return nil
+}
+
+// reloadCurrentTransaction reads the current transaction back, picking up
+// changes made by applying rules
+func (tc *TestContext) reloadCurrentTransaction() (*model.Transaction, error) {
+	transaction, ok := tc.CurrentTransaction.(*model.Transaction)
+	if !ok {
+		return nil, fmt.Errorf("no current transaction")
+	}
+	return tc.TransactionService.GetByID(context.Background(), tc.householdID(), transaction.ID)
+}
+
+func expectCategory(t *model.Transaction, category *model.Category) error {
+	if t.CategoryID == nil {
+		return fmt.Errorf("expected category %q, transaction %q is uncategorized", category.Name, t.Description)
+	}
+	if *t.CategoryID != category.ID {
+		return fmt.Errorf("expected category %q (%s), got %s", category.Name, category.ID, *t.CategoryID)
+	}
+	return nil
+}
+
+func (tc *TestContext) theTransactionShouldBeInCategory(categoryName string) error {
+	category, err := tc.categoryByName(categoryName)
+	if err != nil {
+		return err
+	}
+
+	transaction, err := tc.reloadCurrentTransaction()
+	if err != nil {
+		return err
+	}
+
+	return expectCategory(transaction, category)
+}
+
+func (tc *TestContext) theTransactionShouldBeUncategorized() error {
+	transaction, err := tc.reloadCurrentTransaction()
+	if err != nil {
+		return err
+	}
+
+	if transaction.CategoryID != nil {
+		return fmt.Errorf("expected no category, got %s", *transaction.CategoryID)
+	}
+	return nil
+}
+
+func (tc *TestContext) theTransactionShouldHaveTag(tag string) error {
+	transaction, err := tc.reloadCurrentTransaction()
+	if err != nil {
+		return err
+	}
+
+	for _, t := range transaction.Tags {
+		if t == tag {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected tag %q, got %v", tag, transaction.Tags)
+}
+
+func (tc *TestContext) theTransactionShouldNotBeShared() error {
+	transaction, err := tc.reloadCurrentTransaction()
+	if err != nil {
+		return err
+	}
+
+	if transaction.IsShared {
+		return fmt.Errorf("expected transaction not to be shared")
+	}
+	return nil
+}
+
+func (tc *TestContext) theTransactionDescribedShouldBeInCategory(description, categoryName string) error {
+	category, err := tc.categoryByName(categoryName)
+	if err != nil {
+		return err
+	}
+
+	page, err := tc.TransactionService.GetAll(context.Background(), tc.householdID(), &model.TransactionFilters{})
+	if err != nil {
+		return err
+	}
+
+	for _, t := range page.Transactions {
+		if t.Description == description {
+			return expectCategory(t, category)
+		}
+	}
+	return fmt.Errorf("no transaction with description %q", description)
+}
@@ -0,0 +1,110 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerCategoryTreeSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^a subcategory "([^"]*)" of the category exists$`, tc.aSubcategoryOfTheCategoryExists)
+	ctx.Step(`^the current category is "([^"]*)"$`, tc.theCurrentCategoryIs)
+	ctx.Step(`^I get the category tree for month (\d+) and year (\d+)$`, tc.iGetTheCategoryTree)
+	ctx.Step(`^I get the category tree for month (\d+) and year (\d+) down to depth (\d+)$`, tc.iGetTheCategoryTreeDownToDepth)
+	ctx.Step(`^the category tree should have (\d+) top-level categories$`, tc.theCategoryTreeShouldHaveNTopLevelCategories)
+	ctx.Step(`^the category tree entry "([^"]*)" should have own amount (\d+) and subtotal (\d+)$`, tc.theCategoryTreeEntryShouldHave)
+	ctx.Step(`^the category tree entry "([^"]*)" should have (\d+) subcategories$`, tc.theCategoryTreeEntryShouldHaveNSubcategories)
+}
+
+// aSubcategoryOfTheCategoryExists creates a category under the current one
+// and makes it the current category
+func (tc *TestContext) aSubcategoryOfTheCategoryExists(name string) error {
+	parent, ok := tc.CurrentCategory.(*model.Category)
+	if !ok {
+		return fmt.Errorf("no current category")
+	}
+
+	category, err := tc.CategoryService.Create(context.Background(), tc.householdID(), &model.CreateCategoryRequest{
+		ParentID: &parent.ID,
+		Name:     name,
+		Type:     parent.Type,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create subcategory: %w", err)
+	}
+
+	tc.CurrentCategory = category
+	return nil
+}
+
+func (tc *TestContext) theCurrentCategoryIs(name string) error {
+	category, err := tc.categoryByName(name)
+	if err != nil {
+		return err
+	}
+
+	tc.CurrentCategory = category
+	return nil
+}
+
+func (tc *TestContext) iGetTheCategoryTree(month, year int) error {
+	return tc.iGetTheCategoryTreeDownToDepth(month, year, 0)
+}
+
+func (tc *TestContext) iGetTheCategoryTreeDownToDepth(month, year, depth int) error {
+	tree, err := tc.ReportService.GetSpendingTree(context.Background(), tc.householdID(), nil, month, year, depth)
+	if err != nil {
+		return fmt.Errorf("failed to get category tree: %w", err)
+	}
+
+	tc.CategoryTree = tree
+	return nil
+}
+
+func (tc *TestContext) theCategoryTreeShouldHaveNTopLevelCategories(expected int) error {
+	if len(tc.CategoryTree) != expected {
+		return fmt.Errorf("expected %d top-level categories, got %d", expected, len(tc.CategoryTree))
+	}
+	return nil
+}
+
+func findCategoryTreeEntry(nodes []*model.CategorySpendingNode, name string) *model.CategorySpendingNode {
+	for _, n := range nodes {
+		if n.CategoryName == name {
+			return n
+		}
+		if found := findCategoryTreeEntry(n.Children, name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func (tc *TestContext) theCategoryTreeEntryShouldHave(name string, expectedOwn, expectedTotal int64) error {
+	entry := findCategoryTreeEntry(tc.CategoryTree, name)
+	if entry == nil {
+		return fmt.Errorf("category %q not in the tree", name)
+	}
+
+	if entry.OwnAmount != expectedOwn {
+		return fmt.Errorf("expected own amount %d for %q, got %d", expectedOwn, name, entry.OwnAmount)
+	}
+	if entry.TotalAmount != expectedTotal {
+		return fmt.Errorf("expected subtotal %d for %q, got %d", expectedTotal, name, entry.TotalAmount)
+	}
+	return nil
+}
+
+func (tc *TestContext) theCategoryTreeEntryShouldHaveNSubcategories(name string, expected int) error {
+	entry := findCategoryTreeEntry(tc.CategoryTree, name)
+	if entry == nil {
+		return fmt.Errorf("category %q not in the tree", name)
+	}
+
+	if len(entry.Children) != expected {
+		return fmt.Errorf("expected %d subcategories under %q, got %d", expected, name, len(entry.Children))
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerChoreSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^a "([^"]*)" chore "([^"]*)" with a reward of (\d+) exists$`, tc.aChoreExists)
+	ctx.Step(`^a "([^"]*)" chore "([^"]*)" with a reward of (\d+) assigned to the child exists$`, tc.aChoreAssignedToTheChildExists)
+	ctx.Step(`^I assign the chore to "([^"]*)"$`, tc.iAssignTheChoreTo)
+	ctx.Step(`^the chore is retired$`, tc.theChoreIsRetired)
+	ctx.Step(`^the child marks the chore done on "([^"]*)"$`, tc.theChildMarksTheChoreDoneOn)
+	ctx.Step(`^the child marks the chore done for "([^"]*)" on "([^"]*)"$`, tc.theChildMarksTheChoreDoneForOn)
+	ctx.Step(`^"([^"]*)" marks the chore done on "([^"]*)"$`, tc.userMarksTheChoreDoneOn)
+	ctx.Step(`^the chore should be waiting for approval$`, tc.theChoreShouldBeWaitingForApproval)
+	ctx.Step(`^I approve the chore$`, tc.iApproveTheChore)
+	ctx.Step(`^I approve the chore into the child's account$`, tc.iApproveTheChoreIntoTheChildsAccount)
+	ctx.Step(`^I approve the chore into the current account$`, tc.iApproveTheChoreIntoTheCurrentAccount)
+	ctx.Step(`^I reject the chore$`, tc.iRejectTheChore)
+	ctx.Step(`^the chore should be "([^"]*)"$`, tc.theChoreShouldBe)
+	ctx.Step(`^I get chore earnings from "([^"]*)" to "([^"]*)"$`, tc.iGetChoreEarningsFromTo)
+	ctx.Step(`^the child should have earned (\d+) for (\d+) chores? with (\d+) pending$`, tc.theChildShouldHaveEarned)
+}
+
+func (tc *TestContext) createChore(req *model.CreateChoreRequest) error {
+	chore, err := tc.ChoreService.Create(context.Background(), tc.householdID(), req)
+	if err != nil {
+		return fmt.Errorf("failed to create chore: %w", err)
+	}
+
+	tc.CurrentChore = chore
+	return nil
+}
+
+func (tc *TestContext) aChoreExists(frequency, name string, reward int64) error {
+	return tc.createChore(&model.CreateChoreRequest{
+		Name:      name,
+		Reward:    reward,
+		Frequency: frequency,
+	})
+}
+
+func (tc *TestContext) aChoreAssignedToTheChildExists(frequency, name string, reward int64) error {
+	childUser, ok := tc.ChildUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no child user set")
+	}
+
+	return tc.createChore(&model.CreateChoreRequest{
+		Name:       name,
+		Reward:     reward,
+		Frequency:  frequency,
+		AssignedTo: &childUser.ID,
+	})
+}
+
+func (tc *TestContext) iAssignTheChoreTo(email string) error {
+	if tc.CurrentChore == nil {
+		return fmt.Errorf("no current chore")
+	}
+
+	user, err := tc.UserRepo.FindByEmail(context.Background(), email)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	chore, err := tc.ChoreService.Update(context.Background(), tc.householdID(), tc.CurrentChore.ID, &model.UpdateChoreRequest{
+		AssignedTo: &user.ID,
+	})
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentChore = chore
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) theChoreIsRetired() error {
+	if tc.CurrentChore == nil {
+		return fmt.Errorf("no current chore")
+	}
+
+	inactive := false
+	chore, err := tc.ChoreService.Update(context.Background(), tc.householdID(), tc.CurrentChore.ID, &model.UpdateChoreRequest{
+		IsActive: &inactive,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to retire chore: %w", err)
+	}
+
+	tc.CurrentChore = chore
+	return nil
+}
+
+// markChoreDone marks the current chore done for date, on the day today
+func (tc *TestContext) markChoreDone(userID, date, today string) error {
+	if tc.CurrentChore == nil {
+		return fmt.Errorf("no current chore")
+	}
+
+	now, err := time.Parse("2006-01-02", today)
+	if err != nil {
+		return fmt.Errorf("invalid date %q: %w", today, err)
+	}
+
+	completion, err := tc.ChoreService.Complete(context.Background(), tc.householdID(), userID, tc.CurrentChore.ID, &model.CompleteChoreRequest{
+		Date: &date,
+	}, now)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentChoreCompletion = completion
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) theChildMarksTheChoreDoneOn(date string) error {
+	childUser, ok := tc.ChildUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no child user set")
+	}
+
+	return tc.markChoreDone(childUser.ID, date, date)
+}
+
+func (tc *TestContext) theChildMarksTheChoreDoneForOn(date, today string) error {
+	childUser, ok := tc.ChildUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no child user set")
+	}
+
+	return tc.markChoreDone(childUser.ID, date, today)
+}
+
+func (tc *TestContext) userMarksTheChoreDoneOn(email, date string) error {
+	user, err := tc.UserRepo.FindByEmail(context.Background(), email)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return tc.markChoreDone(user.ID, date, date)
+}
+
+func (tc *TestContext) theChoreShouldBeWaitingForApproval() error {
+	if tc.LastError != nil {
+		return fmt.Errorf("expected chore to be marked done, got error: %v", tc.LastError)
+	}
+	return tc.theChoreShouldBe(model.ChorePending)
+}
+
+func (tc *TestContext) approveChore(accountID *string) error {
+	if tc.CurrentChoreCompletion == nil {
+		return fmt.Errorf("no chore marked done")
+	}
+
+	reviewerID, err := tc.currentUserID()
+	if err != nil {
+		return err
+	}
+
+	completion, err := tc.ChoreService.Approve(context.Background(), tc.householdID(), reviewerID, tc.CurrentChoreCompletion.ID, &model.ApproveChoreRequest{
+		AccountID: accountID,
+	})
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentChoreCompletion = completion
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) iApproveTheChore() error {
+	return tc.approveChore(nil)
+}
+
+func (tc *TestContext) iApproveTheChoreIntoTheChildsAccount() error {
+	account, ok := tc.ChildAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no child account set")
+	}
+
+	return tc.approveChore(&account.ID)
+}
+
+func (tc *TestContext) iApproveTheChoreIntoTheCurrentAccount() error {
+	account, ok := tc.CurrentAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no current account")
+	}
+
+	return tc.approveChore(&account.ID)
+}
+
+func (tc *TestContext) iRejectTheChore() error {
+	if tc.CurrentChoreCompletion == nil {
+		return fmt.Errorf("no chore marked done")
+	}
+
+	reviewerID, err := tc.currentUserID()
+	if err != nil {
+		return err
+	}
+
+	completion, err := tc.ChoreService.Reject(context.Background(), tc.householdID(), reviewerID, tc.CurrentChoreCompletion.ID)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentChoreCompletion = completion
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) theChoreShouldBe(status string) error {
+	if tc.CurrentChoreCompletion == nil {
+		return fmt.Errorf("no chore marked done")
+	}
+	if tc.CurrentChoreCompletion.Status != status {
+		return fmt.Errorf("expected chore to be %q, got %q", status, tc.CurrentChoreCompletion.Status)
+	}
+	return nil
+}
+
+func (tc *TestContext) iGetChoreEarningsFromTo(startDate, endDate string) error {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return fmt.Errorf("invalid date %q: %w", startDate, err)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return fmt.Errorf("invalid date %q: %w", endDate, err)
+	}
+	end = end.AddDate(0, 0, 1)
+
+	earnings, err := tc.ChoreService.GetEarnings(context.Background(), tc.householdID(), &start, &end, nil)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.ChoreEarnings = earnings
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) theChildShouldHaveEarned(earned int64, approved, pending int) error {
+	childUser, ok := tc.ChildUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no child user set")
+	}
+
+	for _, e := range tc.ChoreEarnings {
+		if e.UserID != childUser.ID {
+			continue
+		}
+		if e.Earned != earned || e.Approved != approved || e.Pending != pending {
+			return fmt.Errorf("expected %d earned for %d chores with %d pending, got %d for %d with %d pending",
+				earned, approved, pending, e.Earned, e.Approved, e.Pending)
+		}
+		return nil
+	}
+	return fmt.Errorf("no chore earnings for the child")
+}
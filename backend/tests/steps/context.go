@@ -4,62 +4,116 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/asilingas/fambudg/backend/internal/config"
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/notify"
+	"github.com/asilingas/fambudg/backend/internal/notify/smtptest"
 	"github.com/asilingas/fambudg/backend/internal/repository"
+	"github.com/asilingas/fambudg/backend/internal/scheduler"
 	"github.com/asilingas/fambudg/backend/internal/service"
 	"github.com/cucumber/godog"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type TestContext struct {
-	Pool                *pgxpool.Pool
-	AuthService         *service.AuthService
-	TransactionService  *service.TransactionService
-	AccountService      *service.AccountService
-	CategoryService     *service.CategoryService
-	BudgetService       *service.BudgetService
-	ReportService       *service.ReportService
-	SavingGoalService   *service.SavingGoalService
-	BillReminderService *service.BillReminderService
-	AllowanceService    *service.AllowanceService
-	UserRepo            *repository.UserRepository
-	AccountRepo         *repository.AccountRepository
-	CategoryRepo        *repository.CategoryRepository
-	TransactionRepo     *repository.TransactionRepository
+	Pool                      *pgxpool.Pool
+	AuthService               *service.AuthService
+	TransactionService        *service.TransactionService
+	AccountService            *service.AccountService
+	CategoryService           *service.CategoryService
+	BudgetService             *service.BudgetService
+	BudgetTemplateService     *service.BudgetTemplateService
+	ReportService             *service.ReportService
+	SavingGoalService         *service.SavingGoalService
+	BillReminderService       *service.BillReminderService
+	AllowanceService          *service.AllowanceService
+	ChoreService              *service.ChoreService
+	HouseholdService          *service.HouseholdService
+	ExchangeRateService       *service.ExchangeRateService
+	StatementImportService    *service.StatementImportService
+	ImportProfileService      *service.ImportProfileService
+	CategorizationRuleService *service.CategorizationRuleService
+	ReconciliationService     *service.ReconciliationService
+	AuditService              *service.AuditService
+	TrashService              *service.TrashService
+	NotificationService       *service.NotificationService
+	UserRepo                  *repository.UserRepository
+	AccountRepo               *repository.AccountRepository
+	CategoryRepo              *repository.CategoryRepository
+	TransactionRepo           *repository.TransactionRepository
+	JobRunRepo                *repository.JobRunRepository
+	Scheduler                 *scheduler.Scheduler
+	SMTPServer                *smtptest.Server
 
 	// Test state
-	CurrentUser          any
-	CurrentToken         string
-	CurrentAccount       any
-	CurrentCategory      any
-	CurrentTransaction   any
-	CurrentBudget        any
-	CurrentSavingGoal    any
-	CurrentBillReminder  any
-	CurrentAllowance     any
-	SecondAccount        any
-	CreatedUser          any
-	ChildUser            any
-	ChildAccount         any
-	ChildCategory        any
-	TransactionList      []any
-	BudgetList           []any
-	BudgetSummaryList    []any
-	SavingGoalList       []any
-	BillReminderList     []any
-	AllowanceList        []any
-	UserList             []any
-	CategoryReportResult []any
-	TrendResult          []any
-	DashboardResult      any
-	MonthlyReportResult  any
-	SearchResult         any
-	RecurringResult      any
-	ExportedCSV          []string
-	ImportedCount        int
-	LastError            error
-	LastStatusCode       int
+	CurrentUser              any
+	CurrentToken             string
+	CurrentRefreshToken      string
+	UsedRefreshToken         string
+	RevokeSessionsResult     *model.RevokeSessionsResponse
+	LoginResult              *model.LoginResponse
+	TwoFactorSetup           *model.TwoFactorSetupResponse
+	UsedTOTPStep             int64
+	UsedTwoFactorCode        string
+	RecoveryCodes            []string
+	CurrentAccount           any
+	CurrentCategory          any
+	CurrentTransaction       any
+	CurrentBudget            any
+	CurrentBudgetTemplate    *model.BudgetTemplate
+	CopiedBudgets            []*model.Budget
+	CurrentSavingGoal        any
+	GoalProjection           *model.GoalProjection
+	CurrentBillReminder      any
+	BillHistory              *model.BillHistory
+	CurrentAllowance         any
+	AllowancePeriods         []*model.AllowancePeriod
+	CurrentChore             *model.Chore
+	CurrentChoreCompletion   *model.ChoreCompletion
+	ChoreEarnings            []*model.ChoreEarnings
+	SecondAccount            any
+	CreatedUser              any
+	ChildUser                any
+	ChildAccount             any
+	ChildCategory            any
+	OtherUser                any
+	OtherAccount             any
+	OtherTransaction         any
+	TransactionList          []any
+	AccountList              []any
+	CategoryList             []any
+	BudgetList               []any
+	BudgetSummaryList        []any
+	SavingGoalList           []any
+	BillReminderList         []any
+	AllowanceList            []any
+	UserList                 []any
+	CategoryReportResult     []any
+	CategoryTree             []*model.CategorySpendingNode
+	TrendResult              []any
+	DashboardResult          any
+	MonthlyReportResult      any
+	SearchResult             any
+	RecurringResult          any
+	ExportedCSV              []string
+	ImportedCount            int
+	ExchangeRateImportResult any
+	StatementImportResult    *model.ImportStatementResponse
+	ImportPreviewResult      *model.ImportPreviewResponse
+	CurrentImportProfile     *model.ImportProfile
+	ApplyRulesResult         *model.ApplyRulesResponse
+	CurrentReconciliation    *model.Reconciliation
+	AuditFilters             *model.AuditFilters
+	AuditPage                *model.AuditPage
+	SchedulerRan             bool
+	SchedulerLockConn        any
+	TransactionPage          *model.TransactionPage
+	PageParams               model.PageParams
+	PageFetcher              func(page model.PageParams) (*model.TransactionPage, error)
+	LastError                error
+	LastStatusCode           int
 }
 
 func InitializeScenario(ctx *godog.ScenarioContext) {
@@ -80,7 +134,9 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	registerAuthSteps(ctx, tc)
 	registerTransactionSteps(ctx, tc)
 	registerBudgetSteps(ctx, tc)
+	registerBudgetTemplateSteps(ctx, tc)
 	registerReportSteps(ctx, tc)
+	registerCategoryTreeSteps(ctx, tc)
 	registerSearchSteps(ctx, tc)
 	registerSavingGoalSteps(ctx, tc)
 	registerTrendSteps(ctx, tc)
@@ -90,6 +146,23 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	registerCSVSteps(ctx, tc)
 	registerRoleSteps(ctx, tc)
 	registerAllowanceSteps(ctx, tc)
+	registerChoreSteps(ctx, tc)
+	registerPurchaseApprovalSteps(ctx, tc)
+	registerLedgerSteps(ctx, tc)
+	registerHouseholdSteps(ctx, tc)
+	registerExchangeRateSteps(ctx, tc)
+	registerSchedulerSteps(ctx, tc)
+	registerPaginationSteps(ctx, tc)
+	registerStatementImportSteps(ctx, tc)
+	registerImportProfileSteps(ctx, tc)
+	registerCategorizationRuleSteps(ctx, tc)
+	registerTransactionSplitSteps(ctx, tc)
+	registerReconciliationSteps(ctx, tc)
+	registerSessionSteps(ctx, tc)
+	registerTwoFactorSteps(ctx, tc)
+	registerAuditSteps(ctx, tc)
+	registerTrashSteps(ctx, tc)
+	registerNotificationSteps(ctx, tc)
 }
 
 func (tc *TestContext) setupTestDatabase() error {
@@ -128,6 +201,7 @@ func (tc *TestContext) setupTestDatabase() error {
 	}
 
 	// Initialize repositories
+	uow := repository.NewUnitOfWork(tc.Pool)
 	tc.UserRepo = repository.NewUserRepository(tc.Pool)
 	tc.AccountRepo = repository.NewAccountRepository(tc.Pool)
 	tc.CategoryRepo = repository.NewCategoryRepository(tc.Pool)
@@ -137,26 +211,70 @@ func (tc *TestContext) setupTestDatabase() error {
 	savingGoalRepo := repository.NewSavingGoalRepository(tc.Pool)
 	billReminderRepo := repository.NewBillReminderRepository(tc.Pool)
 	allowanceRepo := repository.NewAllowanceRepository(tc.Pool)
+	choreRepo := repository.NewChoreRepository(tc.Pool)
+	householdRepo := repository.NewHouseholdRepository(tc.Pool)
+	exchangeRateRepo := repository.NewExchangeRateRepository(tc.Pool)
+	importProfileRepo := repository.NewImportProfileRepository(tc.Pool)
+	categorizationRuleRepo := repository.NewCategorizationRuleRepository(tc.Pool)
+	reconciliationRepo := repository.NewReconciliationRepository(tc.Pool)
+	sessionRepo := repository.NewSessionRepository(tc.Pool)
+	tc.JobRunRepo = repository.NewJobRunRepository(tc.Pool)
+	auditRepo := repository.NewAuditRepository(tc.Pool)
+	trashRepo := repository.NewTrashRepository(tc.Pool)
+
+	// Email notifications go to a fake SMTP server that keeps them
+	tc.SMTPServer, err = smtptest.NewServer()
+	if err != nil {
+		return fmt.Errorf("failed to start fake SMTP server: %w", err)
+	}
+	emailChannel := notify.NewSMTPChannel(tc.SMTPServer.Host, tc.SMTPServer.Port, "", "", "fambudg@localhost")
 
 	// Initialize services
-	tc.AuthService = service.NewAuthService(tc.UserRepo, cfg.JWT.Secret)
-	tc.AccountService = service.NewAccountService(tc.AccountRepo)
+	tc.AuthService = service.NewAuthService(uow, tc.UserRepo, sessionRepo, householdRepo, auditRepo, cfg.JWT.Secret)
+	tc.AccountService = service.NewAccountService(uow, tc.AccountRepo, auditRepo)
 	tc.CategoryService = service.NewCategoryService(tc.CategoryRepo)
-	tc.TransactionService = service.NewTransactionService(tc.TransactionRepo, tc.AccountRepo)
-	tc.BudgetService = service.NewBudgetService(budgetRepo)
+	tc.BudgetService = service.NewBudgetService(uow, budgetRepo, auditRepo)
+	tc.NotificationService = service.NewNotificationService(repository.NewNotificationRepository(tc.Pool), tc.UserRepo, tc.BudgetService, allowanceRepo, billReminderRepo, emailChannel)
+	tc.TransactionService = service.NewTransactionService(uow, tc.TransactionRepo, tc.AccountRepo, tc.UserRepo, categorizationRuleRepo, auditRepo, tc.NotificationService)
+	tc.ChoreService = service.NewChoreService(uow, choreRepo, tc.UserRepo, allowanceRepo, tc.TransactionService, tc.AccountRepo, auditRepo)
+	tc.AllowanceService = service.NewAllowanceService(uow, allowanceRepo, tc.TransactionService, tc.AccountRepo, auditRepo)
+	tc.BudgetTemplateService = service.NewBudgetTemplateService(uow, repository.NewBudgetTemplateRepository(tc.Pool), budgetRepo, auditRepo)
 	tc.ReportService = service.NewReportService(reportRepo, tc.AccountRepo)
-	tc.SavingGoalService = service.NewSavingGoalService(savingGoalRepo)
-	tc.BillReminderService = service.NewBillReminderService(billReminderRepo, tc.TransactionRepo, tc.AccountRepo)
-	tc.AllowanceService = service.NewAllowanceService(allowanceRepo)
+	tc.SavingGoalService = service.NewSavingGoalService(uow, savingGoalRepo, tc.TransactionService, auditRepo)
+	tc.BillReminderService = service.NewBillReminderService(uow, billReminderRepo, tc.TransactionService, auditRepo)
+	tc.HouseholdService = service.NewHouseholdService(householdRepo)
+	tc.ExchangeRateService = service.NewExchangeRateService(exchangeRateRepo)
+	tc.ImportProfileService = service.NewImportProfileService(importProfileRepo)
+	tc.StatementImportService = service.NewStatementImportService(tc.TransactionService, tc.TransactionRepo, tc.AccountRepo, importProfileRepo, categorizationRuleRepo)
+	tc.CategorizationRuleService = service.NewCategorizationRuleService(uow, categorizationRuleRepo, tc.TransactionRepo, tc.AccountRepo, tc.CategoryRepo, auditRepo)
+	tc.ReconciliationService = service.NewReconciliationService(uow, reconciliationRepo, tc.TransactionRepo, tc.TransactionService, tc.AccountRepo)
+	tc.AuditService = service.NewAuditService(auditRepo)
+	tc.TrashService = service.NewTrashService(uow, trashRepo, tc.TransactionRepo, tc.AccountRepo, tc.CategoryRepo, budgetRepo, savingGoalRepo, auditRepo, cfg.Trash.Retention)
+	tc.Scheduler = scheduler.New(uow, tc.JobRunRepo, time.Hour, scheduler.DefaultJobs(tc.TransactionService, tc.BillReminderService, tc.AllowanceService, tc.NotificationService, tc.TrashService)...)
 
 	return nil
 }
 
 func (tc *TestContext) cleanupTestDatabase() {
+	if tc.SMTPServer != nil {
+		tc.SMTPServer.Close()
+	}
+
 	if tc.Pool != nil {
+		tc.releaseSchedulerLock()
+
 		// Clean up all tables
 		ctx := context.Background()
-		tc.Pool.Exec(ctx, "TRUNCATE transactions, allowances, bill_reminders, saving_goals, budgets, accounts, categories, users CASCADE")
+		tc.Pool.Exec(ctx, "DROP TRIGGER IF EXISTS fail_balance_update ON accounts")
+		tc.Pool.Exec(ctx, "TRUNCATE notifications, notification_preferences, audit_events, job_runs, sessions, reconciliations, transaction_splits, categorization_rules, import_profiles, exchange_rates, transactions, chore_completions, chores, allowances, bill_reminders, saving_goals, budgets, budget_templates, accounts, categories, users, households CASCADE")
 		tc.Pool.Close()
 	}
 }
+
+// householdID returns the household of the current user
+func (tc *TestContext) householdID() string {
+	if user, ok := tc.CurrentUser.(*model.User); ok {
+		return user.HouseholdID
+	}
+	return ""
+}
@@ -24,7 +24,7 @@ func (tc *TestContext) iExportTransactionsAsCSV() error {
 		return fmt.Errorf("no current user")
 	}
 
-	transactions, err := tc.TransactionService.GetByUserID(context.Background(), user.ID, &model.TransactionFilters{})
+	page, err := tc.TransactionService.GetByUserID(context.Background(), user.ID, &model.TransactionFilters{})
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -33,13 +33,18 @@ func (tc *TestContext) iExportTransactionsAsCSV() error {
 	// Build CSV in memory
 	var lines []string
 	lines = append(lines, "date,amount,type,description,category_id,account_id,is_shared")
-	for _, t := range transactions {
+	for _, t := range page.Transactions {
+		var categoryID string
+		if t.CategoryID != nil {
+			categoryID = *t.CategoryID
+		}
+
 		line := fmt.Sprintf("%s,%d,%s,%s,%s,%s,%t",
 			t.Date.Format("2006-01-02"),
 			t.Amount,
 			t.Type,
 			t.Description,
-			t.CategoryID,
+			categoryID,
 			t.AccountID,
 			t.IsShared,
 		)
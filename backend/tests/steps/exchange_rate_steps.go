@@ -0,0 +1,105 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/service"
+	"github.com/cucumber/godog"
+)
+
+func registerExchangeRateSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^the household base currency is "([^"]*)"$`, tc.theHouseholdBaseCurrencyIs)
+	ctx.Step(`^an account "([^"]*)" of type "([^"]*)" in currency "([^"]*)" exists$`, tc.anAccountInCurrencyExists)
+	ctx.Step(`^the following exchange rates are imported:$`, tc.iImportTheFollowingExchangeRates)
+	ctx.Step(`^I import the following exchange rates:$`, tc.iImportTheFollowingExchangeRates)
+	ctx.Step(`^(\d+) exchange rates should be imported$`, tc.nExchangeRatesShouldBeImported)
+	ctx.Step(`^the exchange rate import should report (\d+) errors$`, tc.theExchangeRateImportShouldReportNErrors)
+	ctx.Step(`^the report should fail with an error containing "([^"]*)"$`, tc.theReportShouldFailWithErrorContaining)
+	ctx.Step(`^the report should fail for a missing exchange rate$`, tc.theReportShouldFailForMissingExchangeRate)
+}
+
+func (tc *TestContext) theHouseholdBaseCurrencyIs(currency string) error {
+	_, err := tc.HouseholdService.Update(context.Background(), tc.householdID(), &model.UpdateHouseholdRequest{
+		BaseCurrency: &currency,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set base currency: %w", err)
+	}
+	return nil
+}
+
+func (tc *TestContext) anAccountInCurrencyExists(name, accountType, currency string) error {
+	user, ok := tc.CurrentUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no current user")
+	}
+
+	account, err := tc.AccountService.Create(context.Background(), user.ID, &model.CreateAccountRequest{
+		Name:     name,
+		Type:     accountType,
+		Currency: currency,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create account: %w", err)
+	}
+
+	tc.CurrentAccount = account
+	return nil
+}
+
+func (tc *TestContext) iImportTheFollowingExchangeRates(doc *godog.DocString) error {
+	result, err := tc.ExchangeRateService.ImportCSV(context.Background(), tc.householdID(), strings.NewReader(doc.Content), "")
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.ExchangeRateImportResult = result
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) nExchangeRatesShouldBeImported(expected int) error {
+	result, ok := tc.ExchangeRateImportResult.(*model.ImportExchangeRatesResponse)
+	if !ok {
+		return fmt.Errorf("no exchange rate import result, last error: %v", tc.LastError)
+	}
+
+	if result.Imported != expected {
+		return fmt.Errorf("expected %d exchange rates imported, got %d", expected, result.Imported)
+	}
+	return nil
+}
+
+func (tc *TestContext) theExchangeRateImportShouldReportNErrors(expected int) error {
+	result, ok := tc.ExchangeRateImportResult.(*model.ImportExchangeRatesResponse)
+	if !ok {
+		return fmt.Errorf("no exchange rate import result, last error: %v", tc.LastError)
+	}
+
+	if len(result.Errors) != expected {
+		return fmt.Errorf("expected %d import errors, got %d: %v", expected, len(result.Errors), result.Errors)
+	}
+	return nil
+}
+
+func (tc *TestContext) theReportShouldFailWithErrorContaining(expected string) error {
+	if tc.LastError == nil {
+		return fmt.Errorf("expected error containing %q, got nil", expected)
+	}
+	if !strings.Contains(tc.LastError.Error(), expected) {
+		return fmt.Errorf("expected error containing %q, got %q", expected, tc.LastError.Error())
+	}
+	return nil
+}
+
+func (tc *TestContext) theReportShouldFailForMissingExchangeRate() error {
+	if !errors.Is(tc.LastError, service.ErrMissingExchangeRate) {
+		return fmt.Errorf("expected a missing exchange rate error, got %v", tc.LastError)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerHouseholdSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^another household exists with admin "([^"]*)" and a transaction of (-?\d+)$`, tc.anotherHouseholdExistsWithTransaction)
+	ctx.Step(`^my household should be different from the other household$`, tc.myHouseholdShouldBeDifferent)
+	ctx.Step(`^I list all accounts$`, tc.iListAllAccounts)
+	ctx.Step(`^I should see (\d+) accounts$`, tc.iShouldSeeNAccounts)
+	ctx.Step(`^I list categories$`, tc.iListCategories)
+	ctx.Step(`^I should see (\d+) categories$`, tc.iShouldSeeNCategories)
+	ctx.Step(`^I list all transactions$`, tc.iListAllTransactions)
+	ctx.Step(`^I try to get the other household's account$`, tc.iTryToGetOtherHouseholdAccount)
+	ctx.Step(`^I try to update the other household's transaction amount to (-?\d+)$`, tc.iTryToUpdateOtherHouseholdTransaction)
+	ctx.Step(`^the request should fail with error "([^"]*)"$`, tc.theRequestShouldFailWithError)
+	ctx.Step(`^the other household's transaction should still have amount (-?\d+)$`, tc.theOtherHouseholdTransactionShouldStillHaveAmount)
+	ctx.Step(`^I try to create a transaction on the other household's account$`, tc.iTryToCreateTransactionOnOtherHouseholdAccount)
+}
+
+// anotherHouseholdExistsWithTransaction registers a second family with its
+// own account, category and transaction
+func (tc *TestContext) anotherHouseholdExistsWithTransaction(email string, amount int64) error {
+	ctx := context.Background()
+
+	user, err := tc.AuthService.Register(ctx, &model.RegisterRequest{
+		Email:         email,
+		Password:      "password123",
+		Name:          "Other Admin",
+		HouseholdName: "Other Family",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register other household: %w", err)
+	}
+
+	category, err := tc.CategoryService.Create(ctx, user.HouseholdID, &model.CreateCategoryRequest{
+		Name: "Other Groceries",
+		Type: "expense",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create other category: %w", err)
+	}
+
+	account, err := tc.AccountService.Create(ctx, user.ID, &model.CreateAccountRequest{
+		Name:     "Other Checking",
+		Type:     "checking",
+		Currency: "EUR",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create other account: %w", err)
+	}
+
+	transaction, err := tc.TransactionService.Create(ctx, user.ID, &model.CreateTransactionRequest{
+		AccountID:   account.ID,
+		CategoryID:  category.ID,
+		Amount:      amount,
+		Type:        "expense",
+		Description: "Other household shopping",
+		Date:        "2026-02-15",
+		IsShared:    true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create other transaction: %w", err)
+	}
+
+	tc.OtherUser = user
+	tc.OtherAccount = account
+	tc.OtherTransaction = transaction
+	return nil
+}
+
+func (tc *TestContext) myHouseholdShouldBeDifferent() error {
+	other, ok := tc.OtherUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no other household")
+	}
+
+	if tc.householdID() == "" {
+		return fmt.Errorf("current user has no household")
+	}
+	if tc.householdID() == other.HouseholdID {
+		return fmt.Errorf("expected separate households, both are %s", other.HouseholdID)
+	}
+	return nil
+}
+
+func (tc *TestContext) iListAllAccounts() error {
+	accounts, err := tc.AccountService.GetAll(context.Background(), tc.householdID())
+	if err != nil {
+		return fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	tc.AccountList = make([]any, len(accounts))
+	for i, a := range accounts {
+		tc.AccountList[i] = a
+	}
+	return nil
+}
+
+func (tc *TestContext) iShouldSeeNAccounts(expected int) error {
+	if len(tc.AccountList) != expected {
+		return fmt.Errorf("expected %d accounts, got %d", expected, len(tc.AccountList))
+	}
+	return nil
+}
+
+func (tc *TestContext) iListCategories() error {
+	categories, err := tc.CategoryService.GetAll(context.Background(), tc.householdID())
+	if err != nil {
+		return fmt.Errorf("failed to list categories: %w", err)
+	}
+
+	tc.CategoryList = make([]any, len(categories))
+	for i, c := range categories {
+		tc.CategoryList[i] = c
+	}
+	return nil
+}
+
+func (tc *TestContext) iShouldSeeNCategories(expected int) error {
+	if len(tc.CategoryList) != expected {
+		return fmt.Errorf("expected %d categories, got %d", expected, len(tc.CategoryList))
+	}
+	return nil
+}
+
+func (tc *TestContext) iListAllTransactions() error {
+	page, err := tc.TransactionService.GetAll(context.Background(), tc.householdID(), &model.TransactionFilters{})
+	if err != nil {
+		return fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	tc.TransactionList = make([]any, len(page.Transactions))
+	for i, t := range page.Transactions {
+		tc.TransactionList[i] = t
+	}
+	return nil
+}
+
+func (tc *TestContext) iTryToGetOtherHouseholdAccount() error {
+	account, ok := tc.OtherAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no other account")
+	}
+
+	_, err := tc.AccountService.GetByID(context.Background(), tc.householdID(), account.ID)
+	tc.LastError = err
+	return nil
+}
+
+func (tc *TestContext) iTryToUpdateOtherHouseholdTransaction(amount int64) error {
+	transaction, ok := tc.OtherTransaction.(*model.Transaction)
+	if !ok {
+		return fmt.Errorf("no other transaction")
+	}
+
+	req := &model.UpdateTransactionRequest{Amount: &amount}
+	_, err := tc.TransactionService.Update(context.Background(), tc.householdID(), transaction.ID, req)
+	tc.LastError = err
+	return nil
+}
+
+func (tc *TestContext) theRequestShouldFailWithError(expectedError string) error {
+	if tc.LastError == nil {
+		return fmt.Errorf("expected error %q, got nil", expectedError)
+	}
+	if tc.LastError.Error() != expectedError {
+		return fmt.Errorf("expected error %q, got %q", expectedError, tc.LastError.Error())
+	}
+	return nil
+}
+
+func (tc *TestContext) theOtherHouseholdTransactionShouldStillHaveAmount(expected int64) error {
+	transaction, ok := tc.OtherTransaction.(*model.Transaction)
+	if !ok {
+		return fmt.Errorf("no other transaction")
+	}
+	other, ok := tc.OtherUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no other household")
+	}
+
+	reloaded, err := tc.TransactionService.GetByID(context.Background(), other.HouseholdID, transaction.ID)
+	if err != nil {
+		return fmt.Errorf("failed to reload transaction: %w", err)
+	}
+
+	if reloaded.Amount != expected {
+		return fmt.Errorf("expected amount %d, got %d", expected, reloaded.Amount)
+	}
+	return nil
+}
+
+func (tc *TestContext) iTryToCreateTransactionOnOtherHouseholdAccount() error {
+	user, ok := tc.CurrentUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no current user")
+	}
+
+	account, ok := tc.OtherAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no other account")
+	}
+
+	category, ok := tc.CurrentCategory.(*model.Category)
+	if !ok {
+		return fmt.Errorf("no current category")
+	}
+
+	req := &model.CreateTransactionRequest{
+		AccountID:   account.ID,
+		CategoryID:  category.ID,
+		Amount:      -100,
+		Type:        "expense",
+		Description: "Cross-household attempt",
+		Date:        "2026-02-15",
+		IsShared:    true,
+	}
+
+	_, err := tc.TransactionService.Create(context.Background(), user.ID, req)
+	tc.LastError = err
+	return nil
+}
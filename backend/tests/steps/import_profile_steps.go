@@ -0,0 +1,117 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerImportProfileSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^an import profile exists with:$`, tc.anImportProfileExistsWith)
+	ctx.Step(`^I create an import profile with:$`, tc.iCreateAnImportProfileWith)
+	ctx.Step(`^I preview the following "([^"]*)" statement:$`, tc.iPreviewTheFollowingStatement)
+	ctx.Step(`^the preview should show (\d+) valid, (\d+) duplicate and (\d+) invalid rows$`, tc.thePreviewShouldShowRows)
+	ctx.Step(`^preview row (\d+) should have amount (-?\d+) and description "([^"]*)"$`, tc.previewRowShouldHaveAmountAndDescription)
+	ctx.Step(`^preview row (\d+) should have an error containing "([^"]*)"$`, tc.previewRowShouldHaveErrorContaining)
+}
+
+func (tc *TestContext) createImportProfile(doc *godog.DocString) error {
+	var req model.CreateImportProfileRequest
+	if err := json.Unmarshal([]byte(doc.Content), &req); err != nil {
+		return fmt.Errorf("invalid import profile JSON: %w", err)
+	}
+
+	profile, err := tc.ImportProfileService.Create(context.Background(), tc.householdID(), &req)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentImportProfile = profile
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) anImportProfileExistsWith(doc *godog.DocString) error {
+	if err := tc.createImportProfile(doc); err != nil {
+		return err
+	}
+	if tc.LastError != nil {
+		return fmt.Errorf("failed to create import profile: %w", tc.LastError)
+	}
+	return nil
+}
+
+func (tc *TestContext) iCreateAnImportProfileWith(doc *godog.DocString) error {
+	return tc.createImportProfile(doc)
+}
+
+func (tc *TestContext) iPreviewTheFollowingStatement(format string, doc *godog.DocString) error {
+	req, err := tc.statementImportRequest(format)
+	if err != nil {
+		return err
+	}
+
+	result, err := tc.StatementImportService.Preview(context.Background(), tc.householdID(), req, strings.NewReader(doc.Content))
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.ImportPreviewResult = result
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) previewRow(n int) (*model.ImportPreviewRow, error) {
+	if tc.ImportPreviewResult == nil {
+		return nil, fmt.Errorf("no preview result, last error: %v", tc.LastError)
+	}
+	if n < 1 || n > len(tc.ImportPreviewResult.Rows) {
+		return nil, fmt.Errorf("preview has %d rows, no row %d", len(tc.ImportPreviewResult.Rows), n)
+	}
+	return tc.ImportPreviewResult.Rows[n-1], nil
+}
+
+func (tc *TestContext) thePreviewShouldShowRows(valid, duplicates, invalid int) error {
+	result := tc.ImportPreviewResult
+	if result == nil {
+		return fmt.Errorf("no preview result, last error: %v", tc.LastError)
+	}
+	if result.Valid != valid || result.Duplicates != duplicates || result.Invalid != invalid {
+		return fmt.Errorf("expected %d valid, %d duplicate and %d invalid rows, got %d, %d and %d",
+			valid, duplicates, invalid, result.Valid, result.Duplicates, result.Invalid)
+	}
+	return nil
+}
+
+func (tc *TestContext) previewRowShouldHaveAmountAndDescription(n int, amount int64, description string) error {
+	row, err := tc.previewRow(n)
+	if err != nil {
+		return err
+	}
+	if row.Amount != amount {
+		return fmt.Errorf("expected preview row %d amount %d, got %d", n, amount, row.Amount)
+	}
+	if row.Description != description {
+		return fmt.Errorf("expected preview row %d description %q, got %q", n, description, row.Description)
+	}
+	return nil
+}
+
+func (tc *TestContext) previewRowShouldHaveErrorContaining(n int, expected string) error {
+	row, err := tc.previewRow(n)
+	if err != nil {
+		return err
+	}
+	for _, e := range row.Errors {
+		if strings.Contains(e, expected) {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected preview row %d to have an error containing %q, got %v", n, expected, row.Errors)
+}
@@ -0,0 +1,171 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerLedgerSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^account balance updates are failing$`, tc.accountBalanceUpdatesAreFailing)
+	ctx.Step(`^I try to create a transaction with amount (-?\d+)$`, tc.iTryToCreateTransactionWithAmount)
+	ctx.Step(`^I try to update the transaction amount to (-?\d+)$`, tc.iTryToUpdateTransactionAmount)
+	ctx.Step(`^the ledger operation should fail$`, tc.theLedgerOperationShouldFail)
+	ctx.Step(`^the account balance should be (-?\d+)$`, tc.theAccountBalanceShouldBe)
+	ctx.Step(`^the account should have (\d+) transactions$`, tc.theAccountShouldHaveNTransactions)
+	ctx.Step(`^the transaction should still have amount (-?\d+)$`, tc.theTransactionShouldStillHaveAmount)
+	ctx.Step(`^the bill next due date should still be "([^"]*)"$`, tc.theBillNextDueDateShouldStillBe)
+}
+
+// accountBalanceUpdatesAreFailing installs a trigger that rejects every
+// balance change, simulating a failure after the transaction row is written
+func (tc *TestContext) accountBalanceUpdatesAreFailing() error {
+	ctx := context.Background()
+
+	_, err := tc.Pool.Exec(ctx, `
+		CREATE OR REPLACE FUNCTION fail_balance_update() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'injected balance update failure';
+		END;
+		$$ LANGUAGE plpgsql
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create failure function: %w", err)
+	}
+
+	_, err = tc.Pool.Exec(ctx, `
+		CREATE TRIGGER fail_balance_update
+		BEFORE UPDATE OF balance ON accounts
+		FOR EACH ROW EXECUTE FUNCTION fail_balance_update()
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create failure trigger: %w", err)
+	}
+
+	return nil
+}
+
+func (tc *TestContext) iTryToCreateTransactionWithAmount(amount int64) error {
+	user, ok := tc.CurrentUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no current user")
+	}
+
+	account, ok := tc.CurrentAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no current account")
+	}
+
+	category, ok := tc.CurrentCategory.(*model.Category)
+	if !ok {
+		return fmt.Errorf("no current category")
+	}
+
+	req := &model.CreateTransactionRequest{
+		AccountID:   account.ID,
+		CategoryID:  category.ID,
+		Amount:      amount,
+		Type:        "expense",
+		Description: "Atomicity check",
+		Date:        "2026-02-15",
+		IsShared:    true,
+	}
+
+	_, err := tc.TransactionService.Create(context.Background(), user.ID, req)
+	tc.LastError = err
+	return nil
+}
+
+func (tc *TestContext) iTryToUpdateTransactionAmount(amount int64) error {
+	transaction, ok := tc.CurrentTransaction.(*model.Transaction)
+	if !ok {
+		return fmt.Errorf("no current transaction")
+	}
+
+	req := &model.UpdateTransactionRequest{
+		Amount: &amount,
+	}
+
+	_, err := tc.TransactionService.Update(context.Background(), tc.householdID(), transaction.ID, req)
+	tc.LastError = err
+	return nil
+}
+
+func (tc *TestContext) theLedgerOperationShouldFail() error {
+	if tc.LastError == nil {
+		return fmt.Errorf("expected ledger operation to fail, got nil error")
+	}
+	return nil
+}
+
+func (tc *TestContext) theAccountBalanceShouldBe(expected int64) error {
+	account, ok := tc.CurrentAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no current account")
+	}
+
+	current, err := tc.AccountService.GetByID(context.Background(), tc.householdID(), account.ID)
+	if err != nil {
+		return fmt.Errorf("failed to reload account: %w", err)
+	}
+
+	if current.Balance != expected {
+		return fmt.Errorf("expected balance %d, got %d", expected, current.Balance)
+	}
+	return nil
+}
+
+func (tc *TestContext) theAccountShouldHaveNTransactions(expected int) error {
+	account, ok := tc.CurrentAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no current account")
+	}
+
+	page, err := tc.TransactionService.GetAll(context.Background(), tc.householdID(), &model.TransactionFilters{
+		AccountID: account.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	if len(page.Transactions) != expected {
+		return fmt.Errorf("expected %d transactions, got %d", expected, len(page.Transactions))
+	}
+	return nil
+}
+
+func (tc *TestContext) theTransactionShouldStillHaveAmount(expected int64) error {
+	transaction, ok := tc.CurrentTransaction.(*model.Transaction)
+	if !ok {
+		return fmt.Errorf("no current transaction")
+	}
+
+	current, err := tc.TransactionService.GetByID(context.Background(), tc.householdID(), transaction.ID)
+	if err != nil {
+		return fmt.Errorf("failed to reload transaction: %w", err)
+	}
+
+	if current.Amount != expected {
+		return fmt.Errorf("expected amount %d, got %d", expected, current.Amount)
+	}
+	return nil
+}
+
+func (tc *TestContext) theBillNextDueDateShouldStillBe(expected string) error {
+	bill, ok := tc.CurrentBillReminder.(*model.BillReminder)
+	if !ok {
+		return fmt.Errorf("no current bill reminder")
+	}
+
+	current, err := tc.BillReminderService.GetByID(context.Background(), tc.householdID(), bill.ID)
+	if err != nil {
+		return fmt.Errorf("failed to reload bill reminder: %w", err)
+	}
+
+	if got := current.NextDueDate.Format("2006-01-02"); got != expected {
+		return fmt.Errorf("expected next due date %s, got %s", expected, got)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerNotificationSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^my notification feed should have (\d+) notifications?$`, tc.myNotificationFeedShouldHaveN)
+	ctx.Step(`^my notification feed should include "([^"]*)"$`, tc.myNotificationFeedShouldInclude)
+	ctx.Step(`^the notification feed of "([^"]*)" should have (\d+) notifications?$`, tc.theNotificationFeedOfShouldHaveN)
+	ctx.Step(`^the notification feed of "([^"]*)" should include "([^"]*)"$`, tc.theNotificationFeedOfShouldInclude)
+	ctx.Step(`^I should have (\d+) unread notifications?$`, tc.iShouldHaveNUnreadNotifications)
+	ctx.Step(`^I mark all my notifications as read$`, tc.iMarkAllMyNotificationsAsRead)
+	ctx.Step(`^I turn off "([^"]*)" notifications$`, tc.iTurnOffNotifications)
+	ctx.Step(`^I turn on email for "([^"]*)" notifications$`, tc.iTurnOnEmailForNotifications)
+	ctx.Step(`^I set my large transaction threshold to (\d+)$`, tc.iSetMyLargeTransactionThreshold)
+	ctx.Step(`^(\d+) emails? should have been sent$`, tc.nEmailsShouldHaveBeenSent)
+	ctx.Step(`^an email "([^"]*)" should have been sent to "([^"]*)"$`, tc.anEmailShouldHaveBeenSentTo)
+}
+
+func (tc *TestContext) notificationFeedOf(email string) (*model.NotificationFeed, error) {
+	user, err := tc.UserRepo.FindByEmail(context.Background(), email)
+	if err != nil {
+		return nil, err
+	}
+
+	return tc.NotificationService.GetFeed(context.Background(), user.ID, false)
+}
+
+func (tc *TestContext) myNotificationFeed() (*model.NotificationFeed, error) {
+	userID, err := tc.currentUserID()
+	if err != nil {
+		return nil, err
+	}
+
+	return tc.NotificationService.GetFeed(context.Background(), userID, false)
+}
+
+func checkFeedSize(feed *model.NotificationFeed, expected int) error {
+	if len(feed.Notifications) != expected {
+		var titles []string
+		for _, n := range feed.Notifications {
+			titles = append(titles, n.Title)
+		}
+		return fmt.Errorf("expected %d notifications, got %d: %v", expected, len(feed.Notifications), titles)
+	}
+	return nil
+}
+
+func checkFeedIncludes(feed *model.NotificationFeed, title string) error {
+	for _, n := range feed.Notifications {
+		if n.Title == title {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected a notification %q in the feed", title)
+}
+
+func (tc *TestContext) myNotificationFeedShouldHaveN(expected int) error {
+	feed, err := tc.myNotificationFeed()
+	if err != nil {
+		return err
+	}
+	return checkFeedSize(feed, expected)
+}
+
+func (tc *TestContext) myNotificationFeedShouldInclude(title string) error {
+	feed, err := tc.myNotificationFeed()
+	if err != nil {
+		return err
+	}
+	return checkFeedIncludes(feed, title)
+}
+
+func (tc *TestContext) theNotificationFeedOfShouldHaveN(email string, expected int) error {
+	feed, err := tc.notificationFeedOf(email)
+	if err != nil {
+		return err
+	}
+	return checkFeedSize(feed, expected)
+}
+
+func (tc *TestContext) theNotificationFeedOfShouldInclude(email, title string) error {
+	feed, err := tc.notificationFeedOf(email)
+	if err != nil {
+		return err
+	}
+	return checkFeedIncludes(feed, title)
+}
+
+func (tc *TestContext) iShouldHaveNUnreadNotifications(expected int) error {
+	feed, err := tc.myNotificationFeed()
+	if err != nil {
+		return err
+	}
+
+	if feed.Unread != expected {
+		return fmt.Errorf("expected %d unread notifications, got %d", expected, feed.Unread)
+	}
+	return nil
+}
+
+func (tc *TestContext) iMarkAllMyNotificationsAsRead() error {
+	userID, err := tc.currentUserID()
+	if err != nil {
+		return err
+	}
+
+	return tc.NotificationService.MarkAllRead(context.Background(), userID)
+}
+
+// updateMyPreference changes the current user's preference for one kind of
+// notification, keeping the rest of it
+func (tc *TestContext) updateMyPreference(notificationType string, change func(*model.NotificationPreferenceRequest)) error {
+	userID, err := tc.currentUserID()
+	if err != nil {
+		return err
+	}
+
+	prefs, err := tc.NotificationService.GetPreferences(context.Background(), userID)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range prefs {
+		if p.Type != notificationType {
+			continue
+		}
+
+		req := model.NotificationPreferenceRequest{
+			Type:      p.Type,
+			Enabled:   p.Enabled,
+			Channels:  p.Channels,
+			Threshold: p.Threshold,
+		}
+		change(&req)
+
+		_, err := tc.NotificationService.UpdatePreferences(context.Background(), userID, &model.UpdateNotificationPreferencesRequest{
+			Preferences: []model.NotificationPreferenceRequest{req},
+		})
+		return err
+	}
+	return fmt.Errorf("unknown notification type %q", notificationType)
+}
+
+func (tc *TestContext) iTurnOffNotifications(notificationType string) error {
+	return tc.updateMyPreference(notificationType, func(req *model.NotificationPreferenceRequest) {
+		req.Enabled = false
+	})
+}
+
+func (tc *TestContext) iTurnOnEmailForNotifications(notificationType string) error {
+	return tc.updateMyPreference(notificationType, func(req *model.NotificationPreferenceRequest) {
+		req.Channels = []string{model.ChannelEmail}
+	})
+}
+
+func (tc *TestContext) iSetMyLargeTransactionThreshold(threshold int64) error {
+	return tc.updateMyPreference(model.NotificationLargeTransaction, func(req *model.NotificationPreferenceRequest) {
+		req.Threshold = &threshold
+	})
+}
+
+func (tc *TestContext) nEmailsShouldHaveBeenSent(expected int) error {
+	messages := tc.SMTPServer.Messages()
+	if len(messages) != expected {
+		return fmt.Errorf("expected %d emails, got %d", expected, len(messages))
+	}
+	return nil
+}
+
+func (tc *TestContext) anEmailShouldHaveBeenSentTo(subject, to string) error {
+	for _, msg := range tc.SMTPServer.Messages() {
+		if len(msg.To) == 1 && msg.To[0] == to && strings.Contains(msg.Data, "Subject: "+subject+"\r\n") {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected an email %q to %s", subject, to)
+}
@@ -0,0 +1,195 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerPaginationSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^I list my transactions with limit (\d+)$`, tc.iListMyTransactionsWithLimit)
+	ctx.Step(`^I list my transactions sorted by "([^"]*)" "([^"]*)" with limit (\d+)$`, tc.iListMyTransactionsSortedBy)
+	ctx.Step(`^I search transactions with description "([^"]*)" and limit (\d+)$`, tc.iSearchTransactionsWithLimit)
+	ctx.Step(`^I request the next page$`, tc.iRequestTheNextPage)
+	ctx.Step(`^I request the next page sorted by "([^"]*)" "([^"]*)"$`, tc.iRequestTheNextPageSortedBy)
+	ctx.Step(`^I request a page sorted by "([^"]*)" "([^"]*)" after the value "([^"]*)"$`, tc.iRequestAPageAfterTheValue)
+	ctx.Step(`^the page should contain "([^"]*)"$`, tc.thePageShouldContain)
+	ctx.Step(`^the total count should be (\d+)$`, tc.theTotalCountShouldBe)
+	ctx.Step(`^there should be no next page$`, tc.thereShouldBeNoNextPage)
+	ctx.Step(`^I page through my transactions with limit (\d+)$`, tc.iPageThroughMyTransactions)
+	ctx.Step(`^I should have seen (\d+) distinct transactions$`, tc.iShouldHaveSeenNDistinctTransactions)
+}
+
+// fetchPage loads a page with the current list or search query
+func (tc *TestContext) fetchPage(page model.PageParams) error {
+	if tc.PageFetcher == nil {
+		return fmt.Errorf("no list or search in progress")
+	}
+
+	result, err := tc.PageFetcher(page)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.PageParams = page
+	tc.TransactionPage = result
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) iListMyTransactionsWithLimit(limit int) error {
+	return tc.iListMyTransactionsSortedBy("", "", limit)
+}
+
+func (tc *TestContext) iListMyTransactionsSortedBy(sort, order string, limit int) error {
+	user, ok := tc.CurrentUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no current user")
+	}
+
+	tc.PageFetcher = func(page model.PageParams) (*model.TransactionPage, error) {
+		return tc.TransactionService.GetByUserID(context.Background(), user.ID, &model.TransactionFilters{PageParams: page})
+	}
+	return tc.fetchPage(model.PageParams{Sort: sort, Order: order, Limit: limit})
+}
+
+func (tc *TestContext) iSearchTransactionsWithLimit(description string, limit int) error {
+	user, ok := tc.CurrentUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no current user")
+	}
+
+	tc.PageFetcher = func(page model.PageParams) (*model.TransactionPage, error) {
+		return tc.ReportService.SearchTransactions(context.Background(), &model.SearchFilters{
+			UserID:      user.ID,
+			Description: description,
+			PageParams:  page,
+		})
+	}
+	return tc.fetchPage(model.PageParams{Limit: limit})
+}
+
+func (tc *TestContext) iRequestTheNextPage() error {
+	if tc.TransactionPage == nil || tc.TransactionPage.NextCursor == "" {
+		return fmt.Errorf("no next page available")
+	}
+
+	page := tc.PageParams
+	page.Cursor = tc.TransactionPage.NextCursor
+	return tc.fetchPage(page)
+}
+
+func (tc *TestContext) iRequestTheNextPageSortedBy(sort, order string) error {
+	if tc.TransactionPage == nil || tc.TransactionPage.NextCursor == "" {
+		return fmt.Errorf("no next page available")
+	}
+
+	page := model.PageParams{
+		Sort:   sort,
+		Order:  order,
+		Limit:  tc.PageParams.Limit,
+		Cursor: tc.TransactionPage.NextCursor,
+	}
+	return tc.fetchPage(page)
+}
+
+// iRequestAPageAfterTheValue sends a cursor that was not issued by a page,
+// pointing after the given sort value
+func (tc *TestContext) iRequestAPageAfterTheValue(sort, order, value string) error {
+	cursor := &model.Cursor{
+		Sort:      sort,
+		Order:     order,
+		Value:     value,
+		CreatedAt: time.Now(),
+		ID:        "00000000-0000-0000-0000-000000000000",
+	}
+
+	page := model.PageParams{
+		Sort:   sort,
+		Order:  order,
+		Limit:  tc.PageParams.Limit,
+		Cursor: cursor.Encode(),
+	}
+	return tc.fetchPage(page)
+}
+
+func (tc *TestContext) thePageShouldContain(expected string) error {
+	if tc.TransactionPage == nil {
+		return fmt.Errorf("no page loaded, last error: %v", tc.LastError)
+	}
+
+	var actual []string
+	for _, t := range tc.TransactionPage.Transactions {
+		actual = append(actual, t.Description)
+	}
+
+	if strings.Join(actual, ", ") != expected {
+		return fmt.Errorf("expected page %q, got %q", expected, strings.Join(actual, ", "))
+	}
+	return nil
+}
+
+func (tc *TestContext) theTotalCountShouldBe(expected int) error {
+	if tc.TransactionPage == nil {
+		return fmt.Errorf("no page loaded, last error: %v", tc.LastError)
+	}
+
+	if tc.TransactionPage.TotalCount != expected {
+		return fmt.Errorf("expected total count %d, got %d", expected, tc.TransactionPage.TotalCount)
+	}
+	return nil
+}
+
+func (tc *TestContext) thereShouldBeNoNextPage() error {
+	if tc.TransactionPage == nil {
+		return fmt.Errorf("no page loaded, last error: %v", tc.LastError)
+	}
+
+	if tc.TransactionPage.NextCursor != "" {
+		return fmt.Errorf("expected no next page, got cursor %q", tc.TransactionPage.NextCursor)
+	}
+	return nil
+}
+
+func (tc *TestContext) iPageThroughMyTransactions(limit int) error {
+	if err := tc.iListMyTransactionsWithLimit(limit); err != nil {
+		return err
+	}
+
+	tc.TransactionList = nil
+	for {
+		if tc.LastError != nil {
+			return tc.LastError
+		}
+		for _, t := range tc.TransactionPage.Transactions {
+			tc.TransactionList = append(tc.TransactionList, t)
+		}
+		if tc.TransactionPage.NextCursor == "" {
+			return nil
+		}
+		if err := tc.iRequestTheNextPage(); err != nil {
+			return err
+		}
+	}
+}
+
+func (tc *TestContext) iShouldHaveSeenNDistinctTransactions(expected int) error {
+	seen := map[string]bool{}
+	for _, item := range tc.TransactionList {
+		t := item.(*model.Transaction)
+		if seen[t.ID] {
+			return fmt.Errorf("transaction %q was returned twice", t.Description)
+		}
+		seen[t.ID] = true
+	}
+
+	if len(seen) != expected {
+		return fmt.Errorf("expected %d distinct transactions, got %d", expected, len(seen))
+	}
+	return nil
+}
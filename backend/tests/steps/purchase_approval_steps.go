@@ -0,0 +1,169 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerPurchaseApprovalSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^the child needs approval for purchases above (\d+)$`, tc.theChildNeedsApprovalForPurchasesAbove)
+	ctx.Step(`^I remove the child's approval threshold$`, tc.iRemoveTheChildsApprovalThreshold)
+	ctx.Step(`^I set an approval threshold of (\d+) for "([^"]*)"$`, tc.iSetAnApprovalThresholdFor)
+	ctx.Step(`^the child spends (\d+) on "([^"]*)"$`, tc.theChildSpendsOn)
+	ctx.Step(`^the child books an? "([^"]*)" of (-?\d+) on "([^"]*)"$`, tc.theChildBooksOn)
+	ctx.Step(`^the child changes the purchase amount to (-?\d+)$`, tc.theChildChangesThePurchaseAmountTo)
+	ctx.Step(`^the purchase should be "([^"]*)"$`, tc.thePurchaseShouldBe)
+	ctx.Step(`^I approve the purchase$`, tc.iApproveThePurchase)
+	ctx.Step(`^I reject the purchase$`, tc.iRejectThePurchase)
+	ctx.Step(`^there should be (\d+) purchases? waiting for approval$`, tc.thereShouldBeNPurchasesWaitingForApproval)
+}
+
+func (tc *TestContext) setChildApprovalThreshold(threshold *int64) error {
+	childUser, ok := tc.ChildUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no child user set")
+	}
+
+	if _, err := tc.AuthService.SetApprovalThreshold(context.Background(), tc.householdID(), childUser.ID, threshold); err != nil {
+		return fmt.Errorf("failed to set approval threshold: %w", err)
+	}
+	return nil
+}
+
+func (tc *TestContext) theChildNeedsApprovalForPurchasesAbove(threshold int64) error {
+	return tc.setChildApprovalThreshold(&threshold)
+}
+
+func (tc *TestContext) iRemoveTheChildsApprovalThreshold() error {
+	return tc.setChildApprovalThreshold(nil)
+}
+
+func (tc *TestContext) iSetAnApprovalThresholdFor(threshold int64, email string) error {
+	user, err := tc.UserRepo.FindByEmail(context.Background(), email)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	_, err = tc.AuthService.SetApprovalThreshold(context.Background(), tc.householdID(), user.ID, &threshold)
+	tc.LastError = err
+	return nil
+}
+
+func (tc *TestContext) theChildSpendsOn(amount int64, date string) error {
+	return tc.theChildBooksOn("expense", -amount, date)
+}
+
+// theChildBooksOn books a transaction of the given type on the child's
+// account. A transfer goes to the current account.
+func (tc *TestContext) theChildBooksOn(transactionType string, amount int64, date string) error {
+	childUser, ok := tc.ChildUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no child user set")
+	}
+	account, ok := tc.ChildAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no child account set")
+	}
+	category, ok := tc.ChildCategory.(*model.Category)
+	if !ok {
+		return fmt.Errorf("no child category set")
+	}
+
+	req := &model.CreateTransactionRequest{
+		AccountID:  account.ID,
+		CategoryID: category.ID,
+		Amount:     amount,
+		Type:       transactionType,
+		Date:       date,
+	}
+	if transactionType == "transfer" {
+		to, ok := tc.CurrentAccount.(*model.Account)
+		if !ok {
+			return fmt.Errorf("no current account")
+		}
+		req.CategoryID = ""
+		req.TransferToAccountID = &to.ID
+	}
+
+	transaction, err := tc.TransactionService.Create(context.Background(), childUser.ID, req)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentTransaction = transaction
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) theChildChangesThePurchaseAmountTo(amount int64) error {
+	transaction, ok := tc.CurrentTransaction.(*model.Transaction)
+	if !ok {
+		return fmt.Errorf("no current transaction")
+	}
+
+	updated, err := tc.TransactionService.Update(context.Background(), tc.householdID(), transaction.ID, &model.UpdateTransactionRequest{
+		Amount: &amount,
+	})
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentTransaction = updated
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) thePurchaseShouldBe(status string) error {
+	if tc.LastError != nil {
+		return fmt.Errorf("expected the purchase to succeed, got error: %v", tc.LastError)
+	}
+	transaction, ok := tc.CurrentTransaction.(*model.Transaction)
+	if !ok {
+		return fmt.Errorf("no current transaction")
+	}
+	if transaction.Status != status {
+		return fmt.Errorf("expected purchase to be %q, got %q", status, transaction.Status)
+	}
+	return nil
+}
+
+func (tc *TestContext) reviewPurchase(review func(ctx context.Context, householdID, id string) (*model.Transaction, error)) error {
+	transaction, ok := tc.CurrentTransaction.(*model.Transaction)
+	if !ok {
+		return fmt.Errorf("no current transaction")
+	}
+
+	reviewed, err := review(context.Background(), tc.householdID(), transaction.ID)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentTransaction = reviewed
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) iApproveThePurchase() error {
+	return tc.reviewPurchase(tc.TransactionService.Approve)
+}
+
+func (tc *TestContext) iRejectThePurchase() error {
+	return tc.reviewPurchase(tc.TransactionService.Reject)
+}
+
+func (tc *TestContext) thereShouldBeNPurchasesWaitingForApproval(expected int) error {
+	pending, err := tc.TransactionService.GetPendingApproval(context.Background(), tc.householdID())
+	if err != nil {
+		return fmt.Errorf("failed to list purchases waiting for approval: %w", err)
+	}
+	if len(pending) != expected {
+		return fmt.Errorf("expected %d purchases waiting for approval, got %d", expected, len(pending))
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerReconciliationSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^I record a transaction "([^"]*)" of (-?\d+) on "([^"]*)"$`, tc.iRecordATransactionOfOn)
+	ctx.Step(`^I start a reconciliation on "([^"]*)" with closing balance (-?\d+)$`, tc.iStartAReconciliationOnWithClosingBalance)
+	ctx.Step(`^I clear the transaction "([^"]*)"$`, tc.iClearTheTransaction)
+	ctx.Step(`^I complete the reconciliation$`, tc.iCompleteTheReconciliation)
+	ctx.Step(`^I complete the reconciliation with an adjustment$`, tc.iCompleteTheReconciliationWithAnAdjustment)
+	ctx.Step(`^I change the description of transaction "([^"]*)" to "([^"]*)"$`, tc.iChangeTheDescriptionOfTransactionTo)
+	ctx.Step(`^I unlock the transaction "([^"]*)"$`, tc.iUnlockTheTransaction)
+	ctx.Step(`^the reconciliation difference should be (-?\d+)$`, tc.theReconciliationDifferenceShouldBe)
+	ctx.Step(`^the reconciliation should list (\d+) transactions$`, tc.theReconciliationShouldListNTransactions)
+	ctx.Step(`^the reconciliation should be completed$`, tc.theReconciliationShouldBeCompleted)
+	ctx.Step(`^the transaction "([^"]*)" should be "([^"]*)"$`, tc.theTransactionShouldHaveStatus)
+}
+
+func (tc *TestContext) transactionByDescription(description string) (*model.Transaction, error) {
+	page, err := tc.TransactionService.GetAll(context.Background(), tc.householdID(), &model.TransactionFilters{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range page.Transactions {
+		if t.Description == description {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("no transaction with description %q", description)
+}
+
+func (tc *TestContext) iRecordATransactionOfOn(description string, amount int64, date string) error {
+	user, ok := tc.CurrentUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no current user")
+	}
+
+	account, ok := tc.CurrentAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no current account")
+	}
+
+	req := &model.CreateTransactionRequest{
+		AccountID:   account.ID,
+		Amount:      amount,
+		Type:        "expense",
+		Description: description,
+		Date:        date,
+		IsShared:    true,
+	}
+	if amount > 0 {
+		req.Type = "income"
+	}
+
+	if _, err := tc.TransactionService.Create(context.Background(), user.ID, req); err != nil {
+		return fmt.Errorf("failed to create transaction: %w", err)
+	}
+	return nil
+}
+
+func (tc *TestContext) iStartAReconciliationOnWithClosingBalance(date string, closingBalance int64) error {
+	account, ok := tc.CurrentAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no current account")
+	}
+
+	rec, err := tc.ReconciliationService.Start(context.Background(), tc.householdID(), &model.CreateReconciliationRequest{
+		AccountID:      account.ID,
+		StatementDate:  date,
+		ClosingBalance: closingBalance,
+	})
+	tc.LastError = err
+	if err == nil {
+		tc.CurrentReconciliation = rec
+	}
+	return nil
+}
+
+func (tc *TestContext) iClearTheTransaction(description string) error {
+	if tc.CurrentReconciliation == nil {
+		return fmt.Errorf("no current reconciliation")
+	}
+
+	transaction, err := tc.transactionByDescription(description)
+	if err != nil {
+		return err
+	}
+
+	rec, err := tc.ReconciliationService.Clear(context.Background(), tc.householdID(), tc.CurrentReconciliation.ID, &model.ClearTransactionsRequest{
+		TransactionIDs: []string{transaction.ID},
+		Cleared:        true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear transaction: %w", err)
+	}
+
+	tc.CurrentReconciliation = rec
+	return nil
+}
+
+func (tc *TestContext) completeReconciliation(req *model.CompleteReconciliationRequest) error {
+	if tc.CurrentReconciliation == nil {
+		return fmt.Errorf("no current reconciliation")
+	}
+
+	user, ok := tc.CurrentUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no current user")
+	}
+
+	rec, err := tc.ReconciliationService.Complete(context.Background(), tc.householdID(), user.ID, tc.CurrentReconciliation.ID, req)
+	tc.LastError = err
+	if err == nil {
+		tc.CurrentReconciliation = rec
+	}
+	return nil
+}
+
+func (tc *TestContext) iCompleteTheReconciliation() error {
+	return tc.completeReconciliation(&model.CompleteReconciliationRequest{})
+}
+
+func (tc *TestContext) iCompleteTheReconciliationWithAnAdjustment() error {
+	return tc.completeReconciliation(&model.CompleteReconciliationRequest{Adjust: true})
+}
+
+func (tc *TestContext) iChangeTheDescriptionOfTransactionTo(description, newDescription string) error {
+	transaction, err := tc.transactionByDescription(description)
+	if err != nil {
+		return err
+	}
+
+	tc.CurrentTransaction = transaction
+	return tc.updateCurrentTransaction(&model.UpdateTransactionRequest{Description: &newDescription})
+}
+
+func (tc *TestContext) iUnlockTheTransaction(description string) error {
+	transaction, err := tc.transactionByDescription(description)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tc.TransactionService.Unlock(context.Background(), tc.householdID(), transaction.ID); err != nil {
+		return fmt.Errorf("failed to unlock transaction: %w", err)
+	}
+	return nil
+}
+
+func (tc *TestContext) theReconciliationDifferenceShouldBe(expected int64) error {
+	if tc.CurrentReconciliation == nil {
+		return fmt.Errorf("no current reconciliation (last error: %v)", tc.LastError)
+	}
+	if tc.CurrentReconciliation.Difference == nil {
+		return fmt.Errorf("reconciliation has no difference; is it still open?")
+	}
+	if *tc.CurrentReconciliation.Difference != expected {
+		return fmt.Errorf("expected difference %d, got %d", expected, *tc.CurrentReconciliation.Difference)
+	}
+	return nil
+}
+
+func (tc *TestContext) theReconciliationShouldListNTransactions(expected int) error {
+	if tc.CurrentReconciliation == nil {
+		return fmt.Errorf("no current reconciliation")
+	}
+	if len(tc.CurrentReconciliation.Transactions) != expected {
+		return fmt.Errorf("expected %d transactions, got %d", expected, len(tc.CurrentReconciliation.Transactions))
+	}
+	return nil
+}
+
+func (tc *TestContext) theReconciliationShouldBeCompleted() error {
+	if tc.LastError != nil {
+		return fmt.Errorf("expected reconciliation to complete, got error: %v", tc.LastError)
+	}
+	if tc.CurrentReconciliation.Status != model.ReconciliationCompleted {
+		return fmt.Errorf("expected status %q, got %q", model.ReconciliationCompleted, tc.CurrentReconciliation.Status)
+	}
+	return nil
+}
+
+func (tc *TestContext) theTransactionShouldHaveStatus(description, status string) error {
+	transaction, err := tc.transactionByDescription(description)
+	if err != nil {
+		return err
+	}
+
+	if transaction.Status != status {
+		return fmt.Errorf("expected transaction %q to be %s, got %s", description, status, transaction.Status)
+	}
+	return nil
+}
@@ -102,12 +102,12 @@ func (tc *TestContext) theGeneratedTransactionsShouldHaveAmounts(expectedAmount
 	}
 
 	// Fetch all non-recurring transactions to verify amounts
-	transactions, err := tc.TransactionService.GetByUserID(context.Background(), user.ID, &model.TransactionFilters{})
+	page, err := tc.TransactionService.GetByUserID(context.Background(), user.ID, &model.TransactionFilters{})
 	if err != nil {
 		return fmt.Errorf("failed to get transactions: %w", err)
 	}
 
-	for _, t := range transactions {
+	for _, t := range page.Transactions {
 		if !t.IsRecurring && t.Amount != expectedAmount {
 			return fmt.Errorf("expected amount %d, got %d for transaction %s", expectedAmount, t.Amount, t.ID)
 		}
@@ -36,7 +36,7 @@ func (tc *TestContext) iCreateUserWithRole(email, password, name, role string) e
 		Role:     role,
 	}
 
-	user, err := tc.AuthService.CreateUser(context.Background(), req)
+	user, err := tc.AuthService.CreateUser(context.Background(), tc.householdID(), req)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -76,7 +76,7 @@ func (tc *TestContext) aUserExistsWithRole(email, password, name, role string) e
 		Role:     role,
 	}
 
-	user, err := tc.AuthService.CreateUser(context.Background(), req)
+	user, err := tc.AuthService.CreateUser(context.Background(), tc.householdID(), req)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -86,7 +86,7 @@ func (tc *TestContext) aUserExistsWithRole(email, password, name, role string) e
 }
 
 func (tc *TestContext) iListAllUsers() error {
-	users, err := tc.AuthService.ListUsers(context.Background())
+	users, err := tc.AuthService.ListUsers(context.Background(), tc.householdID())
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -118,7 +118,7 @@ func (tc *TestContext) iUpdateTheUserRoleTo(newRole string) error {
 		Role: &newRole,
 	}
 
-	updated, err := tc.AuthService.UpdateUser(context.Background(), user.ID, req)
+	updated, err := tc.AuthService.UpdateUser(context.Background(), tc.householdID(), user.ID, req)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -146,7 +146,7 @@ func (tc *TestContext) iDeleteTheUser() error {
 		return fmt.Errorf("no created user to delete")
 	}
 
-	err := tc.AuthService.DeleteUser(context.Background(), user.ID)
+	err := tc.AuthService.DeleteUser(context.Background(), tc.householdID(), user.ID)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -171,7 +171,7 @@ func (tc *TestContext) aChildUserExists(email string) error {
 		Role:     "child",
 	}
 
-	user, err := tc.AuthService.CreateUser(context.Background(), req)
+	user, err := tc.AuthService.CreateUser(context.Background(), tc.householdID(), req)
 	if err != nil {
 		return fmt.Errorf("failed to create child user: %w", err)
 	}
@@ -189,7 +189,7 @@ func (tc *TestContext) aChildUserExistsWithAccountAndTransaction(email string, a
 		Role:     "child",
 	}
 
-	childUser, err := tc.AuthService.CreateUser(context.Background(), req)
+	childUser, err := tc.AuthService.CreateUser(context.Background(), tc.householdID(), req)
 	if err != nil {
 		return fmt.Errorf("failed to create child user: %w", err)
 	}
@@ -230,14 +230,14 @@ func (tc *TestContext) iListTransactionsForChildUser() error {
 		return fmt.Errorf("no child user set")
 	}
 
-	transactions, err := tc.TransactionService.GetByUserID(context.Background(), childUser.ID, &model.TransactionFilters{})
+	page, err := tc.TransactionService.GetByUserID(context.Background(), childUser.ID, &model.TransactionFilters{})
 	if err != nil {
 		tc.LastError = err
 		return nil
 	}
 
-	tc.TransactionList = make([]any, len(transactions))
-	for i, t := range transactions {
+	tc.TransactionList = make([]any, len(page.Transactions))
+	for i, t := range page.Transactions {
 		tc.TransactionList[i] = t
 	}
 	tc.LastError = nil
@@ -325,14 +325,14 @@ func (tc *TestContext) iFilterTransactionsByIsShared(isSharedStr string) error {
 		IsShared: &isShared,
 	}
 
-	transactions, err := tc.TransactionService.GetByUserID(context.Background(), user.ID, filters)
+	page, err := tc.TransactionService.GetByUserID(context.Background(), user.ID, filters)
 	if err != nil {
 		tc.LastError = err
 		return nil
 	}
 
-	tc.TransactionList = make([]any, len(transactions))
-	for i, t := range transactions {
+	tc.TransactionList = make([]any, len(page.Transactions))
+	for i, t := range page.Transactions {
 		tc.TransactionList[i] = t
 	}
 	tc.LastError = nil
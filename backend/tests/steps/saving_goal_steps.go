@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/asilingas/fambudg/backend/internal/model"
 	"github.com/cucumber/godog"
@@ -23,6 +24,25 @@ func registerSavingGoalSteps(ctx *godog.ScenarioContext, tc *TestContext) {
 	ctx.Step(`^I contribute (\d+) to the saving goal$`, tc.iContributeToSavingGoal)
 	ctx.Step(`^the saving goal current amount should be (\d+)$`, tc.theSavingGoalCurrentAmountShouldBe)
 	ctx.Step(`^the saving goal status should be "([^"]*)"$`, tc.theSavingGoalStatusShouldBe)
+	ctx.Step(`^a saving goal "([^"]*)" exists with target (\d+) in the second account$`, tc.aSavingGoalExistsWithTargetInTheSecondAccount)
+	ctx.Step(`^I contribute (\d+) to the saving goal from the current account$`, tc.iContributeToTheSavingGoalFromTheCurrentAccount)
+	ctx.Step(`^I withdraw (\d+) from the saving goal to the current account$`, tc.iWithdrawFromTheSavingGoalToTheCurrentAccount)
+	ctx.Step(`^I withdraw (\d+) from the saving goal$`, tc.iWithdrawFromTheSavingGoal)
+	ctx.Step(`^the second account balance should be (-?\d+)$`, tc.theSecondAccountBalanceShouldBe)
+	ctx.Step(`^the saving goal should have (\d+) contributions?$`, tc.theSavingGoalShouldHaveNContributions)
+	ctx.Step(`^the latest goal contribution should have amount (-?\d+) and a transfer$`, tc.theLatestGoalContributionShouldHaveAmountAndATransfer)
+	ctx.Step(`^I delete the transfer of the latest goal contribution$`, tc.iDeleteTheTransferOfTheLatestGoalContribution)
+	ctx.Step(`^a saving goal "([^"]*)" exists with target (\d+) due on "([^"]*)"$`, tc.aSavingGoalExistsWithTargetDueOn)
+	ctx.Step(`^I contribute (\d+) to the saving goal on "([^"]*)"$`, tc.iContributeToTheSavingGoalOn)
+	ctx.Step(`^I get the saving goal projection on "([^"]*)"$`, tc.iGetTheSavingGoalProjectionOn)
+	ctx.Step(`^I list saving goals on "([^"]*)"$`, tc.iListSavingGoalsOn)
+	ctx.Step(`^the goal projection status should be "([^"]*)"$`, tc.theGoalProjectionStatusShouldBe)
+	ctx.Step(`^the goal projection monthly pace should be (-?\d+)$`, tc.theGoalProjectionMonthlyPaceShouldBe)
+	ctx.Step(`^the goal projection should require (\d+) a month$`, tc.theGoalProjectionShouldRequireAMonth)
+	ctx.Step(`^the goal projection should not require a monthly amount$`, tc.theGoalProjectionShouldNotRequireAMonthlyAmount)
+	ctx.Step(`^the goal should be projected to complete on "([^"]*)"$`, tc.theGoalShouldBeProjectedToCompleteOn)
+	ctx.Step(`^the goal should have no projected completion$`, tc.theGoalShouldHaveNoProjectedCompletion)
+	ctx.Step(`^the listed saving goal "([^"]*)" should have projection status "([^"]*)"$`, tc.theListedSavingGoalShouldHaveProjectionStatus)
 }
 
 func (tc *TestContext) iCreateSavingGoalWith(table *godog.Table) error {
@@ -43,7 +63,7 @@ func (tc *TestContext) iCreateSavingGoalWith(table *godog.Table) error {
 		Priority:     priority,
 	}
 
-	goal, err := tc.SavingGoalService.Create(context.Background(), req)
+	goal, err := tc.SavingGoalService.Create(context.Background(), tc.householdID(), req)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -87,7 +107,7 @@ func (tc *TestContext) theFollowingSavingGoalsExist(table *godog.Table) error {
 			Priority:     priority,
 		}
 
-		_, err := tc.SavingGoalService.Create(context.Background(), req)
+		_, err := tc.SavingGoalService.Create(context.Background(), tc.householdID(), req)
 		if err != nil {
 			return fmt.Errorf("failed to create saving goal: %w", err)
 		}
@@ -97,7 +117,7 @@ func (tc *TestContext) theFollowingSavingGoalsExist(table *godog.Table) error {
 }
 
 func (tc *TestContext) iListSavingGoals() error {
-	goals, err := tc.SavingGoalService.GetAll(context.Background())
+	goals, err := tc.SavingGoalService.GetAll(context.Background(), tc.householdID(), time.Now())
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -127,7 +147,7 @@ func (tc *TestContext) aSavingGoalExistsWithTarget(name string, targetAmount int
 		Priority:     1,
 	}
 
-	goal, err := tc.SavingGoalService.Create(context.Background(), req)
+	goal, err := tc.SavingGoalService.Create(context.Background(), tc.householdID(), req)
 	if err != nil {
 		return fmt.Errorf("failed to create saving goal: %w", err)
 	}
@@ -146,7 +166,7 @@ func (tc *TestContext) iUpdateSavingGoalWithName(newName string) error {
 		Name: &newName,
 	}
 
-	updated, err := tc.SavingGoalService.Update(context.Background(), goal.ID, req)
+	updated, err := tc.SavingGoalService.Update(context.Background(), tc.householdID(), goal.ID, req)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -186,7 +206,12 @@ func (tc *TestContext) iContributeToSavingGoal(amount int64) error {
 		Amount: amount,
 	}
 
-	updated, err := tc.SavingGoalService.Contribute(context.Background(), goal.ID, req)
+	userID, err := tc.currentUserID()
+	if err != nil {
+		return err
+	}
+
+	updated, err := tc.SavingGoalService.Contribute(context.Background(), tc.householdID(), userID, goal.ID, req)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -220,3 +245,326 @@ func (tc *TestContext) theSavingGoalStatusShouldBe(expectedStatus string) error
 	}
 	return nil
 }
+
+func (tc *TestContext) aSavingGoalExistsWithTargetInTheSecondAccount(name string, target int64) error {
+	account, ok := tc.SecondAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no second account")
+	}
+
+	goal, err := tc.SavingGoalService.Create(context.Background(), tc.householdID(), &model.CreateSavingGoalRequest{
+		Name:         name,
+		TargetAmount: target,
+		AccountID:    &account.ID,
+		Priority:     1,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create saving goal: %w", err)
+	}
+
+	tc.CurrentSavingGoal = goal
+	return nil
+}
+
+func (tc *TestContext) iContributeToTheSavingGoalFromTheCurrentAccount(amount int64) error {
+	account, ok := tc.CurrentAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no current account")
+	}
+
+	return tc.moveGoalMoney(func(userID, goalID string) (*model.SavingGoal, error) {
+		return tc.SavingGoalService.Contribute(context.Background(), tc.householdID(), userID, goalID, &model.ContributeRequest{
+			Amount:        amount,
+			FromAccountID: &account.ID,
+		})
+	})
+}
+
+func (tc *TestContext) iWithdrawFromTheSavingGoalToTheCurrentAccount(amount int64) error {
+	account, ok := tc.CurrentAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no current account")
+	}
+
+	return tc.moveGoalMoney(func(userID, goalID string) (*model.SavingGoal, error) {
+		return tc.SavingGoalService.Withdraw(context.Background(), tc.householdID(), userID, goalID, &model.WithdrawRequest{
+			Amount:      amount,
+			ToAccountID: &account.ID,
+		})
+	})
+}
+
+func (tc *TestContext) iWithdrawFromTheSavingGoal(amount int64) error {
+	return tc.moveGoalMoney(func(userID, goalID string) (*model.SavingGoal, error) {
+		return tc.SavingGoalService.Withdraw(context.Background(), tc.householdID(), userID, goalID, &model.WithdrawRequest{Amount: amount})
+	})
+}
+
+// moveGoalMoney runs a contribution or withdrawal by the current user on the
+// current saving goal
+func (tc *TestContext) moveGoalMoney(move func(userID, goalID string) (*model.SavingGoal, error)) error {
+	goal, ok := tc.CurrentSavingGoal.(*model.SavingGoal)
+	if !ok {
+		return fmt.Errorf("no current saving goal")
+	}
+
+	userID, err := tc.currentUserID()
+	if err != nil {
+		return err
+	}
+
+	updated, err := move(userID, goal.ID)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentSavingGoal = updated
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) theSecondAccountBalanceShouldBe(expected int64) error {
+	account, ok := tc.SecondAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no second account")
+	}
+
+	current, err := tc.AccountService.GetByID(context.Background(), tc.householdID(), account.ID)
+	if err != nil {
+		return fmt.Errorf("failed to reload account: %w", err)
+	}
+
+	if current.Balance != expected {
+		return fmt.Errorf("expected balance %d, got %d", expected, current.Balance)
+	}
+	return nil
+}
+
+func (tc *TestContext) goalContributions() ([]*model.GoalContribution, error) {
+	goal, ok := tc.CurrentSavingGoal.(*model.SavingGoal)
+	if !ok {
+		return nil, fmt.Errorf("no current saving goal")
+	}
+
+	return tc.SavingGoalService.GetContributions(context.Background(), tc.householdID(), goal.ID)
+}
+
+func (tc *TestContext) theSavingGoalShouldHaveNContributions(expected int) error {
+	contributions, err := tc.goalContributions()
+	if err != nil {
+		return err
+	}
+
+	if len(contributions) != expected {
+		return fmt.Errorf("expected %d contributions, got %d", expected, len(contributions))
+	}
+	return nil
+}
+
+func (tc *TestContext) theLatestGoalContributionShouldHaveAmountAndATransfer(expected int64) error {
+	contributions, err := tc.goalContributions()
+	if err != nil {
+		return err
+	}
+	if len(contributions) == 0 {
+		return fmt.Errorf("expected a contribution, got none")
+	}
+
+	latest := contributions[0]
+	if latest.Amount != expected {
+		return fmt.Errorf("expected amount %d, got %d", expected, latest.Amount)
+	}
+	if latest.TransactionID == nil {
+		return fmt.Errorf("expected the contribution to have a transfer")
+	}
+
+	userID, err := tc.currentUserID()
+	if err != nil {
+		return err
+	}
+	if latest.UserID == nil || *latest.UserID != userID {
+		return fmt.Errorf("expected the contribution to be made by the current user, got %v", latest.UserID)
+	}
+	return nil
+}
+
+func (tc *TestContext) iDeleteTheTransferOfTheLatestGoalContribution() error {
+	contributions, err := tc.goalContributions()
+	if err != nil {
+		return err
+	}
+	if len(contributions) == 0 || contributions[0].TransactionID == nil {
+		return fmt.Errorf("expected the latest contribution to have a transfer")
+	}
+
+	if err := tc.TransactionService.Delete(context.Background(), tc.householdID(), *contributions[0].TransactionID); err != nil {
+		return fmt.Errorf("failed to delete transfer: %w", err)
+	}
+
+	goal := tc.CurrentSavingGoal.(*model.SavingGoal)
+	reloaded, err := tc.SavingGoalService.GetByID(context.Background(), tc.householdID(), goal.ID)
+	if err != nil {
+		return err
+	}
+
+	tc.CurrentSavingGoal = reloaded
+	return nil
+}
+
+func (tc *TestContext) aSavingGoalExistsWithTargetDueOn(name string, target int64, targetDate string) error {
+	goal, err := tc.SavingGoalService.Create(context.Background(), tc.householdID(), &model.CreateSavingGoalRequest{
+		Name:         name,
+		TargetAmount: target,
+		TargetDate:   &targetDate,
+		Priority:     1,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create saving goal: %w", err)
+	}
+
+	tc.CurrentSavingGoal = goal
+	return nil
+}
+
+func (tc *TestContext) iContributeToTheSavingGoalOn(amount int64, date string) error {
+	return tc.moveGoalMoney(func(userID, goalID string) (*model.SavingGoal, error) {
+		return tc.SavingGoalService.Contribute(context.Background(), tc.householdID(), userID, goalID, &model.ContributeRequest{
+			Amount: amount,
+			Date:   &date,
+		})
+	})
+}
+
+func (tc *TestContext) iGetTheSavingGoalProjectionOn(date string) error {
+	goal, ok := tc.CurrentSavingGoal.(*model.SavingGoal)
+	if !ok {
+		return fmt.Errorf("no current saving goal")
+	}
+
+	today, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return err
+	}
+
+	projection, err := tc.SavingGoalService.GetProjection(context.Background(), tc.householdID(), goal.ID, today)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.GoalProjection = projection
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) iListSavingGoalsOn(date string) error {
+	today, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return err
+	}
+
+	goals, err := tc.SavingGoalService.GetAll(context.Background(), tc.householdID(), today)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.SavingGoalList = make([]any, len(goals))
+	for i, g := range goals {
+		tc.SavingGoalList[i] = g
+	}
+
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) theGoalProjectionStatusShouldBe(expected string) error {
+	if tc.GoalProjection == nil {
+		return fmt.Errorf("no goal projection")
+	}
+
+	if tc.GoalProjection.Status != expected {
+		return fmt.Errorf("expected projection status %q, got %q", expected, tc.GoalProjection.Status)
+	}
+	return nil
+}
+
+func (tc *TestContext) theGoalProjectionMonthlyPaceShouldBe(expected int64) error {
+	if tc.GoalProjection == nil {
+		return fmt.Errorf("no goal projection")
+	}
+
+	if tc.GoalProjection.MonthlyPace != expected {
+		return fmt.Errorf("expected monthly pace %d, got %d", expected, tc.GoalProjection.MonthlyPace)
+	}
+	return nil
+}
+
+func (tc *TestContext) theGoalProjectionShouldRequireAMonth(expected int64) error {
+	if tc.GoalProjection == nil {
+		return fmt.Errorf("no goal projection")
+	}
+
+	if tc.GoalProjection.RequiredMonthly == nil {
+		return fmt.Errorf("expected %d required a month, got none", expected)
+	}
+	if *tc.GoalProjection.RequiredMonthly != expected {
+		return fmt.Errorf("expected %d required a month, got %d", expected, *tc.GoalProjection.RequiredMonthly)
+	}
+	return nil
+}
+
+func (tc *TestContext) theGoalProjectionShouldNotRequireAMonthlyAmount() error {
+	if tc.GoalProjection == nil {
+		return fmt.Errorf("no goal projection")
+	}
+
+	if tc.GoalProjection.RequiredMonthly != nil {
+		return fmt.Errorf("expected no required monthly amount, got %d", *tc.GoalProjection.RequiredMonthly)
+	}
+	return nil
+}
+
+func (tc *TestContext) theGoalShouldBeProjectedToCompleteOn(expected string) error {
+	if tc.GoalProjection == nil {
+		return fmt.Errorf("no goal projection")
+	}
+
+	if tc.GoalProjection.ProjectedCompletion == nil {
+		return fmt.Errorf("expected projected completion %s, got none", expected)
+	}
+	if actual := tc.GoalProjection.ProjectedCompletion.Format("2006-01-02"); actual != expected {
+		return fmt.Errorf("expected projected completion %s, got %s", expected, actual)
+	}
+	return nil
+}
+
+func (tc *TestContext) theGoalShouldHaveNoProjectedCompletion() error {
+	if tc.GoalProjection == nil {
+		return fmt.Errorf("no goal projection")
+	}
+
+	if tc.GoalProjection.ProjectedCompletion != nil {
+		return fmt.Errorf("expected no projected completion, got %s", tc.GoalProjection.ProjectedCompletion.Format("2006-01-02"))
+	}
+	return nil
+}
+
+func (tc *TestContext) theListedSavingGoalShouldHaveProjectionStatus(name, expected string) error {
+	for _, item := range tc.SavingGoalList {
+		goal := item.(*model.SavingGoal)
+		if goal.Name != name {
+			continue
+		}
+		if goal.Projection == nil {
+			return fmt.Errorf("saving goal %q has no projection", name)
+		}
+		if goal.Projection.Status != expected {
+			return fmt.Errorf("expected saving goal %q projection status %q, got %q", name, expected, goal.Projection.Status)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("saving goal %q not found", name)
+}
@@ -0,0 +1,208 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/scheduler"
+	"github.com/cucumber/godog"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func registerSchedulerSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^member "([^"]*)" has a recurring transaction of (-?\d+) on "([^"]*)" with frequency "([^"]*)"$`, tc.memberHasARecurringTransaction)
+	ctx.Step(`^the scheduler runs on "([^"]*)"$`, tc.theSchedulerRunsOn)
+	ctx.Step(`^the last "([^"]*)" job run should have status "([^"]*)" and summary "([^"]*)"$`, tc.theLastJobRunShouldHave)
+	ctx.Step(`^(\d+) job runs should be recorded$`, tc.nJobRunsShouldBeRecorded)
+	ctx.Step(`^(\d+) "([^"]*)" job runs should be recorded$`, tc.nNamedJobRunsShouldBeRecorded)
+	ctx.Step(`^the bill reminder "([^"]*)" should be overdue$`, tc.theBillReminderShouldBeOverdue)
+	ctx.Step(`^the bill reminder "([^"]*)" should not be overdue$`, tc.theBillReminderShouldNotBeOverdue)
+	ctx.Step(`^another replica holds the scheduler lock$`, tc.anotherReplicaHoldsTheSchedulerLock)
+	ctx.Step(`^the scheduler run should have been skipped$`, tc.theSchedulerRunShouldHaveBeenSkipped)
+}
+
+func (tc *TestContext) memberHasARecurringTransaction(email string, amount int64, dateStr, frequency string) error {
+	ctx := context.Background()
+
+	category, ok := tc.CurrentCategory.(*model.Category)
+	if !ok {
+		return fmt.Errorf("no current category")
+	}
+
+	member, err := tc.AuthService.CreateUser(ctx, tc.householdID(), &model.CreateUserRequest{
+		Email:    email,
+		Password: "password123",
+		Name:     "Member",
+		Role:     "member",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create member: %w", err)
+	}
+
+	account, err := tc.AccountService.Create(ctx, member.ID, &model.CreateAccountRequest{
+		Name:     "Member Account",
+		Type:     "checking",
+		Currency: "EUR",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create member account: %w", err)
+	}
+
+	_, err = tc.TransactionService.Create(ctx, member.ID, &model.CreateTransactionRequest{
+		AccountID:     account.ID,
+		CategoryID:    category.ID,
+		Amount:        amount,
+		Type:          "expense",
+		Description:   "Recurring " + frequency,
+		Date:          dateStr,
+		IsRecurring:   true,
+		RecurringRule: &model.RecurringRule{Frequency: frequency},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create member recurring transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (tc *TestContext) theSchedulerRunsOn(dateStr string) error {
+	now, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return fmt.Errorf("invalid date: %w", err)
+	}
+
+	ran, err := tc.Scheduler.RunDue(context.Background(), now)
+	if err != nil {
+		return fmt.Errorf("scheduler run failed: %w", err)
+	}
+
+	tc.SchedulerRan = ran
+	return nil
+}
+
+func (tc *TestContext) theLastJobRunShouldHave(jobName, expectedStatus, expectedSummary string) error {
+	runs, err := tc.JobRunRepo.FindRecent(context.Background(), 100)
+	if err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		if run.JobName != jobName {
+			continue
+		}
+		if run.Status != expectedStatus {
+			return fmt.Errorf("expected status %q, got %q (error: %v)", expectedStatus, run.Status, run.Error)
+		}
+		if run.Summary != expectedSummary {
+			return fmt.Errorf("expected summary %q, got %q", expectedSummary, run.Summary)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("no %q job run recorded", jobName)
+}
+
+func (tc *TestContext) nJobRunsShouldBeRecorded(expected int) error {
+	runs, err := tc.JobRunRepo.FindRecent(context.Background(), 100)
+	if err != nil {
+		return err
+	}
+
+	if len(runs) != expected {
+		return fmt.Errorf("expected %d job runs, got %d", expected, len(runs))
+	}
+	return nil
+}
+
+func (tc *TestContext) nNamedJobRunsShouldBeRecorded(expected int, jobName string) error {
+	runs, err := tc.JobRunRepo.FindRecent(context.Background(), 100)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	for _, run := range runs {
+		if run.JobName == jobName {
+			count++
+		}
+	}
+
+	if count != expected {
+		return fmt.Errorf("expected %d %q job runs, got %d", expected, jobName, count)
+	}
+	return nil
+}
+
+func (tc *TestContext) findBillReminderByName(name string) (*model.BillReminder, error) {
+	bills, err := tc.BillReminderService.GetAll(context.Background(), tc.householdID())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, bill := range bills {
+		if bill.Name == name {
+			return bill, nil
+		}
+	}
+
+	return nil, fmt.Errorf("bill reminder %q not found", name)
+}
+
+func (tc *TestContext) theBillReminderShouldBeOverdue(name string) error {
+	bill, err := tc.findBillReminderByName(name)
+	if err != nil {
+		return err
+	}
+
+	if !bill.IsOverdue {
+		return fmt.Errorf("expected bill reminder %q to be overdue", name)
+	}
+	return nil
+}
+
+func (tc *TestContext) theBillReminderShouldNotBeOverdue(name string) error {
+	bill, err := tc.findBillReminderByName(name)
+	if err != nil {
+		return err
+	}
+
+	if bill.IsOverdue {
+		return fmt.Errorf("expected bill reminder %q not to be overdue", name)
+	}
+	return nil
+}
+
+// anotherReplicaHoldsTheSchedulerLock takes the scheduler's advisory lock on a
+// separate connection, the way a second server instance would
+func (tc *TestContext) anotherReplicaHoldsTheSchedulerLock() error {
+	conn, err := tc.Pool.Acquire(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_lock($1)", scheduler.AdvisoryLockKey); err != nil {
+		conn.Release()
+		return fmt.Errorf("failed to take advisory lock: %w", err)
+	}
+
+	tc.SchedulerLockConn = conn
+	return nil
+}
+
+func (tc *TestContext) theSchedulerRunShouldHaveBeenSkipped() error {
+	if tc.SchedulerRan {
+		return fmt.Errorf("expected the scheduler run to be skipped")
+	}
+	return nil
+}
+
+// releaseSchedulerLock gives up the lock taken by anotherReplicaHoldsTheSchedulerLock
+func (tc *TestContext) releaseSchedulerLock() {
+	if conn, ok := tc.SchedulerLockConn.(*pgxpool.Conn); ok {
+		conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", scheduler.AdvisoryLockKey)
+		conn.Release()
+		tc.SchedulerLockConn = nil
+	}
+}
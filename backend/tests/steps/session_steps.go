@@ -0,0 +1,105 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerSessionSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^I refresh my session$`, tc.iRefreshMySession)
+	ctx.Step(`^I refresh my session with the used refresh token$`, tc.iRefreshMySessionWithTheUsedRefreshToken)
+	ctx.Step(`^I log out$`, tc.iLogOut)
+	ctx.Step(`^I revoke all sessions of the user$`, tc.iRevokeAllSessionsOfTheUser)
+	ctx.Step(`^the refresh should succeed$`, tc.theRefreshShouldSucceed)
+	ctx.Step(`^I should receive a new refresh token$`, tc.iShouldReceiveANewRefreshToken)
+	ctx.Step(`^my access token should have role "([^"]*)"$`, tc.myAccessTokenShouldHaveRole)
+	ctx.Step(`^(\d+) sessions should be revoked$`, tc.nSessionsShouldBeRevoked)
+}
+
+func (tc *TestContext) refresh(refreshToken string) error {
+	loginResp, err := tc.AuthService.Refresh(context.Background(), refreshToken)
+	tc.LastError = err
+	if err != nil {
+		return nil
+	}
+
+	tc.UsedRefreshToken = refreshToken
+	tc.CurrentToken = loginResp.Token
+	tc.CurrentRefreshToken = loginResp.RefreshToken
+	return nil
+}
+
+func (tc *TestContext) iRefreshMySession() error {
+	return tc.refresh(tc.CurrentRefreshToken)
+}
+
+func (tc *TestContext) iRefreshMySessionWithTheUsedRefreshToken() error {
+	if tc.UsedRefreshToken == "" {
+		return fmt.Errorf("no refresh token has been used yet")
+	}
+	return tc.refresh(tc.UsedRefreshToken)
+}
+
+func (tc *TestContext) iLogOut() error {
+	if err := tc.AuthService.Logout(context.Background(), tc.CurrentRefreshToken); err != nil {
+		return fmt.Errorf("failed to log out: %w", err)
+	}
+	return nil
+}
+
+func (tc *TestContext) iRevokeAllSessionsOfTheUser() error {
+	user, ok := tc.CreatedUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no created user")
+	}
+
+	result, err := tc.AuthService.RevokeSessions(context.Background(), tc.householdID(), user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	tc.RevokeSessionsResult = result
+	return nil
+}
+
+func (tc *TestContext) theRefreshShouldSucceed() error {
+	if tc.LastError != nil {
+		return fmt.Errorf("expected refresh to succeed, got error: %v", tc.LastError)
+	}
+	return nil
+}
+
+func (tc *TestContext) iShouldReceiveANewRefreshToken() error {
+	if tc.CurrentRefreshToken == "" {
+		return fmt.Errorf("expected a refresh token, got empty string")
+	}
+	if tc.CurrentRefreshToken == tc.UsedRefreshToken {
+		return fmt.Errorf("expected the refresh token to change")
+	}
+	return nil
+}
+
+func (tc *TestContext) myAccessTokenShouldHaveRole(expected string) error {
+	claims, err := tc.AuthService.ValidateToken(tc.CurrentToken)
+	if err != nil {
+		return fmt.Errorf("invalid access token: %w", err)
+	}
+
+	if claims["role"] != expected {
+		return fmt.Errorf("expected role %q, got %v", expected, claims["role"])
+	}
+	return nil
+}
+
+func (tc *TestContext) nSessionsShouldBeRevoked(expected int64) error {
+	if tc.RevokeSessionsResult == nil {
+		return fmt.Errorf("sessions were not revoked")
+	}
+	if tc.RevokeSessionsResult.Revoked != expected {
+		return fmt.Errorf("expected %d revoked sessions, got %d", expected, tc.RevokeSessionsResult.Revoked)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerStatementImportSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^I import the following "([^"]*)" statement:$`, tc.iImportTheFollowingStatement)
+	ctx.Step(`^(\d+) statement lines should be imported$`, tc.nStatementLinesShouldBeImported)
+	ctx.Step(`^(\d+) statement lines should be reported as duplicates$`, tc.nStatementLinesShouldBeReportedAsDuplicates)
+	ctx.Step(`^the statement import should report (\d+) errors$`, tc.theStatementImportShouldReportNErrors)
+}
+
+func (tc *TestContext) iImportTheFollowingStatement(format string, doc *godog.DocString) error {
+	user, ok := tc.CurrentUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no current user")
+	}
+
+	req, err := tc.statementImportRequest(format)
+	if err != nil {
+		return err
+	}
+
+	result, err := tc.StatementImportService.Import(context.Background(), tc.householdID(), user.ID, req, strings.NewReader(doc.Content))
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.StatementImportResult = result
+	tc.LastError = nil
+	return nil
+}
+
+// statementImportRequest targets the current account and category, reading
+// CSV files with the current import profile
+func (tc *TestContext) statementImportRequest(format string) (*model.ImportStatementRequest, error) {
+	account, ok := tc.CurrentAccount.(*model.Account)
+	if !ok {
+		return nil, fmt.Errorf("no current account")
+	}
+
+	category, ok := tc.CurrentCategory.(*model.Category)
+	if !ok {
+		return nil, fmt.Errorf("no current category")
+	}
+
+	req := &model.ImportStatementRequest{
+		AccountID:  account.ID,
+		CategoryID: category.ID,
+		Format:     format,
+	}
+	if format == model.StatementFormatCSV {
+		if tc.CurrentImportProfile == nil {
+			return nil, fmt.Errorf("no current import profile")
+		}
+		req.ProfileID = tc.CurrentImportProfile.ID
+	}
+
+	return req, nil
+}
+
+func (tc *TestContext) statementImportResult() (*model.ImportStatementResponse, error) {
+	if tc.StatementImportResult == nil {
+		return nil, fmt.Errorf("no statement import result, last error: %v", tc.LastError)
+	}
+	return tc.StatementImportResult, nil
+}
+
+func (tc *TestContext) nStatementLinesShouldBeImported(expected int) error {
+	result, err := tc.statementImportResult()
+	if err != nil {
+		return err
+	}
+	if result.Imported != expected {
+		return fmt.Errorf("expected %d statement lines imported, got %d (errors: %v)", expected, result.Imported, result.Errors)
+	}
+	return nil
+}
+
+func (tc *TestContext) nStatementLinesShouldBeReportedAsDuplicates(expected int) error {
+	result, err := tc.statementImportResult()
+	if err != nil {
+		return err
+	}
+	if result.Duplicates != expected {
+		return fmt.Errorf("expected %d duplicate statement lines, got %d", expected, result.Duplicates)
+	}
+	return nil
+}
+
+func (tc *TestContext) theStatementImportShouldReportNErrors(expected int) error {
+	result, err := tc.statementImportResult()
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) != expected {
+		return fmt.Errorf("expected %d import errors, got %d: %v", expected, len(result.Errors), result.Errors)
+	}
+	return nil
+}
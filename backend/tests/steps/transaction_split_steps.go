@@ -0,0 +1,124 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerTransactionSplitSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^I create a transaction of (-?\d+) on "([^"]*)" split as:$`, tc.iCreateATransactionSplitAs)
+	ctx.Step(`^I change the transaction splits to:$`, tc.iChangeTheTransactionSplitsTo)
+	ctx.Step(`^I change the transaction amount to (-?\d+)$`, tc.iChangeTheTransactionAmountTo)
+	ctx.Step(`^the transaction should have (\d+) splits$`, tc.theTransactionShouldHaveNSplits)
+}
+
+// splitRequests reads a table of category names, amounts and optional memos
+func (tc *TestContext) splitRequests(table *godog.Table) ([]model.TransactionSplitRequest, error) {
+	var splits []model.TransactionSplitRequest
+	for _, row := range table.Rows[1:] { // Skip header
+		category, err := tc.categoryByName(row.Cells[0].Value)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := strconv.ParseInt(row.Cells[1].Value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid split amount %q", row.Cells[1].Value)
+		}
+
+		split := model.TransactionSplitRequest{CategoryID: category.ID, Amount: amount}
+		if len(row.Cells) > 2 {
+			split.Memo = row.Cells[2].Value
+		}
+		splits = append(splits, split)
+	}
+	return splits, nil
+}
+
+func (tc *TestContext) iCreateATransactionSplitAs(amount int64, date string, table *godog.Table) error {
+	user, ok := tc.CurrentUser.(*model.User)
+	if !ok {
+		return fmt.Errorf("no current user")
+	}
+
+	account, ok := tc.CurrentAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no current account")
+	}
+
+	splits, err := tc.splitRequests(table)
+	if err != nil {
+		return err
+	}
+
+	req := &model.CreateTransactionRequest{
+		AccountID:   account.ID,
+		Amount:      amount,
+		Type:        "expense",
+		Description: "Supermarket receipt",
+		Date:        date,
+		IsShared:    true,
+		Splits:      splits,
+	}
+	if amount > 0 {
+		req.Type = "income"
+	}
+
+	transaction, err := tc.TransactionService.Create(context.Background(), user.ID, req)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentTransaction = transaction
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) updateCurrentTransaction(req *model.UpdateTransactionRequest) error {
+	transaction, ok := tc.CurrentTransaction.(*model.Transaction)
+	if !ok {
+		return fmt.Errorf("no current transaction")
+	}
+
+	updated, err := tc.TransactionService.Update(context.Background(), tc.householdID(), transaction.ID, req)
+	if err != nil {
+		tc.LastError = err
+		return nil
+	}
+
+	tc.CurrentTransaction = updated
+	tc.LastError = nil
+	return nil
+}
+
+func (tc *TestContext) iChangeTheTransactionSplitsTo(table *godog.Table) error {
+	splits, err := tc.splitRequests(table)
+	if err != nil {
+		return err
+	}
+	return tc.updateCurrentTransaction(&model.UpdateTransactionRequest{Splits: &splits})
+}
+
+func (tc *TestContext) iChangeTheTransactionAmountTo(amount int64) error {
+	return tc.updateCurrentTransaction(&model.UpdateTransactionRequest{Amount: &amount})
+}
+
+func (tc *TestContext) theTransactionShouldHaveNSplits(expected int) error {
+	if tc.LastError != nil {
+		return fmt.Errorf("expected transaction to be saved, got error: %v", tc.LastError)
+	}
+
+	transaction, err := tc.reloadCurrentTransaction()
+	if err != nil {
+		return err
+	}
+
+	if len(transaction.Splits) != expected {
+		return fmt.Errorf("expected %d splits, got %d", expected, len(transaction.Splits))
+	}
+	return nil
+}
@@ -36,7 +36,7 @@ func (tc *TestContext) aCategoryExists(name, categoryType string) error {
 		SortOrder: 0,
 	}
 
-	category, err := tc.CategoryService.Create(context.Background(), req)
+	category, err := tc.CategoryService.Create(context.Background(), tc.householdID(), req)
 	if err != nil {
 		return fmt.Errorf("failed to create category: %w", err)
 	}
@@ -193,14 +193,14 @@ func (tc *TestContext) iListTransactionsFromTo(startDate, endDate string) error
 		EndDate:   endDate,
 	}
 
-	transactions, err := tc.TransactionService.GetByUserID(context.Background(), user.ID, filters)
+	page, err := tc.TransactionService.GetByUserID(context.Background(), user.ID, filters)
 	if err != nil {
 		tc.LastError = err
 		return nil
 	}
 
-	tc.TransactionList = make([]interface{}, len(transactions))
-	for i, t := range transactions {
+	tc.TransactionList = make([]interface{}, len(page.Transactions))
+	for i, t := range page.Transactions {
 		tc.TransactionList[i] = t
 	}
 
@@ -265,7 +265,7 @@ func (tc *TestContext) iUpdateTransactionWithDescription(newDescription string)
 		Description: &newDescription,
 	}
 
-	updated, err := tc.TransactionService.Update(context.Background(), transaction.ID, req)
+	updated, err := tc.TransactionService.Update(context.Background(), tc.householdID(), transaction.ID, req)
 	if err != nil {
 		tc.LastError = err
 		return nil
@@ -301,7 +301,7 @@ func (tc *TestContext) iDeleteTheTransaction() error {
 		return fmt.Errorf("no current transaction")
 	}
 
-	err := tc.TransactionService.Delete(context.Background(), transaction.ID)
+	err := tc.TransactionService.Delete(context.Background(), tc.householdID(), transaction.ID)
 	if err != nil {
 		tc.LastError = err
 		return nil
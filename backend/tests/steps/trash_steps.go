@@ -0,0 +1,150 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/cucumber/godog"
+)
+
+func registerTrashSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^I delete the account$`, tc.iDeleteTheAccount)
+	ctx.Step(`^I delete the category$`, tc.iDeleteTheCategory)
+	ctx.Step(`^I delete the saving goal$`, tc.iDeleteTheSavingGoal)
+	ctx.Step(`^I restore the (transaction|account|category|budget|saving goal) from the trash$`, tc.iRestoreFromTheTrash)
+	ctx.Step(`^the trash should contain the (transaction|account|category|budget|saving goal)$`, tc.theTrashShouldContain)
+	ctx.Step(`^the trash should not contain the (transaction|account|category|budget|saving goal)$`, tc.theTrashShouldNotContain)
+	ctx.Step(`^the trash should be empty$`, tc.theTrashShouldBeEmpty)
+	ctx.Step(`^the trash is purged (\d+) days from now$`, tc.theTrashIsPurgedDaysFromNow)
+}
+
+func (tc *TestContext) iDeleteTheAccount() error {
+	account, ok := tc.CurrentAccount.(*model.Account)
+	if !ok {
+		return fmt.Errorf("no current account")
+	}
+
+	tc.LastError = tc.AccountService.Delete(context.Background(), tc.householdID(), account.ID)
+	return nil
+}
+
+func (tc *TestContext) iDeleteTheCategory() error {
+	category, ok := tc.CurrentCategory.(*model.Category)
+	if !ok {
+		return fmt.Errorf("no current category")
+	}
+
+	tc.LastError = tc.CategoryService.Delete(context.Background(), tc.householdID(), category.ID)
+	return nil
+}
+
+func (tc *TestContext) iDeleteTheSavingGoal() error {
+	goal, ok := tc.CurrentSavingGoal.(*model.SavingGoal)
+	if !ok {
+		return fmt.Errorf("no current saving goal")
+	}
+
+	tc.LastError = tc.SavingGoalService.Delete(context.Background(), tc.householdID(), goal.ID)
+	return nil
+}
+
+// currentTrashItem returns the trash type and ID of the current item of the
+// kind named in a step
+func (tc *TestContext) currentTrashItem(kind string) (string, string, error) {
+	switch kind {
+	case "transaction":
+		if transaction, ok := tc.CurrentTransaction.(*model.Transaction); ok {
+			return model.TrashTransaction, transaction.ID, nil
+		}
+	case "account":
+		if account, ok := tc.CurrentAccount.(*model.Account); ok {
+			return model.TrashAccount, account.ID, nil
+		}
+	case "category":
+		if category, ok := tc.CurrentCategory.(*model.Category); ok {
+			return model.TrashCategory, category.ID, nil
+		}
+	case "budget":
+		if budget, ok := tc.CurrentBudget.(*model.Budget); ok {
+			return model.TrashBudget, budget.ID, nil
+		}
+	case "saving goal":
+		if goal, ok := tc.CurrentSavingGoal.(*model.SavingGoal); ok {
+			return model.TrashSavingGoal, goal.ID, nil
+		}
+	}
+	return "", "", fmt.Errorf("no current %s", kind)
+}
+
+func (tc *TestContext) iRestoreFromTheTrash(kind string) error {
+	itemType, id, err := tc.currentTrashItem(kind)
+	if err != nil {
+		return err
+	}
+
+	tc.LastError = tc.TrashService.Restore(context.Background(), tc.householdID(), itemType, id)
+	return nil
+}
+
+func (tc *TestContext) trashContains(kind string) (bool, error) {
+	itemType, id, err := tc.currentTrashItem(kind)
+	if err != nil {
+		return false, err
+	}
+
+	items, err := tc.TrashService.GetAll(context.Background(), tc.householdID(), nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	for _, item := range items {
+		if item.Type == itemType && item.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (tc *TestContext) theTrashShouldContain(kind string) error {
+	found, err := tc.trashContains(kind)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("expected the %s in the trash", kind)
+	}
+	return nil
+}
+
+func (tc *TestContext) theTrashShouldNotContain(kind string) error {
+	found, err := tc.trashContains(kind)
+	if err != nil {
+		return err
+	}
+	if found {
+		return fmt.Errorf("expected the %s not to be listed in the trash", kind)
+	}
+	return nil
+}
+
+func (tc *TestContext) theTrashShouldBeEmpty() error {
+	items, err := tc.TrashService.GetAll(context.Background(), tc.householdID(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	if len(items) != 0 {
+		return fmt.Errorf("expected an empty trash, got %d items", len(items))
+	}
+	return nil
+}
+
+func (tc *TestContext) theTrashIsPurgedDaysFromNow(days int) error {
+	_, err := tc.TrashService.PurgeExpired(context.Background(), time.Now().AddDate(0, 0, days))
+	if err != nil {
+		return fmt.Errorf("failed to purge trash: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/asilingas/fambudg/backend/internal/model"
+	"github.com/asilingas/fambudg/backend/internal/service"
+	"github.com/asilingas/fambudg/backend/internal/totp"
+	"github.com/cucumber/godog"
+)
+
+func registerTwoFactorSteps(ctx *godog.ScenarioContext, tc *TestContext) {
+	ctx.Step(`^I set up two-factor authentication$`, tc.iSetUpTwoFactorAuthentication)
+	ctx.Step(`^I enable two-factor authentication with a valid code$`, tc.iEnableTwoFactorAuthenticationWithAValidCode)
+	ctx.Step(`^I enable two-factor authentication with code "([^"]*)"$`, tc.iEnableTwoFactorAuthenticationWithCode)
+	ctx.Step(`^two-factor authentication is enabled for me$`, tc.twoFactorAuthenticationIsEnabledForMe)
+	ctx.Step(`^I verify the login with a valid code$`, tc.iVerifyTheLoginWithAValidCode)
+	ctx.Step(`^I verify the login with a recovery code$`, tc.iVerifyTheLoginWithARecoveryCode)
+	ctx.Step(`^I verify the login with the same code again$`, tc.iVerifyTheLoginWithTheSameCodeAgain)
+	ctx.Step(`^I verify the login with code "([^"]*)" (\d+) times$`, tc.iVerifyTheLoginWithCodeNTimes)
+	ctx.Step(`^I disable two-factor authentication with a valid code$`, tc.iDisableTwoFactorAuthenticationWithAValidCode)
+	ctx.Step(`^my household requires two-factor authentication for admins$`, tc.myHouseholdRequiresTwoFactorAuthenticationForAdmins)
+	ctx.Step(`^I should receive a provisioning URI for "([^"]*)"$`, tc.iShouldReceiveAProvisioningURIFor)
+	ctx.Step(`^I should receive (\d+) recovery codes$`, tc.iShouldReceiveNRecoveryCodes)
+	ctx.Step(`^a two-factor challenge should be returned$`, tc.aTwoFactorChallengeShouldBeReturned)
+	ctx.Step(`^two-factor setup should be required$`, tc.twoFactorSetupShouldBeRequired)
+	ctx.Step(`^two-factor setup should not be required$`, tc.twoFactorSetupShouldNotBeRequired)
+}
+
+// nextTOTPCode returns a code the server accepts now. Every step is accepted
+// once, so within a 30 second window the codes of the previous, current and
+// next step are handed out in turn.
+func (tc *TestContext) nextTOTPCode() (string, error) {
+	current := totp.Step(time.Now())
+	step := current - 1
+	if tc.UsedTOTPStep >= step {
+		step = tc.UsedTOTPStep + 1
+	}
+	if step > current+1 {
+		return "", fmt.Errorf("no unused two-factor code left in this time window")
+	}
+
+	if tc.TwoFactorSetup == nil {
+		return "", fmt.Errorf("two-factor authentication was not set up")
+	}
+
+	code, err := totp.Code(tc.TwoFactorSetup.Secret, step)
+	if err != nil {
+		return "", err
+	}
+
+	tc.UsedTOTPStep = step
+	return code, nil
+}
+
+func (tc *TestContext) currentUserID() (string, error) {
+	user, ok := tc.CurrentUser.(*model.User)
+	if !ok {
+		return "", fmt.Errorf("no current user")
+	}
+	return user.ID, nil
+}
+
+func (tc *TestContext) iSetUpTwoFactorAuthentication() error {
+	userID, err := tc.currentUserID()
+	if err != nil {
+		return err
+	}
+
+	setup, err := tc.AuthService.SetupTwoFactor(context.Background(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to set up two-factor authentication: %w", err)
+	}
+
+	tc.TwoFactorSetup = setup
+	return nil
+}
+
+func (tc *TestContext) enableTwoFactor(code string) error {
+	userID, err := tc.currentUserID()
+	if err != nil {
+		return err
+	}
+
+	result, err := tc.AuthService.EnableTwoFactor(context.Background(), userID, code)
+	tc.LastError = err
+	if err == nil {
+		tc.RecoveryCodes = result.RecoveryCodes
+	}
+	return nil
+}
+
+func (tc *TestContext) iEnableTwoFactorAuthenticationWithAValidCode() error {
+	code, err := tc.nextTOTPCode()
+	if err != nil {
+		return err
+	}
+	return tc.enableTwoFactor(code)
+}
+
+func (tc *TestContext) iEnableTwoFactorAuthenticationWithCode(code string) error {
+	return tc.enableTwoFactor(code)
+}
+
+func (tc *TestContext) twoFactorAuthenticationIsEnabledForMe() error {
+	if err := tc.iSetUpTwoFactorAuthentication(); err != nil {
+		return err
+	}
+	if err := tc.iEnableTwoFactorAuthenticationWithAValidCode(); err != nil {
+		return err
+	}
+	if tc.LastError != nil {
+		return fmt.Errorf("failed to enable two-factor authentication: %w", tc.LastError)
+	}
+	return nil
+}
+
+func (tc *TestContext) verifyLogin(code string) error {
+	if tc.LoginResult == nil || tc.LoginResult.ChallengeToken == "" {
+		return fmt.Errorf("no two-factor challenge to answer")
+	}
+
+	loginResp, err := tc.AuthService.VerifyLogin(context.Background(), &model.VerifyLoginRequest{
+		ChallengeToken: tc.LoginResult.ChallengeToken,
+		Code:           code,
+	})
+	tc.UsedTwoFactorCode = code
+	tc.LastError = err
+	if err != nil {
+		return nil
+	}
+
+	tc.LoginResult = loginResp
+	tc.CurrentToken = loginResp.Token
+	tc.CurrentRefreshToken = loginResp.RefreshToken
+	return nil
+}
+
+func (tc *TestContext) iVerifyTheLoginWithAValidCode() error {
+	code, err := tc.nextTOTPCode()
+	if err != nil {
+		return err
+	}
+	return tc.verifyLogin(code)
+}
+
+func (tc *TestContext) iVerifyTheLoginWithARecoveryCode() error {
+	if len(tc.RecoveryCodes) == 0 {
+		return fmt.Errorf("no recovery codes")
+	}
+	return tc.verifyLogin(tc.RecoveryCodes[0])
+}
+
+func (tc *TestContext) iVerifyTheLoginWithTheSameCodeAgain() error {
+	if tc.UsedTwoFactorCode == "" {
+		return fmt.Errorf("no code has been used yet")
+	}
+	return tc.verifyLogin(tc.UsedTwoFactorCode)
+}
+
+func (tc *TestContext) iVerifyTheLoginWithCodeNTimes(code string, times int) error {
+	for range times {
+		if err := tc.verifyLogin(code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tc *TestContext) iDisableTwoFactorAuthenticationWithAValidCode() error {
+	userID, err := tc.currentUserID()
+	if err != nil {
+		return err
+	}
+
+	code, err := tc.nextTOTPCode()
+	if err != nil {
+		return err
+	}
+
+	tc.LastError = tc.AuthService.DisableTwoFactor(context.Background(), userID, code)
+	return nil
+}
+
+func (tc *TestContext) myHouseholdRequiresTwoFactorAuthenticationForAdmins() error {
+	required := true
+	_, err := tc.HouseholdService.Update(context.Background(), tc.householdID(), &model.UpdateHouseholdRequest{
+		RequireAdminTwoFactor: &required,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update household: %w", err)
+	}
+	return nil
+}
+
+func (tc *TestContext) iShouldReceiveAProvisioningURIFor(email string) error {
+	setup := tc.TwoFactorSetup
+	if setup == nil {
+		return fmt.Errorf("two-factor authentication was not set up")
+	}
+
+	if !strings.HasPrefix(setup.ProvisioningURI, "otpauth://totp/") {
+		return fmt.Errorf("expected an otpauth://totp/ URI, got %q", setup.ProvisioningURI)
+	}
+	if !strings.Contains(setup.ProvisioningURI, "secret="+setup.Secret) {
+		return fmt.Errorf("expected the URI to carry the secret, got %q", setup.ProvisioningURI)
+	}
+	if !strings.Contains(setup.ProvisioningURI, url.PathEscape(email)) {
+		return fmt.Errorf("expected the URI to name %q, got %q", email, setup.ProvisioningURI)
+	}
+	return nil
+}
+
+func (tc *TestContext) iShouldReceiveNRecoveryCodes(expected int) error {
+	if tc.LastError != nil {
+		return fmt.Errorf("expected two-factor authentication to be enabled, got error: %v", tc.LastError)
+	}
+	if len(tc.RecoveryCodes) != expected {
+		return fmt.Errorf("expected %d recovery codes, got %d", expected, len(tc.RecoveryCodes))
+	}
+	return nil
+}
+
+func (tc *TestContext) aTwoFactorChallengeShouldBeReturned() error {
+	if tc.LastError != nil {
+		return fmt.Errorf("expected login to succeed, got error: %v", tc.LastError)
+	}
+	if !tc.LoginResult.TwoFactorRequired || tc.LoginResult.ChallengeToken == "" {
+		return fmt.Errorf("expected a two-factor challenge")
+	}
+	if tc.LoginResult.Token != "" || tc.LoginResult.RefreshToken != "" {
+		return fmt.Errorf("expected no tokens before the two-factor step")
+	}
+
+	// The challenge must not work as an access token
+	if _, err := tc.AuthService.ValidateToken(tc.LoginResult.ChallengeToken); err == nil {
+		return fmt.Errorf("expected the challenge token to be rejected as an access token")
+	}
+	return nil
+}
+
+func (tc *TestContext) twoFactorSetupShouldBeRequired() error {
+	if tc.LastError != nil {
+		return fmt.Errorf("expected login to succeed, got error: %v", tc.LastError)
+	}
+	if !tc.LoginResult.TwoFactorSetupRequired {
+		return fmt.Errorf("expected two-factor setup to be required")
+	}
+
+	claims, err := tc.AuthService.ValidateToken(tc.CurrentToken)
+	if err != nil {
+		return fmt.Errorf("invalid access token: %w", err)
+	}
+	if claims[service.TwoFactorSetupClaim] != true {
+		return fmt.Errorf("expected the access token to be limited to two-factor setup")
+	}
+	return nil
+}
+
+func (tc *TestContext) twoFactorSetupShouldNotBeRequired() error {
+	if tc.LastError != nil {
+		return fmt.Errorf("expected refresh to succeed, got error: %v", tc.LastError)
+	}
+
+	claims, err := tc.AuthService.ValidateToken(tc.CurrentToken)
+	if err != nil {
+		return fmt.Errorf("invalid access token: %w", err)
+	}
+	if _, ok := claims[service.TwoFactorSetupClaim]; ok {
+		return fmt.Errorf("expected a full access token")
+	}
+	return nil
+}
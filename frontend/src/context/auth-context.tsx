@@ -20,7 +20,10 @@ export interface User {
 interface AuthContextValue {
   user: User | null
   loading: boolean
-  login: (email: string, password: string) => Promise<void>
+  // login resolves to true when the account asks for a two-factor code,
+  // which is then sent with verifyCode
+  login: (email: string, password: string) => Promise<boolean>
+  verifyCode: (code: string) => Promise<void>
   logout: () => void
 }
 
@@ -29,6 +32,7 @@ const AuthContext = createContext<AuthContextValue | null>(null)
 export function AuthProvider({ children }: { children: ReactNode }) {
   const [user, setUser] = useState<User | null>(null)
   const [loading, setLoading] = useState(true)
+  const [challengeToken, setChallengeToken] = useState<string | null>(null)
 
   const fetchUser = useCallback(async () => {
     const token = localStorage.getItem("token")
@@ -41,6 +45,7 @@ export function AuthProvider({ children }: { children: ReactNode }) {
       setUser(data)
     } catch {
       localStorage.removeItem("token")
+      localStorage.removeItem("refreshToken")
       setUser(null)
     } finally {
       setLoading(false)
@@ -51,19 +56,53 @@ export function AuthProvider({ children }: { children: ReactNode }) {
     fetchUser()
   }, [fetchUser])
 
-  const login = useCallback(async (email: string, password: string) => {
-    const { data } = await api.post("/auth/login", { email, password })
-    localStorage.setItem("token", data.token)
-    setUser(data.user)
-  }, [])
+  const startSession = useCallback(
+    (data: { token: string; refreshToken: string; user: User }) => {
+      localStorage.setItem("token", data.token)
+      localStorage.setItem("refreshToken", data.refreshToken)
+      setUser(data.user)
+    },
+    [],
+  )
+
+  const login = useCallback(
+    async (email: string, password: string) => {
+      const { data } = await api.post("/auth/login", { email, password })
+      if (data.twoFactorRequired) {
+        setChallengeToken(data.challengeToken)
+        return true
+      }
+      startSession(data)
+      return false
+    },
+    [startSession],
+  )
+
+  const verifyCode = useCallback(
+    async (code: string) => {
+      const { data } = await api.post("/auth/login/verify", {
+        challengeToken,
+        code,
+      })
+      setChallengeToken(null)
+      startSession(data)
+    },
+    [challengeToken, startSession],
+  )
 
   const logout = useCallback(() => {
+    const refreshToken = localStorage.getItem("refreshToken")
+    if (refreshToken) {
+      // End the session on the server too; signing out locally does not wait for it
+      api.post("/auth/logout", { refreshToken }).catch(() => {})
+    }
     localStorage.removeItem("token")
+    localStorage.removeItem("refreshToken")
     setUser(null)
   }, [])
 
   return (
-    <AuthContext.Provider value={{ user, loading, login, logout }}>
+    <AuthContext.Provider value={{ user, loading, login, verifyCode, logout }}>
       {children}
     </AuthContext.Provider>
   )
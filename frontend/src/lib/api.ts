@@ -1,4 +1,4 @@
-import axios from "axios"
+import axios, { type InternalAxiosRequestConfig } from "axios"
 
 const api = axios.create({
   baseURL: "/api",
@@ -12,11 +12,46 @@ api.interceptors.request.use((config) => {
   return config
 })
 
+// Access tokens are short-lived. On a 401 the refresh token is traded for
+// new tokens once and the request retried; concurrent requests share the
+// same refresh.
+let refreshing: Promise<string> | null = null
+
+function refreshAccessToken(): Promise<string> {
+  if (!refreshing) {
+    const refreshToken = localStorage.getItem("refreshToken")
+    refreshing = (
+      refreshToken
+        ? axios.post("/api/auth/refresh", { refreshToken }).then(({ data }) => {
+            localStorage.setItem("token", data.token)
+            localStorage.setItem("refreshToken", data.refreshToken)
+            return data.token as string
+          })
+        : Promise.reject(new Error("no refresh token"))
+    ).finally(() => {
+      refreshing = null
+    })
+  }
+  return refreshing
+}
+
 api.interceptors.response.use(
   (response) => response,
-  (error) => {
+  async (error) => {
+    const request = error.config as (InternalAxiosRequestConfig & { _retried?: boolean }) | undefined
     if (error.response?.status === 401) {
+      if (request && !request._retried && !request.url?.startsWith("/auth/")) {
+        request._retried = true
+        try {
+          const token = await refreshAccessToken()
+          request.headers.Authorization = `Bearer ${token}`
+          return api(request)
+        } catch {
+          // fall through to signing out
+        }
+      }
       localStorage.removeItem("token")
+      localStorage.removeItem("refreshToken")
       window.location.href = "/login"
     }
     return Promise.reject(error)
@@ -22,6 +22,9 @@ export const en = {
   "login.submit": "Sign in",
   "login.submitting": "Signing in...",
   "login.error": "Login failed. Please try again.",
+  "login.code": "Authentication code",
+  "login.codeHint": "Enter the 6-digit code from your authenticator app or one of your recovery codes.",
+  "login.verify": "Verify",
 
   // Dashboard
   "dashboard.title": "Dashboard",
@@ -106,6 +109,9 @@ export const en = {
   // Budgets
   "budgets.title": "Budgets",
   "budgets.add": "Add Budget",
+  "budgets.copyLastMonth": "Copy Last Month",
+  "budgets.copied": "{count} budgets copied from last month",
+  "budgets.copyFailed": "Failed to copy budgets",
   "budgets.noData": "No budgets set for this month.",
   "budgets.spent": "spent",
   "budgets.of": "of",
@@ -118,6 +124,11 @@ export const en = {
   "budgets.amount": "Amount",
   "budgets.month": "Month",
   "budgets.year": "Year",
+  "budgets.rollover": "At month end",
+  "budgets.rolloverNone": "Start fresh next month",
+  "budgets.rolloverSurplus": "Carry unspent money forward",
+  "budgets.rolloverFull": "Carry unspent and overspent money forward",
+  "budgets.carriedIn": "Carried in from last month:",
   "budgets.deleteTitle": "Delete Budget",
   "budgets.deleteConfirm": "Are you sure you want to delete the budget for \"{name}\"?",
   "budgets.updated": "Budget updated",
@@ -318,5 +329,6 @@ export const en = {
   "common.deleting": "Deleting...",
   "common.edit": "Edit",
   "common.loading": "Loading...",
+  "common.loadMore": "Load more",
   "common.noData": "No data available.",
 } as const
@@ -22,6 +22,9 @@ export const lt = {
   "login.submit": "Prisijungti",
   "login.submitting": "Jungiamasi...",
   "login.error": "Prisijungimas nepavyko. Bandykite dar kartą.",
+  "login.code": "Patvirtinimo kodas",
+  "login.codeHint": "Įveskite 6 skaitmenų kodą iš autentifikavimo programėlės arba vieną iš atkūrimo kodų.",
+  "login.verify": "Patvirtinti",
 
   // Dashboard
   "dashboard.title": "Skydelis",
@@ -106,6 +109,9 @@ export const lt = {
   // Budgets
   "budgets.title": "Biudžetas",
   "budgets.add": "Pridėti biudžetą",
+  "budgets.copyLastMonth": "Kopijuoti praėjusį mėnesį",
+  "budgets.copied": "Nukopijuota biudžetų iš praėjusio mėnesio: {count}",
+  "budgets.copyFailed": "Nepavyko nukopijuoti biudžetų",
   "budgets.noData": "Šį mėnesį biudžetas nenustatytas.",
   "budgets.spent": "išleista",
   "budgets.of": "iš",
@@ -118,6 +124,11 @@ export const lt = {
   "budgets.amount": "Suma",
   "budgets.month": "Mėnuo",
   "budgets.year": "Metai",
+  "budgets.rollover": "Mėnesio pabaigoje",
+  "budgets.rolloverNone": "Kitą mėnesį pradėti iš naujo",
+  "budgets.rolloverSurplus": "Perkelti nepanaudotą sumą",
+  "budgets.rolloverFull": "Perkelti nepanaudotą ir viršytą sumą",
+  "budgets.carriedIn": "Perkelta iš praėjusio mėnesio:",
   "budgets.deleteTitle": "Ištrinti biudžetą",
   "budgets.deleteConfirm": "Ar tikrai norite ištrinti biudžetą kategorijai \"{name}\"?",
   "budgets.updated": "Biudžetas atnaujintas",
@@ -318,5 +329,6 @@ export const lt = {
   "common.deleting": "Trinama...",
   "common.edit": "Redaguoti",
   "common.loading": "Kraunama...",
+  "common.loadMore": "Rodyti daugiau",
   "common.noData": "Nėra duomenų.",
 } as const
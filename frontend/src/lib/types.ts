@@ -22,16 +22,28 @@ export interface Transaction {
   amount: number
   type: "expense" | "income" | "transfer"
   description?: string
+  counterparty?: string
   date: string
   isShared: boolean
   isRecurring: boolean
   recurringRule?: RecurringRule
   tags?: string[]
   transferToAccountId?: string
+  externalId?: string
+  status: "uncleared" | "cleared" | "reconciled" | "pending_approval" | "rejected"
+  splits?: TransactionSplit[]
   createdAt: string
   updatedAt: string
 }
 
+export interface TransactionSplit {
+  id: string
+  categoryId: string
+  amount: number
+  memo?: string
+  tags?: string[]
+}
+
 export interface Category {
   id: string
   parentId?: string
@@ -47,54 +59,166 @@ export interface Budget {
   amount: number
   month: number
   year: number
+  rollover: "none" | "surplus" | "full"
   createdAt: string
 }
 
 export interface BudgetSummary {
   categoryId: string
   categoryName: string
+  rollover: "none" | "surplus" | "full"
   budgetAmount: number
+  carriedIn: number
+  effectiveAmount: number
   actualAmount: number
   remaining: number
 }
 
+export interface BudgetTemplate {
+  id: string
+  categoryId: string
+  amount?: number
+  annualAmount?: number
+  startMonth: number
+  startYear: number
+  endMonth?: number
+  endYear?: number
+  rollover: "none" | "surplus" | "full"
+  createdAt: string
+  updatedAt: string
+}
+
 export interface SavingGoal {
   id: string
   name: string
   targetAmount: number
   currentAmount: number
+  accountId?: string
   targetDate?: string
   priority: number
   status: "active" | "completed" | "cancelled"
+  projection?: GoalProjection
   createdAt: string
   updatedAt: string
 }
 
+export interface GoalProjection {
+  remaining: number
+  monthlyPace: number
+  projectedCompletion?: string
+  requiredMonthly?: number
+  status: "on_track" | "behind" | "completed" | "no_target_date"
+}
+
+export interface GoalContribution {
+  id: string
+  userId?: string
+  transactionId?: string
+  amount: number
+  date: string
+  createdAt: string
+}
+
 export interface BillReminder {
   id: string
   name: string
   amount: number
   dueDay: number
   frequency: "monthly" | "quarterly" | "yearly"
-  categoryId?: string
+  categoryId: string
   accountId?: string
   isActive: boolean
+  isOverdue: boolean
   nextDueDate: string
+  leadDays: number
+  deferredFrom?: string
+  recipientIds: string[]
   createdAt: string
   updatedAt: string
 }
 
+export interface BillPayment {
+  id: string
+  transactionId?: string
+  status: "paid" | "skipped"
+  periodDate: string
+  dueDate: string
+  amount?: number
+  paidOn?: string
+  isLate: boolean
+  createdAt: string
+}
+
+export interface BillHistory {
+  payments: BillPayment[]
+  averageAmount?: number
+  lastPaidAmount?: number
+  forecastAmount: number
+}
+
 export interface Allowance {
   id: string
   userId: string
   amount: number
+  frequency: "weekly" | "biweekly" | "monthly"
+  rollover: boolean
+  fromAccountId?: string
+  toAccountId?: string
+  carriedOver: number
   spent: number
   remaining: number
   periodStart: string
+  periodEnd: string
   createdAt: string
   updatedAt: string
 }
 
+export interface AllowancePeriod {
+  id: string
+  periodStart: string
+  periodEnd: string
+  amount: number
+  carriedOver: number
+  spent: number
+  remaining: number
+  transactionId?: string
+}
+
+export interface Chore {
+  id: string
+  name: string
+  description: string
+  reward: number
+  frequency: "once" | "daily" | "weekly" | "monthly"
+  assignedTo?: string
+  isActive: boolean
+  createdAt: string
+  updatedAt: string
+}
+
+export interface ChoreCompletion {
+  id: string
+  choreId?: string
+  choreName: string
+  userId: string
+  reward: number
+  date: string
+  status: "pending" | "approved" | "rejected"
+  reviewedBy?: string
+  reviewedAt?: string
+  transactionId?: string
+  createdAt: string
+}
+
+export interface ChoreEarnings {
+  userId: string
+  name: string
+  approved: number
+  earned: number
+  pending: number
+  pendingAmount: number
+}
+
 export interface MonthSummary {
   month: number
   year: number
@@ -116,6 +240,17 @@ export interface CategorySpending {
   percentage: number
 }
 
+export interface CategorySpendingNode {
+  categoryId: string
+  categoryName: string
+  parentId?: string
+  depth: number
+  ownAmount: number
+  totalAmount: number
+  percentage: number
+  children?: CategorySpendingNode[]
+}
+
 export interface MemberSpending {
   userId: string
   userName: string
@@ -132,16 +267,181 @@ export interface TrendPoint {
   net: number
 }
 
-export interface SearchResult {
+export interface TransactionPage {
   transactions: Transaction[]
   totalCount: number
+  nextCursor?: string
+}
+
+export type SearchResult = TransactionPage
+
+export interface Household {
+  id: string
+  name: string
+  baseCurrency: string
+  requireAdminTwoFactor: boolean
+  createdAt: string
+  updatedAt: string
+}
+
+export interface ExchangeRate {
+  id: string
+  date: string
+  base: string
+  quote: string
+  rate: number
+  createdAt: string
+}
+
+export interface ImportColumnMapping {
+  date: string
+  amount?: string
+  debit?: string
+  credit?: string
+  description?: string
+  counterparty?: string
+  reference?: string
+  currency?: string
+}
+
+export interface ImportProfile {
+  id: string
+  name: string
+  delimiter: string
+  encoding: string
+  headerRow: number
+  dateLayout: string
+  decimalSeparator: "." | ","
+  amountSign: "signed" | "inverted" | "debit_credit"
+  columns: ImportColumnMapping
+  createdAt: string
+  updatedAt: string
+}
+
+export interface CategorizationRule {
+  id: string
+  name: string
+  priority: number
+  isActive: boolean
+  descriptionPattern?: string
+  descriptionMatch: "contains" | "regex"
+  minAmount?: number
+  maxAmount?: number
+  accountId?: string
+  counterparty?: string
+  categoryId: string
+  addTags?: string[]
+  setShared?: boolean
+  createdAt: string
+  updatedAt: string
+}
+
+export interface Reconciliation {
+  id: string
+  accountId: string
+  statementDate: string
+  closingBalance: number
+  status: "open" | "completed"
+  adjustmentTransactionId?: string
+  completedAt?: string
+  createdAt: string
+  clearedBalance?: number
+  difference?: number
+  transactions?: Transaction[]
+}
+
+export interface ImportPreviewRow {
+  row: number
+  status: "valid" | "duplicate" | "invalid"
+  date?: string
+  amount: number
+  type?: string
+  categoryId: string
+  description?: string
+  externalId?: string
+  errors?: string[]
+}
+
+export interface ImportPreviewResponse {
+  rows: ImportPreviewRow[]
+  valid: number
+  duplicates: number
+  invalid: number
 }
 
 export interface User {
   id: string
+  householdId: string
   email: string
   name: string
   role: "admin" | "member" | "child"
+  twoFactorEnabled: boolean
+  approvalThreshold?: number
   createdAt: string
   updatedAt: string
 }
+
+export interface AuditEvent {
+  id: string
+  actorId?: string
+  actorName?: string
+  entityType:
+    | "transaction"
+    | "account"
+    | "budget"
+    | "budget_template"
+    | "saving_goal"
+    | "bill_reminder"
+    | "allowance"
+    | "user"
+  entityId: string
+  action:
+    | "create"
+    | "update"
+    | "delete"
+    | "restore"
+    | "unlock"
+    | "pay"
+    | "contribute"
+  before?: Record<string, unknown>
+  after?: Record<string, unknown>
+  requestId?: string
+  createdAt: string
+}
+
+export interface AuditPage {
+  events: AuditEvent[]
+  nextCursor?: string
+}
+
+export interface TrashItem {
+  type: "transaction" | "account" | "category" | "budget" | "saving_goal"
+  id: string
+  name: string
+  amount?: number
+  userId?: string
+  deletedAt: string
+  purgeAt: string
+}
+
+export interface Notification {
+  id: string
+  type: "budget_threshold" | "allowance_depleted" | "large_transaction" | "bill_due"
+  title: string
+  body?: string
+  entityId?: string
+  readAt?: string
+  createdAt: string
+}
+
+export interface NotificationFeed {
+  notifications: Notification[]
+  unread: number
+}
+
+export interface NotificationPreference {
+  type: Notification["type"]
+  enabled: boolean
+  channels: ("email" | "webhook")[]
+  threshold?: number
+}
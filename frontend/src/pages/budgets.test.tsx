@@ -22,14 +22,25 @@ import api from "@/lib/api"
 const mockedApi = vi.mocked(api, true)
 
 const budgets = [
-  { id: "b1", categoryId: "c1", amount: 50000, month: 2, year: 2026, createdAt: "2026-02-01" },
+  {
+    id: "b1",
+    categoryId: "c1",
+    amount: 50000,
+    month: 2,
+    year: 2026,
+    rollover: "none",
+    createdAt: "2026-02-01",
+  },
 ]
 
 const summary = [
   {
     categoryId: "c1",
     categoryName: "Groceries",
+    rollover: "none",
     budgetAmount: 50000,
+    carriedIn: 0,
+    effectiveAmount: 50000,
     actualAmount: -35000,
     remaining: 15000,
   },
@@ -73,7 +84,10 @@ describe("BudgetsPage", () => {
       {
         categoryId: "c1",
         categoryName: "Groceries",
+        rollover: "none",
         budgetAmount: 30000,
+        carriedIn: 0,
+        effectiveAmount: 30000,
         actualAmount: -45000,
         remaining: -15000,
       },
@@ -97,6 +111,40 @@ describe("BudgetsPage", () => {
     })
   })
 
+  it("adds the amount carried in from last month to the limit", async () => {
+    const rolledOverSummary = [
+      {
+        categoryId: "c1",
+        categoryName: "Groceries",
+        rollover: "surplus",
+        budgetAmount: 50000,
+        carriedIn: 12000,
+        effectiveAmount: 62000,
+        actualAmount: -35000,
+        remaining: 27000,
+      },
+    ]
+    mockedApi.get.mockImplementation((url: string) => {
+      if (url.startsWith("/budgets/summary")) return Promise.resolve({ data: rolledOverSummary })
+      if (url.startsWith("/budgets")) return Promise.resolve({ data: budgets })
+      if (url === "/categories") return Promise.resolve({ data: categories })
+      if (url === "/auth/me") {
+        return Promise.resolve({
+          data: { id: "u1", email: "admin@test.com", name: "Admin", role: "admin" },
+        })
+      }
+      return Promise.reject(new Error("not mocked"))
+    })
+
+    renderWithProviders(<BudgetsPage />)
+
+    await waitFor(() => {
+      expect(screen.getByText(/Carried in from last month/)).toBeInTheDocument()
+    })
+    expect(screen.getByText(/620,00/)).toBeInTheDocument()
+    expect(screen.getByText(/270,00.*remaining/)).toBeInTheDocument()
+  })
+
   it("shows add button for admin", async () => {
     renderWithProviders(<BudgetsPage />)
 
@@ -118,6 +166,22 @@ describe("BudgetsPage", () => {
     expect(screen.getByText("New Budget")).toBeInTheDocument()
   })
 
+  it("copies last month's budgets", async () => {
+    const user = userEvent.setup()
+    mockedApi.post.mockResolvedValueOnce({ data: budgets })
+    renderWithProviders(<BudgetsPage />)
+
+    await waitFor(() => {
+      expect(screen.getByText("Groceries")).toBeInTheDocument()
+    })
+
+    await user.click(screen.getByRole("button", { name: /copy last month/i }))
+    expect(mockedApi.post).toHaveBeenCalledWith("/budgets/copy", {
+      month: new Date().getMonth() + 1,
+      year: new Date().getFullYear(),
+    })
+  })
+
   it("shows empty state when no budgets", async () => {
     mockedApi.get.mockImplementation((url: string) => {
       if (url.startsWith("/budgets/summary")) return Promise.resolve({ data: [] })
@@ -24,7 +24,7 @@ import {
   SelectTrigger,
   SelectValue,
 } from "@/components/ui/select"
-import { Plus, Pencil, Trash2, AlertTriangle } from "lucide-react"
+import { Plus, Pencil, Trash2, AlertTriangle, Copy } from "lucide-react"
 import { toast } from "sonner"
 import { useAuth } from "@/context/auth-context"
 import { useLanguage } from "@/context/language-context"
@@ -39,6 +39,7 @@ interface FormData {
   amount: string
   month: string
   year: string
+  rollover: Budget["rollover"]
 }
 
 const emptyForm: FormData = {
@@ -46,6 +47,7 @@ const emptyForm: FormData = {
   amount: "",
   month: String(now.getMonth() + 1),
   year: String(now.getFullYear()),
+  rollover: "none",
 }
 
 export default function BudgetsPage() {
@@ -102,6 +104,7 @@ export default function BudgetsPage() {
       amount: String(b.amount / 100),
       month: String(b.month),
       year: String(b.year),
+      rollover: b.rollover,
     })
     setDialogOpen(true)
   }
@@ -119,6 +122,7 @@ export default function BudgetsPage() {
         amount: Math.round(parseFloat(form.amount) * 100),
         month: parseInt(form.month),
         year: parseInt(form.year),
+        rollover: form.rollover,
       }
       if (editing) {
         await api.put(`/budgets/${editing.id}`, payload)
@@ -136,6 +140,19 @@ export default function BudgetsPage() {
     }
   }
 
+  async function handleCopy() {
+    setSubmitting(true)
+    try {
+      const res = await api.post("/budgets/copy", { month, year })
+      toast.success(t("budgets.copied").replace("{count}", String(res.data?.length ?? 0)))
+      fetchData()
+    } catch {
+      toast.error(t("budgets.copyFailed"))
+    } finally {
+      setSubmitting(false)
+    }
+  }
+
   async function handleDelete() {
     if (!deleting) return
     setSubmitting(true)
@@ -179,6 +196,12 @@ export default function BudgetsPage() {
             onChange={(e) => setYear(parseInt(e.target.value) || year)}
             className="w-[80px]"
           />
+          {isAdmin && (
+            <Button onClick={handleCopy} size="sm" variant="outline" disabled={submitting}>
+              <Copy className="mr-1 h-4 w-4" />
+              {t("budgets.copyLastMonth")}
+            </Button>
+          )}
           {isAdmin && (
             <Button onClick={openCreate} size="sm">
               <Plus className="mr-1 h-4 w-4" />
@@ -194,8 +217,9 @@ export default function BudgetsPage() {
         <div className="grid gap-4 md:grid-cols-2">
           {summary.map((s) => {
             const spent = Math.abs(s.actualAmount)
-            const pct = s.budgetAmount > 0 ? Math.min((spent / s.budgetAmount) * 100, 100) : 0
-            const overspent = spent > s.budgetAmount
+            const limit = s.effectiveAmount
+            const pct = limit > 0 ? Math.min((spent / limit) * 100, 100) : 0
+            const overspent = spent > limit
             const budget = budgets.find((b) => b.categoryId === s.categoryId)
 
             return (
@@ -220,13 +244,18 @@ export default function BudgetsPage() {
                 <CardContent>
                   <div className="flex items-center justify-between text-sm mb-2">
                     <span>{formatCents(spent)} {t("budgets.spent")}</span>
-                    <span>{t("budgets.of")} {formatCents(s.budgetAmount)}</span>
+                    <span>{t("budgets.of")} {formatCents(limit)}</span>
                   </div>
+                  {s.carriedIn !== 0 && (
+                    <p className="text-xs text-muted-foreground mb-2">
+                      {t("budgets.carriedIn")} {formatCents(s.carriedIn)}
+                    </p>
+                  )}
                   <Progress value={pct} className={overspent ? "[&>div]:bg-destructive" : ""} />
                   {overspent && (
                     <div className="flex items-center gap-1 mt-2 text-xs text-destructive">
                       <AlertTriangle className="h-3 w-3" />
-                      {t("budgets.overspentBy")} {formatCents(spent - s.budgetAmount)}
+                      {t("budgets.overspentBy")} {formatCents(spent - limit)}
                     </div>
                   )}
                   {!overspent && (
@@ -302,6 +331,22 @@ export default function BudgetsPage() {
                 />
               </div>
             </div>
+            <div className="space-y-2">
+              <Label>{t("budgets.rollover")}</Label>
+              <Select
+                value={form.rollover}
+                onValueChange={(v) => setForm({ ...form, rollover: v as Budget["rollover"] })}
+              >
+                <SelectTrigger>
+                  <SelectValue />
+                </SelectTrigger>
+                <SelectContent>
+                  <SelectItem value="none">{t("budgets.rolloverNone")}</SelectItem>
+                  <SelectItem value="surplus">{t("budgets.rolloverSurplus")}</SelectItem>
+                  <SelectItem value="full">{t("budgets.rolloverFull")}</SelectItem>
+                </SelectContent>
+              </Select>
+            </div>
           </div>
           <DialogFooter>
             <Button variant="outline" onClick={() => setDialogOpen(false)}>{t("common.cancel")}</Button>
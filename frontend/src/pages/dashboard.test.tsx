@@ -41,6 +41,7 @@ const dashboardData = {
       date: "2026-02-10",
       isShared: true,
       isRecurring: false,
+      status: "uncleared",
       createdAt: "2026-02-10T10:00:00Z",
       updatedAt: "2026-02-10T10:00:00Z",
     },
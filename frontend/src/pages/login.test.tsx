@@ -71,4 +71,37 @@ describe("LoginPage", () => {
       expect(localStorage.getItem("token")).toBe("jwt-token-123")
     })
   })
+  it("asks for a two-factor code when the account has it enabled", async () => {
+    const user = userEvent.setup()
+    mockedApi.post
+      .mockResolvedValueOnce({
+        data: { twoFactorRequired: true, challengeToken: "challenge-1" },
+      })
+      .mockResolvedValueOnce({
+        data: {
+          token: "jwt-token-456",
+          refreshToken: "refresh-456",
+          user: { id: "1", email: "admin@test.com", name: "Admin", role: "admin" },
+        },
+      })
+
+    renderWithProviders(<LoginPage />, {
+      routerProps: { initialEntries: ["/login"] },
+    })
+
+    await user.type(screen.getByLabelText(/email/i), "admin@test.com")
+    await user.type(screen.getByLabelText(/password/i), "password123")
+    await user.click(screen.getByRole("button", { name: /sign in/i }))
+
+    await user.type(await screen.findByLabelText(/authentication code/i), "123456")
+    await user.click(screen.getByRole("button", { name: /verify/i }))
+
+    await waitFor(() => {
+      expect(localStorage.getItem("token")).toBe("jwt-token-456")
+    })
+    expect(mockedApi.post).toHaveBeenLastCalledWith("/auth/login/verify", {
+      challengeToken: "challenge-1",
+      code: "123456",
+    })
+  })
 })
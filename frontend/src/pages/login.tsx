@@ -14,11 +14,13 @@ import {
 } from "@/components/ui/card"
 
 export default function LoginPage() {
-  const { login } = useAuth()
+  const { login, verifyCode } = useAuth()
   const { t } = useLanguage()
   const navigate = useNavigate()
   const [email, setEmail] = useState("")
   const [password, setPassword] = useState("")
+  const [code, setCode] = useState("")
+  const [needsCode, setNeedsCode] = useState(false)
   const [error, setError] = useState("")
   const [submitting, setSubmitting] = useState(false)
 
@@ -27,7 +29,12 @@ export default function LoginPage() {
     setError("")
     setSubmitting(true)
     try {
-      await login(email, password)
+      if (needsCode) {
+        await verifyCode(code.trim())
+      } else if (await login(email, password)) {
+        setNeedsCode(true)
+        return
+      }
       navigate("/", { replace: true })
     } catch (err: unknown) {
       if (
@@ -65,31 +72,55 @@ export default function LoginPage() {
                 {error}
               </p>
             )}
-            <div className="space-y-2">
-              <Label htmlFor="email">{t("login.email")}</Label>
-              <Input
-                id="email"
-                type="email"
-                value={email}
-                onChange={(e) => setEmail(e.target.value)}
-                required
-                autoComplete="email"
-                placeholder="you@example.com"
-              />
-            </div>
-            <div className="space-y-2">
-              <Label htmlFor="password">{t("login.password")}</Label>
-              <Input
-                id="password"
-                type="password"
-                value={password}
-                onChange={(e) => setPassword(e.target.value)}
-                required
-                autoComplete="current-password"
-              />
-            </div>
+            {needsCode ? (
+              <div className="space-y-2">
+                <Label htmlFor="code">{t("login.code")}</Label>
+                <Input
+                  id="code"
+                  value={code}
+                  onChange={(e) => setCode(e.target.value)}
+                  required
+                  autoFocus
+                  autoComplete="one-time-code"
+                  inputMode="numeric"
+                />
+                <p className="text-xs text-muted-foreground">
+                  {t("login.codeHint")}
+                </p>
+              </div>
+            ) : (
+              <>
+                <div className="space-y-2">
+                  <Label htmlFor="email">{t("login.email")}</Label>
+                  <Input
+                    id="email"
+                    type="email"
+                    value={email}
+                    onChange={(e) => setEmail(e.target.value)}
+                    required
+                    autoComplete="email"
+                    placeholder="you@example.com"
+                  />
+                </div>
+                <div className="space-y-2">
+                  <Label htmlFor="password">{t("login.password")}</Label>
+                  <Input
+                    id="password"
+                    type="password"
+                    value={password}
+                    onChange={(e) => setPassword(e.target.value)}
+                    required
+                    autoComplete="current-password"
+                  />
+                </div>
+              </>
+            )}
             <Button type="submit" className="w-full" disabled={submitting}>
-              {submitting ? t("login.submitting") : t("login.submit")}
+              {submitting
+                ? t("login.submitting")
+                : needsCode
+                  ? t("login.verify")
+                  : t("login.submit")}
             </Button>
           </form>
         </CardContent>
@@ -40,6 +40,7 @@ const searchResults = {
       date: "2026-02-10",
       isShared: false,
       isRecurring: false,
+      status: "uncleared",
       tags: ["food"],
       createdAt: "2026-02-10T10:00:00Z",
       updatedAt: "2026-02-10T10:00:00Z",
@@ -37,6 +37,7 @@ export default function SearchPage() {
 
   const [results, setResults] = useState<Transaction[]>([])
   const [totalCount, setTotalCount] = useState(0)
+  const [nextCursor, setNextCursor] = useState<string | undefined>()
   const [searched, setSearched] = useState(false)
   const [loading, setLoading] = useState(false)
 
@@ -54,7 +55,7 @@ export default function SearchPage() {
     )
   }, [])
 
-  function categoryName(id: string) {
+  function categoryName(id?: string) {
     return categories.find((c) => c.id === id)?.name ?? ""
   }
 
@@ -62,8 +63,7 @@ export default function SearchPage() {
     return accounts.find((a) => a.id === id)?.name ?? ""
   }
 
-  async function handleSearch() {
-    setLoading(true)
+  function searchParams() {
     const params = new URLSearchParams()
     if (description) params.set("description", description)
     if (startDate) params.set("startDate", startDate)
@@ -73,15 +73,35 @@ export default function SearchPage() {
     if (minAmount) params.set("minAmount", String(Math.round(parseFloat(minAmount) * 100)))
     if (maxAmount) params.set("maxAmount", String(Math.round(parseFloat(maxAmount) * 100)))
     if (tags) params.set("tags", tags)
+    return params
+  }
 
+  async function handleSearch() {
+    setLoading(true)
     try {
-      const res = await api.get(`/search?${params.toString()}`)
+      const res = await api.get(`/search?${searchParams().toString()}`)
       setResults(res.data.transactions ?? [])
       setTotalCount(res.data.totalCount ?? 0)
+      setNextCursor(res.data.nextCursor)
       setSearched(true)
     } catch {
       setResults([])
       setTotalCount(0)
+      setNextCursor(undefined)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  async function loadMore() {
+    if (!nextCursor) return
+    setLoading(true)
+    const params = searchParams()
+    params.set("cursor", nextCursor)
+    try {
+      const res = await api.get(`/search?${params.toString()}`)
+      setResults((prev) => [...prev, ...(res.data.transactions ?? [])])
+      setNextCursor(res.data.nextCursor)
     } finally {
       setLoading(false)
     }
@@ -98,6 +118,7 @@ export default function SearchPage() {
     setTags("")
     setResults([])
     setTotalCount(0)
+    setNextCursor(undefined)
     setSearched(false)
   }
 
@@ -251,6 +272,14 @@ export default function SearchPage() {
           ) : (
             <p className="text-sm text-muted-foreground">{t("search.noResults")}</p>
           )}
+
+          {nextCursor && (
+            <div className="flex justify-center">
+              <Button variant="outline" size="sm" onClick={loadMore} disabled={loading}>
+                {loading ? t("common.loading") : t("common.loadMore")}
+              </Button>
+            </div>
+          )}
         </div>
       )}
     </div>
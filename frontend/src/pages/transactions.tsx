@@ -30,7 +30,7 @@ import { Plus, Pencil, Trash2 } from "lucide-react"
 import { toast } from "sonner"
 import api from "@/lib/api"
 import { formatCents, inputToCents, centsToInput } from "@/lib/format"
-import type { Transaction, Account, Category } from "@/lib/types"
+import type { Transaction, TransactionPage, Account, Category } from "@/lib/types"
 import { useLanguage } from "@/context/language-context"
 
 interface FormData {
@@ -58,6 +58,8 @@ const emptyForm: FormData = {
 export default function TransactionsPage() {
   const { t } = useLanguage()
   const [transactions, setTransactions] = useState<Transaction[]>([])
+  const [nextCursor, setNextCursor] = useState<string | undefined>()
+  const [loadingMore, setLoadingMore] = useState(false)
   const [accounts, setAccounts] = useState<Account[]>([])
   const [categories, setCategories] = useState<Category[]>([])
   const [loading, setLoading] = useState(true)
@@ -75,19 +77,37 @@ export default function TransactionsPage() {
   const [filterStartDate, setFilterStartDate] = useState("")
   const [filterEndDate, setFilterEndDate] = useState("")
 
-  const fetchTransactions = useCallback(() => {
+  const filterParams = useCallback(() => {
     const params: Record<string, string> = {}
     if (filterAccount) params.accountId = filterAccount
     if (filterCategory) params.categoryId = filterCategory
     if (filterType) params.type = filterType
     if (filterStartDate) params.startDate = filterStartDate
     if (filterEndDate) params.endDate = filterEndDate
+    return params
+  }, [filterAccount, filterCategory, filterType, filterStartDate, filterEndDate])
 
-    api.get("/transactions", { params }).then((res) => {
-      setTransactions(res.data)
+  const fetchTransactions = useCallback(() => {
+    api.get<TransactionPage>("/transactions", { params: filterParams() }).then((res) => {
+      setTransactions(res.data.transactions ?? [])
+      setNextCursor(res.data.nextCursor)
       setLoading(false)
     })
-  }, [filterAccount, filterCategory, filterType, filterStartDate, filterEndDate])
+  }, [filterParams])
+
+  async function loadMore() {
+    if (!nextCursor) return
+    setLoadingMore(true)
+    try {
+      const res = await api.get<TransactionPage>("/transactions", {
+        params: { ...filterParams(), cursor: nextCursor },
+      })
+      setTransactions((prev) => [...prev, ...(res.data.transactions ?? [])])
+      setNextCursor(res.data.nextCursor)
+    } finally {
+      setLoadingMore(false)
+    }
+  }
 
   useEffect(() => {
     fetchTransactions()
@@ -102,7 +122,7 @@ export default function TransactionsPage() {
     )
   }, [])
 
-  function getCategoryName(id: string) {
+  function getCategoryName(id?: string) {
     return categories.find((c) => c.id === id)?.name ?? "—"
   }
 
@@ -122,7 +142,7 @@ export default function TransactionsPage() {
       amount: centsToInput(Math.abs(tx.amount)),
       type: tx.type === "income" ? "income" : "expense",
       accountId: tx.accountId,
-      categoryId: tx.categoryId,
+      categoryId: tx.categoryId ?? "",
       description: tx.description ?? "",
       date: tx.date.split("T")[0],
       isShared: tx.isShared,
@@ -150,7 +170,7 @@ export default function TransactionsPage() {
         amount,
         type: form.type,
         accountId: form.accountId,
-        categoryId: form.categoryId,
+        categoryId: form.categoryId || undefined,
         description: form.description,
         date: form.date,
         isShared: form.isShared,
@@ -331,6 +351,14 @@ export default function TransactionsPage() {
         </div>
       )}
 
+      {nextCursor && (
+        <div className="flex justify-center">
+          <Button variant="outline" size="sm" onClick={loadMore} disabled={loadingMore}>
+            {loadingMore ? t("common.loading") : t("common.loadMore")}
+          </Button>
+        </div>
+      )}
+
       {/* Create/Edit Dialog */}
       <Dialog open={dialogOpen} onOpenChange={setDialogOpen}>
         <DialogContent>
@@ -466,7 +494,7 @@ export default function TransactionsPage() {
             <Button
               onClick={handleSubmit}
               disabled={
-                submitting || !form.amount || !form.accountId || !form.categoryId
+                submitting || !form.amount || !form.accountId
               }
             >
               {submitting ? t("common.saving") : t("common.save")}